@@ -0,0 +1,81 @@
+package approval
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/audit"
+	"github.com/WeAreAmazingTeam/tcd-backend/rbac"
+)
+
+const (
+	ActionWithdrawalApprove = "withdrawal_approve"
+	ActionCashFlowCreate    = "cash_flow_create"
+)
+
+const (
+	StatusPending  = "pending"
+	StatusApproved = "approved"
+	StatusDeclined = "declined"
+	// approved, but the action could not be carried out anymore, e.g. the owner cancelled the withdrawal meanwhile
+	StatusFailed = "failed"
+)
+
+var (
+	ErrSameAdmin       = errors.New("the admin who asked for the action can not decide on it, another admin has to")
+	ErrNotAllowed      = errors.New("you don't have permission to carry out this action")
+	ErrActionFailed    = errors.New("the approval was recorded but its action could not be carried out")
+	ErrAlreadyRequired = errors.New("the action is already waiting for approval")
+)
+
+// Approval is an action above the threshold held back until a second admin approves it. The
+// maker asked for it, the checker decided on it, and Payload is the original request to replay.
+type Approval struct {
+	ID            int
+	Action        string
+	EntityType    string
+	EntityID      int
+	Amount        int64
+	Payload       string
+	Status        string
+	MakerID       int
+	CheckerID     int
+	DeclineReason sql.NullString
+	FailureReason sql.NullString
+	DecidedAt     sql.NullTime
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+type (
+	withdrawalPayload struct {
+		Status string `json:"status"`
+		Reason string `json:"reason"`
+	}
+
+	cashFlowPayload struct {
+		Status string `json:"status"`
+		Amount int64  `json:"amount"`
+		Note   string `json:"note"`
+	}
+)
+
+func (Approval) TableName() string {
+	return "approvals"
+}
+
+// Permission is what the checker needs besides deciding on approvals, the same one the action's own endpoint asks for
+func (approval Approval) Permission() string {
+	if approval.Action == ActionCashFlowCreate {
+		return rbac.PermissionCashFlowManage
+	}
+	return rbac.PermissionWithdrawalApprove
+}
+
+func entityType(action string) string {
+	if action == ActionCashFlowCreate {
+		return audit.EntityCompanyCashFlow
+	}
+	return audit.EntityWithdrawalRequest
+}
@@ -0,0 +1,47 @@
+package approval
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type ApprovalFormatter struct {
+	ID            int            `json:"id"`
+	Action        string         `json:"action"`
+	EntityType    string         `json:"entity_type"`
+	EntityID      int            `json:"entity_id"`
+	Amount        int64          `json:"amount"`
+	Payload       map[string]any `json:"payload"`
+	Status        string         `json:"status"`
+	MakerID       int            `json:"maker_id"`
+	CheckerID     int            `json:"checker_id"`
+	DeclineReason string         `json:"decline_reason"`
+	FailureReason string         `json:"failure_reason"`
+	DecidedAt     *time.Time     `json:"decided_at"`
+	CreatedAt     time.Time      `json:"created_at"`
+}
+
+func FormatApprovalData(approval Approval) ApprovalFormatter {
+	formatData := ApprovalFormatter{
+		ID:            approval.ID,
+		Action:        approval.Action,
+		EntityType:    approval.EntityType,
+		EntityID:      approval.EntityID,
+		Amount:        approval.Amount,
+		Payload:       map[string]any{},
+		Status:        approval.Status,
+		MakerID:       approval.MakerID,
+		CheckerID:     approval.CheckerID,
+		DeclineReason: approval.DeclineReason.String,
+		FailureReason: approval.FailureReason.String,
+		CreatedAt:     approval.CreatedAt,
+	}
+
+	json.Unmarshal([]byte(approval.Payload), &formatData.Payload)
+
+	if approval.DecidedAt.Valid {
+		formatData.DecidedAt = &approval.DecidedAt.Time
+	}
+
+	return formatData
+}
@@ -0,0 +1,51 @@
+package approval
+
+const (
+	QueryAdminDataTablesApprovals = `
+		SELECT
+			approvals.id,
+			approvals.action,
+			approvals.entity_type,
+			approvals.entity_id,
+			approvals.amount,
+			approvals.payload,
+			approvals.status,
+			approvals.maker_id,
+			COALESCE(makers.name, '') AS maker_name,
+			approvals.checker_id,
+			COALESCE(checkers.name, '') AS checker_name,
+			approvals.decline_reason,
+			approvals.failure_reason,
+			approvals.decided_at,
+			approvals.created_at
+		FROM
+			approvals
+		LEFT JOIN
+			users makers
+		ON
+			makers.id = approvals.maker_id
+		LEFT JOIN
+			users checkers
+		ON
+			checkers.id = approvals.checker_id
+		WHERE
+			1 = 1
+	`
+
+	QueryCountAllAdminDataTablesApprovals = `
+		SELECT
+			COUNT(approvals.id) AS count_id
+		FROM
+			approvals
+		LEFT JOIN
+			users makers
+		ON
+			makers.id = approvals.maker_id
+		LEFT JOIN
+			users checkers
+		ON
+			checkers.id = approvals.checker_id
+		WHERE
+			1 = 1
+	`
+)
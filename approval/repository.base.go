@@ -0,0 +1,24 @@
+package approval
+
+import (
+	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	GetApprovalByID(id int) (Approval, error)
+	GetPendingApproval(action string, entityID int) (Approval, error)
+	SaveApproval(Approval) (Approval, error)
+	UpdateApproval(approval, before Approval) (bool, error)
+
+	AdminDataTablesApprovals(*gin.Context) (helper.DataTables, error)
+}
+
+type repository struct {
+	DB *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *repository {
+	return &repository{DB: db}
+}
@@ -0,0 +1,202 @@
+package approval
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+	"github.com/gin-gonic/gin"
+)
+
+func (repo *repository) GetApprovalByID(id int) (approval Approval, err error) {
+	if err := repo.DB.Where("id = ?", id).Find(&approval).Error; err != nil {
+		return approval, err
+	}
+
+	if approval.ID == 0 {
+		return approval, errors.New("sql: no rows in result set")
+	}
+
+	return approval, nil
+}
+
+func (repo *repository) GetPendingApproval(action string, entityID int) (approval Approval, err error) {
+	if err := repo.DB.Where("action = ? AND entity_id = ? AND status = ?", action, entityID, StatusPending).Order("id DESC").Limit(1).Find(&approval).Error; err != nil {
+		return approval, err
+	}
+
+	if approval.ID == 0 {
+		return approval, errors.New("sql: no rows in result set")
+	}
+
+	return approval, nil
+}
+
+func (repo *repository) SaveApproval(approval Approval) (Approval, error) {
+	if err := repo.DB.Create(&approval).Error; err != nil {
+		return approval, err
+	}
+	return approval, nil
+}
+
+// UpdateApproval only writes when the row is still in the status it was read with, so two admins can not both decide
+func (repo *repository) UpdateApproval(approval, before Approval) (bool, error) {
+	result := repo.DB.Model(&Approval{}).
+		Where("id = ? AND status = ?", before.ID, before.Status).
+		Select("*").
+		Omit("id", "created_at").
+		Updates(&approval)
+
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (repo *repository) AdminDataTablesApprovals(ctx *gin.Context) (result helper.DataTables, err error) {
+	var (
+		query string = QueryAdminDataTablesApprovals
+		where string = ""
+		order string = ""
+		limit string = ""
+	)
+
+	var (
+		no       int = 1
+		total    int = 0
+		filtered int = 0
+	)
+
+	var (
+		data []map[string]any
+		args []any
+	)
+
+	listOrder := []string{"", "approvals.action", "approvals.amount", "approvals.status", "makers.name", "checkers.name", "approvals.created_at", ""}
+
+	if status := ctx.Query("status"); status != "" {
+		where = fmt.Sprintf("%s AND approvals.status = ?", where)
+		args = append(args, status)
+	}
+
+	if action := ctx.Query("action"); action != "" {
+		where = fmt.Sprintf("%s AND approvals.action = ?", where)
+		args = append(args, action)
+	}
+
+	if searchValue := ctx.Query("search[value]"); searchValue != "" {
+		where = fmt.Sprintf("%s AND (makers.name LIKE ? OR checkers.name LIKE ? OR approvals.amount LIKE ? OR approvals.entity_id LIKE ?)", where)
+		for i := 0; i < 4; i++ {
+			args = append(args, "%"+searchValue+"%")
+		}
+	}
+
+	orderColumn := ctx.Query("order[0][column]")
+	starting, _ := strconv.Atoi(ctx.Query("start"))
+
+	if orderColumn != "" {
+		orderType := "ASC"
+		orderColumn, _ := strconv.Atoi(orderColumn)
+
+		if strings.ToUpper(ctx.Query("order[0][dir]")) == "DESC" {
+			orderType = "DESC"
+		}
+
+		if orderColumn > 0 && orderColumn < len(listOrder) && listOrder[orderColumn] != "" {
+			order = fmt.Sprintf("ORDER BY %s %s", listOrder[orderColumn], orderType)
+		} else {
+			order = "ORDER BY approvals.id DESC"
+		}
+	} else {
+		order = "ORDER BY approvals.id DESC"
+	}
+
+	if starting != -1 {
+		length, _ := strconv.Atoi(ctx.Query("length"))
+		limit = fmt.Sprintf("LIMIT %v OFFSET %v", length, starting)
+		no = starting + 1
+	}
+
+	if err := repo.DB.Raw(helper.ConvertToInLineQuery(QueryCountAllAdminDataTablesApprovals)).Scan(&total).Error; err != nil {
+		return result, err
+	}
+
+	if where != "" {
+		query = query + where
+
+		if err := repo.DB.Raw(helper.ConvertToInLineQuery(QueryCountAllAdminDataTablesApprovals)+where, args...).Scan(&filtered).Error; err != nil {
+			return result, err
+		}
+	} else {
+		filtered = total
+	}
+
+	query = fmt.Sprintf("%s %s %s", query, order, limit)
+
+	rows, err := repo.DB.Raw(helper.ConvertToInLineQuery(query), args...).Rows()
+
+	if err != nil {
+		return result, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			makerName   string
+			checkerName string
+		)
+
+		tmp := Approval{}
+
+		err := rows.Scan(
+			&tmp.ID,
+			&tmp.Action,
+			&tmp.EntityType,
+			&tmp.EntityID,
+			&tmp.Amount,
+			&tmp.Payload,
+			&tmp.Status,
+			&tmp.MakerID,
+			&makerName,
+			&tmp.CheckerID,
+			&checkerName,
+			&tmp.DeclineReason,
+			&tmp.FailureReason,
+			&tmp.DecidedAt,
+			&tmp.CreatedAt,
+		)
+
+		if err != nil {
+			return result, err
+		}
+
+		formatData := FormatApprovalData(tmp)
+
+		data = append(data, map[string]any{
+			"no":             no,
+			"id":             formatData.ID,
+			"action":         formatData.Action,
+			"entity_type":    formatData.EntityType,
+			"entity_id":      formatData.EntityID,
+			"amount":         formatData.Amount,
+			"payload":        formatData.Payload,
+			"status":         formatData.Status,
+			"maker_id":       formatData.MakerID,
+			"maker_name":     makerName,
+			"checker_id":     formatData.CheckerID,
+			"checker_name":   checkerName,
+			"decline_reason": formatData.DeclineReason,
+			"failure_reason": formatData.FailureReason,
+			"decided_at":     formatData.DecidedAt,
+			"created_at":     formatData.CreatedAt,
+		})
+
+		no++
+	}
+
+	return helper.BuildDatatTables(data, filtered, total), nil
+}
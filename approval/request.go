@@ -0,0 +1,15 @@
+package approval
+
+import "github.com/WeAreAmazingTeam/tcd-backend/user"
+
+type (
+	RequestGetApprovalByID struct {
+		ID int `uri:"id" binding:"required"`
+	}
+
+	RequestDecideApproval struct {
+		Status string `json:"status" binding:"required,oneof=approved declined"`
+		Reason string `json:"reason"`
+		User   user.User
+	}
+)
@@ -0,0 +1,53 @@
+package approval
+
+import (
+	"github.com/WeAreAmazingTeam/tcd-backend/audit"
+	"github.com/WeAreAmazingTeam/tcd-backend/company"
+	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+	"github.com/WeAreAmazingTeam/tcd-backend/rbac"
+	"github.com/WeAreAmazingTeam/tcd-backend/user"
+	"github.com/gin-gonic/gin"
+)
+
+type Service interface {
+	SubmitWithdrawalUpdate(user.RequestGetUserWithdrawalRequestByID, user.RequestUpdateUserWithdrawalRequest) (approval Approval, required bool, err error)
+	SubmitCompanyCashFlow(company.RequestCreateCompanyCashFlow) (approval Approval, required bool, err error)
+
+	GetApprovalByID(RequestGetApprovalByID) (Approval, error)
+	DecideApproval(RequestGetApprovalByID, RequestDecideApproval) (Approval, error)
+
+	AdminDataTablesApprovals(*gin.Context) (helper.DataTables, error)
+}
+
+// Config is the amount from which each action needs a second admin, 0 lets the action through right away
+type Config struct {
+	WithdrawalThreshold int64
+	CashFlowThreshold   int64
+}
+
+type service struct {
+	repo       Repository
+	userSvc    user.Service
+	companySvc company.Service
+	rbacSvc    rbac.Service
+	config     Config
+	auditSvc   audit.Service
+}
+
+func NewService(
+	repository Repository,
+	userService user.Service,
+	companyService company.Service,
+	rbacService rbac.Service,
+	config Config,
+	auditService audit.Service,
+) *service {
+	return &service{
+		repo:       repository,
+		userSvc:    userService,
+		companySvc: companyService,
+		rbacSvc:    rbacService,
+		config:     config,
+		auditSvc:   auditService,
+	}
+}
@@ -0,0 +1,217 @@
+package approval
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/audit"
+	"github.com/WeAreAmazingTeam/tcd-backend/company"
+	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+	"github.com/WeAreAmazingTeam/tcd-backend/user"
+	"github.com/gin-gonic/gin"
+)
+
+// SubmitWithdrawalUpdate holds back approving a withdrawal from the threshold, any other change goes through right away
+func (svc *service) SubmitWithdrawalUpdate(reqDetail user.RequestGetUserWithdrawalRequestByID, reqUpdate user.RequestUpdateUserWithdrawalRequest) (Approval, bool, error) {
+	if reqUpdate.Status != user.WithdrawalStatusApproved || svc.config.WithdrawalThreshold <= 0 {
+		return Approval{}, false, nil
+	}
+
+	userWithdrawalRequest, err := svc.userSvc.GetWithdrawalRequestByID(reqDetail.ID)
+
+	if err != nil {
+		return Approval{}, false, err
+	}
+
+	// a request that can not be approved anyway gets its error from the update itself
+	if userWithdrawalRequest.Amount < svc.config.WithdrawalThreshold || !userWithdrawalRequest.CanMoveTo(reqUpdate.Status) {
+		return Approval{}, false, nil
+	}
+
+	approval, err := svc.submit(ActionWithdrawalApprove, userWithdrawalRequest.ID, userWithdrawalRequest.Amount, withdrawalPayload{
+		Status: reqUpdate.Status,
+		Reason: reqUpdate.Reason,
+	}, reqUpdate.User)
+
+	return approval, true, err
+}
+
+func (svc *service) SubmitCompanyCashFlow(req company.RequestCreateCompanyCashFlow) (Approval, bool, error) {
+	if svc.config.CashFlowThreshold <= 0 || req.Amount < svc.config.CashFlowThreshold {
+		return Approval{}, false, nil
+	}
+
+	// the cash flow does not exist until it is approved, the entity id is filled in then
+	approval, err := svc.submit(ActionCashFlowCreate, 0, req.Amount, cashFlowPayload{
+		Status: req.Status,
+		Amount: req.Amount,
+		Note:   req.Note,
+	}, req.User)
+
+	return approval, true, err
+}
+
+func (svc *service) submit(action string, entityID int, amount int64, payload any, maker user.User) (Approval, error) {
+	if entityID != 0 {
+		if _, err := svc.repo.GetPendingApproval(action, entityID); err == nil {
+			return Approval{}, ErrAlreadyRequired
+		} else if !helper.IsErrNoRows(err.Error()) {
+			return Approval{}, err
+		}
+	}
+
+	body, err := json.Marshal(payload)
+
+	if err != nil {
+		return Approval{}, err
+	}
+
+	approval, err := svc.repo.SaveApproval(Approval{
+		Action:     action,
+		EntityType: entityType(action),
+		EntityID:   entityID,
+		Amount:     amount,
+		Payload:    string(body),
+		Status:     StatusPending,
+		MakerID:    maker.ID,
+	})
+
+	if err != nil {
+		return approval, err
+	}
+
+	audit.Record(svc.auditSvc, maker, audit.ActionCreate, audit.EntityApproval, approval.ID, nil, approval)
+
+	return approval, nil
+}
+
+func (svc *service) GetApprovalByID(req RequestGetApprovalByID) (Approval, error) {
+	return svc.repo.GetApprovalByID(req.ID)
+}
+
+// DecideApproval carries out the held back action as the checker when it is approved, the
+// approval is claimed first so two checkers deciding at once can not both run it
+func (svc *service) DecideApproval(reqDetail RequestGetApprovalByID, reqDecide RequestDecideApproval) (Approval, error) {
+	approval, err := svc.repo.GetApprovalByID(reqDetail.ID)
+
+	if err != nil {
+		return approval, err
+	}
+
+	if approval.Status != StatusPending {
+		return approval, fmt.Errorf("the approval is already %v", approval.Status)
+	}
+
+	if approval.MakerID == reqDecide.User.ID {
+		return approval, ErrSameAdmin
+	}
+
+	allowed, err := svc.rbacSvc.HasPermission(reqDecide.User.Role, approval.Permission())
+
+	if err != nil {
+		return approval, err
+	}
+
+	if !allowed {
+		return approval, ErrNotAllowed
+	}
+
+	before := approval
+
+	approval.Status = reqDecide.Status
+	approval.CheckerID = reqDecide.User.ID
+	approval.DecidedAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+	if reqDecide.Status == StatusDeclined {
+		approval.DeclineReason = sql.NullString{String: reqDecide.Reason, Valid: reqDecide.Reason != ""}
+	}
+
+	updated, err := svc.repo.UpdateApproval(approval, before)
+
+	if err != nil {
+		return before, err
+	}
+
+	if !updated {
+		return before, fmt.Errorf("the approval was decided by someone else, reload and try again")
+	}
+
+	if approval.Status == StatusDeclined {
+		audit.Record(svc.auditSvc, reqDecide.User, audit.ActionUpdate, audit.EntityApproval, approval.ID, before, approval)
+		return approval, nil
+	}
+
+	decided := approval
+	entityID, execErr := svc.execute(approval, reqDecide.User)
+
+	if execErr != nil {
+		approval.Status = StatusFailed
+		approval.FailureReason = helper.SetNS(execErr.Error())
+	} else {
+		approval.EntityID = entityID
+	}
+
+	if _, err := svc.repo.UpdateApproval(approval, decided); err != nil {
+		return approval, err
+	}
+
+	audit.Record(svc.auditSvc, reqDecide.User, audit.ActionUpdate, audit.EntityApproval, approval.ID, before, approval)
+
+	if execErr != nil {
+		return approval, fmt.Errorf("%w, %s", ErrActionFailed, execErr.Error())
+	}
+
+	return approval, nil
+}
+
+// execute replays the maker's request with the checker as the actor and returns the id of what it changed
+func (svc *service) execute(approval Approval, checker user.User) (int, error) {
+	switch approval.Action {
+	case ActionWithdrawalApprove:
+		var payload withdrawalPayload
+
+		if err := json.Unmarshal([]byte(approval.Payload), &payload); err != nil {
+			return 0, err
+		}
+
+		reqDetail := user.RequestGetUserWithdrawalRequestByID{}
+		reqDetail.ID = approval.EntityID
+
+		userWithdrawalRequest, err := svc.userSvc.UpdateUserWithdrawalRequest(reqDetail, user.RequestUpdateUserWithdrawalRequest{
+			Status: payload.Status,
+			Reason: payload.Reason,
+			User:   checker,
+		})
+
+		return userWithdrawalRequest.ID, err
+	case ActionCashFlowCreate:
+		var payload cashFlowPayload
+
+		if err := json.Unmarshal([]byte(approval.Payload), &payload); err != nil {
+			return 0, err
+		}
+
+		companyCashFlow, err := svc.companySvc.CreateCompanyCashFlow(company.RequestCreateCompanyCashFlow{
+			Status: payload.Status,
+			Amount: payload.Amount,
+			Note:   payload.Note,
+			User:   checker,
+		})
+
+		return companyCashFlow.ID, err
+	}
+
+	return 0, fmt.Errorf("unknown approval action %v", approval.Action)
+}
+
+func (svc *service) AdminDataTablesApprovals(ctx *gin.Context) (helper.DataTables, error) {
+	dataTablesApprovals, err := svc.repo.AdminDataTablesApprovals(ctx)
+
+	if err != nil {
+		return dataTablesApprovals, err
+	}
+
+	return dataTablesApprovals, nil
+}
@@ -0,0 +1,117 @@
+package audit
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+const redacted = "[redacted]"
+
+// field names are compared after lowercasing and removing underscores
+var (
+	// bookkeeping fields, their changes are implied by the audit row itself
+	ignoredFields = map[string]bool{
+		"createdat":    true,
+		"createdby":    true,
+		"updatedat":    true,
+		"updatedby":    true,
+		"lastusedstep": true,
+	}
+
+	// secrets, only the fact that they changed is recorded
+	redactedFields = map[string]bool{
+		"password":  true,
+		"secret":    true,
+		"tokenhash": true,
+		"codehash":  true,
+	}
+)
+
+// Diff compares two values of the same entity field by field using their json form,
+// before is nil for a create and after is nil for a delete
+func Diff(before, after any) (map[string]Change, error) {
+	beforeFields, err := toFields(before)
+
+	if err != nil {
+		return nil, err
+	}
+
+	afterFields, err := toFields(after)
+
+	if err != nil {
+		return nil, err
+	}
+
+	changes := map[string]Change{}
+
+	for key, oldValue := range beforeFields {
+		if isIgnoredField(key) {
+			continue
+		}
+
+		newValue, ok := afterFields[key]
+
+		if !ok || !reflect.DeepEqual(oldValue, newValue) {
+			changes[key] = newChange(key, oldValue, newValue)
+		}
+	}
+
+	for key, newValue := range afterFields {
+		if isIgnoredField(key) {
+			continue
+		}
+
+		if _, ok := beforeFields[key]; !ok {
+			changes[key] = newChange(key, nil, newValue)
+		}
+	}
+
+	return changes, nil
+}
+
+func toFields(value any) (map[string]any, error) {
+	fields := map[string]any{}
+
+	if value == nil {
+		return fields, nil
+	}
+
+	encoded, err := json.Marshal(value)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(encoded, &fields); err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
+
+func newChange(key string, oldValue, newValue any) Change {
+	if !redactedFields[normalizeField(key)] {
+		return Change{Old: oldValue, New: newValue}
+	}
+
+	change := Change{}
+
+	if oldValue != nil {
+		change.Old = redacted
+	}
+
+	if newValue != nil {
+		change.New = redacted
+	}
+
+	return change
+}
+
+func isIgnoredField(key string) bool {
+	return ignoredFields[normalizeField(key)]
+}
+
+func normalizeField(key string) string {
+	return strings.ReplaceAll(strings.ToLower(key), "_", "")
+}
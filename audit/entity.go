@@ -0,0 +1,57 @@
+package audit
+
+import "time"
+
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+
+	// ActorSystem is the role written for changes made by cron jobs and payment callbacks
+	ActorSystem = "system"
+)
+
+const (
+	EntityUser                   = "user"
+	EntityUserTwoFactor          = "user_two_factor"
+	EntityWithdrawalRequest      = "withdrawal_request"
+	EntityCampaign               = "campaign"
+	EntityCampaignImage          = "campaign_image"
+	EntityCampaignCategory       = "campaign_category"
+	EntityExclusiveCampaign      = "exclusive_campaign"
+	EntityTransaction            = "transaction"
+	EntityCompanyCashFlow        = "company_cash_flow"
+	EntityRole                   = "role"
+	EntityEmailTemplate          = "email_template"
+	EntityEmailSuppression       = "email_suppression"
+	EntityNotificationPreference = "notification_preference"
+	EntityWebhookSubscription    = "webhook_subscription"
+	EntityWebhookDelivery        = "webhook_delivery"
+	EntityEventOutbox            = "event_outbox"
+	EntityCampaignFinalization   = "campaign_finalization"
+	EntityScheduledJob           = "scheduled_job"
+	EntityQueuedJob              = "queued_job"
+	EntityExclusiveDraw          = "exclusive_draw"
+	EntityExclusiveWinner        = "exclusive_campaign_winner"
+	EntityUserBankAccount        = "user_bank_account"
+	EntityPayoutBatch            = "payout_batch"
+	EntityApproval               = "approval"
+)
+
+type (
+	AuditLog struct {
+		ID         int       `json:"id"`
+		ActorID    int       `json:"actor_id"`
+		ActorRole  string    `json:"actor_role"`
+		Action     string    `json:"action"`
+		EntityType string    `json:"entity_type"`
+		EntityID   int       `json:"entity_id"`
+		Changes    string    `json:"changes"`
+		CreatedAt  time.Time `json:"created_at"`
+	}
+
+	Change struct {
+		Old any `json:"old"`
+		New any `json:"new"`
+	}
+)
@@ -0,0 +1,46 @@
+package audit
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type AuditLogFormatter struct {
+	ID         int               `json:"id"`
+	ActorID    int               `json:"actor_id"`
+	ActorRole  string            `json:"actor_role"`
+	Action     string            `json:"action"`
+	EntityType string            `json:"entity_type"`
+	EntityID   int               `json:"entity_id"`
+	Changes    map[string]Change `json:"changes"`
+	CreatedAt  time.Time         `json:"created_at"`
+}
+
+func FormatAuditLogData(auditLog AuditLog) AuditLogFormatter {
+	formatData := AuditLogFormatter{
+		ID:         auditLog.ID,
+		ActorID:    auditLog.ActorID,
+		ActorRole:  auditLog.ActorRole,
+		Action:     auditLog.Action,
+		EntityType: auditLog.EntityType,
+		EntityID:   auditLog.EntityID,
+		Changes:    map[string]Change{},
+		CreatedAt:  auditLog.CreatedAt,
+	}
+
+	_ = json.Unmarshal([]byte(auditLog.Changes), &formatData.Changes)
+
+	return formatData
+}
+
+func FormatListAuditLogData(auditLogs []AuditLog) (response []AuditLogFormatter) {
+	for _, val := range auditLogs {
+		response = append(response, FormatAuditLogData(val))
+	}
+
+	if len(response) == 0 {
+		return []AuditLogFormatter{}
+	}
+
+	return response
+}
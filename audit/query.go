@@ -0,0 +1,28 @@
+package audit
+
+const (
+	QueryAdminDataTablesAuditLogs = `
+		SELECT
+			id,
+			actor_id,
+			actor_role,
+			action,
+			entity_type,
+			entity_id,
+			changes,
+			created_at
+		FROM
+			audit_logs
+		WHERE
+			1 = 1
+	`
+
+	QueryCountAllAdminDataTablesAuditLogs = `
+		SELECT
+			COUNT(id) AS count_id
+		FROM
+			audit_logs
+		WHERE
+			1 = 1
+	`
+)
@@ -0,0 +1,22 @@
+package audit
+
+import (
+	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	SaveAuditLog(AuditLog) (AuditLog, error)
+	GetAuditLogByEntity(entityType string, entityID int) ([]AuditLog, error)
+
+	AdminDataTablesAuditLogs(ctx *gin.Context) (helper.DataTables, error)
+}
+
+type repository struct {
+	DB *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *repository {
+	return &repository{DB: db}
+}
@@ -0,0 +1,154 @@
+package audit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+	"github.com/gin-gonic/gin"
+)
+
+func (repo *repository) SaveAuditLog(auditLog AuditLog) (AuditLog, error) {
+	if err := repo.DB.Create(&auditLog).Error; err != nil {
+		return auditLog, err
+	}
+	return auditLog, nil
+}
+
+func (repo *repository) GetAuditLogByEntity(entityType string, entityID int) (auditLogs []AuditLog, err error) {
+	if err := repo.DB.Where("entity_type = ? AND entity_id = ?", entityType, entityID).Order("id ASC").Find(&auditLogs).Error; err != nil {
+		return auditLogs, err
+	}
+	return auditLogs, nil
+}
+
+// besides the datatables params it filters by actor_id, actor_role, action, entity_type and entity_id query params
+func (repo *repository) AdminDataTablesAuditLogs(ctx *gin.Context) (result helper.DataTables, err error) {
+	var (
+		query string = QueryAdminDataTablesAuditLogs
+		where string = ""
+		order string = ""
+		limit string = ""
+	)
+
+	var (
+		no       int = 1
+		total    int = 0
+		filtered int = 0
+	)
+
+	var (
+		data []map[string]any
+		args []any
+	)
+
+	listOrder := []string{"", "actor_id", "actor_role", "action", "entity_type", "entity_id", "created_at", ""}
+
+	for _, column := range []string{"actor_id", "entity_id"} {
+		if value, err := strconv.Atoi(ctx.Query(column)); err == nil {
+			where = fmt.Sprintf("%s AND %s = ?", where, column)
+			args = append(args, value)
+		}
+	}
+
+	for _, column := range []string{"actor_role", "action", "entity_type"} {
+		if value := ctx.Query(column); value != "" {
+			where = fmt.Sprintf("%s AND %s = ?", where, column)
+			args = append(args, value)
+		}
+	}
+
+	if searchValue := ctx.Query("search[value]"); searchValue != "" {
+		where = fmt.Sprintf("%s AND (actor_role LIKE ? OR action LIKE ? OR entity_type LIKE ? OR changes LIKE ?)", where)
+		for i := 0; i < 4; i++ {
+			args = append(args, "%"+searchValue+"%")
+		}
+	}
+
+	orderColumn := ctx.Query("order[0][column]")
+	starting, _ := strconv.Atoi(ctx.Query("start"))
+
+	if orderColumn != "" {
+		orderType := "ASC"
+		orderColumn, _ := strconv.Atoi(orderColumn)
+
+		if strings.ToUpper(ctx.Query("order[0][dir]")) == "DESC" {
+			orderType = "DESC"
+		}
+
+		if orderColumn > 0 && orderColumn < len(listOrder) && listOrder[orderColumn] != "" {
+			order = fmt.Sprintf("ORDER BY %s %s", listOrder[orderColumn], orderType)
+		} else {
+			order = "ORDER BY id DESC"
+		}
+	} else {
+		order = "ORDER BY id DESC"
+	}
+
+	if starting != -1 {
+		length, _ := strconv.Atoi(ctx.Query("length"))
+		limit = fmt.Sprintf("LIMIT %v OFFSET %v", length, starting)
+		no = starting + 1
+	}
+
+	if err := repo.DB.Raw(helper.ConvertToInLineQuery(QueryCountAllAdminDataTablesAuditLogs)).Scan(&total).Error; err != nil {
+		return result, err
+	}
+
+	if where != "" {
+		query = query + where
+
+		if err := repo.DB.Raw(helper.ConvertToInLineQuery(QueryCountAllAdminDataTablesAuditLogs)+where, args...).Scan(&filtered).Error; err != nil {
+			return result, err
+		}
+	} else {
+		filtered = total
+	}
+
+	query = fmt.Sprintf("%s %s %s", query, order, limit)
+
+	rows, err := repo.DB.Raw(helper.ConvertToInLineQuery(query), args...).Rows()
+
+	if err != nil {
+		return result, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		tmp := AuditLog{}
+		err := rows.Scan(
+			&tmp.ID,
+			&tmp.ActorID,
+			&tmp.ActorRole,
+			&tmp.Action,
+			&tmp.EntityType,
+			&tmp.EntityID,
+			&tmp.Changes,
+			&tmp.CreatedAt,
+		)
+
+		if err != nil {
+			return result, err
+		}
+
+		formatData := FormatAuditLogData(tmp)
+
+		data = append(data, map[string]any{
+			"no":          no,
+			"id":          formatData.ID,
+			"actor_id":    formatData.ActorID,
+			"actor_role":  formatData.ActorRole,
+			"action":      formatData.Action,
+			"entity_type": formatData.EntityType,
+			"entity_id":   formatData.EntityID,
+			"changes":     formatData.Changes,
+			"created_at":  formatData.CreatedAt,
+		})
+
+		no++
+	}
+
+	return helper.BuildDatatTables(data, filtered, total), nil
+}
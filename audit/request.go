@@ -0,0 +1,19 @@
+package audit
+
+type (
+	// RequestRecord describes one change, Before is empty for a create and After is empty for a delete
+	RequestRecord struct {
+		ActorID    int
+		ActorRole  string
+		Action     string
+		EntityType string
+		EntityID   int
+		Before     any
+		After      any
+	}
+
+	RequestGetEntityHistory struct {
+		EntityType string `uri:"entity_type" binding:"required"`
+		EntityID   int    `uri:"entity_id" binding:"required"`
+	}
+)
@@ -0,0 +1,30 @@
+package audit
+
+import (
+	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+	"github.com/gin-gonic/gin"
+)
+
+type Service interface {
+	Record(RequestRecord)
+	GetEntityHistory(RequestGetEntityHistory) ([]AuditLog, error)
+
+	AdminDataTablesAuditLogs(*gin.Context) (helper.DataTables, error)
+}
+
+// Actor is whoever makes a change, user.User is one. The zero actor is recorded as the system.
+type Actor interface {
+	AuditActor() (id int, role string)
+}
+
+type service struct {
+	repo Repository
+}
+
+func NewService(
+	repository Repository,
+) *service {
+	return &service{
+		repo: repository,
+	}
+}
@@ -0,0 +1,82 @@
+package audit
+
+import (
+	"encoding/json"
+	"log"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+	"github.com/gin-gonic/gin"
+)
+
+// Record never fails the caller, like the activity log a lost audit row is only reported to stdout
+func (svc *service) Record(req RequestRecord) {
+	changes, err := Diff(req.Before, req.After)
+
+	if err != nil {
+		log.Printf("audit %s %s %d failed, err: %s", req.Action, req.EntityType, req.EntityID, err.Error())
+		return
+	}
+
+	// an update that touched nothing worth tracking
+	if req.Action == ActionUpdate && len(changes) == 0 {
+		return
+	}
+
+	encodedChanges, err := json.Marshal(changes)
+
+	if err != nil {
+		log.Printf("audit %s %s %d failed, err: %s", req.Action, req.EntityType, req.EntityID, err.Error())
+		return
+	}
+
+	auditLog := AuditLog{}
+	auditLog.ActorID = req.ActorID
+	auditLog.ActorRole = req.ActorRole
+	auditLog.Action = req.Action
+	auditLog.EntityType = req.EntityType
+	auditLog.EntityID = req.EntityID
+	auditLog.Changes = string(encodedChanges)
+
+	if auditLog.ActorRole == "" {
+		auditLog.ActorRole = ActorSystem
+	}
+
+	if _, err := svc.repo.SaveAuditLog(auditLog); err != nil {
+		log.Printf("audit %s %s %d failed, err: %s", req.Action, req.EntityType, req.EntityID, err.Error())
+	}
+}
+
+// Record is how the other services write to the audit log, it fills the request from the actor
+func Record(auditService Service, actor Actor, action, entityType string, entityID int, before, after any) {
+	actorID, actorRole := actor.AuditActor()
+
+	auditService.Record(RequestRecord{
+		ActorID:    actorID,
+		ActorRole:  actorRole,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Before:     before,
+		After:      after,
+	})
+}
+
+func (svc *service) GetEntityHistory(req RequestGetEntityHistory) ([]AuditLog, error) {
+	auditLogs, err := svc.repo.GetAuditLogByEntity(req.EntityType, req.EntityID)
+
+	if err != nil {
+		return auditLogs, err
+	}
+
+	return auditLogs, nil
+}
+
+func (svc *service) AdminDataTablesAuditLogs(ctx *gin.Context) (helper.DataTables, error) {
+	dataTablesAuditLogs, err := svc.repo.AdminDataTablesAuditLogs(ctx)
+
+	if err != nil {
+		return dataTablesAuditLogs, err
+	}
+
+	return dataTablesAuditLogs, nil
+}
@@ -1,10 +1,20 @@
 package auth
 
-import "github.com/dgrijalva/jwt-go"
+import (
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+const ChallengeTokenTTL = 5 * time.Minute
 
 type Service interface {
 	GenerateToken(userID int) (string, error)
+	GenerateTwoFactorToken(userID int) (string, error)
 	ValidateToken(token string) (*jwt.Token, error)
+
+	GenerateChallengeToken(userID int) (string, error)
+	ValidateChallengeToken(token string) (int, error)
 }
 
 type authService struct{}
@@ -2,6 +2,7 @@ package auth
 
 import (
 	"errors"
+	"time"
 
 	"github.com/WeAreAmazingTeam/tcd-backend/constant"
 	"github.com/dgrijalva/jwt-go"
@@ -21,6 +22,61 @@ func (svc *authService) GenerateToken(userID int) (string, error) {
 	return signedToken, nil
 }
 
+// same as GenerateToken, but marks the session as passed the second login step
+func (svc *authService) GenerateTwoFactorToken(userID int) (string, error) {
+	claim := jwt.MapClaims{}
+	claim["the_cloud_donation_user_id"] = userID
+	claim["the_cloud_donation_two_factor"] = true
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claim)
+	signedToken, err := token.SignedString(constant.SecretKey)
+
+	if err != nil {
+		return signedToken, err
+	}
+
+	return signedToken, nil
+}
+
+// short-lived token between the password step and the two-factor step of login,
+// it uses its own claim so it can never be accepted as a session token
+func (svc *authService) GenerateChallengeToken(userID int) (string, error) {
+	claim := jwt.MapClaims{}
+	claim["the_cloud_donation_challenge_user_id"] = userID
+	claim["exp"] = time.Now().Add(ChallengeTokenTTL).Unix()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claim)
+	signedToken, err := token.SignedString(constant.SecretKey)
+
+	if err != nil {
+		return signedToken, err
+	}
+
+	return signedToken, nil
+}
+
+func (svc *authService) ValidateChallengeToken(encodedToken string) (int, error) {
+	token, err := svc.ValidateToken(encodedToken)
+
+	if err != nil {
+		return 0, err
+	}
+
+	claim, ok := token.Claims.(jwt.MapClaims)
+
+	if !ok || !token.Valid {
+		return 0, errors.New("Token invalid")
+	}
+
+	userID, ok := claim["the_cloud_donation_challenge_user_id"].(float64)
+
+	if !ok {
+		return 0, errors.New("Token invalid")
+	}
+
+	return int(userID), nil
+}
+
 func (svc *authService) ValidateToken(encodedToken string) (*jwt.Token, error) {
 	token, err := jwt.Parse(encodedToken, func(token *jwt.Token) (any, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
@@ -1,11 +1,23 @@
 package campaign
 
 import (
+	"database/sql"
 	"time"
 
 	"github.com/WeAreAmazingTeam/tcd-backend/constant"
 )
 
+// reward statuses of an exclusive campaign winner, unclaimed goes to pending once the winner sends the details
+// and to expired when the deadline passes, pending goes to shipped and then delivered
+const (
+	RewardStatusPaid      = "paid"
+	RewardStatusUnclaimed = "unclaimed"
+	RewardStatusPending   = "pending"
+	RewardStatusShipped   = "shipped"
+	RewardStatusDelivered = "delivered"
+	RewardStatusExpired   = "expired"
+)
+
 type (
 	CampaignImage struct {
 		ID           int    `json:"id"`
@@ -38,13 +50,63 @@ type (
 		constant.CreatedUpdatedDeleted
 	}
 
+	// ExclusiveCampaign without prizes draws one winner for Reward, WinnerUserID is the winner of the first pick
 	ExclusiveCampaign struct {
-		ID            int    `json:"id"`
-		CampaignID    int    `json:"campaign_id"`
-		WinnerUserID  int    `json:"winner_user_id"`
-		IsRewardMoney int    `json:"is_reward_money"`
-		Reward        string `json:"reward"`
-		IsPaidOff     int    `json:"is_paid_off"`
+		ID            int                       `json:"id"`
+		CampaignID    int                       `json:"campaign_id"`
+		WinnerUserID  int                       `json:"winner_user_id"`
+		IsRewardMoney int                       `json:"is_reward_money"`
+		Reward        string                    `json:"reward"`
+		IsPaidOff     int                       `json:"is_paid_off"`
+		MinDonation   int64                     `json:"min_donation"`
+		EntryAmount   int64                     `json:"entry_amount"`
+		VerifiedOnly  int                       `json:"verified_only"`
+		Prizes        []ExclusiveCampaignPrize  `json:"prizes" gorm:"-"`
+		Winners       []ExclusiveCampaignWinner `json:"winners" gorm:"-"`
 		constant.CreatedUpdatedDeleted
 	}
+
+	// ExclusiveCampaignPrize is one tier, tier 1 is drawn first
+	ExclusiveCampaignPrize struct {
+		ID                  int       `json:"id"`
+		ExclusiveCampaignID int       `json:"exclusive_campaign_id"`
+		Tier                int       `json:"tier"`
+		Name                string    `json:"name"`
+		IsRewardMoney       int       `json:"is_reward_money"`
+		Reward              string    `json:"reward"`
+		Quantity            int       `json:"quantity"`
+		CreatedAt           time.Time `json:"created_at"`
+		UpdatedAt           time.Time `json:"updated_at"`
+	}
+
+	// ExclusiveCampaignWinner copies the prize it won, the prize tiers can not change after the draw anyway.
+	// A money prize is paid when drawn, any other prize waits for the winner to claim it before ClaimDeadline.
+	ExclusiveCampaignWinner struct {
+		ID                  int            `json:"id"`
+		ExclusiveCampaignID int            `json:"exclusive_campaign_id"`
+		CampaignID          int            `json:"campaign_id"`
+		PrizeID             int            `json:"prize_id"`
+		Tier                int            `json:"tier"`
+		Name                string         `json:"name"`
+		Pick                int            `json:"pick"`
+		UserID              int            `json:"user_id"`
+		IsRewardMoney       int            `json:"is_reward_money"`
+		Reward              string         `json:"reward"`
+		IsPaidOff           int            `json:"is_paid_off"`
+		Status              string         `json:"status"`
+		ClaimDeadline       sql.NullTime   `json:"claim_deadline"`
+		ClaimedAt           sql.NullTime   `json:"claimed_at"`
+		RecipientName       sql.NullString `json:"recipient_name"`
+		RecipientPhone      sql.NullString `json:"recipient_phone"`
+		ShippingAddress     sql.NullString `json:"shipping_address"`
+		Notes               sql.NullString `json:"notes"`
+		Courier             sql.NullString `json:"courier"`
+		TrackingNumber      sql.NullString `json:"tracking_number"`
+		ShippedAt           sql.NullTime   `json:"shipped_at"`
+		DeliveredAt         sql.NullTime   `json:"delivered_at"`
+		ExpiredAt           sql.NullTime   `json:"expired_at"`
+		ReplacesWinnerID    int            `json:"replaces_winner_id"`
+		CreatedAt           time.Time      `json:"created_at"`
+		UpdatedAt           time.Time      `json:"updated_at"`
+	}
 )
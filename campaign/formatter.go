@@ -1,6 +1,7 @@
 package campaign
 
 import (
+	"database/sql"
 	"time"
 )
 
@@ -41,12 +42,68 @@ type (
 	}
 
 	CampaignExclusiveFormatter struct {
+		ID            int                                `json:"id"`
+		CampaignID    int                                `json:"campaign_id"`
+		WinnerUserID  int                                `json:"winner_user_id"`
+		IsRewardMoney int                                `json:"is_reward_money"`
+		Reward        string                             `json:"reward"`
+		IsPaidOff     int                                `json:"is_paid_off"`
+		MinDonation   int64                              `json:"min_donation"`
+		EntryAmount   int64                              `json:"entry_amount"`
+		VerifiedOnly  int                                `json:"verified_only"`
+		Prizes        []CampaignExclusivePrizeFormatter  `json:"prizes"`
+		Winners       []CampaignExclusiveWinnerFormatter `json:"winners"`
+	}
+
+	CampaignExclusivePrizeFormatter struct {
 		ID            int    `json:"id"`
-		CampaignID    int    `json:"campaign_id"`
-		WinnerUserID  int    `json:"winner_user_id"`
+		Tier          int    `json:"tier"`
+		Name          string `json:"name"`
 		IsRewardMoney int    `json:"is_reward_money"`
 		Reward        string `json:"reward"`
-		IsPaidOff     int    `json:"is_paid_off"`
+		Quantity      int    `json:"quantity"`
+	}
+
+	// CampaignExclusiveWinnerFormatter is public, the claim details are only in RewardFormatter
+	CampaignExclusiveWinnerFormatter struct {
+		ID            int        `json:"id"`
+		PrizeID       int        `json:"prize_id"`
+		Tier          int        `json:"tier"`
+		Name          string     `json:"name"`
+		UserID        int        `json:"user_id"`
+		IsRewardMoney int        `json:"is_reward_money"`
+		Reward        string     `json:"reward"`
+		IsPaidOff     int        `json:"is_paid_off"`
+		Status        string     `json:"status"`
+		ClaimDeadline *time.Time `json:"claim_deadline"`
+	}
+
+	// RewardFormatter is a winner with the claim and fulfillment details, for the winner and admins only
+	RewardFormatter struct {
+		ID                  int        `json:"id"`
+		ExclusiveCampaignID int        `json:"exclusive_campaign_id"`
+		CampaignID          int        `json:"campaign_id"`
+		PrizeID             int        `json:"prize_id"`
+		Tier                int        `json:"tier"`
+		Name                string     `json:"name"`
+		UserID              int        `json:"user_id"`
+		IsRewardMoney       int        `json:"is_reward_money"`
+		Reward              string     `json:"reward"`
+		IsPaidOff           int        `json:"is_paid_off"`
+		Status              string     `json:"status"`
+		ClaimDeadline       *time.Time `json:"claim_deadline"`
+		ClaimedAt           *time.Time `json:"claimed_at"`
+		RecipientName       string     `json:"recipient_name"`
+		RecipientPhone      string     `json:"recipient_phone"`
+		ShippingAddress     string     `json:"shipping_address"`
+		Notes               string     `json:"notes"`
+		Courier             string     `json:"courier"`
+		TrackingNumber      string     `json:"tracking_number"`
+		ShippedAt           *time.Time `json:"shipped_at"`
+		DeliveredAt         *time.Time `json:"delivered_at"`
+		ExpiredAt           *time.Time `json:"expired_at"`
+		ReplacesWinnerID    int        `json:"replaces_winner_id"`
+		CreatedAt           time.Time  `json:"created_at"`
 	}
 )
 
@@ -186,6 +243,11 @@ func FormatCampaignExclusiveData(exclusiveCampaign ExclusiveCampaign) (response
 		IsRewardMoney: exclusiveCampaign.IsRewardMoney,
 		Reward:        exclusiveCampaign.Reward,
 		IsPaidOff:     exclusiveCampaign.IsPaidOff,
+		MinDonation:   exclusiveCampaign.MinDonation,
+		EntryAmount:   exclusiveCampaign.EntryAmount,
+		VerifiedOnly:  exclusiveCampaign.VerifiedOnly,
+		Prizes:        formatCampaignExclusivePrizes(exclusiveCampaign.Prizes),
+		Winners:       formatCampaignExclusiveWinners(exclusiveCampaign.Winners),
 	}
 
 	return response
@@ -200,6 +262,11 @@ func FormatMultipleCampaignExclusiveData(exclusiveCampaigns []ExclusiveCampaign)
 		tmp.IsRewardMoney = val.IsRewardMoney
 		tmp.Reward = val.Reward
 		tmp.IsPaidOff = val.IsPaidOff
+		tmp.MinDonation = val.MinDonation
+		tmp.EntryAmount = val.EntryAmount
+		tmp.VerifiedOnly = val.VerifiedOnly
+		tmp.Prizes = formatCampaignExclusivePrizes(val.Prizes)
+		tmp.Winners = formatCampaignExclusiveWinners(val.Winners)
 
 		response = append(response, tmp)
 	}
@@ -210,3 +277,88 @@ func FormatMultipleCampaignExclusiveData(exclusiveCampaigns []ExclusiveCampaign)
 
 	return response
 }
+
+func formatCampaignExclusivePrizes(prizes []ExclusiveCampaignPrize) []CampaignExclusivePrizeFormatter {
+	response := []CampaignExclusivePrizeFormatter{}
+
+	for _, prize := range prizes {
+		response = append(response, CampaignExclusivePrizeFormatter{
+			ID:            prize.ID,
+			Tier:          prize.Tier,
+			Name:          prize.Name,
+			IsRewardMoney: prize.IsRewardMoney,
+			Reward:        prize.Reward,
+			Quantity:      prize.Quantity,
+		})
+	}
+
+	return response
+}
+
+func formatCampaignExclusiveWinners(winners []ExclusiveCampaignWinner) []CampaignExclusiveWinnerFormatter {
+	response := []CampaignExclusiveWinnerFormatter{}
+
+	for _, winner := range winners {
+		response = append(response, CampaignExclusiveWinnerFormatter{
+			ID:            winner.ID,
+			PrizeID:       winner.PrizeID,
+			Tier:          winner.Tier,
+			Name:          winner.Name,
+			UserID:        winner.UserID,
+			IsRewardMoney: winner.IsRewardMoney,
+			Reward:        winner.Reward,
+			IsPaidOff:     winner.IsPaidOff,
+			Status:        winner.Status,
+			ClaimDeadline: nullTime(winner.ClaimDeadline),
+		})
+	}
+
+	return response
+}
+
+func FormatRewardData(winner ExclusiveCampaignWinner) RewardFormatter {
+	return RewardFormatter{
+		ID:                  winner.ID,
+		ExclusiveCampaignID: winner.ExclusiveCampaignID,
+		CampaignID:          winner.CampaignID,
+		PrizeID:             winner.PrizeID,
+		Tier:                winner.Tier,
+		Name:                winner.Name,
+		UserID:              winner.UserID,
+		IsRewardMoney:       winner.IsRewardMoney,
+		Reward:              winner.Reward,
+		IsPaidOff:           winner.IsPaidOff,
+		Status:              winner.Status,
+		ClaimDeadline:       nullTime(winner.ClaimDeadline),
+		ClaimedAt:           nullTime(winner.ClaimedAt),
+		RecipientName:       winner.RecipientName.String,
+		RecipientPhone:      winner.RecipientPhone.String,
+		ShippingAddress:     winner.ShippingAddress.String,
+		Notes:               winner.Notes.String,
+		Courier:             winner.Courier.String,
+		TrackingNumber:      winner.TrackingNumber.String,
+		ShippedAt:           nullTime(winner.ShippedAt),
+		DeliveredAt:         nullTime(winner.DeliveredAt),
+		ExpiredAt:           nullTime(winner.ExpiredAt),
+		ReplacesWinnerID:    winner.ReplacesWinnerID,
+		CreatedAt:           winner.CreatedAt,
+	}
+}
+
+func FormatMultipleRewardData(winners []ExclusiveCampaignWinner) []RewardFormatter {
+	response := []RewardFormatter{}
+
+	for _, winner := range winners {
+		response = append(response, FormatRewardData(winner))
+	}
+
+	return response
+}
+
+func nullTime(value sql.NullTime) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+
+	return &value.Time
+}
@@ -0,0 +1,49 @@
+package campaign
+
+import (
+	"log"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/jobqueue"
+)
+
+const JobTypeSelectExclusiveWinner = "campaign.select_exclusive_winner"
+
+// SelectExclusiveWinner is queued by the finalization of a finished exclusive campaign
+type SelectExclusiveWinner struct {
+	CampaignID int `json:"campaign_id"`
+}
+
+func (SelectExclusiveWinner) JobType() string { return JobTypeSelectExclusiveWinner }
+
+// RegisterJobHandlers hangs the campaign jobs on the queue, a job may run more than once
+// so each handler checks the state it changes
+func (svc *service) RegisterJobHandlers(registry *jobqueue.Registry) {
+	jobqueue.Handle(registry, svc.selectExclusiveWinner)
+}
+
+func (svc *service) selectExclusiveWinner(job SelectExclusiveWinner) error {
+	exclusiveCampaign, err := svc.repo.GetCampaignExclusiveByCampaignID(job.CampaignID)
+
+	if err != nil {
+		return err
+	}
+
+	if exclusiveCampaign.WinnerUserID != 0 {
+		return nil
+	}
+
+	var req RequestGetCampaignExclusiveByCampaignID
+	req.ID = job.CampaignID
+
+	if _, err := svc.CheckAndSetWinnerCampaignExclusive(req); err != nil {
+		// nobody donated, retrying does not change that
+		if err.Error() == "no user can be the winner" {
+			log.Printf("[CAMPAIGN] exclusive campaign %v finished without a winner", job.CampaignID)
+			return nil
+		}
+
+		return err
+	}
+
+	return nil
+}
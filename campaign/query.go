@@ -165,6 +165,9 @@ const (
 			is_reward_money,
 			reward,
 			is_paid_off,
+			min_donation,
+			entry_amount,
+			verified_only,
 			created_at,
 			created_by,
 			updated_at,
@@ -185,6 +188,9 @@ const (
 			is_reward_money,
 			reward,
 			is_paid_off,
+			min_donation,
+			entry_amount,
+			verified_only,
 			created_at,
 			created_by,
 			updated_at,
@@ -209,6 +215,9 @@ const (
 			is_reward_money,
 			reward,
 			is_paid_off,
+			min_donation,
+			entry_amount,
+			verified_only,
 			created_at,
 			created_by,
 			updated_at,
@@ -235,6 +244,9 @@ const (
 			is_reward_money,
 			reward,
 			is_paid_off,
+			min_donation,
+			entry_amount,
+			verified_only,
 			created_at,
 			created_by,
 			updated_at,
@@ -246,11 +258,112 @@ const (
 		WHERE
 			deleted_at IS NULL
 		AND
-			winner_user_id = ?
+			(
+				winner_user_id = ?
+			OR
+				id IN (SELECT exclusive_campaign_id FROM exclusive_campaign_winners WHERE user_id = ?)
+			)
+		ORDER BY
+			id ASC
+	`
+
+	QueryGetCampaignExclusivePrizes = `
+		SELECT
+			id,
+			exclusive_campaign_id,
+			tier,
+			name,
+			is_reward_money,
+			reward,
+			quantity,
+			created_at,
+			updated_at
+		FROM
+			exclusive_campaign_prizes
+		WHERE
+			exclusive_campaign_id = ?
+		ORDER BY
+			tier ASC
+	`
+
+	QueryGetCampaignExclusiveWinners = querySelectExclusiveCampaignWinners + `
+		WHERE
+			exclusive_campaign_id = ?
 		ORDER BY
 			id ASC
 	`
 
+	QueryGetCampaignExclusiveWinnerByID = querySelectExclusiveCampaignWinners + `
+		WHERE
+			id = ?
+		LIMIT
+			1
+	`
+
+	QueryGetCampaignExclusiveWinnersByUserID = querySelectExclusiveCampaignWinners + `
+		WHERE
+			user_id = ?
+		ORDER BY
+			id DESC
+	`
+
+	QueryGetExpiredRewardClaims = querySelectExclusiveCampaignWinners + `
+		WHERE
+			status = 'unclaimed'
+		AND
+			claim_deadline < ?
+		ORDER BY
+			claim_deadline ASC
+		LIMIT
+			?
+	`
+
+	QueryAdminDataTablesRewards = `
+		SELECT
+			exclusive_campaign_winners.id,
+			exclusive_campaign_winners.campaign_id,
+			COALESCE(campaigns.title, '') AS campaign_title,
+			exclusive_campaign_winners.user_id,
+			COALESCE(users.name, '') AS user_name,
+			exclusive_campaign_winners.tier,
+			exclusive_campaign_winners.name,
+			exclusive_campaign_winners.reward,
+			exclusive_campaign_winners.status,
+			exclusive_campaign_winners.claim_deadline,
+			exclusive_campaign_winners.courier,
+			exclusive_campaign_winners.tracking_number,
+			exclusive_campaign_winners.created_at
+		FROM
+			exclusive_campaign_winners
+		LEFT JOIN
+			campaigns
+		ON
+			campaigns.id = exclusive_campaign_winners.campaign_id
+		LEFT JOIN
+			users
+		ON
+			users.id = exclusive_campaign_winners.user_id
+		WHERE
+			exclusive_campaign_winners.is_reward_money = 0
+	`
+
+	QueryCountAllAdminDataTablesRewards = `
+		SELECT
+			COUNT(exclusive_campaign_winners.id) AS count_id
+		FROM
+			exclusive_campaign_winners
+		LEFT JOIN
+			campaigns
+		ON
+			campaigns.id = exclusive_campaign_winners.campaign_id
+		LEFT JOIN
+			users
+		ON
+			users.id = exclusive_campaign_winners.user_id
+		WHERE
+			exclusive_campaign_winners.is_reward_money = 0
+	`
+
 	QueryAdminDataTablesCampaigns = `
 		SELECT
 			id,
@@ -395,32 +508,36 @@ const (
 		AND
 			deleted_at IS NULL
 	`
-
-	QueryGetOneRandomUserIDForWinnerExclusiveCampaign = `
-		SELECT
-			user_id
-		FROM
-			transactions
-		WHERE
-			user_id <> 0
-		AND
-			user_id IS NOT NULL
-		AND
-			user_id IN (
-				SELECT
-					id
-				FROM
-					users
-				WHERE
-					deleted_at IS NULL
-			)
-		AND
-			status = 'paid'
-		AND
-			campaign_id = ?
-		ORDER BY
-			RAND()
-		LIMIT
-			1
-	`
 )
+
+const querySelectExclusiveCampaignWinners = `
+	SELECT
+		id,
+		exclusive_campaign_id,
+		campaign_id,
+		prize_id,
+		tier,
+		name,
+		pick,
+		user_id,
+		is_reward_money,
+		reward,
+		is_paid_off,
+		status,
+		claim_deadline,
+		claimed_at,
+		recipient_name,
+		recipient_phone,
+		shipping_address,
+		notes,
+		courier,
+		tracking_number,
+		shipped_at,
+		delivered_at,
+		expired_at,
+		replaces_winner_id,
+		created_at,
+		updated_at
+	FROM
+		exclusive_campaign_winners
+`
@@ -1,6 +1,9 @@
 package campaign
 
 import (
+	"time"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/event"
 	"github.com/WeAreAmazingTeam/tcd-backend/helper"
 	"github.com/WeAreAmazingTeam/tcd-backend/user"
 	"github.com/gin-gonic/gin"
@@ -13,6 +16,8 @@ type Repository interface {
 	SaveCampaign(Campaign) (Campaign, error)
 	UpdateCampaign(Campaign) (Campaign, error)
 	UpdateCampaignFromPayment(campaignID int, transactionAmount int64) error
+	FinishCampaign(campaign Campaign, events ...event.Event) (bool, error)
+	GetExpiredActiveCampaigns(now time.Time) ([]Campaign, error)
 	DeleteCampaign(Campaign) (bool, error)
 
 	GetAllCampaignImage() ([]CampaignImage, error)
@@ -33,12 +38,21 @@ type Repository interface {
 	GetCampaignExclusiveByWinnerUserID(id int) ([]ExclusiveCampaign, error)
 	SaveCampaignExclusive(ExclusiveCampaign) (ExclusiveCampaign, error)
 	UpdateCampaignExclusive(ExclusiveCampaign) (ExclusiveCampaign, error)
-	GetWinnerCampaignExclusive(ExclusiveCampaign) (int, error)
 	DeleteCampaignExclusive(ExclusiveCampaign) (bool, error)
+	GetCampaignExclusivePrizes(exclusiveCampaignID int) ([]ExclusiveCampaignPrize, error)
+	ReplaceCampaignExclusivePrizes(exclusiveCampaignID int, prizes []ExclusiveCampaignPrize) ([]ExclusiveCampaignPrize, error)
+	GetCampaignExclusiveWinners(exclusiveCampaignID int) ([]ExclusiveCampaignWinner, error)
+	GetCampaignExclusiveWinnersByUserID(userID int) ([]ExclusiveCampaignWinner, error)
+	GetCampaignExclusiveWinnerByID(id int) (ExclusiveCampaignWinner, error)
+	GetExpiredRewardClaims(now time.Time, limit int) ([]ExclusiveCampaignWinner, error)
+	AwardCampaignExclusiveWinners(ExclusiveCampaign, []ExclusiveCampaignWinner) (bool, error)
+	UpdateCampaignExclusiveWinner(winner ExclusiveCampaignWinner, fromStatus string) (bool, error)
+	ExpireCampaignExclusiveWinner(expired ExclusiveCampaignWinner, replacement *ExclusiveCampaignWinner) (bool, error)
 
 	AdminDataTablesCampaigns(ctx *gin.Context) (helper.DataTables, error)
 	AdminDataTablesCategories(ctx *gin.Context) (helper.DataTables, error)
 	AdminDataTablesWinnersExclusiveCampaigns(*gin.Context) (helper.DataTables, error)
+	AdminDataTablesRewards(*gin.Context) (helper.DataTables, error)
 
 	UserDataTablesCampaigns(*gin.Context, user.User) (helper.DataTables, error)
 
@@ -6,8 +6,11 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/WeAreAmazingTeam/tcd-backend/company"
 	"github.com/WeAreAmazingTeam/tcd-backend/constant"
+	"github.com/WeAreAmazingTeam/tcd-backend/event"
 
 	"github.com/WeAreAmazingTeam/tcd-backend/helper"
 	"github.com/WeAreAmazingTeam/tcd-backend/user"
@@ -207,6 +210,49 @@ func (repo *repository) UpdateCampaignFromPayment(campaignID int, transactionAmo
 	return nil
 }
 
+// FinishCampaign only finishes an active campaign, false means it was already finished.
+// The events are stored in the same database transaction as the status.
+func (repo *repository) FinishCampaign(campaign Campaign, events ...event.Event) (bool, error) {
+	finished := false
+
+	err := repo.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&Campaign{}).
+			Where("id = ? AND status = ?", campaign.ID, "active").
+			Updates(map[string]any{"status": "finished", "updated_by": campaign.UpdatedBy})
+
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		finished = true
+
+		return event.Store(tx, events...)
+	})
+
+	if err != nil {
+		return false, err
+	}
+
+	if finished {
+		event.Wake()
+	}
+
+	return finished, nil
+}
+
+// GetExpiredActiveCampaigns returns the active campaigns whose deadline (finished_at) has passed,
+// finished_at is compared as a local datetime string the same way the campaign form stores it
+func (repo *repository) GetExpiredActiveCampaigns(now time.Time) (campaigns []Campaign, err error) {
+	if err := repo.DB.Where("status = ? AND finished_at <= ?", "active", now.Format("2006-01-02 15:04:05")).Order("finished_at ASC").Find(&campaigns).Error; err != nil {
+		return campaigns, err
+	}
+	return campaigns, nil
+}
+
 func (repo *repository) DeleteCampaign(campaign Campaign) (bool, error) {
 	if constant.DELETED_BY {
 		if err := repo.DB.Save(&campaign).Error; err != nil {
@@ -420,6 +466,9 @@ func (repo *repository) GetAllCampaignExclusive() (exclusiveCampaigns []Exclusiv
 			&tmp.IsRewardMoney,
 			&tmp.Reward,
 			&tmp.IsPaidOff,
+			&tmp.MinDonation,
+			&tmp.EntryAmount,
+			&tmp.VerifiedOnly,
 			&tmp.CreatedAt,
 			&tmp.CreatedBy,
 			&tmp.UpdatedAt,
@@ -432,6 +481,14 @@ func (repo *repository) GetAllCampaignExclusive() (exclusiveCampaigns []Exclusiv
 			return exclusiveCampaigns, err
 		}
 
+		if tmp.Prizes, err = repo.GetCampaignExclusivePrizes(tmp.ID); err != nil {
+			return exclusiveCampaigns, err
+		}
+
+		if tmp.Winners, err = repo.GetCampaignExclusiveWinners(tmp.ID); err != nil {
+			return exclusiveCampaigns, err
+		}
+
 		exclusiveCampaigns = append(exclusiveCampaigns, tmp)
 	}
 
@@ -455,6 +512,9 @@ func (repo *repository) GetCampaignExclusiveByID(id int) (exclusiveCampaign Excl
 		&exclusiveCampaign.IsRewardMoney,
 		&exclusiveCampaign.Reward,
 		&exclusiveCampaign.IsPaidOff,
+		&exclusiveCampaign.MinDonation,
+		&exclusiveCampaign.EntryAmount,
+		&exclusiveCampaign.VerifiedOnly,
 		&exclusiveCampaign.CreatedAt,
 		&exclusiveCampaign.CreatedBy,
 		&exclusiveCampaign.UpdatedAt,
@@ -467,6 +527,14 @@ func (repo *repository) GetCampaignExclusiveByID(id int) (exclusiveCampaign Excl
 		return exclusiveCampaign, err
 	}
 
+	if exclusiveCampaign.Prizes, err = repo.GetCampaignExclusivePrizes(exclusiveCampaign.ID); err != nil {
+		return exclusiveCampaign, err
+	}
+
+	if exclusiveCampaign.Winners, err = repo.GetCampaignExclusiveWinners(exclusiveCampaign.ID); err != nil {
+		return exclusiveCampaign, err
+	}
+
 	return exclusiveCampaign, nil
 }
 
@@ -480,6 +548,9 @@ func (repo *repository) GetCampaignExclusiveByCampaignID(id int) (exclusiveCampa
 		&exclusiveCampaign.IsRewardMoney,
 		&exclusiveCampaign.Reward,
 		&exclusiveCampaign.IsPaidOff,
+		&exclusiveCampaign.MinDonation,
+		&exclusiveCampaign.EntryAmount,
+		&exclusiveCampaign.VerifiedOnly,
 		&exclusiveCampaign.CreatedAt,
 		&exclusiveCampaign.CreatedBy,
 		&exclusiveCampaign.UpdatedAt,
@@ -492,11 +563,19 @@ func (repo *repository) GetCampaignExclusiveByCampaignID(id int) (exclusiveCampa
 		return exclusiveCampaign, err
 	}
 
+	if exclusiveCampaign.Prizes, err = repo.GetCampaignExclusivePrizes(exclusiveCampaign.ID); err != nil {
+		return exclusiveCampaign, err
+	}
+
+	if exclusiveCampaign.Winners, err = repo.GetCampaignExclusiveWinners(exclusiveCampaign.ID); err != nil {
+		return exclusiveCampaign, err
+	}
+
 	return exclusiveCampaign, nil
 }
 
 func (repo *repository) GetCampaignExclusiveByWinnerUserID(id int) (exclusiveCampaigns []ExclusiveCampaign, err error) {
-	rows, err := repo.DB.Raw(helper.ConvertToInLineQuery(QueryGetCampaignExclusiveByWinnerUserID), id).Rows()
+	rows, err := repo.DB.Raw(helper.ConvertToInLineQuery(QueryGetCampaignExclusiveByWinnerUserID), id, id).Rows()
 
 	if err != nil {
 		return exclusiveCampaigns, err
@@ -513,6 +592,9 @@ func (repo *repository) GetCampaignExclusiveByWinnerUserID(id int) (exclusiveCam
 			&tmp.IsRewardMoney,
 			&tmp.Reward,
 			&tmp.IsPaidOff,
+			&tmp.MinDonation,
+			&tmp.EntryAmount,
+			&tmp.VerifiedOnly,
 			&tmp.CreatedAt,
 			&tmp.CreatedBy,
 			&tmp.UpdatedAt,
@@ -525,6 +607,14 @@ func (repo *repository) GetCampaignExclusiveByWinnerUserID(id int) (exclusiveCam
 			return exclusiveCampaigns, err
 		}
 
+		if tmp.Prizes, err = repo.GetCampaignExclusivePrizes(tmp.ID); err != nil {
+			return exclusiveCampaigns, err
+		}
+
+		if tmp.Winners, err = repo.GetCampaignExclusiveWinners(tmp.ID); err != nil {
+			return exclusiveCampaigns, err
+		}
+
 		exclusiveCampaigns = append(exclusiveCampaigns, tmp)
 	}
 
@@ -545,6 +635,437 @@ func (repo *repository) DeleteCampaignExclusive(exclusiveCampaign ExclusiveCampa
 	return true, nil
 }
 
+func (repo *repository) GetCampaignExclusivePrizes(exclusiveCampaignID int) (prizes []ExclusiveCampaignPrize, err error) {
+	rows, err := repo.DB.Raw(helper.ConvertToInLineQuery(QueryGetCampaignExclusivePrizes), exclusiveCampaignID).Rows()
+
+	if err != nil {
+		return prizes, err
+	}
+
+	defer rows.Close()
+
+	prizes = []ExclusiveCampaignPrize{}
+
+	for rows.Next() {
+		tmp := ExclusiveCampaignPrize{}
+		err := rows.Scan(
+			&tmp.ID,
+			&tmp.ExclusiveCampaignID,
+			&tmp.Tier,
+			&tmp.Name,
+			&tmp.IsRewardMoney,
+			&tmp.Reward,
+			&tmp.Quantity,
+			&tmp.CreatedAt,
+			&tmp.UpdatedAt,
+		)
+
+		if err != nil {
+			return prizes, err
+		}
+
+		prizes = append(prizes, tmp)
+	}
+
+	return prizes, nil
+}
+
+// ReplaceCampaignExclusivePrizes swaps every tier of the campaign for the given ones in one database transaction
+func (repo *repository) ReplaceCampaignExclusivePrizes(exclusiveCampaignID int, prizes []ExclusiveCampaignPrize) ([]ExclusiveCampaignPrize, error) {
+	err := repo.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("exclusive_campaign_id = ?", exclusiveCampaignID).Delete(&ExclusiveCampaignPrize{}).Error; err != nil {
+			return err
+		}
+
+		if len(prizes) == 0 {
+			return nil
+		}
+
+		return tx.Create(&prizes).Error
+	})
+
+	if err != nil {
+		return prizes, err
+	}
+
+	return prizes, nil
+}
+
+func (repo *repository) GetCampaignExclusiveWinners(exclusiveCampaignID int) (winners []ExclusiveCampaignWinner, err error) {
+	return repo.getCampaignExclusiveWinners(QueryGetCampaignExclusiveWinners, exclusiveCampaignID)
+}
+
+func (repo *repository) GetCampaignExclusiveWinnersByUserID(userID int) (winners []ExclusiveCampaignWinner, err error) {
+	return repo.getCampaignExclusiveWinners(QueryGetCampaignExclusiveWinnersByUserID, userID)
+}
+
+func (repo *repository) GetExpiredRewardClaims(now time.Time, limit int) (winners []ExclusiveCampaignWinner, err error) {
+	return repo.getCampaignExclusiveWinners(QueryGetExpiredRewardClaims, now, limit)
+}
+
+func (repo *repository) GetCampaignExclusiveWinnerByID(id int) (winner ExclusiveCampaignWinner, err error) {
+	row := repo.DB.Raw(helper.ConvertToInLineQuery(QueryGetCampaignExclusiveWinnerByID), id).Row()
+
+	if err := scanExclusiveCampaignWinner(row, &winner); err != nil {
+		return winner, err
+	}
+
+	return winner, nil
+}
+
+func (repo *repository) getCampaignExclusiveWinners(query string, args ...any) (winners []ExclusiveCampaignWinner, err error) {
+	rows, err := repo.DB.Raw(helper.ConvertToInLineQuery(query), args...).Rows()
+
+	if err != nil {
+		return winners, err
+	}
+
+	defer rows.Close()
+
+	winners = []ExclusiveCampaignWinner{}
+
+	for rows.Next() {
+		tmp := ExclusiveCampaignWinner{}
+
+		if err := scanExclusiveCampaignWinner(rows, &tmp); err != nil {
+			return winners, err
+		}
+
+		winners = append(winners, tmp)
+	}
+
+	return winners, nil
+}
+
+func scanExclusiveCampaignWinner(row interface{ Scan(...any) error }, winner *ExclusiveCampaignWinner) error {
+	return row.Scan(
+		&winner.ID,
+		&winner.ExclusiveCampaignID,
+		&winner.CampaignID,
+		&winner.PrizeID,
+		&winner.Tier,
+		&winner.Name,
+		&winner.Pick,
+		&winner.UserID,
+		&winner.IsRewardMoney,
+		&winner.Reward,
+		&winner.IsPaidOff,
+		&winner.Status,
+		&winner.ClaimDeadline,
+		&winner.ClaimedAt,
+		&winner.RecipientName,
+		&winner.RecipientPhone,
+		&winner.ShippingAddress,
+		&winner.Notes,
+		&winner.Courier,
+		&winner.TrackingNumber,
+		&winner.ShippedAt,
+		&winner.DeliveredAt,
+		&winner.ExpiredAt,
+		&winner.ReplacesWinnerID,
+		&winner.CreatedAt,
+		&winner.UpdatedAt,
+	)
+}
+
+// UpdateCampaignExclusiveWinner saves the winner only while it is still in fromStatus, false means it moved on.
+// The campaign is paid off in the same database transaction once no reward is left to hand over.
+func (repo *repository) UpdateCampaignExclusiveWinner(winner ExclusiveCampaignWinner, fromStatus string) (bool, error) {
+	updated := false
+
+	err := repo.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&ExclusiveCampaignWinner{}).
+			Where("id = ? AND status = ?", winner.ID, fromStatus).
+			Select("*").
+			Omit("id", "created_at").
+			Updates(&winner)
+
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		updated = true
+
+		return markCampaignExclusivePaidOff(tx, winner.ExclusiveCampaignID)
+	})
+
+	if err != nil {
+		return false, err
+	}
+
+	return updated, nil
+}
+
+// ExpireCampaignExclusiveWinner expires an unclaimed reward and stores its redrawn winner in one database transaction,
+// replacement is nil when nobody is left to draw, false means the reward was claimed or expired in between
+func (repo *repository) ExpireCampaignExclusiveWinner(expired ExclusiveCampaignWinner, replacement *ExclusiveCampaignWinner) (bool, error) {
+	done := false
+
+	err := repo.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&ExclusiveCampaignWinner{}).
+			Where("id = ? AND status = ?", expired.ID, RewardStatusUnclaimed).
+			Updates(map[string]any{"status": RewardStatusExpired, "expired_at": expired.ExpiredAt, "updated_at": time.Now()})
+
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		if replacement != nil {
+			if err := tx.Create(replacement).Error; err != nil {
+				return err
+			}
+
+			// winner_user_id follows the first pick to whoever holds it now
+			err := tx.Model(&ExclusiveCampaign{}).
+				Where("id = ? AND winner_user_id = ?", expired.ExclusiveCampaignID, expired.UserID).
+				Update("winner_user_id", replacement.UserID).Error
+
+			if err != nil {
+				return err
+			}
+		}
+
+		done = true
+
+		return markCampaignExclusivePaidOff(tx, expired.ExclusiveCampaignID)
+	})
+
+	if err != nil {
+		return false, err
+	}
+
+	return done, nil
+}
+
+func markCampaignExclusivePaidOff(tx *gorm.DB, exclusiveCampaignID int) error {
+	outstanding := []string{RewardStatusUnclaimed, RewardStatusPending, RewardStatusShipped}
+
+	return tx.Model(&ExclusiveCampaign{}).
+		Where("id = ? AND is_paid_off = ?", exclusiveCampaignID, 0).
+		Where("NOT EXISTS (SELECT 1 FROM exclusive_campaign_winners WHERE exclusive_campaign_id = ? AND status IN ?)", exclusiveCampaignID, outstanding).
+		Update("is_paid_off", 1).Error
+}
+
+// AwardCampaignExclusiveWinners stores the winners and credits every money prize in one database transaction,
+// false means the campaign already had its winners and nothing was written
+func (repo *repository) AwardCampaignExclusiveWinners(exclusiveCampaign ExclusiveCampaign, winners []ExclusiveCampaignWinner) (bool, error) {
+	awarded := false
+
+	err := repo.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&ExclusiveCampaign{}).
+			Where("id = ? AND winner_user_id = ?", exclusiveCampaign.ID, 0).
+			Updates(map[string]any{"winner_user_id": exclusiveCampaign.WinnerUserID, "is_paid_off": exclusiveCampaign.IsPaidOff})
+
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		if err := tx.Create(&winners).Error; err != nil {
+			return err
+		}
+
+		for _, winner := range winners {
+			if winner.IsRewardMoney != 1 {
+				continue
+			}
+
+			amount, err := strconv.ParseInt(winner.Reward, 10, 64)
+
+			if err != nil {
+				return err
+			}
+
+			if err := tx.Model(&user.User{}).Where("id = ?", winner.UserID).Update("e_money", gorm.Expr("e_money + ?", amount)).Error; err != nil {
+				return err
+			}
+
+			eMoneyFlow := user.UserEMoneyFlow{
+				UserID: winner.UserID,
+				Status: "in",
+				Amount: amount,
+				Note:   fmt.Sprintf("Reward from exclusive campaign id %v.", winner.CampaignID),
+			}
+
+			if err := tx.Create(&eMoneyFlow).Error; err != nil {
+				return err
+			}
+
+			companyCashFlow := company.CompanyCashFlow{
+				Status: "out",
+				Amount: amount,
+				Note:   fmt.Sprintf("Reward for exclusive campaign id %v.", winner.CampaignID),
+			}
+
+			if err := tx.Create(&companyCashFlow).Error; err != nil {
+				return err
+			}
+		}
+
+		awarded = true
+
+		return nil
+	})
+
+	if err != nil {
+		return false, err
+	}
+
+	return awarded, nil
+}
+
+func (repo *repository) AdminDataTablesRewards(ctx *gin.Context) (result helper.DataTables, err error) {
+	var (
+		query string = QueryAdminDataTablesRewards
+		where string = ""
+		order string = ""
+		limit string = ""
+	)
+
+	var (
+		no       int = 1
+		total    int = 0
+		filtered int = 0
+	)
+
+	var (
+		data []map[string]any
+		args []any
+	)
+
+	listOrder := []string{"", "campaigns.title", "users.name", "exclusive_campaign_winners.tier", "exclusive_campaign_winners.reward", "exclusive_campaign_winners.status", "exclusive_campaign_winners.claim_deadline", ""}
+
+	if status := ctx.Query("status"); status != "" {
+		where = fmt.Sprintf("%s AND exclusive_campaign_winners.status = ?", where)
+		args = append(args, status)
+	}
+
+	if campaignID := ctx.Query("campaign_id"); campaignID != "" {
+		where = fmt.Sprintf("%s AND exclusive_campaign_winners.campaign_id = ?", where)
+		args = append(args, campaignID)
+	}
+
+	if searchValue := ctx.Query("search[value]"); searchValue != "" {
+		where = fmt.Sprintf("%s AND (campaigns.title LIKE ? OR users.name LIKE ? OR exclusive_campaign_winners.reward LIKE ? OR exclusive_campaign_winners.tracking_number LIKE ?)", where)
+		for i := 0; i < 4; i++ {
+			args = append(args, "%"+searchValue+"%")
+		}
+	}
+
+	orderColumn := ctx.Query("order[0][column]")
+	starting, _ := strconv.Atoi(ctx.Query("start"))
+
+	if orderColumn != "" {
+		orderType := "ASC"
+		orderColumn, _ := strconv.Atoi(orderColumn)
+
+		if strings.ToUpper(ctx.Query("order[0][dir]")) == "DESC" {
+			orderType = "DESC"
+		}
+
+		if orderColumn > 0 && orderColumn < len(listOrder) && listOrder[orderColumn] != "" {
+			order = fmt.Sprintf("ORDER BY %s %s", listOrder[orderColumn], orderType)
+		} else {
+			order = "ORDER BY exclusive_campaign_winners.id DESC"
+		}
+	} else {
+		order = "ORDER BY exclusive_campaign_winners.id DESC"
+	}
+
+	if starting != -1 {
+		length, _ := strconv.Atoi(ctx.Query("length"))
+		limit = fmt.Sprintf("LIMIT %v OFFSET %v", length, starting)
+		no = starting + 1
+	}
+
+	if err := repo.DB.Raw(helper.ConvertToInLineQuery(QueryCountAllAdminDataTablesRewards)).Scan(&total).Error; err != nil {
+		return result, err
+	}
+
+	if where != "" {
+		query = query + where
+
+		if err := repo.DB.Raw(helper.ConvertToInLineQuery(QueryCountAllAdminDataTablesRewards)+where, args...).Scan(&filtered).Error; err != nil {
+			return result, err
+		}
+	} else {
+		filtered = total
+	}
+
+	query = fmt.Sprintf("%s %s %s", query, order, limit)
+
+	rows, err := repo.DB.Raw(helper.ConvertToInLineQuery(query), args...).Rows()
+
+	if err != nil {
+		return result, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			campaignTitle string
+			userName      string
+		)
+
+		tmp := ExclusiveCampaignWinner{}
+
+		err := rows.Scan(
+			&tmp.ID,
+			&tmp.CampaignID,
+			&campaignTitle,
+			&tmp.UserID,
+			&userName,
+			&tmp.Tier,
+			&tmp.Name,
+			&tmp.Reward,
+			&tmp.Status,
+			&tmp.ClaimDeadline,
+			&tmp.Courier,
+			&tmp.TrackingNumber,
+			&tmp.CreatedAt,
+		)
+
+		if err != nil {
+			return result, err
+		}
+
+		formatData := FormatRewardData(tmp)
+
+		data = append(data, map[string]any{
+			"no":              no,
+			"id":              formatData.ID,
+			"campaign_id":     formatData.CampaignID,
+			"campaign_title":  campaignTitle,
+			"user_id":         formatData.UserID,
+			"user_name":       userName,
+			"tier":            formatData.Tier,
+			"name":            formatData.Name,
+			"reward":          formatData.Reward,
+			"status":          formatData.Status,
+			"claim_deadline":  formatData.ClaimDeadline,
+			"courier":         formatData.Courier,
+			"tracking_number": formatData.TrackingNumber,
+			"created_at":      formatData.CreatedAt,
+		})
+
+		no++
+	}
+
+	return helper.BuildDatatTables(data, filtered, total), nil
+}
+
 func (repo *repository) AdminDataTablesCampaigns(ctx *gin.Context) (result helper.DataTables, err error) {
 	var (
 		query string = QueryAdminDataTablesCampaigns
@@ -1051,17 +1572,3 @@ func (repo *repository) AdminDataTablesWinnersExclusiveCampaigns(ctx *gin.Contex
 
 	return helper.BuildDatatTables(data, filtered, total), nil
 }
-
-func (repo *repository) GetWinnerCampaignExclusive(exclusiveCampaign ExclusiveCampaign) (winnerUserID int, err error) {
-	if exclusiveCampaign.IsPaidOff == 0 {
-		err := repo.DB.Raw(helper.ConvertToInLineQuery(QueryGetOneRandomUserIDForWinnerExclusiveCampaign), exclusiveCampaign.CampaignID).Row().Scan(&winnerUserID)
-
-		if err != nil {
-			return 0, err
-		}
-
-		return winnerUserID, nil
-	}
-
-	return 0, nil
-}
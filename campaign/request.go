@@ -15,6 +15,7 @@ type (
 		FinishedAt       string `json:"finished_at"`
 		Status           string `json:"status" binding:"required"`
 		User             user.User
+		IsModerator      bool `json:"-" form:"-"`
 	}
 
 	RequestUpdateCampaign struct {
@@ -22,7 +23,8 @@ type (
 	}
 
 	RequestDeleteCampaign struct {
-		User user.User
+		User        user.User
+		IsModerator bool `json:"-" form:"-"`
 	}
 
 	RequestGetCampaignByID struct {
@@ -38,13 +40,15 @@ type (
 	}
 
 	RequestCreateCampaignImage struct {
-		CampaignID int  `form:"campaign_id" binding:"required"`
-		IsPrimary  bool `form:"is_primary"`
-		User       user.User
+		CampaignID  int  `form:"campaign_id" binding:"required"`
+		IsPrimary   bool `form:"is_primary"`
+		User        user.User
+		IsModerator bool `json:"-" form:"-"`
 	}
 
 	RequestDeleteCampaignImage struct {
-		User user.User
+		User        user.User
+		IsModerator bool `json:"-" form:"-"`
 	}
 
 	RequestDeleteCampaignCategory struct {
@@ -60,15 +64,27 @@ type (
 		RequestCreateCampaignCategory
 	}
 
+	// RequestCreateCampaignExclusive without prizes has one winner for Reward, on update nil prizes keep the tiers there are
 	RequestCreateCampaignExclusive struct {
-		CampaignID    int    `json:"campaign_id" binding:"required"`
-		WinnerUserID  int    `json:"winner_user_id"`
-		IsRewardMoney int    `json:"is_reward_money"`
-		Reward        string `json:"reward" binding:"required"`
-		IsPaidOff     int    `json:"is_paid_off"`
+		CampaignID    int                                   `json:"campaign_id" binding:"required"`
+		WinnerUserID  int                                   `json:"winner_user_id"`
+		IsRewardMoney int                                   `json:"is_reward_money"`
+		Reward        string                                `json:"reward" binding:"required"`
+		IsPaidOff     int                                   `json:"is_paid_off"`
+		MinDonation   int64                                 `json:"min_donation" binding:"min=0"`
+		EntryAmount   int64                                 `json:"entry_amount" binding:"min=0"`
+		VerifiedOnly  int                                   `json:"verified_only" binding:"oneof=0 1"`
+		Prizes        []RequestCreateCampaignExclusivePrize `json:"prizes" binding:"omitempty,dive"`
 		User          user.User
 	}
 
+	RequestCreateCampaignExclusivePrize struct {
+		Name          string `json:"name" binding:"required"`
+		IsRewardMoney int    `json:"is_reward_money" binding:"oneof=0 1"`
+		Reward        string `json:"reward" binding:"required"`
+		Quantity      int    `json:"quantity" binding:"required,min=1"`
+	}
+
 	RequestGetCampaignExclusiveByID struct {
 		RequestGetCampaignByID
 	}
@@ -88,4 +104,24 @@ type (
 	RequestDeleteCampaignExclusive struct {
 		User user.User
 	}
+
+	RequestGetRewardByID struct {
+		ID int `uri:"id" binding:"required"`
+	}
+
+	// RequestClaimReward takes a shipping address for goods, contact details are enough for anything handed over otherwise
+	RequestClaimReward struct {
+		RecipientName   string `json:"recipient_name" binding:"required"`
+		RecipientPhone  string `json:"recipient_phone" binding:"required"`
+		ShippingAddress string `json:"shipping_address"`
+		Notes           string `json:"notes"`
+		User            user.User
+	}
+
+	RequestUpdateRewardFulfillment struct {
+		Status         string `json:"status" binding:"required,oneof=shipped delivered"`
+		Courier        string `json:"courier"`
+		TrackingNumber string `json:"tracking_number"`
+		User           user.User
+	}
 )
@@ -1,8 +1,11 @@
 package campaign
 
 import (
-	"github.com/WeAreAmazingTeam/tcd-backend/company"
+	"github.com/WeAreAmazingTeam/tcd-backend/audit"
+	"github.com/WeAreAmazingTeam/tcd-backend/draw"
+	"github.com/WeAreAmazingTeam/tcd-backend/event"
 	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+	"github.com/WeAreAmazingTeam/tcd-backend/jobqueue"
 	"github.com/WeAreAmazingTeam/tcd-backend/user"
 	"github.com/gin-gonic/gin"
 )
@@ -33,31 +36,43 @@ type Service interface {
 	UpdateCampaignExclusive(RequestGetCampaignExclusiveByID, RequestUpdateCampaignExclusive) (ExclusiveCampaign, error)
 	CheckAndSetWinnerCampaignExclusive(RequestGetCampaignExclusiveByCampaignID) (ExclusiveCampaign, error)
 	DeleteCampaignExclusive(RequestGetCampaignExclusiveByID, RequestDeleteCampaignExclusive) (bool, error)
+	GetRewardsByUserID(user.User) ([]ExclusiveCampaignWinner, error)
+	GetRewardByID(RequestGetRewardByID) (ExclusiveCampaignWinner, error)
+	ClaimReward(RequestGetRewardByID, RequestClaimReward) (ExclusiveCampaignWinner, error)
+	UpdateRewardFulfillment(RequestGetRewardByID, RequestUpdateRewardFulfillment) (ExclusiveCampaignWinner, error)
+	ExpireRewardClaims() (int, error)
 
 	AdminDataTablesCampaigns(*gin.Context) (helper.DataTables, error)
 	AdminDataTablesCategories(*gin.Context) (helper.DataTables, error)
 	AdminDataTablesWinnersExclusiveCampaigns(*gin.Context) (helper.DataTables, error)
+	AdminDataTablesRewards(*gin.Context) (helper.DataTables, error)
 
 	UserDataTablesCampaigns(*gin.Context, user.User) (helper.DataTables, error)
 
 	GetTotalDonation() (int, error)
 	GetDonationCompleted() (int, error)
+
+	RegisterSubscribers(*event.Bus)
+	RegisterJobHandlers(*jobqueue.Registry)
 }
 
 type service struct {
-	repo        Repository
-	userRepo    user.Repository
-	companyRepo company.Repository
+	repo     Repository
+	userRepo user.Repository
+	drawSvc  draw.Service
+	auditSvc audit.Service
 }
 
 func NewService(
 	repository Repository,
 	userRepository user.Repository,
-	companyRepository company.Repository,
+	drawService draw.Service,
+	auditService audit.Service,
 ) *service {
 	return &service{
-		repo:        repository,
-		userRepo:    userRepository,
-		companyRepo: companyRepository,
+		repo:     repository,
+		userRepo: userRepository,
+		drawSvc:  drawService,
+		auditSvc: auditService,
 	}
 }
@@ -1,14 +1,17 @@
 package campaign
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
+	"log"
 	"os"
 	"strconv"
 	"time"
 
-	"github.com/WeAreAmazingTeam/tcd-backend/company"
+	"github.com/WeAreAmazingTeam/tcd-backend/audit"
 	"github.com/WeAreAmazingTeam/tcd-backend/constant"
+	"github.com/WeAreAmazingTeam/tcd-backend/draw"
 	"github.com/WeAreAmazingTeam/tcd-backend/helper"
 	"github.com/WeAreAmazingTeam/tcd-backend/user"
 	"github.com/gin-gonic/gin"
@@ -59,6 +62,8 @@ func (svc *service) CreateCampaign(req RequestCreateCampaign) (Campaign, error)
 		return newCampaignData, err
 	}
 
+	audit.Record(svc.auditSvc, req.User, audit.ActionCreate, audit.EntityCampaign, newCampaignData.ID, nil, newCampaignData)
+
 	return newCampaignData, nil
 }
 
@@ -69,11 +74,13 @@ func (svc *service) UpdateCampaign(reqDetail RequestGetCampaignByID, reqUpdate R
 		return campaign, err
 	}
 
-	if campaign.UserID != reqUpdate.User.ID && reqUpdate.User.Role == "user" {
+	if campaign.UserID != reqUpdate.User.ID && !reqUpdate.IsModerator {
 		return campaign, errors.New("not an owner of the campaign")
 	}
 
-	if reqUpdate.User.Role == "user" {
+	before := campaign
+
+	if !reqUpdate.IsModerator {
 		campaign.UserID = reqUpdate.User.ID
 	} else {
 		campaign.UserID = reqUpdate.UserID
@@ -101,6 +108,8 @@ func (svc *service) UpdateCampaign(reqDetail RequestGetCampaignByID, reqUpdate R
 		return updatedCampaign, err
 	}
 
+	audit.Record(svc.auditSvc, reqUpdate.User, audit.ActionUpdate, audit.EntityCampaign, updatedCampaign.ID, before, updatedCampaign)
+
 	return updatedCampaign, nil
 }
 
@@ -112,6 +121,11 @@ func (svc *service) DeleteCampaign(reqDetail RequestGetCampaignByID, reqDelete R
 			return false, err
 		}
 
+		if campaign.UserID != reqDelete.User.ID && !reqDelete.IsModerator {
+			return false, errors.New("not an owner of the campaign")
+		}
+
+		before := campaign
 		campaign.UpdatedBy = helper.SetNS(strconv.Itoa(reqDelete.User.ID))
 		campaign.DeletedAt = *helper.SetNowNT()
 		campaign.DeletedBy = helper.SetNS(strconv.Itoa(reqDelete.User.ID))
@@ -122,6 +136,8 @@ func (svc *service) DeleteCampaign(reqDetail RequestGetCampaignByID, reqDelete R
 			return status, err
 		}
 
+		audit.Record(svc.auditSvc, reqDelete.User, audit.ActionDelete, audit.EntityCampaign, campaign.ID, before, nil)
+
 		return status, nil
 	}
 
@@ -131,7 +147,7 @@ func (svc *service) DeleteCampaign(reqDetail RequestGetCampaignByID, reqDelete R
 		return false, err
 	}
 
-	if campaign.UserID != reqDelete.User.ID && reqDelete.User.Role == "user" {
+	if campaign.UserID != reqDelete.User.ID && !reqDelete.IsModerator {
 		return false, errors.New("not an owner of the campaign")
 	}
 
@@ -142,6 +158,8 @@ func (svc *service) DeleteCampaign(reqDetail RequestGetCampaignByID, reqDelete R
 		return status, err
 	}
 
+	audit.Record(svc.auditSvc, reqDelete.User, audit.ActionDelete, audit.EntityCampaign, campaign.ID, campaign, nil)
+
 	return status, nil
 }
 
@@ -152,7 +170,7 @@ func (svc *service) SaveCampaignImage(req RequestCreateCampaignImage, fileLocati
 		return campaignImage, err
 	}
 
-	if campaign.UserID != req.User.ID && req.User.Role == "user" {
+	if campaign.UserID != req.User.ID && !req.IsModerator {
 		return campaignImage, errors.New("not an owner of the campaign")
 	}
 
@@ -175,6 +193,8 @@ func (svc *service) SaveCampaignImage(req RequestCreateCampaignImage, fileLocati
 		return newCampaignImage, err
 	}
 
+	audit.Record(svc.auditSvc, req.User, audit.ActionCreate, audit.EntityCampaignImage, newCampaignImage.ID, nil, newCampaignImage)
+
 	return newCampaignImage, nil
 }
 
@@ -211,7 +231,7 @@ func (svc *service) DeleteCampaignImage(reqDetail RequestGetCampaignImageByID, r
 		return false, err
 	}
 
-	if campaign.UserID != reqDelete.User.ID && reqDelete.User.Role == "user" {
+	if campaign.UserID != reqDelete.User.ID && !reqDelete.IsModerator {
 		return false, errors.New("not an owner of the campaign image")
 	}
 
@@ -223,6 +243,8 @@ func (svc *service) DeleteCampaignImage(reqDetail RequestGetCampaignImageByID, r
 		return status, err
 	}
 
+	audit.Record(svc.auditSvc, reqDelete.User, audit.ActionDelete, audit.EntityCampaignImage, getCampaignImage.ID, getCampaignImage, nil)
+
 	return status, nil
 }
 
@@ -254,6 +276,7 @@ func (svc *service) DeleteCampaignCategory(reqDetail RequestGetCampaignCategoryB
 			return false, err
 		}
 
+		before := campaignCategory
 		campaignCategory.UpdatedBy = helper.SetNS(strconv.Itoa(reqDelete.User.ID))
 		campaignCategory.DeletedAt = *helper.SetNowNT()
 		campaignCategory.DeletedBy = helper.SetNS(strconv.Itoa(reqDelete.User.ID))
@@ -264,9 +287,13 @@ func (svc *service) DeleteCampaignCategory(reqDetail RequestGetCampaignCategoryB
 			return status, err
 		}
 
+		audit.Record(svc.auditSvc, reqDelete.User, audit.ActionDelete, audit.EntityCampaignCategory, campaignCategory.ID, before, nil)
+
 		return status, nil
 	}
 
+	before, _ := svc.repo.GetCampaignCategoryByID(reqDetail.ID)
+
 	campaignCategory := CampaignCategory{}
 	campaignCategory.ID = reqDetail.ID
 	status, err := svc.repo.DeleteCampaignCategory(campaignCategory)
@@ -275,6 +302,8 @@ func (svc *service) DeleteCampaignCategory(reqDetail RequestGetCampaignCategoryB
 		return status, err
 	}
 
+	audit.Record(svc.auditSvc, reqDelete.User, audit.ActionDelete, audit.EntityCampaignCategory, reqDetail.ID, before, nil)
+
 	return status, nil
 }
 
@@ -289,6 +318,8 @@ func (svc *service) CreateCampaignCategory(req RequestCreateCampaignCategory) (C
 		return newCampaignCategoryData, err
 	}
 
+	audit.Record(svc.auditSvc, req.User, audit.ActionCreate, audit.EntityCampaignCategory, newCampaignCategoryData.ID, nil, newCampaignCategoryData)
+
 	return newCampaignCategoryData, nil
 }
 
@@ -299,6 +330,7 @@ func (svc *service) UpdateCampaignCategory(reqDetail RequestGetCampaignCategoryB
 		return campaignCategory, err
 	}
 
+	before := campaignCategory
 	campaignCategory.ID = reqDetail.ID
 	campaignCategory.Category = reqUpdate.Category
 	campaignCategory.UpdatedBy = helper.SetNS(strconv.Itoa(reqUpdate.User.ID))
@@ -309,6 +341,8 @@ func (svc *service) UpdateCampaignCategory(reqDetail RequestGetCampaignCategoryB
 		return updatedCampaignCategory, err
 	}
 
+	audit.Record(svc.auditSvc, reqUpdate.User, audit.ActionUpdate, audit.EntityCampaignCategory, updatedCampaignCategory.ID, before, updatedCampaignCategory)
+
 	return updatedCampaignCategory, nil
 }
 
@@ -328,12 +362,19 @@ func (svc *service) CreateCampaignExclusive(req RequestCreateCampaignExclusive)
 	exclusiveCampaign.IsRewardMoney = req.IsRewardMoney
 	exclusiveCampaign.Reward = req.Reward
 	exclusiveCampaign.IsPaidOff = req.IsPaidOff
+	exclusiveCampaign.MinDonation = req.MinDonation
+	exclusiveCampaign.EntryAmount = req.EntryAmount
+	exclusiveCampaign.VerifiedOnly = req.VerifiedOnly
 	exclusiveCampaign.CreatedBy = helper.SetNS(strconv.Itoa(req.User.ID))
 
 	if req.WinnerUserID != 0 {
 		exclusiveCampaign.WinnerUserID = req.WinnerUserID
 	}
 
+	if err := validateRewards(req); err != nil {
+		return exclusiveCampaign, err
+	}
+
 	_, err := svc.repo.GetCampaignByID(req.CampaignID)
 
 	if err != nil {
@@ -346,12 +387,26 @@ func (svc *service) CreateCampaignExclusive(req RequestCreateCampaignExclusive)
 		return newCampaignExclusiveData, err
 	}
 
+	newCampaignExclusiveData.Prizes, err = svc.repo.ReplaceCampaignExclusivePrizes(newCampaignExclusiveData.ID, newPrizes(newCampaignExclusiveData.ID, req.Prizes))
+
+	if err != nil {
+		return newCampaignExclusiveData, err
+	}
+
+	audit.Record(svc.auditSvc, req.User, audit.ActionCreate, audit.EntityExclusiveCampaign, newCampaignExclusiveData.ID, nil, newCampaignExclusiveData)
+
+	// the seed hash is public from now on, the seed itself only once the winner is drawn
+	if _, err := svc.drawSvc.Commit(newCampaignExclusiveData.CampaignID, newCampaignExclusiveData.ID); err != nil {
+		return newCampaignExclusiveData, err
+	}
+
 	campaign, err := svc.repo.GetCampaignByID(newCampaignExclusiveData.CampaignID)
 
 	if err != nil {
 		return newCampaignExclusiveData, err
 	}
 
+	before := campaign
 	campaign.IsExclusive = 1
 	campaign.UpdatedBy = helper.SetNS(strconv.Itoa(req.User.ID))
 
@@ -359,6 +414,8 @@ func (svc *service) CreateCampaignExclusive(req RequestCreateCampaignExclusive)
 		return newCampaignExclusiveData, err
 	}
 
+	audit.Record(svc.auditSvc, req.User, audit.ActionUpdate, audit.EntityCampaign, campaign.ID, before, campaign)
+
 	return newCampaignExclusiveData, nil
 }
 
@@ -399,12 +456,27 @@ func (svc *service) UpdateCampaignExclusive(reqDetail RequestGetCampaignExclusiv
 		return exclusiveCampaign, err
 	}
 
+	// the draw used the rules and tiers there were, changing them afterwards would not change who won
+	rulesChanged := reqUpdate.MinDonation != exclusiveCampaign.MinDonation || reqUpdate.EntryAmount != exclusiveCampaign.EntryAmount || reqUpdate.VerifiedOnly != exclusiveCampaign.VerifiedOnly
+
+	if len(exclusiveCampaign.Winners) > 0 && (rulesChanged || reqUpdate.Prizes != nil) {
+		return exclusiveCampaign, errors.New("the winners are already drawn")
+	}
+
+	if err := validateRewards(reqUpdate.RequestCreateCampaignExclusive); err != nil {
+		return exclusiveCampaign, err
+	}
+
+	before := exclusiveCampaign
 	exclusiveCampaign.ID = reqDetail.ID
 	exclusiveCampaign.CampaignID = reqUpdate.CampaignID
 	exclusiveCampaign.WinnerUserID = reqUpdate.WinnerUserID
 	exclusiveCampaign.IsRewardMoney = reqUpdate.IsRewardMoney
 	exclusiveCampaign.Reward = reqUpdate.Reward
 	exclusiveCampaign.IsPaidOff = reqUpdate.IsPaidOff
+	exclusiveCampaign.MinDonation = reqUpdate.MinDonation
+	exclusiveCampaign.EntryAmount = reqUpdate.EntryAmount
+	exclusiveCampaign.VerifiedOnly = reqUpdate.VerifiedOnly
 	exclusiveCampaign.UpdatedBy = helper.SetNS(strconv.Itoa(reqUpdate.User.ID))
 
 	updatedExclusiveCampaign, err := svc.repo.UpdateCampaignExclusive(exclusiveCampaign)
@@ -413,6 +485,16 @@ func (svc *service) UpdateCampaignExclusive(reqDetail RequestGetCampaignExclusiv
 		return updatedExclusiveCampaign, err
 	}
 
+	if reqUpdate.Prizes != nil {
+		updatedExclusiveCampaign.Prizes, err = svc.repo.ReplaceCampaignExclusivePrizes(updatedExclusiveCampaign.ID, newPrizes(updatedExclusiveCampaign.ID, reqUpdate.Prizes))
+
+		if err != nil {
+			return updatedExclusiveCampaign, err
+		}
+	}
+
+	audit.Record(svc.auditSvc, reqUpdate.User, audit.ActionUpdate, audit.EntityExclusiveCampaign, updatedExclusiveCampaign.ID, before, updatedExclusiveCampaign)
+
 	return updatedExclusiveCampaign, nil
 }
 
@@ -432,12 +514,15 @@ func (svc *service) DeleteCampaignExclusive(reqDetail RequestGetCampaignExclusiv
 		return status, err
 	}
 
+	audit.Record(svc.auditSvc, reqDelete.User, audit.ActionDelete, audit.EntityExclusiveCampaign, prevData.ID, prevData, nil)
+
 	campaign, err := svc.repo.GetCampaignByID(prevData.CampaignID)
 
 	if err != nil {
 		return false, err
 	}
 
+	before := campaign
 	campaign.IsExclusive = 0
 	campaign.UpdatedBy = helper.SetNS(strconv.Itoa(reqDelete.User.ID))
 
@@ -445,6 +530,8 @@ func (svc *service) DeleteCampaignExclusive(reqDetail RequestGetCampaignExclusiv
 		return false, err
 	}
 
+	audit.Record(svc.auditSvc, reqDelete.User, audit.ActionUpdate, audit.EntityCampaign, campaign.ID, before, campaign)
+
 	return status, nil
 }
 
@@ -515,83 +602,425 @@ func (svc *service) CheckAndSetWinnerCampaignExclusive(req RequestGetCampaignExc
 		return exclusiveCampaign, err
 	}
 
-	winnerUserID, err := svc.repo.GetWinnerCampaignExclusive(exclusiveCampaign)
+	if exclusiveCampaign.IsPaidOff == 1 {
+		return exclusiveCampaign, errors.New("no user can be the winner")
+	}
+
+	prizes := drawPrizes(exclusiveCampaign)
+	tiers := []draw.Prize{}
+	prizeByTier := map[int]ExclusiveCampaignPrize{}
+
+	for _, prize := range prizes {
+		tiers = append(tiers, draw.Prize{ID: prize.ID, Tier: prize.Tier, Quantity: prize.Quantity})
+		prizeByTier[prize.Tier] = prize
+	}
+
+	rules := draw.Rules{
+		MinDonation:  exclusiveCampaign.MinDonation,
+		EntryAmount:  exclusiveCampaign.EntryAmount,
+		VerifiedOnly: exclusiveCampaign.VerifiedOnly == 1,
+	}
+
+	// the draw reveals the seed committed when the campaign was made exclusive
+	_, picks, err := svc.drawSvc.Draw(exclusiveCampaign.CampaignID, exclusiveCampaign.ID, rules, tiers)
 
 	if err != nil {
 		return exclusiveCampaign, err
 	}
 
-	if winnerUserID == 0 {
+	if len(picks) == 0 {
 		return exclusiveCampaign, errors.New("no user can be the winner")
 	}
 
-	exclusiveCampaign.WinnerUserID = winnerUserID
+	before := exclusiveCampaign
+	winners := []ExclusiveCampaignWinner{}
+	exclusiveCampaign.IsPaidOff = 1
+
+	for _, pick := range picks {
+		winner := newWinner(exclusiveCampaign, prizeByTier[pick.PrizeTier], pick)
+		winners = append(winners, winner)
 
-	if exclusiveCampaign.IsRewardMoney == 1 {
-		exclusiveCampaign.IsPaidOff = 1
+		// money prizes are credited with the winners, the campaign is paid off once there is nothing else to hand over
+		if winner.IsRewardMoney != 1 {
+			exclusiveCampaign.IsPaidOff = 0
+		}
 	}
 
-	updatedExclusiveCampaign, err := svc.repo.UpdateCampaignExclusive(exclusiveCampaign)
+	exclusiveCampaign.WinnerUserID = winners[0].UserID
+	awarded, err := svc.repo.AwardCampaignExclusiveWinners(exclusiveCampaign, winners)
 
 	if err != nil {
-		return updatedExclusiveCampaign, err
+		return before, err
+	}
+
+	// someone else awarded the winners in between, theirs is the result
+	if !awarded {
+		return svc.repo.GetCampaignExclusiveByCampaignID(req.ID)
 	}
 
-	if updatedExclusiveCampaign.IsRewardMoney == 1 {
-		userData, err := svc.userRepo.GetUserByID(winnerUserID)
+	exclusiveCampaign.Winners = winners
+	audit.Record(svc.auditSvc, user.User{}, audit.ActionUpdate, audit.EntityExclusiveCampaign, exclusiveCampaign.ID, before, exclusiveCampaign)
+
+	for _, winner := range winners {
+		svc.announceWinner(winner)
+	}
+
+	return exclusiveCampaign, nil
+}
+
+// newWinner gives a non-money prize the claim window, a money prize is paid with the award
+func newWinner(exclusiveCampaign ExclusiveCampaign, prize ExclusiveCampaignPrize, pick draw.DrawWinner) ExclusiveCampaignWinner {
+	winner := ExclusiveCampaignWinner{
+		ExclusiveCampaignID: exclusiveCampaign.ID,
+		CampaignID:          exclusiveCampaign.CampaignID,
+		PrizeID:             prize.ID,
+		Tier:                prize.Tier,
+		Name:                prize.Name,
+		Pick:                pick.Pick,
+		UserID:              pick.UserID,
+		IsRewardMoney:       prize.IsRewardMoney,
+		Reward:              prize.Reward,
+		IsPaidOff:           prize.IsRewardMoney,
+		Status:              RewardStatusPaid,
+	}
+
+	if prize.IsRewardMoney != 1 {
+		winner.Status = RewardStatusUnclaimed
+		winner.ClaimDeadline = sql.NullTime{Time: time.Now().Add(constant.REWARD_CLAIM_WINDOW), Valid: true}
+	}
+
+	return winner
+}
+
+// announceWinner tells the winner and the campaign owner's webhooks, a failure here does not undo the award
+func (svc *service) announceWinner(winner ExclusiveCampaignWinner) {
+	winnerUserData, err := svc.userRepo.GetUserByID(winner.UserID)
+
+	if err != nil {
+		log.Printf("[CAMPAIGN] winner %v of exclusive campaign %v not announced, err: %s", winner.ID, winner.CampaignID, err.Error())
+		return
+	}
+
+	status := "Paid Off"
+
+	if winner.Status == RewardStatusUnclaimed {
+		status = fmt.Sprintf("Claim before %v", winner.ClaimDeadline.Time.Format("02 Jan 2006 15:04"))
+	}
+
+	templateData := helper.EmailEarningRewardFromExclusiveCampaign{
+		CampaignLink: os.Getenv("WEB_URL") + "/donate/" + strconv.Itoa(winner.CampaignID),
+		Name:         winnerUserData.Name,
+		Reward:       rewardLabel(winner),
+		Status:       status,
+	}
+	if err := helper.SendNotification(helper.NotificationRecipient{UserID: winnerUserData.ID, Email: winnerUserData.Email, Locale: winnerUserData.Locale}, helper.EmailTemplateEarnReward, templateData); err != nil {
+		log.Printf("[CAMPAIGN] winner %v of exclusive campaign %v not notified, err: %s", winner.ID, winner.CampaignID, err.Error())
+	}
+
+	if campaignData, err := svc.repo.GetCampaignByID(winner.CampaignID); err == nil {
+		err = helper.PublishWebhookEvent(helper.WebhookEventExclusiveWinnerSelected, campaignData.UserID, helper.WebhookExclusiveWinnerSelected{
+			ExclusiveCampaignID: winner.ExclusiveCampaignID,
+			CampaignID:          winner.CampaignID,
+			WinnerUserID:        winner.UserID,
+			PrizeTier:           winner.Tier,
+			PrizeName:           winner.Name,
+			Reward:              winner.Reward,
+			IsRewardMoney:       winner.IsRewardMoney == 1,
+			SelectedAt:          time.Now(),
+		})
 
 		if err != nil {
-			return updatedExclusiveCampaign, err
+			log.Printf("[CAMPAIGN] winner %v of exclusive campaign %v not published, err: %s", winner.ID, winner.CampaignID, err.Error())
 		}
+	}
+}
 
-		moneyReward, err := strconv.Atoi(updatedExclusiveCampaign.Reward)
+// notifyRewardUpdate sends reward_update to the winner with the status in words
+func (svc *service) notifyRewardUpdate(winner ExclusiveCampaignWinner, status string) {
+	winnerUserData, err := svc.userRepo.GetUserByID(winner.UserID)
 
-		if err != nil {
-			return updatedExclusiveCampaign, err
+	if err != nil {
+		log.Printf("[CAMPAIGN] reward update of winner %v not sent, err: %s", winner.ID, err.Error())
+		return
+	}
+
+	templateData := helper.EmailRewardUpdate{
+		CampaignLink: os.Getenv("WEB_URL") + "/donate/" + strconv.Itoa(winner.CampaignID),
+		Name:         winnerUserData.Name,
+		Reward:       rewardLabel(winner),
+		Status:       status,
+	}
+	if err := helper.SendNotification(helper.NotificationRecipient{UserID: winnerUserData.ID, Email: winnerUserData.Email, Locale: winnerUserData.Locale}, helper.EmailTemplateRewardUpdate, templateData); err != nil {
+		log.Printf("[CAMPAIGN] reward update of winner %v not sent, err: %s", winner.ID, err.Error())
+	}
+}
+
+func rewardLabel(winner ExclusiveCampaignWinner) string {
+	reward := winner.Reward
+
+	if winner.IsRewardMoney == 1 {
+		amount, _ := strconv.Atoi(winner.Reward)
+		reward = helper.FormatRupiah(float64(amount))
+	}
+
+	if winner.Name != "" {
+		reward = fmt.Sprintf("%v (%v)", winner.Name, reward)
+	}
+
+	return reward
+}
+
+func (svc *service) GetRewardsByUserID(userData user.User) ([]ExclusiveCampaignWinner, error) {
+	winners, err := svc.repo.GetCampaignExclusiveWinnersByUserID(userData.ID)
+
+	if err != nil {
+		return winners, err
+	}
+
+	return winners, nil
+}
+
+func (svc *service) GetRewardByID(req RequestGetRewardByID) (ExclusiveCampaignWinner, error) {
+	winner, err := svc.repo.GetCampaignExclusiveWinnerByID(req.ID)
+
+	if err != nil {
+		return winner, err
+	}
+
+	return winner, nil
+}
+
+// ClaimReward takes the details to hand the reward over, only from its winner and only before the deadline
+func (svc *service) ClaimReward(reqDetail RequestGetRewardByID, reqClaim RequestClaimReward) (ExclusiveCampaignWinner, error) {
+	winner, err := svc.repo.GetCampaignExclusiveWinnerByID(reqDetail.ID)
+
+	if err != nil {
+		return winner, err
+	}
+
+	// someone else's reward is not found, not forbidden
+	if winner.UserID != reqClaim.User.ID {
+		return ExclusiveCampaignWinner{}, errors.New("sql: no rows in result set")
+	}
+
+	if winner.Status != RewardStatusUnclaimed {
+		return winner, errors.New("the reward can not be claimed anymore")
+	}
+
+	if time.Now().After(winner.ClaimDeadline.Time) {
+		return winner, errors.New("the claim deadline has passed")
+	}
+
+	before := winner
+	winner.Status = RewardStatusPending
+	winner.ClaimedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	winner.RecipientName = helper.SetNS(reqClaim.RecipientName)
+	winner.RecipientPhone = helper.SetNS(reqClaim.RecipientPhone)
+	winner.ShippingAddress = helper.SetNS(reqClaim.ShippingAddress)
+	winner.Notes = helper.SetNS(reqClaim.Notes)
+
+	updated, err := svc.repo.UpdateCampaignExclusiveWinner(winner, RewardStatusUnclaimed)
+
+	if err != nil {
+		return before, err
+	}
+
+	if !updated {
+		return before, errors.New("the reward can not be claimed anymore")
+	}
+
+	audit.Record(svc.auditSvc, reqClaim.User, audit.ActionUpdate, audit.EntityExclusiveWinner, winner.ID, before, winner)
+	svc.notifyRewardUpdate(winner, "Claimed, waiting to be shipped")
+
+	return winner, nil
+}
+
+// UpdateRewardFulfillment moves a claimed reward to shipped with its tracking number and then to delivered
+func (svc *service) UpdateRewardFulfillment(reqDetail RequestGetRewardByID, reqUpdate RequestUpdateRewardFulfillment) (ExclusiveCampaignWinner, error) {
+	winner, err := svc.repo.GetCampaignExclusiveWinnerByID(reqDetail.ID)
+
+	if err != nil {
+		return winner, err
+	}
+
+	before := winner
+	fromStatus := RewardStatusPending
+	status := ""
+
+	if reqUpdate.Status == RewardStatusDelivered {
+		fromStatus = RewardStatusShipped
+	}
+
+	if winner.Status != fromStatus {
+		return winner, fmt.Errorf("a reward that is %v can not be marked as %v", winner.Status, reqUpdate.Status)
+	}
+
+	switch reqUpdate.Status {
+	case RewardStatusShipped:
+		if reqUpdate.TrackingNumber == "" {
+			return winner, errors.New("tracking number is required to ship the reward")
+		}
+
+		winner.Courier = helper.SetNS(reqUpdate.Courier)
+		winner.TrackingNumber = helper.SetNS(reqUpdate.TrackingNumber)
+		winner.ShippedAt = sql.NullTime{Time: time.Now(), Valid: true}
+		status = fmt.Sprintf("Shipped, tracking number %v", reqUpdate.TrackingNumber)
+
+		if reqUpdate.Courier != "" {
+			status = fmt.Sprintf("Shipped with %v, tracking number %v", reqUpdate.Courier, reqUpdate.TrackingNumber)
 		}
+	case RewardStatusDelivered:
+		winner.DeliveredAt = sql.NullTime{Time: time.Now(), Valid: true}
+		winner.IsPaidOff = 1
+		status = "Delivered"
+	}
+
+	winner.Status = reqUpdate.Status
+	updated, err := svc.repo.UpdateCampaignExclusiveWinner(winner, fromStatus)
+
+	if err != nil {
+		return before, err
+	}
 
-		userData.EMoney = userData.EMoney + float64(moneyReward)
-		userData, err = svc.userRepo.UpdateUser(userData)
+	if !updated {
+		return before, errors.New("the reward was updated by someone else, reload and try again")
+	}
+
+	audit.Record(svc.auditSvc, reqUpdate.User, audit.ActionUpdate, audit.EntityExclusiveWinner, winner.ID, before, winner)
+	svc.notifyRewardUpdate(winner, status)
+
+	return winner, nil
+}
+
+// ExpireRewardClaims expires the rewards not claimed in time and redraws each of them,
+// it returns how many expired, a claim that fails is logged and tried again on the next run
+func (svc *service) ExpireRewardClaims() (int, error) {
+	claims, err := svc.repo.GetExpiredRewardClaims(time.Now(), constant.REWARD_EXPIRY_BATCH_SIZE)
+
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+
+	for _, claim := range claims {
+		done, err := svc.expireRewardClaim(claim)
 
 		if err != nil {
-			return updatedExclusiveCampaign, err
+			log.Printf("[CAMPAIGN] reward claim %v not expired yet, err: %s", claim.ID, err.Error())
+			continue
 		}
 
-		svc.userRepo.CreateEMoneyFlow(user.UserEMoneyFlow{
-			UserID: userData.ID,
-			Status: "in",
-			Amount: int64(moneyReward),
-			Note:   fmt.Sprintf("Reward from exclusive campaign id %v.", updatedExclusiveCampaign.CampaignID),
-		})
+		if done {
+			expired++
+		}
+	}
 
-		svc.companyRepo.CreateCompanyCashFlow(company.CompanyCashFlow{
-			Status: "out",
-			Amount: int64(moneyReward),
-			Note:   fmt.Sprintf("Reward for exclusive campaign id %v.", updatedExclusiveCampaign.CampaignID),
-		})
+	return expired, nil
+}
+
+func (svc *service) expireRewardClaim(claim ExclusiveCampaignWinner) (bool, error) {
+	var replacement *ExclusiveCampaignWinner
+
+	// the replacement is the next pick of the same draw, asking again after a crash gives the same one
+	pick, err := svc.drawSvc.Redraw(claim.CampaignID, claim.Pick)
+
+	if err != nil && !errors.Is(err, draw.ErrNoRedraw) {
+		return false, err
+	}
+
+	if err == nil {
+		exclusiveCampaign := ExclusiveCampaign{}
+		exclusiveCampaign.ID = claim.ExclusiveCampaignID
+		exclusiveCampaign.CampaignID = claim.CampaignID
+
+		prize := ExclusiveCampaignPrize{ID: claim.PrizeID, Tier: claim.Tier, Name: claim.Name, IsRewardMoney: claim.IsRewardMoney, Reward: claim.Reward}
+		winner := newWinner(exclusiveCampaign, prize, pick)
+		winner.ReplacesWinnerID = claim.ID
+		replacement = &winner
 	}
 
-	winnerUserData, err := svc.userRepo.GetUserByID(winnerUserID)
+	before := claim
+	claim.Status = RewardStatusExpired
+	claim.ExpiredAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+	done, err := svc.repo.ExpireCampaignExclusiveWinner(claim, replacement)
+
+	if err != nil || !done {
+		return false, err
+	}
+
+	audit.Record(svc.auditSvc, user.User{}, audit.ActionUpdate, audit.EntityExclusiveWinner, claim.ID, before, claim)
+	svc.notifyRewardUpdate(claim, "Expired, the claim deadline has passed")
+
+	if replacement != nil {
+		audit.Record(svc.auditSvc, user.User{}, audit.ActionCreate, audit.EntityExclusiveWinner, replacement.ID, nil, *replacement)
+		svc.announceWinner(*replacement)
+	}
+
+	return true, nil
+}
+
+func (svc *service) AdminDataTablesRewards(ctx *gin.Context) (helper.DataTables, error) {
+	dataTablesRewards, err := svc.repo.AdminDataTablesRewards(ctx)
 
 	if err != nil {
-		return updatedExclusiveCampaign, err
+		return dataTablesRewards, err
+	}
+
+	return dataTablesRewards, nil
+}
+
+// drawPrizes are the tiers to draw, a campaign without tiers has one winner for its reward
+func drawPrizes(exclusiveCampaign ExclusiveCampaign) []ExclusiveCampaignPrize {
+	if len(exclusiveCampaign.Prizes) > 0 {
+		return exclusiveCampaign.Prizes
+	}
+
+	return []ExclusiveCampaignPrize{{
+		Tier:          1,
+		IsRewardMoney: exclusiveCampaign.IsRewardMoney,
+		Reward:        exclusiveCampaign.Reward,
+		Quantity:      1,
+	}}
+}
+
+func newPrizes(exclusiveCampaignID int, reqPrizes []RequestCreateCampaignExclusivePrize) []ExclusiveCampaignPrize {
+	prizes := []ExclusiveCampaignPrize{}
+
+	for i, reqPrize := range reqPrizes {
+		prizes = append(prizes, ExclusiveCampaignPrize{
+			ExclusiveCampaignID: exclusiveCampaignID,
+			Tier:                i + 1,
+			Name:                reqPrize.Name,
+			IsRewardMoney:       reqPrize.IsRewardMoney,
+			Reward:              reqPrize.Reward,
+			Quantity:            reqPrize.Quantity,
+		})
 	}
 
-	{
-		status := "Pending"
+	return prizes
+}
+
+// validateRewards checks money rewards are whole rupiah now instead of when the winner is paid
+func validateRewards(req RequestCreateCampaignExclusive) error {
+	rewards := []RequestCreateCampaignExclusivePrize{}
+
+	if len(req.Prizes) == 0 {
+		rewards = append(rewards, RequestCreateCampaignExclusivePrize{IsRewardMoney: req.IsRewardMoney, Reward: req.Reward})
+	}
+
+	rewards = append(rewards, req.Prizes...)
 
-		if updatedExclusiveCampaign.IsPaidOff == 1 {
-			status = "Paid Off"
+	for _, reward := range rewards {
+		if reward.IsRewardMoney != 1 {
+			continue
 		}
 
-		templateData := helper.EmailEarningRewardFromExclusiveCampaign{
-			CampaignLink: os.Getenv("WEB_URL") + "/donate/" + strconv.Itoa(updatedExclusiveCampaign.CampaignID),
-			Name:         winnerUserData.Name,
-			Reward:       updatedExclusiveCampaign.Reward,
-			Status:       status,
+		if amount, err := strconv.ParseInt(reward.Reward, 10, 64); err != nil || amount <= 0 {
+			return fmt.Errorf("money reward %q is not a whole amount of rupiah", reward.Reward)
 		}
-		go helper.SendMail(winnerUserData.Email, "Congratulations, You Get Rewards From Exclusive Campaign!", templateData, "html/earn_reward.html")
 	}
 
-	return exclusiveCampaign, nil
+	return nil
 }
@@ -0,0 +1,83 @@
+package campaign
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/audit"
+	"github.com/WeAreAmazingTeam/tcd-backend/event"
+	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+	"github.com/WeAreAmazingTeam/tcd-backend/user"
+)
+
+// RegisterSubscribers hangs the campaign side of a settlement on the bus, every subscriber may run
+// more than once for the same event (a retry after a crash) so each one checks the state it changes.
+// What follows a finished campaign is the finalization package.
+func (svc *service) RegisterSubscribers(bus *event.Bus) {
+	event.On(bus, "campaign.finish_on_goal", svc.finishOnGoal)
+	event.On(bus, "campaign.webhook_goal_reached", svc.publishGoalReachedWebhook)
+}
+
+func (svc *service) finishOnGoal(paid event.DonationPaid) error {
+	campaign, err := svc.repo.GetCampaignByID(paid.CampaignID)
+
+	if err != nil {
+		return err
+	}
+
+	if campaign.Status != "active" || campaign.CurrentAmount < campaign.GoalAmount {
+		return nil
+	}
+
+	before := campaign
+	now := time.Now()
+	campaign.Status = "finished"
+	campaign.UpdatedBy = helper.SetNS("MIDTRANS")
+
+	if paid.PaidWith == event.PaidWithEMoney {
+		campaign.UpdatedBy = helper.SetNS(fmt.Sprintf("Transaction with e-money by user id %v.", paid.UserID))
+	}
+
+	finished, err := svc.repo.FinishCampaign(campaign,
+		event.CampaignGoalReached{
+			CampaignID:      campaign.ID,
+			OwnerUserID:     campaign.UserID,
+			GoalAmount:      campaign.GoalAmount,
+			CollectedAmount: campaign.CurrentAmount,
+			ReachedAt:       now,
+		},
+		event.CampaignFinished{
+			CampaignID:      campaign.ID,
+			OwnerUserID:     campaign.UserID,
+			Reason:          event.FinishReasonGoalReached,
+			CollectedAmount: campaign.CurrentAmount,
+			FinishedAt:      now,
+		},
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if finished {
+		audit.Record(svc.auditSvc, user.User{}, audit.ActionUpdate, audit.EntityCampaign, campaign.ID, before, campaign)
+	}
+
+	return nil
+}
+
+func (svc *service) publishGoalReachedWebhook(reached event.CampaignGoalReached) error {
+	campaign, err := svc.repo.GetCampaignByID(reached.CampaignID)
+
+	if err != nil {
+		return err
+	}
+
+	return helper.PublishWebhookEvent(helper.WebhookEventCampaignGoalReached, reached.OwnerUserID, helper.WebhookCampaignGoalReached{
+		CampaignID:      campaign.ID,
+		Title:           campaign.Title,
+		GoalAmount:      reached.GoalAmount,
+		CollectedAmount: reached.CollectedAmount,
+		ReachedAt:       reached.ReachedAt,
+	})
+}
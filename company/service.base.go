@@ -1,6 +1,7 @@
 package company
 
 import (
+	"github.com/WeAreAmazingTeam/tcd-backend/audit"
 	"github.com/WeAreAmazingTeam/tcd-backend/helper"
 	"github.com/gin-gonic/gin"
 )
@@ -13,13 +14,16 @@ type Service interface {
 }
 
 type service struct {
-	repo Repository
+	repo     Repository
+	auditSvc audit.Service
 }
 
 func NewService(
 	repository Repository,
+	auditService audit.Service,
 ) *service {
 	return &service{
-		repo: repository,
+		repo:     repository,
+		auditSvc: auditService,
 	}
 }
@@ -3,6 +3,7 @@ package company
 import (
 	"strconv"
 
+	"github.com/WeAreAmazingTeam/tcd-backend/audit"
 	"github.com/WeAreAmazingTeam/tcd-backend/constant"
 	"github.com/WeAreAmazingTeam/tcd-backend/helper"
 	"github.com/gin-gonic/gin"
@@ -21,6 +22,8 @@ func (svc *service) CreateCompanyCashFlow(req RequestCreateCompanyCashFlow) (Com
 		return companyCashFlowData, err
 	}
 
+	audit.Record(svc.auditSvc, req.User, audit.ActionCreate, audit.EntityCompanyCashFlow, companyCashFlowData.ID, nil, companyCashFlowData)
+
 	return companyCashFlowData, nil
 }
 
@@ -32,6 +35,7 @@ func (svc *service) DeleteCompanyCashFlow(reqDetail RequestGetCompanyCashFlowByI
 			return false, err
 		}
 
+		before := companyCashFlow
 		companyCashFlow.UpdatedBy = helper.SetNS(strconv.Itoa(reqDelete.User.ID))
 		companyCashFlow.DeletedAt = *helper.SetNowNT()
 		companyCashFlow.DeletedBy = helper.SetNS(strconv.Itoa(reqDelete.User.ID))
@@ -42,9 +46,13 @@ func (svc *service) DeleteCompanyCashFlow(reqDetail RequestGetCompanyCashFlowByI
 			return status, err
 		}
 
+		audit.Record(svc.auditSvc, reqDelete.User, audit.ActionDelete, audit.EntityCompanyCashFlow, companyCashFlow.ID, before, nil)
+
 		return status, nil
 	}
 
+	before, _ := svc.repo.GetCompanyCashFlowByID(reqDetail.ID)
+
 	companyCashFlow := CompanyCashFlow{}
 	companyCashFlow.ID = reqDetail.ID
 	status, err := svc.repo.DeleteCompanyCashFlow(companyCashFlow)
@@ -53,6 +61,8 @@ func (svc *service) DeleteCompanyCashFlow(reqDetail RequestGetCompanyCashFlowByI
 		return status, err
 	}
 
+	audit.Record(svc.auditSvc, reqDelete.User, audit.ActionDelete, audit.EntityCompanyCashFlow, reqDetail.ID, before, nil)
+
 	return status, nil
 }
 
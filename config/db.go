@@ -9,7 +9,6 @@ import (
 	"github.com/Pacific73/gorm-cache/config"
 	"github.com/WeAreAmazingTeam/tcd-backend/constant"
 	"github.com/WeAreAmazingTeam/tcd-backend/helper"
-	"github.com/go-redis/redis"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	"gorm.io/plugin/dbresolver"
@@ -49,37 +48,10 @@ func InitDB(isProduction bool) *gorm.DB {
 	)
 
 	if constant.DB_CACHING {
-		var redisClient *redis.Client
+		redisClient, err := NewRedisClient(isProduction)
 
-		if isProduction {
-			redisOptions := &redis.Options{
-				Addr:     fmt.Sprintf("%v:%v", constant.REDIS_HOST, constant.REDIS_PORT),
-				Password: constant.REDIS_PASS,
-			}
-
-			redisClient = redis.NewClient(redisOptions)
-		} else {
-			redisOptions := &redis.Options{
-				Addr: fmt.Sprintf("%v:%v", constant.REDIS_HOST, constant.REDIS_PORT),
-			}
-
-			redisClient = redis.NewClient(redisOptions)
-		}
-
-		if _, err := redisClient.Ping().Result(); err != nil {
-			if err.Error() == "ERR AUTH <password> called without any password configured for the default user. Are you sure your configuration is correct?" {
-				redisOptions := &redis.Options{
-					Addr: fmt.Sprintf("%v:%v", constant.REDIS_HOST, constant.REDIS_PORT),
-				}
-
-				redisClient = redis.NewClient(redisOptions)
-
-				if _, err := redisClient.Ping().Result(); err != nil {
-					log.Fatal("error connection to redis server, error: ", err.Error())
-				}
-			} else {
-				log.Fatal("error connection to redis server, error: ", err.Error())
-			}
+		if err != nil {
+			log.Fatal("error connection to redis server, error: ", err.Error())
 		}
 
 		gormCache, _ := cache.NewGorm2Cache(&config.CacheConfig{
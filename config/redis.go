@@ -0,0 +1,45 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/constant"
+	"github.com/go-redis/redis"
+)
+
+func NewRedisClient(isProduction bool) (*redis.Client, error) {
+	var redisClient *redis.Client
+
+	if isProduction {
+		redisOptions := &redis.Options{
+			Addr:     fmt.Sprintf("%v:%v", constant.REDIS_HOST, constant.REDIS_PORT),
+			Password: constant.REDIS_PASS,
+		}
+
+		redisClient = redis.NewClient(redisOptions)
+	} else {
+		redisOptions := &redis.Options{
+			Addr: fmt.Sprintf("%v:%v", constant.REDIS_HOST, constant.REDIS_PORT),
+		}
+
+		redisClient = redis.NewClient(redisOptions)
+	}
+
+	if _, err := redisClient.Ping().Result(); err != nil {
+		if err.Error() != "ERR AUTH <password> called without any password configured for the default user. Are you sure your configuration is correct?" {
+			return redisClient, err
+		}
+
+		redisOptions := &redis.Options{
+			Addr: fmt.Sprintf("%v:%v", constant.REDIS_HOST, constant.REDIS_PORT),
+		}
+
+		redisClient = redis.NewClient(redisOptions)
+
+		if _, err := redisClient.Ping().Result(); err != nil {
+			return redisClient, err
+		}
+	}
+
+	return redisClient, nil
+}
@@ -0,0 +1,12 @@
+package constant
+
+var (
+	// amounts from which a second admin has to approve the action, 0 turns the rule off
+	APPROVAL_WITHDRAWAL_THRESHOLD int64
+	APPROVAL_CASH_FLOW_THRESHOLD  int64
+)
+
+func InitApprovalConstant() {
+	APPROVAL_WITHDRAWAL_THRESHOLD = int64(parseIntEnv("APPROVAL_WITHDRAWAL_THRESHOLD", 5000000))
+	APPROVAL_CASH_FLOW_THRESHOLD = int64(parseIntEnv("APPROVAL_CASH_FLOW_THRESHOLD", 10000000))
+}
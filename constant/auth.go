@@ -1,9 +1,62 @@
 package constant
 
-import "os"
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
 
-var SecretKey []byte
+var (
+	SecretKey                       []byte
+	TwoFactorIssuer                 string
+	EmailVerificationTTL            time.Duration
+	EmailVerificationResendInterval time.Duration
+	ForgotPasswordTTL               time.Duration
+	// accounts created before this date are treated as verified
+	EmailVerificationRequiredSince time.Time
+)
 
 func InitAuthConstant() {
 	SecretKey = []byte(os.Getenv("SECRET_KEY"))
+	TwoFactorIssuer = os.Getenv("TWO_FACTOR_ISSUER")
+
+	if TwoFactorIssuer == "" {
+		TwoFactorIssuer = "The Cloud Donation"
+	}
+
+	// in hours, default is one day like the forgot password token
+	emailVerificationTTL, err := strconv.Atoi(os.Getenv("EMAIL_VERIFICATION_TTL"))
+
+	if err != nil || emailVerificationTTL <= 0 {
+		emailVerificationTTL = 24
+	}
+
+	EmailVerificationTTL = time.Duration(emailVerificationTTL) * time.Hour
+
+	// in seconds, minimum gap between two verification emails for the same user
+	emailVerificationResendInterval, err := strconv.Atoi(os.Getenv("EMAIL_VERIFICATION_RESEND_INTERVAL"))
+
+	if err != nil || emailVerificationResendInterval <= 0 {
+		emailVerificationResendInterval = 60
+	}
+
+	EmailVerificationResendInterval = time.Duration(emailVerificationResendInterval) * time.Second
+
+	// YYYY-MM-DD, the day email verification was released. Without it every existing account would lose the
+	// routes that need a verified email, so it is required.
+	EmailVerificationRequiredSince, err = time.ParseInLocation("2006-01-02", os.Getenv("EMAIL_VERIFICATION_REQUIRED_SINCE"), time.Local)
+
+	if err != nil {
+		log.Fatal("EMAIL_VERIFICATION_REQUIRED_SINCE must be the YYYY-MM-DD date email verification was released, err: ", err.Error())
+	}
+
+	// in minutes, how long a forgot password link can be used
+	forgotPasswordTTL, err := strconv.Atoi(os.Getenv("FORGOT_PASSWORD_TTL"))
+
+	if err != nil || forgotPasswordTTL <= 0 {
+		forgotPasswordTTL = 60
+	}
+
+	ForgotPasswordTTL = time.Duration(forgotPasswordTTL) * time.Minute
 }
@@ -0,0 +1,19 @@
+package constant
+
+import "time"
+
+var (
+	EVENT_POLL_INTERVAL time.Duration
+	EVENT_BATCH_SIZE    int
+	EVENT_MAX_ATTEMPTS  int
+	EVENT_BACKOFF_BASE  time.Duration
+	EVENT_BACKOFF_MAX   time.Duration
+)
+
+func InitEventConstant() {
+	EVENT_POLL_INTERVAL = parseDurationEnv("EVENT_POLL_INTERVAL", 5*time.Second)
+	EVENT_BATCH_SIZE = parseIntEnv("EVENT_BATCH_SIZE", 50)
+	EVENT_MAX_ATTEMPTS = parseIntEnv("EVENT_MAX_ATTEMPTS", 10)
+	EVENT_BACKOFF_BASE = parseDurationEnv("EVENT_BACKOFF_BASE", 10*time.Second)
+	EVENT_BACKOFF_MAX = parseDurationEnv("EVENT_BACKOFF_MAX", time.Hour)
+}
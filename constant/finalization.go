@@ -0,0 +1,11 @@
+package constant
+
+var (
+	FINALIZATION_BATCH_SIZE   int
+	FINALIZATION_MAX_ATTEMPTS int
+)
+
+func InitFinalizationConstant() {
+	FINALIZATION_BATCH_SIZE = parseIntEnv("FINALIZATION_BATCH_SIZE", 50)
+	FINALIZATION_MAX_ATTEMPTS = parseIntEnv("FINALIZATION_MAX_ATTEMPTS", 10)
+}
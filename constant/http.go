@@ -0,0 +1,26 @@
+package constant
+
+import (
+	"os"
+	"strings"
+)
+
+var (
+	// addresses or CIDRs of the reverse proxies in front of the app, comma separated. The client ip is read from
+	// X-Forwarded-For only when the request comes from one of them.
+	TRUSTED_PROXIES []string
+	// header a CDN or platform sets to the client ip, e.g. "CF-Connecting-IP", empty to not trust any
+	TRUSTED_PLATFORM string
+)
+
+func InitHTTPConstant() {
+	TRUSTED_PROXIES = nil
+
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			TRUSTED_PROXIES = append(TRUSTED_PROXIES, proxy)
+		}
+	}
+
+	TRUSTED_PLATFORM = strings.TrimSpace(os.Getenv("TRUSTED_PLATFORM"))
+}
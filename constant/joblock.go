@@ -0,0 +1,20 @@
+package constant
+
+import (
+	"os"
+	"time"
+)
+
+var (
+	JOB_LOCK_DRIVER string
+	JOB_LOCK_TTL    time.Duration
+)
+
+func InitJobLockConstant() {
+	JOB_LOCK_DRIVER = os.Getenv("JOB_LOCK_DRIVER")
+	JOB_LOCK_TTL = parseDurationEnv("JOB_LOCK_TTL", 30*time.Second)
+
+	if JOB_LOCK_DRIVER == "" {
+		JOB_LOCK_DRIVER = "redis"
+	}
+}
@@ -0,0 +1,23 @@
+package constant
+
+import "time"
+
+var (
+	JOB_QUEUE_POLL_INTERVAL time.Duration
+	JOB_QUEUE_CONCURRENCY   int
+	JOB_QUEUE_MAX_ATTEMPTS  int
+	JOB_QUEUE_BACKOFF_BASE  time.Duration
+	JOB_QUEUE_BACKOFF_MAX   time.Duration
+)
+
+func InitJobQueueConstant() {
+	JOB_QUEUE_POLL_INTERVAL = parseDurationEnv("JOB_QUEUE_POLL_INTERVAL", 5*time.Second)
+	JOB_QUEUE_CONCURRENCY = parseIntEnv("JOB_QUEUE_CONCURRENCY", 4)
+	JOB_QUEUE_MAX_ATTEMPTS = parseIntEnv("JOB_QUEUE_MAX_ATTEMPTS", 8)
+	JOB_QUEUE_BACKOFF_BASE = parseDurationEnv("JOB_QUEUE_BACKOFF_BASE", 30*time.Second)
+	JOB_QUEUE_BACKOFF_MAX = parseDurationEnv("JOB_QUEUE_BACKOFF_MAX", time.Hour)
+
+	if JOB_QUEUE_CONCURRENCY < 1 {
+		JOB_QUEUE_CONCURRENCY = 1
+	}
+}
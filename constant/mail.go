@@ -0,0 +1,82 @@
+package constant
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// providers tried in order for every outbox message, e.g. "smtp,backup_smtp,mailgun" or "file" for development
+	MAIL_PROVIDERS     []string
+	MAIL_FILE_SINK_DIR string
+
+	MAIL_OUTBOX_POLL_INTERVAL time.Duration
+	MAIL_OUTBOX_BATCH_SIZE    int
+	MAIL_OUTBOX_MAX_ATTEMPTS  int
+	MAIL_OUTBOX_BACKOFF_BASE  time.Duration
+	MAIL_OUTBOX_BACKOFF_MAX   time.Duration
+
+	// locales with a template folder under html/, users without a locale get MAIL_DEFAULT_LOCALE
+	MAIL_LOCALES        = []string{"en", "id"}
+	MAIL_DEFAULT_LOCALE string
+
+	// public base url of this api, used in the List-Unsubscribe header
+	API_URL string
+
+	// signs the bounce and complaint webhooks sent by mailgun
+	MAILGUN_WEBHOOK_SIGNING_KEY string
+
+	// a provider is skipped for MAIL_BREAKER_COOLDOWN after MAIL_BREAKER_THRESHOLD consecutive failures
+	MAIL_BREAKER_THRESHOLD int
+	MAIL_BREAKER_COOLDOWN  time.Duration
+)
+
+func InitMailConstant() {
+	MAIL_PROVIDERS = []string{}
+
+	for _, provider := range strings.Split(os.Getenv("MAIL_PROVIDERS"), ",") {
+		if provider = strings.TrimSpace(provider); provider != "" {
+			MAIL_PROVIDERS = append(MAIL_PROVIDERS, provider)
+		}
+	}
+
+	if len(MAIL_PROVIDERS) == 0 {
+		MAIL_PROVIDERS = []string{"smtp", "backup_smtp", "mailgun"}
+	}
+
+	MAIL_FILE_SINK_DIR = os.Getenv("MAIL_FILE_SINK_DIR")
+
+	if MAIL_FILE_SINK_DIR == "" {
+		MAIL_FILE_SINK_DIR = "./storage/mails"
+	}
+
+	MAIL_DEFAULT_LOCALE = os.Getenv("MAIL_DEFAULT_LOCALE")
+
+	if MAIL_DEFAULT_LOCALE != "en" && MAIL_DEFAULT_LOCALE != "id" {
+		MAIL_DEFAULT_LOCALE = "en"
+	}
+
+	API_URL = strings.TrimRight(os.Getenv("API_URL"), "/")
+	MAILGUN_WEBHOOK_SIGNING_KEY = os.Getenv("MAILGUN_WEBHOOK_SIGNING_KEY")
+
+	MAIL_OUTBOX_POLL_INTERVAL = parseDurationEnv("MAIL_OUTBOX_POLL_INTERVAL", 10*time.Second)
+	MAIL_OUTBOX_BATCH_SIZE = parseIntEnv("MAIL_OUTBOX_BATCH_SIZE", 20)
+	MAIL_OUTBOX_MAX_ATTEMPTS = parseIntEnv("MAIL_OUTBOX_MAX_ATTEMPTS", 8)
+	MAIL_OUTBOX_BACKOFF_BASE = parseDurationEnv("MAIL_OUTBOX_BACKOFF_BASE", 30*time.Second)
+	MAIL_OUTBOX_BACKOFF_MAX = parseDurationEnv("MAIL_OUTBOX_BACKOFF_MAX", 6*time.Hour)
+
+	MAIL_BREAKER_THRESHOLD = parseIntEnv("MAIL_BREAKER_THRESHOLD", 5)
+	MAIL_BREAKER_COOLDOWN = parseDurationEnv("MAIL_BREAKER_COOLDOWN", time.Minute)
+}
+
+func parseIntEnv(key string, def int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+
+	if err != nil || value <= 0 {
+		return def
+	}
+
+	return value
+}
@@ -0,0 +1,50 @@
+package constant
+
+import (
+	"os"
+	"strings"
+	"time"
+)
+
+var (
+	// "gateway" for the http sms/whatsapp gateway, "fcm" for push, "fake" logs and keeps messages in memory, empty turns the channel off
+	SMS_PROVIDER      string
+	WHATSAPP_PROVIDER string
+	PUSH_PROVIDER     string
+
+	SMS_GATEWAY_URL   string
+	SMS_GATEWAY_TOKEN string
+	SMS_SENDER_ID     string
+
+	WHATSAPP_GATEWAY_URL   string
+	WHATSAPP_GATEWAY_TOKEN string
+
+	FCM_URL        string
+	FCM_SERVER_KEY string
+
+	// upper bound for one channel to accept a notification
+	NOTIFICATION_CHANNEL_TIMEOUT time.Duration
+)
+
+func InitNotificationConstant() {
+	SMS_PROVIDER = strings.TrimSpace(os.Getenv("SMS_PROVIDER"))
+	WHATSAPP_PROVIDER = strings.TrimSpace(os.Getenv("WHATSAPP_PROVIDER"))
+	PUSH_PROVIDER = strings.TrimSpace(os.Getenv("PUSH_PROVIDER"))
+
+	SMS_GATEWAY_URL = os.Getenv("SMS_GATEWAY_URL")
+	SMS_GATEWAY_TOKEN = os.Getenv("SMS_GATEWAY_TOKEN")
+	SMS_SENDER_ID = os.Getenv("SMS_SENDER_ID")
+
+	WHATSAPP_GATEWAY_URL = os.Getenv("WHATSAPP_GATEWAY_URL")
+	WHATSAPP_GATEWAY_TOKEN = os.Getenv("WHATSAPP_GATEWAY_TOKEN")
+
+	FCM_URL = os.Getenv("FCM_URL")
+
+	if FCM_URL == "" {
+		FCM_URL = "https://fcm.googleapis.com/fcm/send"
+	}
+
+	FCM_SERVER_KEY = os.Getenv("FCM_SERVER_KEY")
+
+	NOTIFICATION_CHANNEL_TIMEOUT = parseDurationEnv("NOTIFICATION_CHANNEL_TIMEOUT", 15*time.Second)
+}
@@ -0,0 +1,37 @@
+package constant
+
+import (
+	"os"
+	"strings"
+	"time"
+)
+
+var (
+	// "xendit", or "fake" that answers locally without moving money; empty turns payouts off
+	PAYOUT_PROVIDER       string
+	PAYOUT_BASE_URL       string
+	PAYOUT_SECRET_KEY     string
+	PAYOUT_CALLBACK_TOKEN string
+	// upper bound for one call to the payout provider
+	PAYOUT_TIMEOUT time.Duration
+
+	// approved withdrawals put in one batch, and how many times a batch is submitted before its withdrawals fail
+	PAYOUT_BATCH_SIZE   int
+	PAYOUT_MAX_ATTEMPTS int
+)
+
+func InitPayoutConstant() {
+	PAYOUT_PROVIDER = strings.TrimSpace(os.Getenv("PAYOUT_PROVIDER"))
+	PAYOUT_BASE_URL = os.Getenv("PAYOUT_BASE_URL")
+
+	if PAYOUT_BASE_URL == "" {
+		PAYOUT_BASE_URL = "https://api.xendit.co"
+	}
+
+	PAYOUT_SECRET_KEY = os.Getenv("PAYOUT_SECRET_KEY")
+	PAYOUT_CALLBACK_TOKEN = os.Getenv("PAYOUT_CALLBACK_TOKEN")
+	PAYOUT_TIMEOUT = parseDurationEnv("PAYOUT_TIMEOUT", 15*time.Second)
+
+	PAYOUT_BATCH_SIZE = parseIntEnv("PAYOUT_BATCH_SIZE", 50)
+	PAYOUT_MAX_ATTEMPTS = parseIntEnv("PAYOUT_MAX_ATTEMPTS", 5)
+}
@@ -0,0 +1,67 @@
+package constant
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+var (
+	// per route policies in the "limit/window" format, e.g. "10/1m"
+	RATE_LIMIT_LOGIN                 string
+	RATE_LIMIT_TWO_FACTOR            string
+	RATE_LIMIT_REGISTER              string
+	RATE_LIMIT_VERIFY_EMAIL          string
+	RATE_LIMIT_FORGOT_PASSWORD       string
+	RATE_LIMIT_FORGOT_PASSWORD_TOKEN string
+	RATE_LIMIT_RESET_PASSWORD        string
+	RATE_LIMIT_ANONYMOUS_TRANSACTION string
+	RATE_LIMIT_ACTIVITY_LOG          string
+
+	// per account policies, same format
+	RATE_LIMIT_LOGIN_ACCOUNT           string
+	RATE_LIMIT_TWO_FACTOR_ACCOUNT      string
+	RATE_LIMIT_FORGOT_PASSWORD_ACCOUNT string
+
+	LOGIN_LOCKOUT_THRESHOLD int64
+	LOGIN_LOCKOUT_WINDOW    time.Duration
+	LOGIN_LOCKOUT_BASE      time.Duration
+	LOGIN_LOCKOUT_MAX       time.Duration
+)
+
+func InitRateLimitConstant() {
+	RATE_LIMIT_LOGIN = os.Getenv("RATE_LIMIT_LOGIN")
+	RATE_LIMIT_TWO_FACTOR = os.Getenv("RATE_LIMIT_TWO_FACTOR")
+	RATE_LIMIT_REGISTER = os.Getenv("RATE_LIMIT_REGISTER")
+	RATE_LIMIT_VERIFY_EMAIL = os.Getenv("RATE_LIMIT_VERIFY_EMAIL")
+	RATE_LIMIT_FORGOT_PASSWORD = os.Getenv("RATE_LIMIT_FORGOT_PASSWORD")
+	RATE_LIMIT_FORGOT_PASSWORD_TOKEN = os.Getenv("RATE_LIMIT_FORGOT_PASSWORD_TOKEN")
+	RATE_LIMIT_RESET_PASSWORD = os.Getenv("RATE_LIMIT_RESET_PASSWORD")
+	RATE_LIMIT_ANONYMOUS_TRANSACTION = os.Getenv("RATE_LIMIT_ANONYMOUS_TRANSACTION")
+	RATE_LIMIT_ACTIVITY_LOG = os.Getenv("RATE_LIMIT_ACTIVITY_LOG")
+
+	RATE_LIMIT_LOGIN_ACCOUNT = os.Getenv("RATE_LIMIT_LOGIN_ACCOUNT")
+	RATE_LIMIT_TWO_FACTOR_ACCOUNT = os.Getenv("RATE_LIMIT_TWO_FACTOR_ACCOUNT")
+	RATE_LIMIT_FORGOT_PASSWORD_ACCOUNT = os.Getenv("RATE_LIMIT_FORGOT_PASSWORD_ACCOUNT")
+
+	lockoutThreshold, err := strconv.ParseInt(os.Getenv("LOGIN_LOCKOUT_THRESHOLD"), 10, 64)
+
+	if err != nil || lockoutThreshold <= 0 {
+		lockoutThreshold = 5
+	}
+
+	LOGIN_LOCKOUT_THRESHOLD = lockoutThreshold
+	LOGIN_LOCKOUT_WINDOW = parseDurationEnv("LOGIN_LOCKOUT_WINDOW", 15*time.Minute)
+	LOGIN_LOCKOUT_BASE = parseDurationEnv("LOGIN_LOCKOUT_BASE", time.Minute)
+	LOGIN_LOCKOUT_MAX = parseDurationEnv("LOGIN_LOCKOUT_MAX", 24*time.Hour)
+}
+
+func parseDurationEnv(key string, def time.Duration) time.Duration {
+	duration, err := time.ParseDuration(os.Getenv(key))
+
+	if err != nil || duration <= 0 {
+		return def
+	}
+
+	return duration
+}
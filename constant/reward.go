@@ -0,0 +1,13 @@
+package constant
+
+import "time"
+
+var (
+	REWARD_CLAIM_WINDOW      time.Duration
+	REWARD_EXPIRY_BATCH_SIZE int
+)
+
+func InitRewardConstant() {
+	REWARD_CLAIM_WINDOW = parseDurationEnv("REWARD_CLAIM_WINDOW", 7*24*time.Hour)
+	REWARD_EXPIRY_BATCH_SIZE = parseIntEnv("REWARD_EXPIRY_BATCH_SIZE", 50)
+}
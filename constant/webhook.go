@@ -0,0 +1,31 @@
+package constant
+
+import (
+	"os"
+	"time"
+)
+
+var (
+	WEBHOOK_POLL_INTERVAL time.Duration
+	WEBHOOK_BATCH_SIZE    int
+	WEBHOOK_MAX_ATTEMPTS  int
+	WEBHOOK_BACKOFF_BASE  time.Duration
+	WEBHOOK_BACKOFF_MAX   time.Duration
+	WEBHOOK_TIMEOUT       time.Duration
+
+	// plain http endpoints are only accepted when this is "true", for local development
+	WEBHOOK_ALLOW_HTTP bool
+	// endpoints on loopback and private addresses are only accepted when this is "true", for local development
+	WEBHOOK_ALLOW_PRIVATE_NETWORK bool
+)
+
+func InitWebhookConstant() {
+	WEBHOOK_POLL_INTERVAL = parseDurationEnv("WEBHOOK_POLL_INTERVAL", 10*time.Second)
+	WEBHOOK_BATCH_SIZE = parseIntEnv("WEBHOOK_BATCH_SIZE", 20)
+	WEBHOOK_MAX_ATTEMPTS = parseIntEnv("WEBHOOK_MAX_ATTEMPTS", 10)
+	WEBHOOK_BACKOFF_BASE = parseDurationEnv("WEBHOOK_BACKOFF_BASE", 30*time.Second)
+	WEBHOOK_BACKOFF_MAX = parseDurationEnv("WEBHOOK_BACKOFF_MAX", 12*time.Hour)
+	WEBHOOK_TIMEOUT = parseDurationEnv("WEBHOOK_TIMEOUT", 10*time.Second)
+	WEBHOOK_ALLOW_HTTP = os.Getenv("WEBHOOK_ALLOW_HTTP") == "true"
+	WEBHOOK_ALLOW_PRIVATE_NETWORK = os.Getenv("WEBHOOK_ALLOW_PRIVATE_NETWORK") == "true"
+}
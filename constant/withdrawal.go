@@ -0,0 +1,15 @@
+package constant
+
+var (
+	WITHDRAWAL_MIN_AMOUNT  int64
+	WITHDRAWAL_FEE         int64
+	WITHDRAWAL_DAILY_LIMIT int64
+	WITHDRAWAL_DAILY_COUNT int
+)
+
+func InitWithdrawalConstant() {
+	WITHDRAWAL_MIN_AMOUNT = int64(parseIntEnv("WITHDRAWAL_MIN_AMOUNT", 50000))
+	WITHDRAWAL_FEE = int64(parseIntEnv("WITHDRAWAL_FEE", 5000))
+	WITHDRAWAL_DAILY_LIMIT = int64(parseIntEnv("WITHDRAWAL_DAILY_LIMIT", 10000000))
+	WITHDRAWAL_DAILY_COUNT = parseIntEnv("WITHDRAWAL_DAILY_COUNT", 3)
+}
@@ -0,0 +1,39 @@
+package disbursement
+
+import (
+	"database/sql"
+	"strconv"
+	"time"
+)
+
+const (
+	// created, its withdrawals are processing but the provider did not take it yet
+	StatusPending   = "pending"
+	StatusSubmitted = "submitted"
+	// no attempt got a clear answer, the provider may have taken one; its disbursements are looked up by reference
+	StatusUnknown = "unknown"
+	// the provider refused it or never took it, its withdrawals failed and their holds were released
+	StatusFailed = "failed"
+)
+
+// PayoutBatch is one submission of approved withdrawals to the payout provider. The reference is the
+// idempotency key, a pending batch is submitted again with the same one until the provider takes it.
+type PayoutBatch struct {
+	ID               int            `json:"id"`
+	Reference        string         `json:"reference"`
+	Provider         string         `json:"provider"`
+	ProviderBatchID  sql.NullString `json:"provider_batch_id"`
+	Status           string         `json:"status"`
+	TotalAmount      int64          `json:"total_amount"`
+	TotalWithdrawals int            `json:"total_withdrawals"`
+	Attempts         int            `json:"attempts"`
+	LastError        sql.NullString `json:"last_error"`
+	SubmittedAt      sql.NullTime   `json:"submitted_at"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+}
+
+// withdrawalReference is the external id of a withdrawal at the provider
+func withdrawalReference(withdrawalID int) string {
+	return "TCD-WD-" + strconv.Itoa(withdrawalID)
+}
@@ -0,0 +1,48 @@
+package disbursement
+
+import (
+	"time"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/user"
+)
+
+type PayoutBatchFormatter struct {
+	ID               int                               `json:"id"`
+	Reference        string                            `json:"reference"`
+	Provider         string                            `json:"provider"`
+	ProviderBatchID  string                            `json:"provider_batch_id"`
+	Status           string                            `json:"status"`
+	TotalAmount      int64                             `json:"total_amount"`
+	TotalWithdrawals int                               `json:"total_withdrawals"`
+	Attempts         int                               `json:"attempts"`
+	LastError        string                            `json:"last_error"`
+	SubmittedAt      *time.Time                        `json:"submitted_at"`
+	CreatedAt        time.Time                         `json:"created_at"`
+	Withdrawals      []user.WithdrawalRequestFormatter `json:"withdrawals"`
+}
+
+func FormatPayoutBatchData(batch PayoutBatch, withdrawals []user.UserWithdrawalRequest) PayoutBatchFormatter {
+	formatData := PayoutBatchFormatter{
+		ID:               batch.ID,
+		Reference:        batch.Reference,
+		Provider:         batch.Provider,
+		ProviderBatchID:  batch.ProviderBatchID.String,
+		Status:           batch.Status,
+		TotalAmount:      batch.TotalAmount,
+		TotalWithdrawals: batch.TotalWithdrawals,
+		Attempts:         batch.Attempts,
+		LastError:        batch.LastError.String,
+		CreatedAt:        batch.CreatedAt,
+		Withdrawals:      []user.WithdrawalRequestFormatter{},
+	}
+
+	if batch.SubmittedAt.Valid {
+		formatData.SubmittedAt = &batch.SubmittedAt.Time
+	}
+
+	for _, val := range withdrawals {
+		formatData.Withdrawals = append(formatData.Withdrawals, user.FormatWithdrawalRequestData(val))
+	}
+
+	return formatData
+}
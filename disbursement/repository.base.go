@@ -0,0 +1,18 @@
+package disbursement
+
+import "gorm.io/gorm"
+
+type Repository interface {
+	GetPayoutBatchByID(id int) (PayoutBatch, error)
+	GetPayoutBatchesByStatus(status string, limit int) ([]PayoutBatch, error)
+	SavePayoutBatch(PayoutBatch) (PayoutBatch, error)
+	UpdatePayoutBatch(PayoutBatch) (PayoutBatch, error)
+}
+
+type repository struct {
+	DB *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *repository {
+	return &repository{DB: db}
+}
@@ -0,0 +1,36 @@
+package disbursement
+
+import "errors"
+
+func (repo *repository) GetPayoutBatchByID(id int) (batch PayoutBatch, err error) {
+	if err := repo.DB.Where("id = ?", id).Find(&batch).Error; err != nil {
+		return batch, err
+	}
+
+	if batch.ID == 0 {
+		return batch, errors.New("sql: no rows in result set")
+	}
+
+	return batch, nil
+}
+
+func (repo *repository) GetPayoutBatchesByStatus(status string, limit int) (batches []PayoutBatch, err error) {
+	if err := repo.DB.Where("status = ?", status).Order("id ASC").Limit(limit).Find(&batches).Error; err != nil {
+		return batches, err
+	}
+	return batches, nil
+}
+
+func (repo *repository) SavePayoutBatch(batch PayoutBatch) (PayoutBatch, error) {
+	if err := repo.DB.Create(&batch).Error; err != nil {
+		return batch, err
+	}
+	return batch, nil
+}
+
+func (repo *repository) UpdatePayoutBatch(batch PayoutBatch) (PayoutBatch, error) {
+	if err := repo.DB.Save(&batch).Error; err != nil {
+		return batch, err
+	}
+	return batch, nil
+}
@@ -0,0 +1,7 @@
+package disbursement
+
+type (
+	RequestGetPayoutBatch struct {
+		ID int `uri:"id" binding:"required"`
+	}
+)
@@ -0,0 +1,48 @@
+package disbursement
+
+import (
+	"net/http"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/audit"
+	"github.com/WeAreAmazingTeam/tcd-backend/joblock"
+	"github.com/WeAreAmazingTeam/tcd-backend/payout"
+	"github.com/WeAreAmazingTeam/tcd-backend/user"
+)
+
+type Service interface {
+	DisburseApprovedWithdrawals(fence joblock.Fence) (int, error)
+	HandleCallback(header http.Header, body []byte) (int, error)
+	GetPayoutBatch(RequestGetPayoutBatch) (PayoutBatch, []user.UserWithdrawalRequest, error)
+}
+
+type Config struct {
+	BatchSize   int
+	MaxAttempts int
+}
+
+type service struct {
+	repo     Repository
+	userRepo user.Repository
+	userSvc  user.Service
+	provider payout.Provider
+	config   Config
+	auditSvc audit.Service
+}
+
+func NewService(
+	repository Repository,
+	userRepository user.Repository,
+	userService user.Service,
+	provider payout.Provider,
+	config Config,
+	auditService audit.Service,
+) *service {
+	return &service{
+		repo:     repository,
+		userRepo: userRepository,
+		userSvc:  userService,
+		provider: provider,
+		config:   config,
+		auditSvc: auditService,
+	}
+}
@@ -0,0 +1,400 @@
+package disbursement
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/audit"
+	"github.com/WeAreAmazingTeam/tcd-backend/constant"
+	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+	"github.com/WeAreAmazingTeam/tcd-backend/joblock"
+	"github.com/WeAreAmazingTeam/tcd-backend/payout"
+	"github.com/WeAreAmazingTeam/tcd-backend/user"
+)
+
+// DisburseApprovedWithdrawals looks up the batches in an unknown state, submits the pending batches again, then
+// puts the approved withdrawals in a new batch and submits it. It returns how many batches the provider took.
+// Nothing happens when payouts are off.
+func (svc *service) DisburseApprovedWithdrawals(fence joblock.Fence) (int, error) {
+	if svc.provider.Name() == payout.ProviderNone {
+		return 0, nil
+	}
+
+	unknownBatches, err := svc.repo.GetPayoutBatchesByStatus(StatusUnknown, svc.config.BatchSize)
+
+	if err != nil {
+		return 0, err
+	}
+
+	submitted := 0
+
+	for _, batch := range unknownBatches {
+		if err := fence.Err(); err != nil {
+			return submitted, err
+		}
+
+		if svc.reconcile(batch) {
+			submitted++
+		}
+	}
+
+	batches, err := svc.repo.GetPayoutBatchesByStatus(StatusPending, svc.config.BatchSize)
+
+	if err != nil {
+		return submitted, err
+	}
+
+	for _, batch := range batches {
+		if err := fence.Err(); err != nil {
+			return submitted, err
+		}
+
+		if svc.submit(batch) {
+			submitted++
+		}
+	}
+
+	if err := fence.Err(); err != nil {
+		return submitted, err
+	}
+
+	withdrawals, err := svc.userRepo.GetApprovedWithdrawalRequests(svc.config.BatchSize)
+
+	if err != nil || len(withdrawals) == 0 {
+		return submitted, err
+	}
+
+	batch, err := svc.repo.SavePayoutBatch(PayoutBatch{
+		Reference: fmt.Sprintf("TCD-PAYOUT-%v", time.Now().UnixNano()),
+		Provider:  svc.provider.Name(),
+		Status:    StatusPending,
+	})
+
+	if err != nil {
+		return submitted, err
+	}
+
+	for _, withdrawal := range withdrawals {
+		// the batch stays pending with what it has, the next run submits it
+		if err := fence.Err(); err != nil {
+			break
+		}
+
+		reqDetail := user.RequestGetUserWithdrawalRequestByID{}
+		reqDetail.ID = withdrawal.ID
+
+		moved, err := svc.userSvc.MoveWithdrawalRequest(reqDetail, user.RequestMoveWithdrawalRequest{
+			Status:          user.WithdrawalStatusProcessing,
+			PayoutBatchID:   batch.ID,
+			PayoutReference: withdrawalReference(withdrawal.ID),
+		})
+
+		if err != nil {
+			log.Printf("[PAYOUT] withdrawal %v not added to batch %v, err: %s", withdrawal.ID, batch.ID, err.Error())
+			continue
+		}
+
+		batch.TotalAmount += moved.NetAmount
+		batch.TotalWithdrawals++
+	}
+
+	if batch, err = svc.repo.UpdatePayoutBatch(batch); err != nil {
+		return submitted, err
+	}
+
+	audit.Record(svc.auditSvc, user.User{}, audit.ActionCreate, audit.EntityPayoutBatch, batch.ID, nil, batch)
+
+	if err := fence.Err(); err != nil {
+		return submitted, err
+	}
+
+	if svc.submit(batch) {
+		submitted++
+	}
+
+	return submitted, nil
+}
+
+// submit sends the withdrawals of the batch still processing, a withdrawal closed in the meantime is not paid.
+// Only a rejection of the first attempt fails the withdrawals. Any other error may hide a batch the provider
+// took, so it is sent again with the same reference and after the last attempt it is looked up instead.
+func (svc *service) submit(batch PayoutBatch) bool {
+	withdrawals, err := svc.userRepo.GetWithdrawalRequestsByPayoutBatchID(batch.ID)
+
+	if err != nil {
+		log.Printf("[PAYOUT] batch %v not submitted, err: %s", batch.ID, err.Error())
+		return false
+	}
+
+	disbursements := []payout.Disbursement{}
+
+	for _, withdrawal := range withdrawals {
+		if withdrawal.Status != user.WithdrawalStatusProcessing {
+			continue
+		}
+
+		disbursements = append(disbursements, payout.Disbursement{
+			ExternalID: withdrawal.PayoutReference.String,
+			Account: payout.Account{
+				BankCode:      withdrawal.BankCode,
+				AccountNumber: withdrawal.AccountNumber,
+				HolderName:    withdrawal.AccountHolderName,
+			},
+			Amount:      withdrawal.NetAmount,
+			Description: fmt.Sprintf("Withdrawal #%v", withdrawal.ID),
+		})
+	}
+
+	before := batch
+
+	if len(disbursements) == 0 {
+		batch.Status = StatusFailed
+		batch.LastError = helper.SetNS("no withdrawal of the batch is left to pay")
+		svc.updateBatch(before, batch)
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), constant.PAYOUT_TIMEOUT)
+	defer cancel()
+
+	providerBatchID, err := svc.provider.Disburse(ctx, batch.Reference, disbursements)
+
+	batch.Attempts++
+
+	if err != nil {
+		log.Printf("[PAYOUT] batch %v not submitted, attempt %v, err: %s", batch.ID, batch.Attempts, err.Error())
+
+		batch.LastError = helper.SetNS(err.Error())
+
+		if errors.Is(err, payout.ErrRejected) && batch.Attempts == 1 {
+			batch.Status = StatusFailed
+
+			for _, withdrawal := range withdrawals {
+				if withdrawal.Status == user.WithdrawalStatusProcessing {
+					svc.fail(withdrawal, "the payout was refused by the bank")
+				}
+			}
+		} else if batch.Attempts >= svc.config.MaxAttempts {
+			batch.Status = StatusUnknown
+		}
+
+		svc.updateBatch(before, batch)
+		return false
+	}
+
+	batch.Status = StatusSubmitted
+	batch.ProviderBatchID = helper.SetNS(providerBatchID)
+	batch.LastError = sql.NullString{}
+	batch.SubmittedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	svc.updateBatch(before, batch)
+
+	return true
+}
+
+// HandleCallback settles the withdrawals a provider callback reports on and returns how many it moved.
+// Providers send a callback again until it is answered, a withdrawal already settled is skipped.
+func (svc *service) HandleCallback(header http.Header, body []byte) (int, error) {
+	updates, err := svc.provider.ParseCallback(header, body)
+
+	if err != nil {
+		return 0, err
+	}
+
+	settled := 0
+
+	for _, update := range updates {
+		withdrawal, err := svc.userRepo.GetWithdrawalRequestByPayoutReference(update.ExternalID)
+
+		if err != nil {
+			if helper.IsErrNoRows(err.Error()) {
+				log.Printf("[PAYOUT] callback for unknown disbursement %v", update.ExternalID)
+				continue
+			}
+
+			return settled, err
+		}
+
+		moved, err := svc.settle(withdrawal, update)
+
+		if err != nil {
+			return settled, err
+		}
+
+		if moved {
+			settled++
+		}
+	}
+
+	return settled, nil
+}
+
+// reconcile looks up the disbursements of a batch no attempt got a clear answer for. When the provider has any
+// of them the batch was taken, the ones it settled are settled here and the callbacks settle the rest. When it
+// has none the batch never arrived and its withdrawals fail. A lookup error leaves the batch for the next run.
+func (svc *service) reconcile(batch PayoutBatch) bool {
+	withdrawals, err := svc.userRepo.GetWithdrawalRequestsByPayoutBatchID(batch.ID)
+
+	if err != nil {
+		log.Printf("[PAYOUT] batch %v not reconciled, err: %s", batch.ID, err.Error())
+		return false
+	}
+
+	found := 0
+	missing := []user.UserWithdrawalRequest{}
+	updates := map[int]payout.DisbursementUpdate{}
+
+	for _, withdrawal := range withdrawals {
+		if withdrawal.Status != user.WithdrawalStatusProcessing {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), constant.PAYOUT_TIMEOUT)
+		update, err := svc.provider.GetDisbursement(ctx, withdrawal.PayoutReference.String)
+		cancel()
+
+		if errors.Is(err, payout.ErrDisbursementNotFound) {
+			missing = append(missing, withdrawal)
+			continue
+		}
+
+		if err != nil {
+			log.Printf("[PAYOUT] batch %v not reconciled, withdrawal %v lookup failed, err: %s", batch.ID, withdrawal.ID, err.Error())
+			return false
+		}
+
+		found++
+		updates[withdrawal.ID] = update
+	}
+
+	before := batch
+
+	if found == 0 && len(missing) > 0 {
+		batch.Status = StatusFailed
+		batch.LastError = helper.SetNS("the payout provider never received the batch")
+
+		for _, withdrawal := range missing {
+			svc.fail(withdrawal, "the payout could not be submitted to the bank")
+		}
+
+		svc.updateBatch(before, batch)
+		return false
+	}
+
+	for _, withdrawal := range withdrawals {
+		if update, ok := updates[withdrawal.ID]; ok {
+			if _, err := svc.settle(withdrawal, update); err != nil {
+				log.Printf("[PAYOUT] withdrawal %v of batch %v not settled, err: %s", withdrawal.ID, batch.ID, err.Error())
+			}
+		}
+	}
+
+	// a batch is taken whole, one missing from a taken batch is left processing for a person to look at
+	for _, withdrawal := range missing {
+		log.Printf("[PAYOUT] withdrawal %v is missing from taken batch %v at the provider, it needs a review", withdrawal.ID, batch.ID)
+	}
+
+	batch.Status = StatusSubmitted
+	batch.SubmittedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	svc.updateBatch(before, batch)
+
+	return true
+}
+
+// settle moves a processing withdrawal to what the provider reports, false when there was nothing to move
+func (svc *service) settle(withdrawal user.UserWithdrawalRequest, update payout.DisbursementUpdate) (bool, error) {
+	if withdrawal.Status != user.WithdrawalStatusProcessing {
+		if (withdrawal.Status == user.WithdrawalStatusPaid) != (update.Status == payout.DisbursementCompleted) {
+			log.Printf("[PAYOUT] withdrawal %v is %v but the provider reports it %v, it needs a review", withdrawal.ID, withdrawal.Status, update.Status)
+		}
+
+		return false, nil
+	}
+
+	switch update.Status {
+	case payout.DisbursementFailed:
+		reason := update.FailureReason
+
+		if reason == "" {
+			reason = "the bank did not accept the transfer"
+		}
+
+		if err := svc.fail(withdrawal, reason); err != nil {
+			return false, err
+		}
+
+		return true, nil
+	case payout.DisbursementCompleted:
+		reqDetail := user.RequestGetUserWithdrawalRequestByID{}
+		reqDetail.ID = withdrawal.ID
+
+		// the company cash flow is written in the same database transaction as the payment
+		if _, err := svc.userSvc.MoveWithdrawalRequest(reqDetail, user.RequestMoveWithdrawalRequest{
+			Status: user.WithdrawalStatusPaid,
+		}); err != nil {
+			return false, err
+		}
+
+		return true, nil
+	}
+
+	// still on its way
+	return false, nil
+}
+
+func (svc *service) GetPayoutBatch(req RequestGetPayoutBatch) (PayoutBatch, []user.UserWithdrawalRequest, error) {
+	batch, err := svc.repo.GetPayoutBatchByID(req.ID)
+
+	if err != nil {
+		return batch, nil, err
+	}
+
+	withdrawals, err := svc.userRepo.GetWithdrawalRequestsByPayoutBatchID(batch.ID)
+
+	return batch, withdrawals, err
+}
+
+// fail releases the hold of the withdrawal, so the amount can be spent or withdrawn again, and tells the owner
+func (svc *service) fail(withdrawal user.UserWithdrawalRequest, reason string) error {
+	reqDetail := user.RequestGetUserWithdrawalRequestByID{}
+	reqDetail.ID = withdrawal.ID
+
+	failed, err := svc.userSvc.MoveWithdrawalRequest(reqDetail, user.RequestMoveWithdrawalRequest{
+		Status: user.WithdrawalStatusFailed,
+		Reason: reason,
+	})
+
+	if err != nil {
+		log.Printf("[PAYOUT] withdrawal %v not failed, err: %s", withdrawal.ID, err.Error())
+		return err
+	}
+
+	owner, err := svc.userRepo.GetUserByID(failed.UserID)
+
+	if err != nil {
+		log.Printf("[PAYOUT] owner of withdrawal %v not notified, err: %s", failed.ID, err.Error())
+		return nil
+	}
+
+	if err := helper.SendNotification(helper.NotificationRecipient{UserID: owner.ID, Email: owner.Email, Locale: owner.Locale}, helper.EmailTemplateWithdrawalRejected, helper.EmailWithdrawalRequest{
+		Name:   owner.Name,
+		Amount: helper.FormatRupiah(float64(failed.Amount)),
+	}); err != nil {
+		log.Printf("[PAYOUT] owner of withdrawal %v not notified, err: %s", failed.ID, err.Error())
+	}
+
+	return nil
+}
+
+func (svc *service) updateBatch(before, batch PayoutBatch) {
+	if _, err := svc.repo.UpdatePayoutBatch(batch); err != nil {
+		log.Printf("[PAYOUT] batch %v not saved, err: %s", batch.ID, err.Error())
+		return
+	}
+
+	audit.Record(svc.auditSvc, user.User{}, audit.ActionUpdate, audit.EntityPayoutBatch, batch.ID, before, batch)
+}
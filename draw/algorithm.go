@@ -0,0 +1,81 @@
+package draw
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+func HashSeed(seed string) string {
+	sum := sha256.Sum256([]byte(seed))
+	return hex.EncodeToString(sum[:])
+}
+
+// HashEntrants hashes the user ids in position order, the same list always gives the same hash
+func HashEntrants(userIDs []int) string {
+	lines := make([]string, len(userIDs))
+
+	for i, userID := range userIDs {
+		lines[i] = strconv.Itoa(userID)
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join(lines, "\n")))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// WinnerIndex is the position of the winner among count entrants, see AlgorithmV1
+func WinnerIndex(seed, entrantsHash string, count int) int {
+	sum := sha256.Sum256([]byte(seed + ":" + entrantsHash))
+	number := new(big.Int).SetBytes(sum[:])
+
+	return int(number.Mod(number, big.NewInt(int64(count))).Int64())
+}
+
+// HashWeightedEntrants hashes "user_id:tickets" in position order, see AlgorithmV2
+func HashWeightedEntrants(entrants []Entrant) string {
+	lines := make([]string, len(entrants))
+
+	for i, entrant := range entrants {
+		lines[i] = strconv.Itoa(entrant.UserID) + ":" + strconv.FormatInt(entrant.Tickets, 10)
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join(lines, "\n")))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// WeightedPicks returns the winning positions in pick order, see AlgorithmV2,
+// it stops early once every entrant has won
+func WeightedPicks(seed, entrantsHash string, tickets []int64, count int) []int {
+	left := make([]int64, len(tickets))
+	total := int64(0)
+
+	for i, ticket := range tickets {
+		left[i] = ticket
+		total += ticket
+	}
+
+	picks := []int{}
+
+	for pick := 0; pick < count && total > 0; pick++ {
+		sum := sha256.Sum256([]byte(seed + ":" + entrantsHash + ":" + strconv.Itoa(pick)))
+		number := new(big.Int).SetBytes(sum[:])
+		ticket := number.Mod(number, big.NewInt(total)).Int64()
+
+		for position, held := range left {
+			if ticket < held {
+				picks = append(picks, position)
+				total -= held
+				left[position] = 0
+				break
+			}
+
+			ticket -= held
+		}
+	}
+
+	return picks
+}
@@ -0,0 +1,103 @@
+package draw
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+const (
+	StatusCommitted  = "committed"
+	StatusDrawn      = "drawn"
+	StatusNoEntrants = "no_entrants"
+)
+
+// AlgorithmV1 is how the winner follows from the stored values, anyone can run it again:
+// entrants_hash = sha256 of the entrant user ids in position order joined by "\n",
+// winner_index = sha256(seed + ":" + entrants_hash) read as a big-endian number, modulo the entrant count
+const AlgorithmV1 = "sha256-mod-v1"
+
+// AlgorithmV2 draws every prize from weighted entries, one ticket per entry amount donated:
+// entrants_hash = sha256 of "user_id:tickets" per entrant in position order joined by "\n",
+// pick k = sha256(seed + ":" + entrants_hash + ":" + k) read as a big-endian number, modulo the tickets left,
+// walked over the tickets left in position order, the entrant it lands on wins and leaves the pool
+const AlgorithmV2 = "sha256-weighted-v2"
+
+// ErrNoRedraw is a redraw that can not happen, every entrant already won or the draw is on AlgorithmV1
+var ErrNoRedraw = errors.New("the draw can not pick another winner")
+
+type (
+	// ExclusiveDraw commits to sha256(seed) while the campaign runs and reveals the seed when it draws,
+	// so the seed can not be picked after the entrants are known. The seed is not serialized, it never reaches the
+	// API or the audit log.
+	ExclusiveDraw struct {
+		ID                  int            `json:"id"`
+		CampaignID          int            `json:"campaign_id"`
+		ExclusiveCampaignID int            `json:"exclusive_campaign_id"`
+		Status              string         `json:"status"`
+		Algorithm           string         `json:"algorithm"`
+		SeedHash            string         `json:"seed_hash"`
+		Seed                string         `json:"-"`
+		MinDonation         int64          `json:"min_donation"`
+		EntryAmount         int64          `json:"entry_amount"`
+		VerifiedOnly        int            `json:"verified_only"`
+		EntrantCount        int            `json:"entrant_count"`
+		TotalTickets        int64          `json:"total_tickets"`
+		EntrantsHash        sql.NullString `json:"entrants_hash"`
+		WinnerIndex         sql.NullInt64  `json:"winner_index"`
+		WinnerUserID        int            `json:"winner_user_id"`
+		CommittedAt         time.Time      `json:"committed_at"`
+		DrawnAt             sql.NullTime   `json:"drawn_at"`
+		CreatedAt           time.Time      `json:"created_at"`
+		UpdatedAt           time.Time      `json:"updated_at"`
+	}
+
+	// DrawEntrant is the snapshot of one eligible donor, Position is the index the winner index points at
+	DrawEntrant struct {
+		ID              int       `json:"id"`
+		ExclusiveDrawID int       `json:"exclusive_draw_id"`
+		Position        int       `json:"position"`
+		UserID          int       `json:"user_id"`
+		Donated         int64     `json:"donated"`
+		Tickets         int64     `json:"tickets"`
+		CreatedAt       time.Time `json:"created_at"`
+	}
+
+	// DrawWinner is one pick of the draw, picks run from 0 in prize tier order,
+	// a redraw is the next pick of the same sequence and names the pick whose winner forfeited
+	DrawWinner struct {
+		ID              int           `json:"id"`
+		ExclusiveDrawID int           `json:"exclusive_draw_id"`
+		Pick            int           `json:"pick"`
+		PrizeID         int           `json:"prize_id"`
+		PrizeTier       int           `json:"prize_tier"`
+		Position        int           `json:"position"`
+		UserID          int           `json:"user_id"`
+		ReplacesPick    sql.NullInt64 `json:"replaces_pick"`
+		CreatedAt       time.Time     `json:"created_at"`
+	}
+
+	// Rules decide who enters and with how many tickets, they are stored on the draw when it is drawn
+	Rules struct {
+		MinDonation  int64
+		EntryAmount  int64
+		VerifiedOnly bool
+	}
+
+	// Prize is one tier to draw, Quantity winners for each
+	Prize struct {
+		ID       int
+		Tier     int
+		Quantity int
+	}
+
+	// Entrant is an eligible donor, Position is only set once the entrant is in a snapshot
+	Entrant struct {
+		Position int
+		UserID   int
+		Name     string
+		Donated  int64
+		Tickets  int64
+		Verified bool
+	}
+)
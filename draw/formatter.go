@@ -0,0 +1,179 @@
+package draw
+
+import (
+	"strings"
+	"time"
+)
+
+type (
+	DrawEntrantFormatter struct {
+		Position int    `json:"position"`
+		UserID   int    `json:"user_id"`
+		Name     string `json:"name"`
+		Tickets  int64  `json:"tickets"`
+	}
+
+	DrawWinnerFormatter struct {
+		Pick         int    `json:"pick"`
+		PrizeID      int    `json:"prize_id"`
+		PrizeTier    int    `json:"prize_tier"`
+		Position     int    `json:"position"`
+		UserID       int    `json:"user_id"`
+		Name         string `json:"name"`
+		ReplacesPick *int64 `json:"replaces_pick"`
+	}
+
+	// DrawVerificationFormatter is the server running the draw algorithm again on the stored values
+	DrawVerificationFormatter struct {
+		SeedMatchesHash     bool `json:"seed_matches_hash"`
+		EntrantsHashMatches bool `json:"entrants_hash_matches"`
+		WinnerMatches       bool `json:"winner_matches"`
+		Verified            bool `json:"verified"`
+	}
+
+	// DrawFormatter leaves the seed out until the draw, before that only its hash is public
+	DrawFormatter struct {
+		CampaignID   int                        `json:"campaign_id"`
+		Status       string                     `json:"status"`
+		Algorithm    string                     `json:"algorithm"`
+		SeedHash     string                     `json:"seed_hash"`
+		Seed         string                     `json:"seed"`
+		CommittedAt  time.Time                  `json:"committed_at"`
+		DrawnAt      *time.Time                 `json:"drawn_at"`
+		MinDonation  int64                      `json:"min_donation"`
+		EntryAmount  int64                      `json:"entry_amount"`
+		VerifiedOnly int                        `json:"verified_only"`
+		EntrantCount int                        `json:"entrant_count"`
+		TotalTickets int64                      `json:"total_tickets"`
+		EntrantsHash string                     `json:"entrants_hash"`
+		WinnerIndex  *int64                     `json:"winner_index"`
+		WinnerUserID int                        `json:"winner_user_id"`
+		Entrants     []DrawEntrantFormatter     `json:"entrants"`
+		Winners      []DrawWinnerFormatter      `json:"winners"`
+		Verification *DrawVerificationFormatter `json:"verification"`
+	}
+)
+
+func FormatDrawData(draw ExclusiveDraw, entrants []Entrant, winners []DrawWinner) DrawFormatter {
+	formatData := DrawFormatter{
+		CampaignID:   draw.CampaignID,
+		Status:       draw.Status,
+		Algorithm:    draw.Algorithm,
+		SeedHash:     draw.SeedHash,
+		CommittedAt:  draw.CommittedAt,
+		MinDonation:  draw.MinDonation,
+		EntryAmount:  draw.EntryAmount,
+		VerifiedOnly: draw.VerifiedOnly,
+		EntrantCount: draw.EntrantCount,
+		TotalTickets: draw.TotalTickets,
+		EntrantsHash: draw.EntrantsHash.String,
+		WinnerUserID: draw.WinnerUserID,
+		Entrants:     []DrawEntrantFormatter{},
+		Winners:      []DrawWinnerFormatter{},
+	}
+
+	if draw.Status == StatusCommitted {
+		return formatData
+	}
+
+	formatData.Seed = draw.Seed
+
+	if draw.DrawnAt.Valid {
+		formatData.DrawnAt = &draw.DrawnAt.Time
+	}
+
+	if draw.WinnerIndex.Valid {
+		formatData.WinnerIndex = &draw.WinnerIndex.Int64
+	}
+
+	userIDs := []int{}
+	tickets := []int64{}
+
+	for _, entrant := range entrants {
+		formatData.Entrants = append(formatData.Entrants, DrawEntrantFormatter{
+			Position: entrant.Position,
+			UserID:   entrant.UserID,
+			Name:     maskName(entrant.Name),
+			Tickets:  entrant.Tickets,
+		})
+		userIDs = append(userIDs, entrant.UserID)
+		tickets = append(tickets, entrant.Tickets)
+	}
+
+	positions := []int{}
+
+	for _, winner := range winners {
+		name := ""
+
+		if winner.Position < len(entrants) {
+			name = maskName(entrants[winner.Position].Name)
+		}
+
+		tmp := DrawWinnerFormatter{
+			Pick:      winner.Pick,
+			PrizeID:   winner.PrizeID,
+			PrizeTier: winner.PrizeTier,
+			Position:  winner.Position,
+			UserID:    winner.UserID,
+			Name:      name,
+		}
+
+		if winner.ReplacesPick.Valid {
+			replacesPick := winner.ReplacesPick.Int64
+			tmp.ReplacesPick = &replacesPick
+		}
+
+		formatData.Winners = append(formatData.Winners, tmp)
+		positions = append(positions, winner.Position)
+	}
+
+	verification := DrawVerificationFormatter{
+		SeedMatchesHash: HashSeed(draw.Seed) == draw.SeedHash,
+	}
+
+	expected := []int{}
+
+	if draw.Algorithm == AlgorithmV1 {
+		verification.EntrantsHashMatches = HashEntrants(userIDs) == draw.EntrantsHash.String
+
+		if len(userIDs) > 0 && len(winners) > 0 {
+			expected = append(expected, WinnerIndex(draw.Seed, draw.EntrantsHash.String, len(userIDs)))
+		}
+	} else {
+		verification.EntrantsHashMatches = HashWeightedEntrants(entrants) == draw.EntrantsHash.String
+		expected = WeightedPicks(draw.Seed, draw.EntrantsHash.String, tickets, len(winners))
+	}
+
+	verification.WinnerMatches = len(expected) == len(winners)
+
+	for pick, position := range expected {
+		if !verification.WinnerMatches {
+			break
+		}
+
+		verification.WinnerMatches = position == positions[pick] && userIDs[position] == winners[pick].UserID
+	}
+
+	verification.Verified = verification.SeedMatchesHash && verification.EntrantsHashMatches && verification.WinnerMatches
+	formatData.Verification = &verification
+
+	return formatData
+}
+
+// maskName keeps the first and last letter of every word, donors find themselves by user id
+func maskName(name string) string {
+	words := strings.Fields(name)
+
+	for i, word := range words {
+		runes := []rune(word)
+
+		if len(runes) <= 2 {
+			words[i] = strings.Repeat("*", len(runes))
+			continue
+		}
+
+		words[i] = string(runes[0]) + strings.Repeat("*", len(runes)-2) + string(runes[len(runes)-1])
+	}
+
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,52 @@
+package draw
+
+const (
+	// every donor with a paid donation and what they gave in total, ordered by user id so the snapshot is the same
+	// whoever takes it, the draw rules are applied on top of this
+	QueryGetDrawEntrants = `
+		SELECT
+			users.id,
+			users.name,
+			SUM(transactions.amount) AS donated,
+			users.email_verified_at IS NOT NULL AS verified
+		FROM
+			transactions
+		JOIN
+			users
+		ON
+			users.id = transactions.user_id
+		WHERE
+			users.deleted_at IS NULL
+		AND
+			transactions.status = 'paid'
+		AND
+			transactions.user_id > 0
+		AND
+			transactions.campaign_id = ?
+		GROUP BY
+			users.id,
+			users.name,
+			users.email_verified_at
+		ORDER BY
+			users.id ASC
+	`
+
+	QueryGetDrawEntrantsWithName = `
+		SELECT
+			draw_entrants.position,
+			draw_entrants.user_id,
+			COALESCE(users.name, ''),
+			draw_entrants.donated,
+			draw_entrants.tickets
+		FROM
+			draw_entrants
+		LEFT JOIN
+			users
+		ON
+			users.id = draw_entrants.user_id
+		WHERE
+			draw_entrants.exclusive_draw_id = ?
+		ORDER BY
+			draw_entrants.position ASC
+	`
+)
@@ -0,0 +1,22 @@
+package draw
+
+import "gorm.io/gorm"
+
+type Repository interface {
+	GetDrawByCampaignID(campaignID int) (ExclusiveDraw, error)
+	SaveDraw(ExclusiveDraw) (ExclusiveDraw, error)
+	SaveDrawResult(ExclusiveDraw, []DrawEntrant, []DrawWinner) (bool, error)
+
+	GetEligibleEntrants(campaignID int) ([]Entrant, error)
+	GetDrawEntrants(drawID int) ([]Entrant, error)
+	GetDrawWinners(drawID int) ([]DrawWinner, error)
+	SaveDrawWinner(DrawWinner) (DrawWinner, error)
+}
+
+type repository struct {
+	DB *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *repository {
+	return &repository{DB: db}
+}
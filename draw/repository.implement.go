@@ -0,0 +1,135 @@
+package draw
+
+import (
+	"errors"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+	"gorm.io/gorm"
+)
+
+func (repo *repository) GetDrawByCampaignID(campaignID int) (draw ExclusiveDraw, err error) {
+	if err := repo.DB.Where("campaign_id = ?", campaignID).Find(&draw).Error; err != nil {
+		return draw, err
+	}
+
+	if draw.ID == 0 {
+		return draw, errors.New("sql: no rows in result set")
+	}
+
+	return draw, nil
+}
+
+func (repo *repository) SaveDraw(draw ExclusiveDraw) (ExclusiveDraw, error) {
+	if err := repo.DB.Create(&draw).Error; err != nil {
+		return draw, err
+	}
+	return draw, nil
+}
+
+// SaveDrawResult stores the snapshot with the result in one database transaction,
+// false means the draw was already done and nothing was written
+func (repo *repository) SaveDrawResult(draw ExclusiveDraw, entrants []DrawEntrant, winners []DrawWinner) (bool, error) {
+	drawn := false
+
+	err := repo.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&ExclusiveDraw{}).
+			Where("id = ? AND status = ?", draw.ID, StatusCommitted).
+			Updates(map[string]any{
+				"status":         draw.Status,
+				"min_donation":   draw.MinDonation,
+				"entry_amount":   draw.EntryAmount,
+				"verified_only":  draw.VerifiedOnly,
+				"entrant_count":  draw.EntrantCount,
+				"total_tickets":  draw.TotalTickets,
+				"entrants_hash":  draw.EntrantsHash,
+				"winner_index":   draw.WinnerIndex,
+				"winner_user_id": draw.WinnerUserID,
+				"drawn_at":       draw.DrawnAt,
+				"updated_at":     draw.DrawnAt,
+			})
+
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		if len(entrants) > 0 {
+			if err := tx.Create(&entrants).Error; err != nil {
+				return err
+			}
+		}
+
+		if len(winners) > 0 {
+			if err := tx.Create(&winners).Error; err != nil {
+				return err
+			}
+		}
+
+		drawn = true
+
+		return nil
+	})
+
+	return drawn, err
+}
+
+func (repo *repository) GetEligibleEntrants(campaignID int) (entrants []Entrant, err error) {
+	rows, err := repo.DB.Raw(helper.ConvertToInLineQuery(QueryGetDrawEntrants), campaignID).Rows()
+
+	if err != nil {
+		return entrants, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		tmp := Entrant{}
+
+		if err := rows.Scan(&tmp.UserID, &tmp.Name, &tmp.Donated, &tmp.Verified); err != nil {
+			return entrants, err
+		}
+
+		entrants = append(entrants, tmp)
+	}
+
+	return entrants, nil
+}
+
+func (repo *repository) GetDrawEntrants(drawID int) (entrants []Entrant, err error) {
+	rows, err := repo.DB.Raw(helper.ConvertToInLineQuery(QueryGetDrawEntrantsWithName), drawID).Rows()
+
+	if err != nil {
+		return entrants, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		tmp := Entrant{}
+
+		if err := rows.Scan(&tmp.Position, &tmp.UserID, &tmp.Name, &tmp.Donated, &tmp.Tickets); err != nil {
+			return entrants, err
+		}
+
+		entrants = append(entrants, tmp)
+	}
+
+	return entrants, nil
+}
+
+func (repo *repository) GetDrawWinners(drawID int) (winners []DrawWinner, err error) {
+	if err := repo.DB.Where("exclusive_draw_id = ?", drawID).Order("pick ASC").Find(&winners).Error; err != nil {
+		return winners, err
+	}
+	return winners, nil
+}
+
+func (repo *repository) SaveDrawWinner(winner DrawWinner) (DrawWinner, error) {
+	if err := repo.DB.Create(&winner).Error; err != nil {
+		return winner, err
+	}
+	return winner, nil
+}
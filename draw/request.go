@@ -0,0 +1,5 @@
+package draw
+
+type RequestGetDrawByCampaignID struct {
+	CampaignID int `uri:"id" binding:"required"`
+}
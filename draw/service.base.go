@@ -0,0 +1,28 @@
+package draw
+
+import (
+	"sync"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/audit"
+)
+
+type Service interface {
+	Commit(campaignID, exclusiveCampaignID int) (ExclusiveDraw, error)
+	Draw(campaignID, exclusiveCampaignID int, rules Rules, prizes []Prize) (ExclusiveDraw, []DrawWinner, error)
+	Redraw(campaignID, forfeitedPick int) (DrawWinner, error)
+	GetDraw(RequestGetDrawByCampaignID) (DrawFormatter, error)
+}
+
+type service struct {
+	// commit and redraw are get or create, one at a time in this process
+	mu       sync.Mutex
+	repo     Repository
+	auditSvc audit.Service
+}
+
+func NewService(repository Repository, auditService audit.Service) *service {
+	return &service{
+		repo:     repository,
+		auditSvc: auditService,
+	}
+}
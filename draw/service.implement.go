@@ -0,0 +1,313 @@
+package draw
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/audit"
+	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+	"github.com/WeAreAmazingTeam/tcd-backend/user"
+)
+
+// Commit picks the secret seed of a campaign and publishes its hash, a second call returns the first commitment
+func (svc *service) Commit(campaignID, exclusiveCampaignID int) (ExclusiveDraw, error) {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+
+	draw, err := svc.repo.GetDrawByCampaignID(campaignID)
+
+	if err == nil {
+		return draw, nil
+	}
+
+	if !helper.IsErrNoRows(err.Error()) {
+		return draw, err
+	}
+
+	seed := make([]byte, 32)
+
+	if _, err := rand.Read(seed); err != nil {
+		return draw, err
+	}
+
+	draw = ExclusiveDraw{}
+	draw.CampaignID = campaignID
+	draw.ExclusiveCampaignID = exclusiveCampaignID
+	draw.Status = StatusCommitted
+	draw.Algorithm = AlgorithmV2
+	draw.Seed = hex.EncodeToString(seed)
+	draw.SeedHash = HashSeed(draw.Seed)
+	draw.CommittedAt = time.Now()
+
+	newDraw, err := svc.repo.SaveDraw(draw)
+
+	if err != nil {
+		return newDraw, err
+	}
+
+	audit.Record(svc.auditSvc, user.User{}, audit.ActionCreate, audit.EntityExclusiveDraw, newDraw.ID, nil, newDraw)
+
+	return newDraw, nil
+}
+
+// Draw snapshots the eligible donors under the rules and reveals the seed, a done draw returns its stored result.
+// A campaign made exclusive before draws existed commits here, its committed_at shows it was late.
+// Prizes come in tier order, a draw committed under AlgorithmV1 stays on it and only draws the first winner.
+func (svc *service) Draw(campaignID, exclusiveCampaignID int, rules Rules, prizes []Prize) (ExclusiveDraw, []DrawWinner, error) {
+	draw, err := svc.Commit(campaignID, exclusiveCampaignID)
+
+	if err != nil {
+		return draw, nil, err
+	}
+
+	if draw.Status != StatusCommitted {
+		winners, err := svc.getWinners(draw)
+		return draw, winners, err
+	}
+
+	eligible, err := svc.repo.GetEligibleEntrants(campaignID)
+
+	if err != nil {
+		return draw, nil, err
+	}
+
+	weighted := draw.Algorithm != AlgorithmV1
+	before := draw
+	entrants := []DrawEntrant{}
+	snapshot := []Entrant{}
+	tickets := []int64{}
+
+	for _, entrant := range eligible {
+		if entrant.Donated < rules.MinDonation || (rules.VerifiedOnly && !entrant.Verified) {
+			continue
+		}
+
+		entrant.Tickets = 1
+
+		if weighted && rules.EntryAmount > 0 {
+			entrant.Tickets = entrant.Donated / rules.EntryAmount
+		}
+
+		if entrant.Tickets == 0 {
+			continue
+		}
+
+		entrant.Position = len(snapshot)
+		snapshot = append(snapshot, entrant)
+		tickets = append(tickets, entrant.Tickets)
+		entrants = append(entrants, DrawEntrant{
+			ExclusiveDrawID: draw.ID,
+			Position:        entrant.Position,
+			UserID:          entrant.UserID,
+			Donated:         entrant.Donated,
+			Tickets:         entrant.Tickets,
+		})
+		draw.TotalTickets += entrant.Tickets
+	}
+
+	draw.MinDonation = rules.MinDonation
+	draw.EntryAmount = rules.EntryAmount
+
+	if rules.VerifiedOnly {
+		draw.VerifiedOnly = 1
+	}
+
+	draw.EntrantCount = len(snapshot)
+	draw.DrawnAt = sql.NullTime{Time: time.Now(), Valid: true}
+	draw.Status = StatusNoEntrants
+
+	// pick k goes to the prize whose quantities, in tier order, cover k
+	slots := []Prize{}
+
+	for _, prize := range prizes {
+		for i := 0; i < prize.Quantity; i++ {
+			slots = append(slots, prize)
+		}
+	}
+
+	picks := []int{}
+
+	if weighted {
+		draw.EntrantsHash = helper.SetNS(HashWeightedEntrants(snapshot))
+		picks = WeightedPicks(draw.Seed, draw.EntrantsHash.String, tickets, len(slots))
+	} else {
+		userIDs := []int{}
+
+		for _, entrant := range snapshot {
+			userIDs = append(userIDs, entrant.UserID)
+		}
+
+		draw.EntrantsHash = helper.SetNS(HashEntrants(userIDs))
+
+		if len(userIDs) > 0 && len(slots) > 0 {
+			picks = append(picks, WinnerIndex(draw.Seed, draw.EntrantsHash.String, len(userIDs)))
+		}
+	}
+
+	winners := []DrawWinner{}
+
+	for pick, position := range picks {
+		winners = append(winners, DrawWinner{
+			ExclusiveDrawID: draw.ID,
+			Pick:            pick,
+			PrizeID:         slots[pick].ID,
+			PrizeTier:       slots[pick].Tier,
+			Position:        position,
+			UserID:          snapshot[position].UserID,
+		})
+	}
+
+	if len(winners) > 0 {
+		draw.Status = StatusDrawn
+		draw.WinnerIndex = sql.NullInt64{Int64: int64(winners[0].Position), Valid: true}
+		draw.WinnerUserID = winners[0].UserID
+	}
+
+	drawn, err := svc.repo.SaveDrawResult(draw, entrants, winners)
+
+	if err != nil {
+		return draw, nil, err
+	}
+
+	// someone else drew in between, theirs is the result
+	if !drawn {
+		if draw, err = svc.repo.GetDrawByCampaignID(campaignID); err != nil {
+			return draw, nil, err
+		}
+
+		winners, err := svc.getWinners(draw)
+		return draw, winners, err
+	}
+
+	audit.Record(svc.auditSvc, user.User{}, audit.ActionUpdate, audit.EntityExclusiveDraw, draw.ID, before, draw)
+
+	return draw, winners, nil
+}
+
+// Redraw draws the next pick for the prize of a forfeited pick, the winners so far stay out of the pool.
+// Asking again for the same forfeited pick returns the replacement already drawn.
+func (svc *service) Redraw(campaignID, forfeitedPick int) (DrawWinner, error) {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+
+	draw, err := svc.repo.GetDrawByCampaignID(campaignID)
+
+	if err != nil {
+		return DrawWinner{}, err
+	}
+
+	if draw.Status != StatusDrawn {
+		return DrawWinner{}, errors.New("the draw is not done yet")
+	}
+
+	if draw.Algorithm == AlgorithmV1 {
+		return DrawWinner{}, ErrNoRedraw
+	}
+
+	winners, err := svc.repo.GetDrawWinners(draw.ID)
+
+	if err != nil {
+		return DrawWinner{}, err
+	}
+
+	forfeited := DrawWinner{}
+
+	for _, winner := range winners {
+		if winner.ReplacesPick.Valid && winner.ReplacesPick.Int64 == int64(forfeitedPick) {
+			return winner, nil
+		}
+
+		if winner.Pick == forfeitedPick {
+			forfeited = winner
+		}
+	}
+
+	if forfeited.ID == 0 {
+		return DrawWinner{}, errors.New("sql: no rows in result set")
+	}
+
+	entrants, err := svc.repo.GetDrawEntrants(draw.ID)
+
+	if err != nil {
+		return DrawWinner{}, err
+	}
+
+	tickets := []int64{}
+
+	for _, entrant := range entrants {
+		tickets = append(tickets, entrant.Tickets)
+	}
+
+	picks := WeightedPicks(draw.Seed, draw.EntrantsHash.String, tickets, len(winners)+1)
+
+	if len(picks) <= len(winners) {
+		return DrawWinner{}, ErrNoRedraw
+	}
+
+	position := picks[len(winners)]
+	winner := DrawWinner{
+		ExclusiveDrawID: draw.ID,
+		Pick:            len(winners),
+		PrizeID:         forfeited.PrizeID,
+		PrizeTier:       forfeited.PrizeTier,
+		Position:        position,
+		UserID:          entrants[position].UserID,
+		ReplacesPick:    sql.NullInt64{Int64: int64(forfeitedPick), Valid: true},
+	}
+
+	newWinner, err := svc.repo.SaveDrawWinner(winner)
+
+	if err != nil {
+		return newWinner, err
+	}
+
+	audit.Record(svc.auditSvc, user.User{}, audit.ActionCreate, audit.EntityExclusiveDraw, draw.ID, nil, newWinner)
+
+	return newWinner, nil
+}
+
+func (svc *service) GetDraw(req RequestGetDrawByCampaignID) (DrawFormatter, error) {
+	draw, err := svc.repo.GetDrawByCampaignID(req.CampaignID)
+
+	if err != nil {
+		return DrawFormatter{}, err
+	}
+
+	entrants := []Entrant{}
+	winners := []DrawWinner{}
+
+	if draw.Status != StatusCommitted {
+		if entrants, err = svc.repo.GetDrawEntrants(draw.ID); err != nil {
+			return DrawFormatter{}, err
+		}
+
+		if winners, err = svc.getWinners(draw); err != nil {
+			return DrawFormatter{}, err
+		}
+	}
+
+	return FormatDrawData(draw, entrants, winners), nil
+}
+
+// getWinners reads the picks of a done draw, a draw done before picks were stored has its one winner on the draw row
+func (svc *service) getWinners(draw ExclusiveDraw) ([]DrawWinner, error) {
+	winners, err := svc.repo.GetDrawWinners(draw.ID)
+
+	if err != nil {
+		return winners, err
+	}
+
+	if len(winners) == 0 && draw.WinnerIndex.Valid {
+		winner := DrawWinner{
+			ExclusiveDrawID: draw.ID,
+			Position:        int(draw.WinnerIndex.Int64),
+			UserID:          draw.WinnerUserID,
+		}
+		winners = append(winners, winner)
+	}
+
+	return winners, nil
+}
@@ -0,0 +1,70 @@
+package event
+
+import (
+	"fmt"
+	"sync"
+)
+
+type Handler func(Envelope) error
+
+type subscriber struct {
+	name    string
+	handler Handler
+}
+
+// Bus keeps the in-process subscribers, the outbox worker is the only one calling them
+type Bus struct {
+	mu          sync.RWMutex
+	subscribers map[string][]subscriber
+}
+
+func NewBus() *Bus {
+	return &Bus{subscribers: map[string][]subscriber{}}
+}
+
+// Subscribe panics on a duplicate name, the name is what marks the subscriber as done with an event
+func (bus *Bus) Subscribe(eventType, name string, handler Handler) {
+	bus.mu.Lock()
+	defer bus.mu.Unlock()
+
+	for _, val := range bus.subscribers[eventType] {
+		if val.name == name {
+			panic(fmt.Sprintf("event subscriber %s already subscribed to %s", name, eventType))
+		}
+	}
+
+	bus.subscribers[eventType] = append(bus.subscribers[eventType], subscriber{name: name, handler: handler})
+}
+
+func (bus *Bus) subscribersOf(eventType string) []subscriber {
+	bus.mu.RLock()
+	defer bus.mu.RUnlock()
+
+	return append([]subscriber{}, bus.subscribers[eventType]...)
+}
+
+// SubscriberNames is used by the admin detail to show which subscribers are still to run
+func (bus *Bus) SubscriberNames(eventType string) []string {
+	names := []string{}
+
+	for _, val := range bus.subscribersOf(eventType) {
+		names = append(names, val.name)
+	}
+
+	return names
+}
+
+// On subscribes a handler that receives the decoded event instead of the envelope
+func On[T Event](bus *Bus, name string, handler func(T) error) {
+	var zero T
+
+	bus.Subscribe(zero.EventType(), name, func(envelope Envelope) error {
+		var payload T
+
+		if err := envelope.Decode(&payload); err != nil {
+			return err
+		}
+
+		return handler(payload)
+	})
+}
@@ -0,0 +1,111 @@
+package event
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+const (
+	TypeDonationPaid        = "donation.paid"
+	TypeCampaignGoalReached = "campaign.goal_reached"
+	TypeCampaignFinished    = "campaign.finished"
+)
+
+const (
+	StatusPending    = "pending"
+	StatusProcessing = "processing"
+	StatusPublished  = "published"
+	StatusFailed     = "failed"
+)
+
+const (
+	PaidWithMidtrans = "midtrans"
+	PaidWithEMoney   = "e_money"
+
+	FinishReasonGoalReached = "goal_reached"
+	FinishReasonDeadline    = "deadline"
+	FinishReasonManual      = "manual"
+)
+
+// Event is a fact that already happened, the type names the payload for the subscribers
+type Event interface {
+	EventType() string
+}
+
+type (
+	// DonationPaid is stored together with the paid transaction and the campaign amount
+	DonationPaid struct {
+		TransactionID   int       `json:"transaction_id"`
+		Code            string    `json:"code"`
+		CampaignID      int       `json:"campaign_id"`
+		CampaignOwnerID int       `json:"campaign_owner_id"`
+		UserID          int       `json:"user_id"`
+		Amount          int64     `json:"amount"`
+		PaidWith        string    `json:"paid_with"`
+		PaidAt          time.Time `json:"paid_at"`
+	}
+
+	CampaignGoalReached struct {
+		CampaignID      int       `json:"campaign_id"`
+		OwnerUserID     int       `json:"owner_user_id"`
+		GoalAmount      int64     `json:"goal_amount"`
+		CollectedAmount int64     `json:"collected_amount"`
+		ReachedAt       time.Time `json:"reached_at"`
+	}
+
+	CampaignFinished struct {
+		CampaignID      int       `json:"campaign_id"`
+		OwnerUserID     int       `json:"owner_user_id"`
+		Reason          string    `json:"reason"`
+		CollectedAmount int64     `json:"collected_amount"`
+		FinishedAt      time.Time `json:"finished_at"`
+	}
+)
+
+func (DonationPaid) EventType() string        { return TypeDonationPaid }
+func (CampaignGoalReached) EventType() string { return TypeCampaignGoalReached }
+func (CampaignFinished) EventType() string    { return TypeCampaignFinished }
+
+// donation paid by someone without an account, midtrans anonymous transactions use -1
+func (event DonationPaid) IsAnonymous() bool {
+	return event.UserID <= 0
+}
+
+type (
+	// EventOutbox is written in the same database transaction as the change it describes,
+	// the worker hands it to the subscribers afterwards so nothing is lost on a restart
+	EventOutbox struct {
+		ID            int            `json:"id"`
+		EventID       string         `json:"event_id"`
+		EventType     string         `json:"event_type"`
+		Payload       string         `json:"payload"`
+		Status        string         `json:"status"`
+		Attempts      int            `json:"attempts"`
+		NextAttemptAt time.Time      `json:"next_attempt_at"`
+		LastError     sql.NullString `json:"last_error"`
+		PublishedAt   sql.NullTime   `json:"published_at"`
+		CreatedAt     time.Time      `json:"created_at"`
+		UpdatedAt     time.Time      `json:"updated_at"`
+	}
+
+	// EventDelivery marks a subscriber as done with an event, a retry only runs the subscribers without one
+	EventDelivery struct {
+		ID            int       `json:"id"`
+		EventOutboxID int       `json:"event_outbox_id"`
+		Subscriber    string    `json:"subscriber"`
+		CreatedAt     time.Time `json:"created_at"`
+	}
+
+	// Envelope is what a subscriber receives, Decode turns the payload back into the typed event
+	Envelope struct {
+		ID         string
+		Type       string
+		OccurredAt time.Time
+		Payload    json.RawMessage
+	}
+)
+
+func (envelope Envelope) Decode(v any) error {
+	return json.Unmarshal(envelope.Payload, v)
+}
@@ -0,0 +1,79 @@
+package event
+
+import "time"
+
+type (
+	EventOutboxFormatter struct {
+		ID            int        `json:"id"`
+		EventID       string     `json:"event_id"`
+		EventType     string     `json:"event_type"`
+		Status        string     `json:"status"`
+		Attempts      int        `json:"attempts"`
+		NextAttemptAt time.Time  `json:"next_attempt_at"`
+		LastError     string     `json:"last_error"`
+		PublishedAt   *time.Time `json:"published_at"`
+		CreatedAt     time.Time  `json:"created_at"`
+	}
+
+	EventSubscriberFormatter struct {
+		Name   string     `json:"name"`
+		Done   bool       `json:"done"`
+		DoneAt *time.Time `json:"done_at"`
+	}
+
+	EventOutboxDetailFormatter struct {
+		EventOutboxFormatter
+		Payload     string                     `json:"payload"`
+		Subscribers []EventSubscriberFormatter `json:"subscribers"`
+	}
+)
+
+func FormatEventOutboxData(outbox EventOutbox) EventOutboxFormatter {
+	formatData := EventOutboxFormatter{
+		ID:            outbox.ID,
+		EventID:       outbox.EventID,
+		EventType:     outbox.EventType,
+		Status:        outbox.Status,
+		Attempts:      outbox.Attempts,
+		NextAttemptAt: outbox.NextAttemptAt,
+		LastError:     outbox.LastError.String,
+		CreatedAt:     outbox.CreatedAt,
+	}
+
+	if outbox.PublishedAt.Valid {
+		formatData.PublishedAt = &outbox.PublishedAt.Time
+	}
+
+	return formatData
+}
+
+// subscriberNames are the ones currently on the bus, deliveries of a removed subscriber are still listed
+func FormatEventOutboxDetailData(outbox EventOutbox, subscriberNames []string, deliveries []EventDelivery) EventOutboxDetailFormatter {
+	subscribers := []EventSubscriberFormatter{}
+	listed := map[string]bool{}
+
+	for _, name := range subscriberNames {
+		subscribers = append(subscribers, EventSubscriberFormatter{Name: name})
+		listed[name] = true
+	}
+
+	for _, delivery := range deliveries {
+		if !listed[delivery.Subscriber] {
+			subscribers = append(subscribers, EventSubscriberFormatter{Name: delivery.Subscriber})
+		}
+
+		for i := range subscribers {
+			if subscribers[i].Name == delivery.Subscriber {
+				doneAt := delivery.CreatedAt
+				subscribers[i].Done = true
+				subscribers[i].DoneAt = &doneAt
+			}
+		}
+	}
+
+	return EventOutboxDetailFormatter{
+		EventOutboxFormatter: FormatEventOutboxData(outbox),
+		Payload:              outbox.Payload,
+		Subscribers:          subscribers,
+	}
+}
@@ -0,0 +1,53 @@
+package event
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/thanhpk/randstr"
+	"gorm.io/gorm"
+)
+
+var wake = make(chan struct{}, 1)
+
+// Store writes the events with the caller database transaction, so they are saved only if the change is.
+// Call Wake after the commit.
+func Store(tx *gorm.DB, events ...Event) error {
+	for _, evt := range events {
+		outbox, err := NewEventOutbox(evt)
+
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Create(&outbox).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func NewEventOutbox(evt Event) (EventOutbox, error) {
+	payload, err := json.Marshal(evt)
+
+	if err != nil {
+		return EventOutbox{}, err
+	}
+
+	return EventOutbox{
+		EventID:       "evt_" + randstr.Hex(12),
+		EventType:     evt.EventType(),
+		Payload:       string(payload),
+		Status:        StatusPending,
+		NextAttemptAt: time.Now(),
+	}, nil
+}
+
+// Wake lets the worker pick up new events now instead of on its next tick
+func Wake() {
+	select {
+	case wake <- struct{}{}:
+	default:
+	}
+}
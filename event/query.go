@@ -0,0 +1,29 @@
+package event
+
+const (
+	QueryAdminDataTablesEventOutbox = `
+		SELECT
+			id,
+			event_id,
+			event_type,
+			status,
+			attempts,
+			next_attempt_at,
+			last_error,
+			published_at,
+			created_at
+		FROM
+			event_outboxes
+		WHERE
+			1 = 1
+	`
+
+	QueryCountAllAdminDataTablesEventOutbox = `
+		SELECT
+			COUNT(id) AS count_id
+		FROM
+			event_outboxes
+		WHERE
+			1 = 1
+	`
+)
@@ -0,0 +1,31 @@
+package event
+
+import (
+	"time"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	GetEventOutboxByID(int) (EventOutbox, error)
+	GetDueEventOutbox(now time.Time, limit int) ([]EventOutbox, error)
+	ClaimEventOutbox(EventOutbox) (bool, error)
+	ReleaseStaleEventOutbox(before time.Time) (int64, error)
+	SaveEventOutbox(EventOutbox) (EventOutbox, error)
+	UpdateEventOutbox(EventOutbox) (EventOutbox, error)
+
+	GetEventDeliveries(eventOutboxID int) ([]EventDelivery, error)
+	SaveEventDelivery(EventDelivery) (EventDelivery, error)
+
+	AdminDataTablesEventOutbox(ctx *gin.Context) (helper.DataTables, error)
+}
+
+type repository struct {
+	DB *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *repository {
+	return &repository{DB: db}
+}
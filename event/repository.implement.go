@@ -0,0 +1,214 @@
+package event
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+	"github.com/gin-gonic/gin"
+)
+
+func (repo *repository) GetEventOutboxByID(id int) (outbox EventOutbox, err error) {
+	if err := repo.DB.Where("id = ?", id).Find(&outbox).Error; err != nil {
+		return outbox, err
+	}
+
+	if outbox.ID == 0 {
+		return outbox, errors.New("sql: no rows in result set")
+	}
+
+	return outbox, nil
+}
+
+func (repo *repository) GetDueEventOutbox(now time.Time, limit int) (outboxes []EventOutbox, err error) {
+	if err := repo.DB.Where("status = ? AND next_attempt_at <= ?", StatusPending, now).Order("id ASC").Limit(limit).Find(&outboxes).Error; err != nil {
+		return outboxes, err
+	}
+	return outboxes, nil
+}
+
+// ClaimEventOutbox flips pending to processing, false means another worker got there first
+func (repo *repository) ClaimEventOutbox(outbox EventOutbox) (bool, error) {
+	result := repo.DB.Model(&EventOutbox{}).
+		Where("id = ? AND status = ?", outbox.ID, StatusPending).
+		Updates(map[string]any{"status": StatusProcessing, "updated_at": time.Now()})
+
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+// ReleaseStaleEventOutbox puts back events left in processing by a process that died mid dispatch
+func (repo *repository) ReleaseStaleEventOutbox(before time.Time) (int64, error) {
+	result := repo.DB.Model(&EventOutbox{}).
+		Where("status = ? AND updated_at < ?", StatusProcessing, before).
+		Updates(map[string]any{"status": StatusPending, "updated_at": time.Now()})
+
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
+
+func (repo *repository) SaveEventOutbox(outbox EventOutbox) (EventOutbox, error) {
+	if err := repo.DB.Create(&outbox).Error; err != nil {
+		return outbox, err
+	}
+	return outbox, nil
+}
+
+func (repo *repository) UpdateEventOutbox(outbox EventOutbox) (EventOutbox, error) {
+	if err := repo.DB.Save(&outbox).Error; err != nil {
+		return outbox, err
+	}
+	return outbox, nil
+}
+
+func (repo *repository) GetEventDeliveries(eventOutboxID int) (deliveries []EventDelivery, err error) {
+	if err := repo.DB.Where("event_outbox_id = ?", eventOutboxID).Order("id ASC").Find(&deliveries).Error; err != nil {
+		return deliveries, err
+	}
+	return deliveries, nil
+}
+
+func (repo *repository) SaveEventDelivery(delivery EventDelivery) (EventDelivery, error) {
+	if err := repo.DB.Create(&delivery).Error; err != nil {
+		return delivery, err
+	}
+	return delivery, nil
+}
+
+// besides the datatables params it filters by the status and event_type query params
+func (repo *repository) AdminDataTablesEventOutbox(ctx *gin.Context) (result helper.DataTables, err error) {
+	var (
+		query string = QueryAdminDataTablesEventOutbox
+		where string = ""
+		order string = ""
+		limit string = ""
+	)
+
+	var (
+		no       int = 1
+		total    int = 0
+		filtered int = 0
+	)
+
+	var (
+		data []map[string]any
+		args []any
+	)
+
+	listOrder := []string{"", "event_id", "event_type", "status", "attempts", "next_attempt_at", "published_at", "created_at", ""}
+
+	if status := ctx.Query("status"); status != "" {
+		where = fmt.Sprintf("%s AND status = ?", where)
+		args = append(args, status)
+	}
+
+	if eventType := ctx.Query("event_type"); eventType != "" {
+		where = fmt.Sprintf("%s AND event_type = ?", where)
+		args = append(args, eventType)
+	}
+
+	if searchValue := ctx.Query("search[value]"); searchValue != "" {
+		where = fmt.Sprintf("%s AND (event_id LIKE ? OR event_type LIKE ? OR payload LIKE ? OR last_error LIKE ?)", where)
+		for i := 0; i < 4; i++ {
+			args = append(args, "%"+searchValue+"%")
+		}
+	}
+
+	orderColumn := ctx.Query("order[0][column]")
+	starting, _ := strconv.Atoi(ctx.Query("start"))
+
+	if orderColumn != "" {
+		orderType := "ASC"
+		orderColumn, _ := strconv.Atoi(orderColumn)
+
+		if strings.ToUpper(ctx.Query("order[0][dir]")) == "DESC" {
+			orderType = "DESC"
+		}
+
+		if orderColumn > 0 && orderColumn < len(listOrder) && listOrder[orderColumn] != "" {
+			order = fmt.Sprintf("ORDER BY %s %s", listOrder[orderColumn], orderType)
+		} else {
+			order = "ORDER BY id DESC"
+		}
+	} else {
+		order = "ORDER BY id DESC"
+	}
+
+	if starting != -1 {
+		length, _ := strconv.Atoi(ctx.Query("length"))
+		limit = fmt.Sprintf("LIMIT %v OFFSET %v", length, starting)
+		no = starting + 1
+	}
+
+	if err := repo.DB.Raw(helper.ConvertToInLineQuery(QueryCountAllAdminDataTablesEventOutbox)).Scan(&total).Error; err != nil {
+		return result, err
+	}
+
+	if where != "" {
+		query = query + where
+
+		if err := repo.DB.Raw(helper.ConvertToInLineQuery(QueryCountAllAdminDataTablesEventOutbox)+where, args...).Scan(&filtered).Error; err != nil {
+			return result, err
+		}
+	} else {
+		filtered = total
+	}
+
+	query = fmt.Sprintf("%s %s %s", query, order, limit)
+
+	rows, err := repo.DB.Raw(helper.ConvertToInLineQuery(query), args...).Rows()
+
+	if err != nil {
+		return result, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		tmp := EventOutbox{}
+
+		err := rows.Scan(
+			&tmp.ID,
+			&tmp.EventID,
+			&tmp.EventType,
+			&tmp.Status,
+			&tmp.Attempts,
+			&tmp.NextAttemptAt,
+			&tmp.LastError,
+			&tmp.PublishedAt,
+			&tmp.CreatedAt,
+		)
+
+		if err != nil {
+			return result, err
+		}
+
+		formatData := FormatEventOutboxData(tmp)
+
+		data = append(data, map[string]any{
+			"no":              no,
+			"id":              formatData.ID,
+			"event_id":        formatData.EventID,
+			"event_type":      formatData.EventType,
+			"status":          formatData.Status,
+			"attempts":        formatData.Attempts,
+			"next_attempt_at": formatData.NextAttemptAt,
+			"last_error":      formatData.LastError,
+			"published_at":    formatData.PublishedAt,
+			"created_at":      formatData.CreatedAt,
+		})
+
+		no++
+	}
+
+	return helper.BuildDatatTables(data, filtered, total), nil
+}
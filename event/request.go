@@ -0,0 +1,13 @@
+package event
+
+import "github.com/WeAreAmazingTeam/tcd-backend/user"
+
+type (
+	RequestGetEventOutboxByID struct {
+		ID int `uri:"id" binding:"required"`
+	}
+
+	RequestReplayEventOutbox struct {
+		User user.User
+	}
+)
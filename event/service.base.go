@@ -0,0 +1,42 @@
+package event
+
+import (
+	"time"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/audit"
+	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+	"github.com/gin-gonic/gin"
+)
+
+type Service interface {
+	Publish(events ...Event) error
+	ProcessDueEvents() (int, error)
+	RunWorker(interval time.Duration)
+
+	GetEventOutboxDetail(RequestGetEventOutboxByID) (EventOutboxDetailFormatter, error)
+	ReplayEvent(RequestGetEventOutboxByID, RequestReplayEventOutbox) (EventOutbox, error)
+	AdminDataTablesEventOutbox(*gin.Context) (helper.DataTables, error)
+}
+
+type Config struct {
+	BatchSize   int
+	MaxAttempts int
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+}
+
+type service struct {
+	repo     Repository
+	bus      *Bus
+	config   Config
+	auditSvc audit.Service
+}
+
+func NewService(repository Repository, bus *Bus, config Config, auditService audit.Service) *service {
+	return &service{
+		repo:     repository,
+		bus:      bus,
+		config:   config,
+		auditSvc: auditService,
+	}
+}
@@ -0,0 +1,185 @@
+package event
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/audit"
+	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+	"github.com/WeAreAmazingTeam/tcd-backend/jobqueue"
+	"github.com/gin-gonic/gin"
+)
+
+// Publish is for callers without a database transaction of their own, the others use Store
+func (svc *service) Publish(events ...Event) error {
+	for _, evt := range events {
+		outbox, err := NewEventOutbox(evt)
+
+		if err != nil {
+			return err
+		}
+
+		if _, err := svc.repo.SaveEventOutbox(outbox); err != nil {
+			return err
+		}
+	}
+
+	Wake()
+
+	return nil
+}
+
+func (svc *service) ProcessDueEvents() (int, error) {
+	return svc.poller().ProcessDue()
+}
+
+// RunWorker blocks, call it in its own goroutine. Besides the interval it runs whenever Wake is called.
+func (svc *service) RunWorker(interval time.Duration) {
+	svc.poller().Run(interval, wake)
+}
+
+func (svc *service) poller() jobqueue.Poller[EventOutbox] {
+	return jobqueue.Poller[EventOutbox]{
+		Name:         "EVENT",
+		BatchSize:    svc.config.BatchSize,
+		ReleaseStale: svc.repo.ReleaseStaleEventOutbox,
+		GetDue:       svc.repo.GetDueEventOutbox,
+		Claim:        svc.repo.ClaimEventOutbox,
+		Process:      svc.dispatch,
+	}
+}
+
+func (svc *service) GetEventOutboxDetail(req RequestGetEventOutboxByID) (EventOutboxDetailFormatter, error) {
+	outbox, err := svc.repo.GetEventOutboxByID(req.ID)
+
+	if err != nil {
+		return EventOutboxDetailFormatter{}, err
+	}
+
+	deliveries, err := svc.repo.GetEventDeliveries(outbox.ID)
+
+	if err != nil {
+		return EventOutboxDetailFormatter{}, err
+	}
+
+	return FormatEventOutboxDetailData(outbox, svc.bus.SubscriberNames(outbox.EventType), deliveries), nil
+}
+
+// ReplayEvent only runs the subscribers that have not handled the event yet
+func (svc *service) ReplayEvent(reqDetail RequestGetEventOutboxByID, reqReplay RequestReplayEventOutbox) (EventOutbox, error) {
+	outbox, err := svc.repo.GetEventOutboxByID(reqDetail.ID)
+
+	if err != nil {
+		return outbox, err
+	}
+
+	if outbox.Status != StatusFailed {
+		return outbox, errors.New("only failed events can be replayed")
+	}
+
+	before := outbox
+	outbox.Status = StatusPending
+	outbox.Attempts = 0
+	outbox.NextAttemptAt = time.Now()
+	outbox.LastError = sql.NullString{}
+
+	outbox, err = svc.repo.UpdateEventOutbox(outbox)
+
+	if err != nil {
+		return outbox, err
+	}
+
+	audit.Record(svc.auditSvc, reqReplay.User, audit.ActionUpdate, audit.EntityEventOutbox, outbox.ID, before, outbox)
+
+	Wake()
+
+	return outbox, nil
+}
+
+func (svc *service) AdminDataTablesEventOutbox(ctx *gin.Context) (helper.DataTables, error) {
+	dataTablesEventOutbox, err := svc.repo.AdminDataTablesEventOutbox(ctx)
+
+	if err != nil {
+		return dataTablesEventOutbox, err
+	}
+
+	return dataTablesEventOutbox, nil
+}
+
+// dispatch runs every subscriber that has not handled the event, one failing subscriber
+// does not stop the others and only the failed ones run again on the next attempt
+func (svc *service) dispatch(outbox EventOutbox) {
+	var failures []string
+
+	deliveries, err := svc.repo.GetEventDeliveries(outbox.ID)
+
+	if err != nil {
+		failures = append(failures, err.Error())
+	} else {
+		done := map[string]bool{}
+
+		for _, delivery := range deliveries {
+			done[delivery.Subscriber] = true
+		}
+
+		envelope := Envelope{
+			ID:         outbox.EventID,
+			Type:       outbox.EventType,
+			OccurredAt: outbox.CreatedAt,
+			Payload:    json.RawMessage(outbox.Payload),
+		}
+
+		for _, sub := range svc.bus.subscribersOf(outbox.EventType) {
+			if done[sub.name] {
+				continue
+			}
+
+			if err := call(sub, envelope); err != nil {
+				log.Printf("[EVENT] subscriber %s failed on %v %v, err: %s", sub.name, outbox.EventType, outbox.EventID, err.Error())
+				failures = append(failures, fmt.Sprintf("%s: %s", sub.name, err.Error()))
+				continue
+			}
+
+			// without the mark the subscriber runs again on the next attempt
+			if _, err := svc.repo.SaveEventDelivery(EventDelivery{EventOutboxID: outbox.ID, Subscriber: sub.name}); err != nil {
+				failures = append(failures, fmt.Sprintf("%s: %s", sub.name, err.Error()))
+			}
+		}
+	}
+
+	outbox.Attempts++
+
+	if len(failures) == 0 {
+		outbox.Status = StatusPublished
+		outbox.PublishedAt = sql.NullTime{Time: time.Now(), Valid: true}
+		outbox.LastError = sql.NullString{}
+	} else {
+		outbox.Status = StatusPending
+		outbox.LastError = helper.SetNS(strings.Join(failures, "; "))
+		outbox.NextAttemptAt = time.Now().Add(jobqueue.Backoff(outbox.Attempts, svc.config.BackoffBase, svc.config.BackoffMax))
+
+		if outbox.Attempts >= svc.config.MaxAttempts {
+			outbox.Status = StatusFailed
+		}
+	}
+
+	if _, err := svc.repo.UpdateEventOutbox(outbox); err != nil {
+		log.Printf("[EVENT] event %d status not saved, err: %s", outbox.ID, err.Error())
+	}
+}
+
+// a panicking subscriber is a failed attempt, not a dead worker
+func call(sub subscriber, envelope Envelope) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("panic: %v", recovered)
+		}
+	}()
+
+	return sub.handler(envelope)
+}
@@ -0,0 +1,57 @@
+package finalization
+
+import (
+	"database/sql"
+	"time"
+)
+
+const (
+	StatusPending   = "pending"
+	StatusCompleted = "completed"
+	// gave up after the max attempts, an admin can run it again with the manual finalize
+	StatusFailed = "failed"
+)
+
+const (
+	StepDisburseFunds  = "disburse_funds"
+	StepSelectWinner   = "select_winner"
+	StepNotifyOwner    = "notify_owner"
+	StepPublishWebhook = "publish_webhook"
+)
+
+// CampaignFinalization is the idempotency marker of a finished campaign, one row per campaign.
+// The amounts are fixed when the row is created and every step stamps its column once done,
+// a rerun only does the steps without a stamp.
+type CampaignFinalization struct {
+	ID                 int            `json:"id"`
+	CampaignID         int            `json:"campaign_id"`
+	Reason             string         `json:"reason"`
+	CollectedAmount    int64          `json:"collected_amount"`
+	AdminFee           int64          `json:"admin_fee"`
+	FinalAmount        int64          `json:"final_amount"`
+	Status             string         `json:"status"`
+	Attempts           int            `json:"attempts"`
+	LastError          sql.NullString `json:"last_error"`
+	FundsDisbursedAt   sql.NullTime   `json:"funds_disbursed_at"`
+	WinnerSelectedAt   sql.NullTime   `json:"winner_selected_at"`
+	OwnerNotifiedAt    sql.NullTime   `json:"owner_notified_at"`
+	WebhookPublishedAt sql.NullTime   `json:"webhook_published_at"`
+	CompletedAt        sql.NullTime   `json:"completed_at"`
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+}
+
+type step struct {
+	name string
+	done sql.NullTime
+}
+
+// steps in the order they run
+func (finalization CampaignFinalization) steps() []step {
+	return []step{
+		{name: StepDisburseFunds, done: finalization.FundsDisbursedAt},
+		{name: StepSelectWinner, done: finalization.WinnerSelectedAt},
+		{name: StepNotifyOwner, done: finalization.OwnerNotifiedAt},
+		{name: StepPublishWebhook, done: finalization.WebhookPublishedAt},
+	}
+}
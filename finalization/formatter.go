@@ -0,0 +1,58 @@
+package finalization
+
+import "time"
+
+type (
+	CampaignFinalizationStepFormatter struct {
+		Name   string     `json:"name"`
+		DoneAt *time.Time `json:"done_at"`
+	}
+
+	CampaignFinalizationFormatter struct {
+		ID              int                                 `json:"id"`
+		CampaignID      int                                 `json:"campaign_id"`
+		Reason          string                              `json:"reason"`
+		CollectedAmount int64                               `json:"collected_amount"`
+		AdminFee        int64                               `json:"admin_fee"`
+		FinalAmount     int64                               `json:"final_amount"`
+		Status          string                              `json:"status"`
+		Attempts        int                                 `json:"attempts"`
+		LastError       string                              `json:"last_error"`
+		Steps           []CampaignFinalizationStepFormatter `json:"steps"`
+		CompletedAt     *time.Time                          `json:"completed_at"`
+		CreatedAt       time.Time                           `json:"created_at"`
+	}
+)
+
+func FormatCampaignFinalizationData(finalization CampaignFinalization) CampaignFinalizationFormatter {
+	formatData := CampaignFinalizationFormatter{
+		ID:              finalization.ID,
+		CampaignID:      finalization.CampaignID,
+		Reason:          finalization.Reason,
+		CollectedAmount: finalization.CollectedAmount,
+		AdminFee:        finalization.AdminFee,
+		FinalAmount:     finalization.FinalAmount,
+		Status:          finalization.Status,
+		Attempts:        finalization.Attempts,
+		LastError:       finalization.LastError.String,
+		Steps:           []CampaignFinalizationStepFormatter{},
+		CreatedAt:       finalization.CreatedAt,
+	}
+
+	for _, step := range finalization.steps() {
+		stepData := CampaignFinalizationStepFormatter{Name: step.name}
+
+		if step.done.Valid {
+			doneAt := step.done.Time
+			stepData.DoneAt = &doneAt
+		}
+
+		formatData.Steps = append(formatData.Steps, stepData)
+	}
+
+	if finalization.CompletedAt.Valid {
+		formatData.CompletedAt = &finalization.CompletedAt.Time
+	}
+
+	return formatData
+}
@@ -0,0 +1,22 @@
+package finalization
+
+import (
+	"github.com/WeAreAmazingTeam/tcd-backend/joblock"
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	GetCampaignFinalizationByCampaignID(campaignID int) (CampaignFinalization, error)
+	GetPendingCampaignFinalizations(limit int) ([]CampaignFinalization, error)
+	SaveCampaignFinalization(CampaignFinalization) (CampaignFinalization, error)
+	UpdateCampaignFinalization(CampaignFinalization) (CampaignFinalization, error)
+	DisburseCampaignFunds(finalization CampaignFinalization, ownerUserID int, campaignTitle string, fence joblock.Fence) (bool, error)
+}
+
+type repository struct {
+	DB *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *repository {
+	return &repository{DB: db}
+}
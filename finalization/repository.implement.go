@@ -0,0 +1,120 @@
+package finalization
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/company"
+	"github.com/WeAreAmazingTeam/tcd-backend/joblock"
+	"github.com/WeAreAmazingTeam/tcd-backend/user"
+	"gorm.io/gorm"
+)
+
+func (repo *repository) GetCampaignFinalizationByCampaignID(campaignID int) (finalization CampaignFinalization, err error) {
+	if err := repo.DB.Where("campaign_id = ?", campaignID).Find(&finalization).Error; err != nil {
+		return finalization, err
+	}
+
+	if finalization.ID == 0 {
+		return finalization, errors.New("sql: no rows in result set")
+	}
+
+	return finalization, nil
+}
+
+func (repo *repository) GetPendingCampaignFinalizations(limit int) (finalizations []CampaignFinalization, err error) {
+	if err := repo.DB.Where("status = ?", StatusPending).Order("id ASC").Limit(limit).Find(&finalizations).Error; err != nil {
+		return finalizations, err
+	}
+	return finalizations, nil
+}
+
+func (repo *repository) SaveCampaignFinalization(finalization CampaignFinalization) (CampaignFinalization, error) {
+	if err := repo.DB.Create(&finalization).Error; err != nil {
+		return finalization, err
+	}
+	return finalization, nil
+}
+
+func (repo *repository) UpdateCampaignFinalization(finalization CampaignFinalization) (CampaignFinalization, error) {
+	if err := repo.DB.Save(&finalization).Error; err != nil {
+		return finalization, err
+	}
+	return finalization, nil
+}
+
+// DisburseCampaignFunds credits the owner and writes the cash flows in the same database transaction as the
+// funds_disbursed_at stamp, false means an earlier run already did it. The fence is checked in the same
+// database transaction, so a job that lost its lease can not pay out.
+func (repo *repository) DisburseCampaignFunds(finalization CampaignFinalization, ownerUserID int, campaignTitle string, fence joblock.Fence) (bool, error) {
+	disbursed := false
+
+	err := repo.DB.Transaction(func(tx *gorm.DB) error {
+		if err := fence.Check(tx); err != nil {
+			return err
+		}
+
+		result := tx.Model(&CampaignFinalization{}).
+			Where("id = ? AND funds_disbursed_at IS NULL", finalization.ID).
+			Updates(map[string]any{"funds_disbursed_at": time.Now(), "updated_at": time.Now()})
+
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		if err := tx.Model(&user.User{}).Where("id = ?", ownerUserID).Update("e_money", gorm.Expr("e_money + ?", finalization.FinalAmount)).Error; err != nil {
+			return err
+		}
+
+		eMoneyFlows := []user.UserEMoneyFlow{
+			{
+				UserID: ownerUserID,
+				Status: "in",
+				Amount: finalization.CollectedAmount,
+				Note:   fmt.Sprintf("Funds from the donation campaign: %v.", campaignTitle),
+			},
+			{
+				UserID: ownerUserID,
+				Status: "out",
+				Amount: finalization.AdminFee,
+				Note:   fmt.Sprintf("Admin fee for the donation campaign: %v.", campaignTitle),
+			},
+		}
+
+		if err := tx.Create(&eMoneyFlows).Error; err != nil {
+			return err
+		}
+
+		companyCashFlows := []company.CompanyCashFlow{
+			{
+				Status: "out",
+				Amount: finalization.CollectedAmount,
+				Note:   fmt.Sprintf("Disburse funds for donation campaign: %v.", campaignTitle),
+			},
+			{
+				Status: "in",
+				Amount: finalization.AdminFee,
+				Note:   fmt.Sprintf("Admin fee from donation campaign: %v.", campaignTitle),
+			},
+		}
+
+		if err := tx.Create(&companyCashFlows).Error; err != nil {
+			return err
+		}
+
+		disbursed = true
+
+		return nil
+	})
+
+	if err != nil {
+		return false, err
+	}
+
+	return disbursed, nil
+}
@@ -0,0 +1,13 @@
+package finalization
+
+import "github.com/WeAreAmazingTeam/tcd-backend/user"
+
+type (
+	RequestGetCampaignFinalization struct {
+		CampaignID int `uri:"id" binding:"required"`
+	}
+
+	RequestFinalizeCampaign struct {
+		User user.User
+	}
+)
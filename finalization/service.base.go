@@ -0,0 +1,54 @@
+package finalization
+
+import (
+	"sync"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/audit"
+	"github.com/WeAreAmazingTeam/tcd-backend/campaign"
+	"github.com/WeAreAmazingTeam/tcd-backend/event"
+	"github.com/WeAreAmazingTeam/tcd-backend/joblock"
+	"github.com/WeAreAmazingTeam/tcd-backend/jobqueue"
+	"github.com/WeAreAmazingTeam/tcd-backend/user"
+)
+
+type Service interface {
+	FinalizeExpiredCampaigns(fence joblock.Fence) (int, error)
+	FinalizeCampaign(RequestGetCampaignFinalization, RequestFinalizeCampaign) (CampaignFinalization, error)
+	GetCampaignFinalization(RequestGetCampaignFinalization) (CampaignFinalization, error)
+
+	RegisterSubscribers(*event.Bus)
+}
+
+type Config struct {
+	BatchSize   int
+	MaxAttempts int
+}
+
+type service struct {
+	// one finalization at a time in this process, the steps of a campaign never run concurrently
+	mu           sync.Mutex
+	repo         Repository
+	campaignRepo campaign.Repository
+	userRepo     user.Repository
+	queue        jobqueue.Service
+	config       Config
+	auditSvc     audit.Service
+}
+
+func NewService(
+	repository Repository,
+	campaignRepository campaign.Repository,
+	userRepository user.Repository,
+	queue jobqueue.Service,
+	config Config,
+	auditService audit.Service,
+) *service {
+	return &service{
+		repo:         repository,
+		campaignRepo: campaignRepository,
+		userRepo:     userRepository,
+		queue:        queue,
+		config:       config,
+		auditSvc:     auditService,
+	}
+}
@@ -0,0 +1,383 @@
+package finalization
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/audit"
+	"github.com/WeAreAmazingTeam/tcd-backend/campaign"
+	"github.com/WeAreAmazingTeam/tcd-backend/event"
+	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+	"github.com/WeAreAmazingTeam/tcd-backend/joblock"
+	"github.com/WeAreAmazingTeam/tcd-backend/user"
+)
+
+// RegisterSubscribers starts a finalization for every finished campaign, whatever finished it
+func (svc *service) RegisterSubscribers(bus *event.Bus) {
+	event.On(bus, "finalization.start", svc.onCampaignFinished)
+}
+
+func (svc *service) onCampaignFinished(finished event.CampaignFinished) error {
+	if _, err := svc.prepare(finished.CampaignID, finished.Reason, finished.CollectedAmount); err != nil {
+		return err
+	}
+
+	// a failed step stays on the finalization, FinalizeExpiredCampaigns resumes it
+	if _, err := svc.run(finished.CampaignID, joblock.NoFence{}); err != nil {
+		log.Printf("[FINALIZATION] campaign %v not finalized yet, err: %s", finished.CampaignID, err.Error())
+	}
+
+	return nil
+}
+
+// FinalizeExpiredCampaigns finishes the active campaigns past their deadline and resumes the finalizations
+// a failed step left pending, it returns how many campaigns were finished. It stops at the first
+// campaign after the fence reports the job lease lost.
+func (svc *service) FinalizeExpiredCampaigns(fence joblock.Fence) (int, error) {
+	campaigns, err := svc.campaignRepo.GetExpiredActiveCampaigns(time.Now())
+
+	if err != nil {
+		return 0, err
+	}
+
+	finished := 0
+
+	for _, campaignData := range campaigns {
+		if err := fence.Err(); err != nil {
+			return finished, err
+		}
+
+		ok, err := svc.finish(campaignData, event.FinishReasonDeadline, user.User{})
+
+		if err != nil {
+			return finished, err
+		}
+
+		if ok {
+			finished++
+		}
+	}
+
+	finalizations, err := svc.repo.GetPendingCampaignFinalizations(svc.config.BatchSize)
+
+	if err != nil {
+		return finished, err
+	}
+
+	for _, finalization := range finalizations {
+		if err := fence.Err(); err != nil {
+			return finished, err
+		}
+
+		if _, err := svc.run(finalization.CampaignID, fence); err != nil {
+			log.Printf("[FINALIZATION] campaign %v not finalized yet, err: %s", finalization.CampaignID, err.Error())
+		}
+	}
+
+	return finished, nil
+}
+
+// FinalizeCampaign finishes an active campaign now, or resumes the finalization of a finished one
+// including one that gave up after the max attempts
+func (svc *service) FinalizeCampaign(reqDetail RequestGetCampaignFinalization, reqFinalize RequestFinalizeCampaign) (CampaignFinalization, error) {
+	campaignData, err := svc.campaignRepo.GetCampaignByID(reqDetail.CampaignID)
+
+	if err != nil {
+		return CampaignFinalization{}, err
+	}
+
+	switch campaignData.Status {
+	case "active":
+		if _, err := svc.finish(campaignData, event.FinishReasonManual, reqFinalize.User); err != nil {
+			return CampaignFinalization{}, err
+		}
+
+		if _, err := svc.prepare(campaignData.ID, event.FinishReasonManual, campaignData.CurrentAmount); err != nil {
+			return CampaignFinalization{}, err
+		}
+	case "finished":
+		// campaigns finished before finalizations were recorded were already paid out, never pay them twice
+		if _, err := svc.repo.GetCampaignFinalizationByCampaignID(campaignData.ID); err != nil {
+			if helper.IsErrNoRows(err.Error()) {
+				return CampaignFinalization{}, errors.New("campaign has no finalization, it was finished before finalizations were recorded or its event is still queued")
+			}
+
+			return CampaignFinalization{}, err
+		}
+	default:
+		return CampaignFinalization{}, errors.New("only active or finished campaigns can be finalized")
+	}
+
+	finalization, err := svc.resume(campaignData.ID, reqFinalize.User)
+
+	if err != nil {
+		return finalization, err
+	}
+
+	return svc.run(campaignData.ID, joblock.NoFence{})
+}
+
+func (svc *service) GetCampaignFinalization(req RequestGetCampaignFinalization) (CampaignFinalization, error) {
+	finalization, err := svc.repo.GetCampaignFinalizationByCampaignID(req.CampaignID)
+
+	if err != nil {
+		return finalization, err
+	}
+
+	return finalization, nil
+}
+
+// finish is a no-op for a campaign that is no longer active, the finalization starts from the CampaignFinished event
+func (svc *service) finish(campaignData campaign.Campaign, reason string, actor user.User) (bool, error) {
+	before := campaignData
+	campaignData.Status = "finished"
+	campaignData.UpdatedBy = helper.SetNS("SYSTEM")
+
+	if actor.ID != 0 {
+		campaignData.UpdatedBy = helper.SetNS(strconv.Itoa(actor.ID))
+	}
+
+	finished, err := svc.campaignRepo.FinishCampaign(campaignData, event.CampaignFinished{
+		CampaignID:      campaignData.ID,
+		OwnerUserID:     campaignData.UserID,
+		Reason:          reason,
+		CollectedAmount: campaignData.CurrentAmount,
+		FinishedAt:      time.Now(),
+	})
+
+	if err != nil {
+		return false, err
+	}
+
+	if finished {
+		audit.Record(svc.auditSvc, actor, audit.ActionUpdate, audit.EntityCampaign, campaignData.ID, before, campaignData)
+	}
+
+	return finished, nil
+}
+
+// prepare fixes the amounts of the finalization, a second call for the same campaign returns the first row
+func (svc *service) prepare(campaignID int, reason string, collectedAmount int64) (CampaignFinalization, error) {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+
+	finalization, err := svc.repo.GetCampaignFinalizationByCampaignID(campaignID)
+
+	if err == nil {
+		return finalization, nil
+	}
+
+	if !helper.IsErrNoRows(err.Error()) {
+		return finalization, err
+	}
+
+	adminFee, finalAmount := splitAdminFee(collectedAmount)
+
+	finalization = CampaignFinalization{}
+	finalization.CampaignID = campaignID
+	finalization.Reason = reason
+	finalization.CollectedAmount = collectedAmount
+	finalization.AdminFee = int64(adminFee)
+	finalization.FinalAmount = int64(finalAmount)
+	finalization.Status = StatusPending
+
+	newFinalization, err := svc.repo.SaveCampaignFinalization(finalization)
+
+	if err != nil {
+		return newFinalization, err
+	}
+
+	audit.Record(svc.auditSvc, user.User{}, audit.ActionCreate, audit.EntityCampaignFinalization, newFinalization.ID, nil, newFinalization)
+
+	return newFinalization, nil
+}
+
+func (svc *service) resume(campaignID int, actor user.User) (CampaignFinalization, error) {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+
+	finalization, err := svc.repo.GetCampaignFinalizationByCampaignID(campaignID)
+
+	if err != nil {
+		return finalization, err
+	}
+
+	if finalization.Status == StatusCompleted {
+		return finalization, errors.New("campaign is already finalized")
+	}
+
+	before := finalization
+	finalization.Status = StatusPending
+	finalization.Attempts = 0
+
+	finalization, err = svc.repo.UpdateCampaignFinalization(finalization)
+
+	if err != nil {
+		return finalization, err
+	}
+
+	audit.Record(svc.auditSvc, actor, audit.ActionUpdate, audit.EntityCampaignFinalization, finalization.ID, before, finalization)
+
+	return finalization, nil
+}
+
+// run does the steps without a stamp in order, stamping each one as soon as it is done.
+// A lost lease stops it between steps without counting as an attempt.
+func (svc *service) run(campaignID int, fence joblock.Fence) (CampaignFinalization, error) {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+
+	finalization, err := svc.repo.GetCampaignFinalizationByCampaignID(campaignID)
+
+	if err != nil {
+		return finalization, err
+	}
+
+	if finalization.Status != StatusPending {
+		return finalization, nil
+	}
+
+	campaignData, err := svc.campaignRepo.GetCampaignByID(campaignID)
+
+	if err != nil {
+		return svc.fail(finalization, err)
+	}
+
+	before := finalization
+
+	for _, step := range finalization.steps() {
+		if step.done.Valid {
+			continue
+		}
+
+		if err := fence.Err(); err != nil {
+			return finalization, err
+		}
+
+		if err := svc.runStep(&finalization, step.name, campaignData, fence); err != nil {
+			if err == joblock.ErrLeaseLost {
+				return finalization, err
+			}
+
+			return svc.fail(finalization, fmt.Errorf("%s: %w", step.name, err))
+		}
+
+		if finalization, err = svc.repo.UpdateCampaignFinalization(finalization); err != nil {
+			return finalization, err
+		}
+	}
+
+	finalization.Status = StatusCompleted
+	finalization.LastError = sql.NullString{}
+	finalization.CompletedAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+	finalization, err = svc.repo.UpdateCampaignFinalization(finalization)
+
+	if err != nil {
+		return finalization, err
+	}
+
+	audit.Record(svc.auditSvc, user.User{}, audit.ActionUpdate, audit.EntityCampaignFinalization, finalization.ID, before, finalization)
+
+	return finalization, nil
+}
+
+func (svc *service) runStep(finalization *CampaignFinalization, name string, campaignData campaign.Campaign, fence joblock.Fence) error {
+	done := sql.NullTime{Time: time.Now(), Valid: true}
+
+	switch name {
+	case StepDisburseFunds:
+		// stamped by the repository in the same database transaction as the money
+		if _, err := svc.repo.DisburseCampaignFunds(*finalization, campaignData.UserID, campaignData.Title, fence); err != nil {
+			return err
+		}
+
+		finalization.FundsDisbursedAt = done
+	case StepSelectWinner:
+		if err := svc.selectExclusiveWinner(campaignData); err != nil {
+			return err
+		}
+
+		finalization.WinnerSelectedAt = done
+	case StepNotifyOwner:
+		if err := svc.notifyOwner(*finalization, campaignData); err != nil {
+			return err
+		}
+
+		finalization.OwnerNotifiedAt = done
+	case StepPublishWebhook:
+		err := helper.PublishWebhookEvent(helper.WebhookEventCampaignFinished, campaignData.UserID, helper.WebhookCampaignFinished{
+			CampaignID:      campaignData.ID,
+			Title:           campaignData.Title,
+			GoalAmount:      campaignData.GoalAmount,
+			CollectedAmount: finalization.CollectedAmount,
+			AdminFee:        finalization.AdminFee,
+			DisbursedAmount: finalization.FinalAmount,
+			FinishedAt:      finalization.CreatedAt,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		finalization.WebhookPublishedAt = done
+	}
+
+	return nil
+}
+
+// selectExclusiveWinner hands the draw to the job queue, the stamp means the job is queued
+func (svc *service) selectExclusiveWinner(campaignData campaign.Campaign) error {
+	if campaignData.IsExclusive != 1 {
+		return nil
+	}
+
+	return svc.queue.Enqueue(campaign.SelectExclusiveWinner{CampaignID: campaignData.ID})
+}
+
+func (svc *service) notifyOwner(finalization CampaignFinalization, campaignData campaign.Campaign) error {
+	owner, err := svc.userRepo.GetUserByID(campaignData.UserID)
+
+	if err != nil {
+		return err
+	}
+
+	templateData := helper.EmailCampaignFinished{
+		Campaign:       campaignData,
+		Name:           owner.Name,
+		GoalAmount:     helper.FormatRupiah(float64(campaignData.GoalAmount)),
+		CollectedFunds: helper.FormatRupiah(float64(finalization.CollectedAmount)),
+		AdminFee:       helper.FormatRupiah(float64(finalization.AdminFee)),
+		FinalAmount:    helper.FormatRupiah(float64(finalization.FinalAmount)),
+	}
+	return helper.SendNotification(helper.NotificationRecipient{UserID: owner.ID, Email: owner.Email, Locale: owner.Locale}, helper.EmailTemplateCampaignFinished, templateData)
+}
+
+func (svc *service) fail(finalization CampaignFinalization, cause error) (CampaignFinalization, error) {
+	finalization.Attempts++
+	finalization.LastError = helper.SetNS(cause.Error())
+
+	if finalization.Attempts >= svc.config.MaxAttempts {
+		finalization.Status = StatusFailed
+	}
+
+	if _, err := svc.repo.UpdateCampaignFinalization(finalization); err != nil {
+		log.Printf("[FINALIZATION] finalization %d status not saved, err: %s", finalization.ID, err.Error())
+	}
+
+	return finalization, cause
+}
+
+// splitAdminFee returns the 6% admin fee and what is left for the campaign owner
+func splitAdminFee(collectedAmount int64) (adminFee, finalAmount float64) {
+	sumAmount := float64(collectedAmount)
+	adminFee = sumAmount - math.Round(float64(sumAmount-(sumAmount*(float64(6)/float64(100)))))
+	finalAmount = sumAmount - adminFee
+
+	return adminFee, finalAmount
+}
@@ -9,6 +9,7 @@ require (
 	github.com/gin-contrib/gzip v0.0.6
 	github.com/go-redis/redis v6.15.9+incompatible
 	github.com/joho/godotenv v1.5.1
+	github.com/mailgun/mailgun-go/v4 v4.8.2
 	github.com/midtrans/midtrans-go v1.3.6
 	github.com/robfig/cron/v3 v3.0.0
 	github.com/thanhpk/randstr v1.0.4
@@ -20,7 +21,6 @@ require (
 	github.com/gorilla/mux v1.8.0 // indirect
 	github.com/gosimple/unidecode v1.0.1 // indirect
 	github.com/karlseguin/ccache/v2 v2.0.8 // indirect
-	github.com/mailgun/mailgun-go/v4 v4.8.2 // indirect
 	github.com/pkg/errors v0.8.1 // indirect
 	gopkg.in/alexcesaro/quotedprintable.v3 v3.0.0-20150716171945-2caba252f4dc // indirect
 )
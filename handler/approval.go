@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/approval"
+	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+	"github.com/WeAreAmazingTeam/tcd-backend/logs"
+	"github.com/WeAreAmazingTeam/tcd-backend/user"
+	"github.com/gin-gonic/gin"
+)
+
+type approvalHandler struct {
+	approvalSvc approval.Service
+	userSvc     user.Service
+	logsSvc     logs.Service
+}
+
+func NewApprovalHandler(
+	approvalService approval.Service,
+	userService user.Service,
+	logsService logs.Service,
+) *approvalHandler {
+	return &approvalHandler{
+		approvalSvc: approvalService,
+		userSvc:     userService,
+		logsSvc:     logsService,
+	}
+}
+
+func (handler *approvalHandler) AdminDataTablesApprovals(ctx *gin.Context) {
+	dataTablesApprovals, err := handler.approvalSvc.AdminDataTablesApprovals(ctx)
+
+	if err != nil {
+		response := helper.APIResponseError(http.StatusInternalServerError, "Get datatables approvals failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dataTablesApprovals)
+}
+
+func (handler *approvalHandler) GetApprovalByID(ctx *gin.Context) {
+	var req approval.RequestGetApprovalByID
+
+	err := ctx.ShouldBindUri(&req)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Get approval failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	approvalData, err := handler.approvalSvc.GetApprovalByID(req)
+
+	if err != nil {
+		if helper.IsErrNoRows(err.Error()) {
+			response := helper.APIResponseError(http.StatusNotFound, "Get approval failed!", "Data not found!")
+			ctx.JSON(http.StatusNotFound, response)
+			return
+		}
+
+		response := helper.APIResponseError(http.StatusInternalServerError, "Get approval failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := helper.APIResponse(http.StatusOK, "Get approval successfully!", approval.FormatApprovalData(approvalData))
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (handler *approvalHandler) DecideApproval(ctx *gin.Context) {
+	var reqID approval.RequestGetApprovalByID
+
+	err := ctx.ShouldBindUri(&reqID)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Decide approval failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	var reqDecide approval.RequestDecideApproval
+
+	err = ctx.ShouldBind(&reqDecide)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Decide approval failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	reqDecide.User = ctx.MustGet("userData").(user.User)
+
+	approvalData, err := handler.approvalSvc.DecideApproval(reqID, reqDecide)
+
+	if err != nil {
+		if errors.Is(err, approval.ErrSameAdmin) || errors.Is(err, approval.ErrNotAllowed) {
+			response := helper.APIResponseError(http.StatusForbidden, "Decide approval failed!", err.Error())
+			ctx.JSON(http.StatusForbidden, response)
+			return
+		}
+
+		// the decision is kept as failed, the action itself was refused
+		if errors.Is(err, approval.ErrActionFailed) {
+			handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("%v approving approval id %v, its action failed.", reqDecide.User.Name, reqID.ID))
+
+			response := helper.APIResponseError(http.StatusConflict, "Decide approval failed!", err.Error())
+			ctx.JSON(http.StatusConflict, response)
+			return
+		}
+
+		if helper.IsErrNoRows(err.Error()) {
+			response := helper.APIResponseError(http.StatusNotFound, "Decide approval failed!", fmt.Sprintf("Approval with ID %d not found!", reqID.ID))
+			ctx.JSON(http.StatusNotFound, response)
+			return
+		}
+
+		response := helper.APIResponseError(http.StatusBadRequest, "Decide approval failed!", err.Error())
+		ctx.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	if approvalData.Status == approval.StatusApproved && approvalData.Action == approval.ActionWithdrawalApprove {
+		userWithdrawalRequest, err := handler.userSvc.GetWithdrawalRequestByID(approvalData.EntityID)
+
+		if err == nil {
+			err = withdrawalRequestUpdated(handler.userSvc, userWithdrawalRequest)
+		}
+
+		if err != nil {
+			handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("Failed to notify about user request withdrawal id %v: %s.", approvalData.EntityID, err.Error()))
+		}
+	}
+
+	response := helper.APIResponse(http.StatusOK, "Decide approval successfully!", approval.FormatApprovalData(approvalData))
+
+	handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("%v deciding approval id %v as %v.", reqDecide.User.Name, reqID.ID, approvalData.Status))
+
+	ctx.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/audit"
+	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+	"github.com/gin-gonic/gin"
+)
+
+type auditHandler struct {
+	auditSvc audit.Service
+}
+
+func NewAuditHandler(auditService audit.Service) *auditHandler {
+	return &auditHandler{auditSvc: auditService}
+}
+
+func (handler *auditHandler) AdminDataTablesAuditLogs(ctx *gin.Context) {
+	dataTablesAuditLogs, err := handler.auditSvc.AdminDataTablesAuditLogs(ctx)
+
+	if err != nil {
+		response := helper.APIResponseError(http.StatusInternalServerError, "Get datatables audit logs failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dataTablesAuditLogs)
+}
+
+func (handler *auditHandler) GetEntityHistory(ctx *gin.Context) {
+	var req audit.RequestGetEntityHistory
+
+	err := ctx.ShouldBindUri(&req)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Get entity history failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	auditLogs, err := handler.auditSvc.GetEntityHistory(req)
+
+	if err != nil {
+		response := helper.APIResponseError(http.StatusInternalServerError, "Get entity history failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	formatData := audit.FormatListAuditLogData(auditLogs)
+	response := helper.APIResponse(http.StatusOK, "Get entity history successfully!", formatData)
+
+	ctx.JSON(http.StatusOK, response)
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/WeAreAmazingTeam/tcd-backend/campaign"
 	"github.com/WeAreAmazingTeam/tcd-backend/helper"
 	"github.com/WeAreAmazingTeam/tcd-backend/logs"
+	"github.com/WeAreAmazingTeam/tcd-backend/rbac"
 	"github.com/WeAreAmazingTeam/tcd-backend/user"
 	"github.com/gin-gonic/gin"
 	"github.com/gosimple/slug"
@@ -20,20 +21,29 @@ type campaignHandler struct {
 	campaignSvc campaign.Service
 	userSvc     user.Service
 	logsSvc     logs.Service
+	rbacSvc     rbac.Service
 }
 
 func NewCampaignHandler(
 	campaignService campaign.Service,
 	userService user.Service,
 	logsService logs.Service,
+	rbacService rbac.Service,
 ) *campaignHandler {
 	return &campaignHandler{
 		campaignSvc: campaignService,
 		userSvc:     userService,
 		logsSvc:     logsService,
+		rbacSvc:     rbacService,
 	}
 }
 
+// moderators may manage campaigns they don't own
+func (handler *campaignHandler) isModerator(userData user.User) bool {
+	allowed, err := handler.rbacSvc.HasPermission(userData.Role, rbac.PermissionCampaignModerate)
+	return err == nil && allowed
+}
+
 func (handler *campaignHandler) GetAllCampaign(ctx *gin.Context) {
 	campaigns, err := handler.campaignSvc.GetAllCampaign(ctx)
 
@@ -96,7 +106,7 @@ func (handler *campaignHandler) CreateCampaign(ctx *gin.Context) {
 	userData := ctx.MustGet("userData").(user.User)
 
 	if req.UserID != 0 {
-		if userData.Role == "user" {
+		if !handler.isModerator(userData) {
 			response := helper.APIResponseError(http.StatusBadRequest, "Create campaign failed!", "Bad Request!")
 			ctx.JSON(http.StatusBadRequest, response)
 			return
@@ -159,6 +169,7 @@ func (handler *campaignHandler) UpdateCampaign(ctx *gin.Context) {
 	}
 
 	reqUpdate.User = ctx.MustGet("userData").(user.User)
+	reqUpdate.IsModerator = handler.isModerator(reqUpdate.User)
 
 	oldCampaign, err := handler.campaignSvc.GetCampaignByID(reqID)
 
@@ -204,7 +215,9 @@ func (handler *campaignHandler) UpdateCampaign(ctx *gin.Context) {
 				GoalAmount:   helper.FormatRupiah(float64(updatedCampaign.GoalAmount)),
 				CampaignLink: os.Getenv("WEB_URL") + "/donate/" + strconv.Itoa(updatedCampaign.ID),
 			}
-			go helper.SendMail(ownerCampaignUserData.Email, "Your Donation Campaign Now Active!", templateData, "html/campaign_active.html")
+			if err := helper.SendNotification(helper.NotificationRecipient{UserID: ownerCampaignUserData.ID, Email: ownerCampaignUserData.Email, Locale: ownerCampaignUserData.Locale}, helper.EmailTemplateCampaignActive, templateData); err != nil {
+				handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("Failed to send campaign active notification to %s: %s.", ownerCampaignUserData.Email, err.Error()))
+			}
 		}
 	}
 
@@ -240,6 +253,7 @@ func (handler *campaignHandler) DeleteCampaign(ctx *gin.Context) {
 	}
 
 	reqDelete.User = ctx.MustGet("userData").(user.User)
+	reqDelete.IsModerator = handler.isModerator(reqDelete.User)
 
 	if _, err = handler.campaignSvc.DeleteCampaign(reqID, reqDelete); err != nil {
 		if helper.IsErrNoRows(err.Error()) {
@@ -320,6 +334,7 @@ func (handler *campaignHandler) UploadImage(ctx *gin.Context) {
 	}
 
 	req.User = ctx.MustGet("userData").(user.User)
+	req.IsModerator = handler.isModerator(req.User)
 
 	file, err := ctx.FormFile("file")
 	if err != nil {
@@ -377,6 +392,7 @@ func (handler *campaignHandler) DeleteCampaignImage(ctx *gin.Context) {
 	}
 
 	reqDelete.User = ctx.MustGet("userData").(user.User)
+	reqDelete.IsModerator = handler.isModerator(reqDelete.User)
 
 	if _, err = handler.campaignSvc.DeleteCampaignImage(reqID, reqDelete); err != nil {
 		if helper.IsErrNoRows(err.Error()) {
@@ -766,7 +782,9 @@ func (handler *campaignHandler) UpdateCampaignExclusive(ctx *gin.Context) {
 				Reward:       reward,
 				Status:       status,
 			}
-			go helper.SendMail(winnerUserData.Email, "Information Update For Your Reward", templateData, "html/reward_update.html")
+			if err := helper.SendNotification(helper.NotificationRecipient{UserID: winnerUserData.ID, Email: winnerUserData.Email, Locale: winnerUserData.Locale}, helper.EmailTemplateRewardUpdate, templateData); err != nil {
+				handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("Failed to send reward update notification to %s: %s.", winnerUserData.Email, err.Error()))
+			}
 		}
 	}
 
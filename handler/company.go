@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/WeAreAmazingTeam/tcd-backend/approval"
 	"github.com/WeAreAmazingTeam/tcd-backend/company"
 	"github.com/WeAreAmazingTeam/tcd-backend/helper"
 	"github.com/WeAreAmazingTeam/tcd-backend/logs"
@@ -12,17 +13,20 @@ import (
 )
 
 type companyHandler struct {
-	companySvc company.Service
-	logsSvc    logs.Service
+	companySvc  company.Service
+	approvalSvc approval.Service
+	logsSvc     logs.Service
 }
 
 func NewCompanyHandler(
 	companyService company.Service,
+	approvalService approval.Service,
 	logsService logs.Service,
 ) *companyHandler {
 	return &companyHandler{
-		companySvc: companyService,
-		logsSvc:    logsService,
+		companySvc:  companyService,
+		approvalSvc: approvalService,
+		logsSvc:     logsService,
 	}
 }
 
@@ -52,6 +56,23 @@ func (handler *companyHandler) CreateCompanyCashFlow(ctx *gin.Context) {
 
 	req.User = ctx.MustGet("userData").(user.User)
 
+	approvalData, required, err := handler.approvalSvc.SubmitCompanyCashFlow(req)
+
+	if err != nil {
+		response := helper.APIResponseError(http.StatusInternalServerError, "Create company cash flow failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	if required {
+		response := helper.APIResponse(http.StatusAccepted, "Create company cash flow is waiting for approval!", approval.FormatApprovalData(approvalData))
+
+		handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("%v asking approval id %v to create company cash flow.", req.User.Name, approvalData.ID))
+
+		ctx.JSON(http.StatusAccepted, response)
+		return
+	}
+
 	companyCashFlowData, err := handler.companySvc.CreateCompanyCashFlow(req)
 
 	if err != nil {
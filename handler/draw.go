@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/draw"
+	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+	"github.com/gin-gonic/gin"
+)
+
+type drawHandler struct {
+	drawSvc draw.Service
+}
+
+func NewDrawHandler(drawService draw.Service) *drawHandler {
+	return &drawHandler{
+		drawSvc: drawService,
+	}
+}
+
+// GetDrawByCampaignID is public, the revealed seed and the entrant snapshot let anyone recompute the winner
+func (handler *drawHandler) GetDrawByCampaignID(ctx *gin.Context) {
+	var req draw.RequestGetDrawByCampaignID
+
+	err := ctx.ShouldBindUri(&req)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Get draw failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	drawData, err := handler.drawSvc.GetDraw(req)
+
+	if err != nil {
+		if helper.IsErrNoRows(err.Error()) {
+			response := helper.APIResponseError(http.StatusNotFound, "Get draw failed!", "Data not found!")
+			ctx.JSON(http.StatusNotFound, response)
+			return
+		}
+
+		response := helper.APIResponseError(http.StatusInternalServerError, "Get draw failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := helper.APIResponse(http.StatusOK, "Get draw successfully!", drawData)
+	ctx.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/event"
+	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+	"github.com/WeAreAmazingTeam/tcd-backend/logs"
+	"github.com/WeAreAmazingTeam/tcd-backend/user"
+	"github.com/gin-gonic/gin"
+)
+
+type eventHandler struct {
+	eventSvc event.Service
+	logsSvc  logs.Service
+}
+
+func NewEventHandler(eventService event.Service, logsService logs.Service) *eventHandler {
+	return &eventHandler{
+		eventSvc: eventService,
+		logsSvc:  logsService,
+	}
+}
+
+func (handler *eventHandler) AdminDataTablesEventOutbox(ctx *gin.Context) {
+	dataTablesEventOutbox, err := handler.eventSvc.AdminDataTablesEventOutbox(ctx)
+
+	if err != nil {
+		response := helper.APIResponseError(http.StatusInternalServerError, "Get datatables event outbox failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dataTablesEventOutbox)
+}
+
+func (handler *eventHandler) AdminGetEventOutbox(ctx *gin.Context) {
+	var req event.RequestGetEventOutboxByID
+
+	err := ctx.ShouldBindUri(&req)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Get event failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	outbox, err := handler.eventSvc.GetEventOutboxDetail(req)
+
+	if err != nil {
+		if helper.IsErrNoRows(err.Error()) {
+			response := helper.APIResponseError(http.StatusNotFound, "Get event failed!", "Data not found!")
+			ctx.JSON(http.StatusNotFound, response)
+			return
+		}
+
+		response := helper.APIResponseError(http.StatusInternalServerError, "Get event failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := helper.APIResponse(http.StatusOK, "Get event successfully!", outbox)
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (handler *eventHandler) AdminReplayEvent(ctx *gin.Context) {
+	var reqDetail event.RequestGetEventOutboxByID
+	var reqReplay event.RequestReplayEventOutbox
+
+	err := ctx.ShouldBindUri(&reqDetail)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Replay event failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	reqReplay.User = ctx.MustGet("userData").(user.User)
+
+	outbox, err := handler.eventSvc.ReplayEvent(reqDetail, reqReplay)
+
+	if err != nil {
+		if helper.IsErrNoRows(err.Error()) {
+			response := helper.APIResponseError(http.StatusNotFound, "Replay event failed!", "Data not found!")
+			ctx.JSON(http.StatusNotFound, response)
+			return
+		}
+
+		response := helper.APIResponseError(http.StatusBadRequest, "Replay event failed!", err.Error())
+		ctx.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("%v replay event id %v.", reqReplay.User.Name, outbox.EventID))
+
+	response := helper.APIResponse(http.StatusOK, "Replay event successfully!", event.FormatEventOutboxData(outbox))
+	ctx.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/finalization"
+	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+	"github.com/WeAreAmazingTeam/tcd-backend/logs"
+	"github.com/WeAreAmazingTeam/tcd-backend/user"
+	"github.com/gin-gonic/gin"
+)
+
+type finalizationHandler struct {
+	finalizationSvc finalization.Service
+	logsSvc         logs.Service
+}
+
+func NewFinalizationHandler(finalizationService finalization.Service, logsService logs.Service) *finalizationHandler {
+	return &finalizationHandler{
+		finalizationSvc: finalizationService,
+		logsSvc:         logsService,
+	}
+}
+
+func (handler *finalizationHandler) AdminGetCampaignFinalization(ctx *gin.Context) {
+	var req finalization.RequestGetCampaignFinalization
+
+	err := ctx.ShouldBindUri(&req)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Get campaign finalization failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	campaignFinalization, err := handler.finalizationSvc.GetCampaignFinalization(req)
+
+	if err != nil {
+		if helper.IsErrNoRows(err.Error()) {
+			response := helper.APIResponseError(http.StatusNotFound, "Get campaign finalization failed!", "Data not found!")
+			ctx.JSON(http.StatusNotFound, response)
+			return
+		}
+
+		response := helper.APIResponseError(http.StatusInternalServerError, "Get campaign finalization failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := helper.APIResponse(http.StatusOK, "Get campaign finalization successfully!", finalization.FormatCampaignFinalizationData(campaignFinalization))
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (handler *finalizationHandler) AdminFinalizeCampaign(ctx *gin.Context) {
+	var reqDetail finalization.RequestGetCampaignFinalization
+	var reqFinalize finalization.RequestFinalizeCampaign
+
+	err := ctx.ShouldBindUri(&reqDetail)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Finalize campaign failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	reqFinalize.User = ctx.MustGet("userData").(user.User)
+
+	campaignFinalization, err := handler.finalizationSvc.FinalizeCampaign(reqDetail, reqFinalize)
+
+	if err != nil {
+		if helper.IsErrNoRows(err.Error()) {
+			response := helper.APIResponseError(http.StatusNotFound, "Finalize campaign failed!", "Data not found!")
+			ctx.JSON(http.StatusNotFound, response)
+			return
+		}
+
+		response := helper.APIResponseError(http.StatusBadRequest, "Finalize campaign failed!", err.Error())
+		ctx.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("%v finalizing campaign id %v.", reqFinalize.User.Name, reqDetail.CampaignID))
+
+	response := helper.APIResponse(http.StatusOK, "Finalize campaign successfully!", finalization.FormatCampaignFinalizationData(campaignFinalization))
+	ctx.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+	"github.com/WeAreAmazingTeam/tcd-backend/jobqueue"
+	"github.com/WeAreAmazingTeam/tcd-backend/logs"
+	"github.com/WeAreAmazingTeam/tcd-backend/user"
+	"github.com/gin-gonic/gin"
+)
+
+type jobQueueHandler struct {
+	jobQueueSvc jobqueue.Service
+	logsSvc     logs.Service
+}
+
+func NewJobQueueHandler(jobQueueService jobqueue.Service, logsService logs.Service) *jobQueueHandler {
+	return &jobQueueHandler{
+		jobQueueSvc: jobQueueService,
+		logsSvc:     logsService,
+	}
+}
+
+func (handler *jobQueueHandler) AdminDataTablesQueuedJob(ctx *gin.Context) {
+	dataTablesQueuedJob, err := handler.jobQueueSvc.AdminDataTablesQueuedJob(ctx)
+
+	if err != nil {
+		response := helper.APIResponseError(http.StatusInternalServerError, "Get datatables queued jobs failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dataTablesQueuedJob)
+}
+
+func (handler *jobQueueHandler) AdminGetQueuedJob(ctx *gin.Context) {
+	var req jobqueue.RequestGetQueuedJobByID
+
+	err := ctx.ShouldBindUri(&req)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Get queued job failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	job, err := handler.jobQueueSvc.GetQueuedJobDetail(req)
+
+	if err != nil {
+		if helper.IsErrNoRows(err.Error()) {
+			response := helper.APIResponseError(http.StatusNotFound, "Get queued job failed!", "Data not found!")
+			ctx.JSON(http.StatusNotFound, response)
+			return
+		}
+
+		response := helper.APIResponseError(http.StatusInternalServerError, "Get queued job failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := helper.APIResponse(http.StatusOK, "Get queued job successfully!", job)
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (handler *jobQueueHandler) AdminRetryQueuedJob(ctx *gin.Context) {
+	var reqDetail jobqueue.RequestGetQueuedJobByID
+	var reqRetry jobqueue.RequestRetryQueuedJob
+
+	err := ctx.ShouldBindUri(&reqDetail)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Retry queued job failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	reqRetry.User = ctx.MustGet("userData").(user.User)
+
+	job, err := handler.jobQueueSvc.RetryQueuedJob(reqDetail, reqRetry)
+
+	if err != nil {
+		if helper.IsErrNoRows(err.Error()) {
+			response := helper.APIResponseError(http.StatusNotFound, "Retry queued job failed!", "Data not found!")
+			ctx.JSON(http.StatusNotFound, response)
+			return
+		}
+
+		response := helper.APIResponseError(http.StatusBadRequest, "Retry queued job failed!", err.Error())
+		ctx.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("%v retry queued job id %v.", reqRetry.User.Name, job.JobID))
+
+	response := helper.APIResponse(http.StatusOK, "Retry queued job successfully!", jobqueue.FormatQueuedJobData(job))
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (handler *jobQueueHandler) AdminGetQueueStats(ctx *gin.Context) {
+	stats, err := handler.jobQueueSvc.GetQueueStats()
+
+	if err != nil {
+		response := helper.APIResponseError(http.StatusInternalServerError, "Get queue stats failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := helper.APIResponse(http.StatusOK, "Get queue stats successfully!", stats)
+	ctx.JSON(http.StatusOK, response)
+}
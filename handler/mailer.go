@@ -0,0 +1,401 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+	"github.com/WeAreAmazingTeam/tcd-backend/logs"
+	"github.com/WeAreAmazingTeam/tcd-backend/mailer"
+	"github.com/WeAreAmazingTeam/tcd-backend/user"
+	"github.com/gin-gonic/gin"
+)
+
+type mailerHandler struct {
+	mailerSvc mailer.Service
+	userSvc   user.Service
+	logsSvc   logs.Service
+}
+
+func NewMailerHandler(mailerService mailer.Service, userService user.Service, logsService logs.Service) *mailerHandler {
+	return &mailerHandler{
+		mailerSvc: mailerService,
+		userSvc:   userService,
+		logsSvc:   logsService,
+	}
+}
+
+func (handler *mailerHandler) AdminDataTablesEmailOutbox(ctx *gin.Context) {
+	dataTablesEmailOutbox, err := handler.mailerSvc.AdminDataTablesEmailOutbox(ctx)
+
+	if err != nil {
+		response := helper.APIResponseError(http.StatusInternalServerError, "Get datatables email outbox failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dataTablesEmailOutbox)
+}
+
+func (handler *mailerHandler) GetProviderStates(ctx *gin.Context) {
+	response := helper.APIResponse(http.StatusOK, "Get mail provider states successfully!", handler.mailerSvc.GetProviderStates())
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (handler *mailerHandler) ResendEmail(ctx *gin.Context) {
+	var req mailer.RequestGetEmailOutboxByID
+
+	err := ctx.ShouldBindUri(&req)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Resend email failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	outbox, err := handler.mailerSvc.ResendEmail(req)
+
+	if err != nil {
+		if helper.IsErrNoRows(err.Error()) {
+			response := helper.APIResponseError(http.StatusNotFound, "Resend email failed!", "Email not found!")
+			ctx.JSON(http.StatusNotFound, response)
+			return
+		}
+
+		response := helper.APIResponseError(http.StatusBadRequest, "Resend email failed!", err.Error())
+		ctx.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	userData := ctx.MustGet("userData").(user.User)
+	handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("%v requeue email id %v to %v.", userData.Name, outbox.ID, outbox.Recipient))
+
+	response := helper.APIResponse(http.StatusOK, "Resend email successfully!", mailer.FormatEmailOutboxData(outbox))
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (handler *mailerHandler) GetAllEmailTemplate(ctx *gin.Context) {
+	templates, err := handler.mailerSvc.GetAllEmailTemplate()
+
+	if err != nil {
+		response := helper.APIResponseError(http.StatusInternalServerError, "Get all email template failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := helper.APIResponse(http.StatusOK, "Get all email template successfully!", templates)
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (handler *mailerHandler) GetEmailTemplateVersions(ctx *gin.Context) {
+	var req mailer.RequestGetEmailTemplateVersions
+
+	err := ctx.ShouldBindUri(&req)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Get email template versions failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	templates, err := handler.mailerSvc.GetEmailTemplateVersions(req)
+
+	if err != nil {
+		response := helper.APIResponseError(http.StatusBadRequest, "Get email template versions failed!", err.Error())
+		ctx.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse(http.StatusOK, "Get email template versions successfully!", mailer.FormatListEmailTemplateData(templates))
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (handler *mailerHandler) CreateEmailTemplate(ctx *gin.Context) {
+	var req mailer.RequestCreateEmailTemplate
+
+	err := ctx.ShouldBindJSON(&req)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Create email template failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	req.User = ctx.MustGet("userData").(user.User)
+
+	newTemplate, err := handler.mailerSvc.CreateEmailTemplate(req)
+
+	if err != nil {
+		response := helper.APIResponseError(http.StatusBadRequest, "Create email template failed!", err.Error())
+		ctx.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("%v creating email template %v (%v) version %v.", req.User.Name, newTemplate.TemplateKey, newTemplate.Locale, newTemplate.Version))
+
+	response := helper.APIResponse(http.StatusOK, "Create email template successfully!", mailer.FormatEmailTemplateData(newTemplate))
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (handler *mailerHandler) ActivateEmailTemplate(ctx *gin.Context) {
+	var reqDetail mailer.RequestGetEmailTemplateByID
+	var reqActivate mailer.RequestActivateEmailTemplate
+
+	err := ctx.ShouldBindUri(&reqDetail)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Activate email template failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	reqActivate.User = ctx.MustGet("userData").(user.User)
+
+	activatedTemplate, err := handler.mailerSvc.ActivateEmailTemplate(reqDetail, reqActivate)
+
+	if err != nil {
+		if helper.IsErrNoRows(err.Error()) {
+			response := helper.APIResponseError(http.StatusNotFound, "Activate email template failed!", "Email template not found!")
+			ctx.JSON(http.StatusNotFound, response)
+			return
+		}
+
+		response := helper.APIResponseError(http.StatusInternalServerError, "Activate email template failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("%v activating email template %v (%v) version %v.", reqActivate.User.Name, activatedTemplate.TemplateKey, activatedTemplate.Locale, activatedTemplate.Version))
+
+	response := helper.APIResponse(http.StatusOK, "Activate email template successfully!", mailer.FormatEmailTemplateData(activatedTemplate))
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (handler *mailerHandler) PreviewEmailTemplate(ctx *gin.Context) {
+	var req mailer.RequestPreviewEmailTemplate
+
+	err := ctx.ShouldBindJSON(&req)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Preview email template failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	rendered, err := handler.mailerSvc.PreviewEmailTemplate(req)
+
+	if err != nil {
+		if helper.IsErrNoRows(err.Error()) {
+			response := helper.APIResponseError(http.StatusNotFound, "Preview email template failed!", "Email template version not found!")
+			ctx.JSON(http.StatusNotFound, response)
+			return
+		}
+
+		response := helper.APIResponseError(http.StatusBadRequest, "Preview email template failed!", err.Error())
+		ctx.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse(http.StatusOK, "Preview email template successfully!", mailer.FormatEmailTemplatePreview(rendered))
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (handler *mailerHandler) MailgunWebhooks(ctx *gin.Context) {
+	var req mailer.MailgunWebhook
+
+	properties, err := ctx.GetRawData()
+
+	if err != nil {
+		response := helper.APIResponseError(http.StatusInternalServerError, "Failed to process mailgun notification!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	handler.logsSvc.CreateActivityWebhook(logs.RequestCreateActivityWebhook{
+		Endpoint:      ctx.Request.URL.Path,
+		TriggeredFrom: "MAILGUN",
+		Properties:    string(properties),
+	})
+
+	if err := json.Unmarshal(properties, &req); err != nil {
+		response := helper.APIResponseError(http.StatusBadRequest, "Failed to process mailgun notification!", err.Error())
+		ctx.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	if err := handler.mailerSvc.HandleMailgunWebhook(req); err != nil {
+		if err == mailer.ErrInvalidSignature {
+			response := helper.APIResponseError(http.StatusUnauthorized, "Failed to process mailgun notification!", err.Error())
+			ctx.JSON(http.StatusUnauthorized, response)
+			return
+		}
+
+		response := helper.APIResponseError(http.StatusInternalServerError, "Failed to process mailgun notification!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helper.BasicAPIResponse(http.StatusOK, "Process mailgun notification successfully!"))
+}
+
+func (handler *mailerHandler) GetDeliverability(ctx *gin.Context) {
+	userData := ctx.MustGet("userData").(user.User)
+
+	deliverability, err := handler.mailerSvc.GetDeliverability(mailer.RequestGetDeliverability{Email: userData.Email})
+
+	if err != nil {
+		response := helper.APIResponseError(http.StatusInternalServerError, "Get email deliverability failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := helper.APIResponse(http.StatusOK, "Get email deliverability successfully!", deliverability)
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (handler *mailerHandler) GetUserDeliverability(ctx *gin.Context) {
+	var req user.RequestGetUserByID
+
+	err := ctx.ShouldBindUri(&req)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Get email deliverability failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	userDetail, err := handler.userSvc.GetUserByID(req.ID)
+
+	if err != nil {
+		if helper.IsErrNoRows(err.Error()) {
+			response := helper.APIResponseError(http.StatusNotFound, "Get email deliverability failed!", "Data not found!")
+			ctx.JSON(http.StatusNotFound, response)
+			return
+		}
+
+		response := helper.APIResponseError(http.StatusInternalServerError, "Get email deliverability failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	deliverability, err := handler.mailerSvc.GetDeliverability(mailer.RequestGetDeliverability{Email: userDetail.Email})
+
+	if err != nil {
+		response := helper.APIResponseError(http.StatusInternalServerError, "Get email deliverability failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := helper.APIResponse(http.StatusOK, "Get email deliverability successfully!", deliverability)
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (handler *mailerHandler) AdminDataTablesEmailSuppression(ctx *gin.Context) {
+	dataTablesEmailSuppression, err := handler.mailerSvc.AdminDataTablesEmailSuppression(ctx)
+
+	if err != nil {
+		response := helper.APIResponseError(http.StatusInternalServerError, "Get datatables email suppression failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dataTablesEmailSuppression)
+}
+
+func (handler *mailerHandler) DeleteEmailSuppression(ctx *gin.Context) {
+	var reqDetail mailer.RequestGetEmailSuppressionByID
+	var reqDelete mailer.RequestDeleteEmailSuppression
+
+	err := ctx.ShouldBindUri(&reqDetail)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Clear email suppression failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	reqDelete.User = ctx.MustGet("userData").(user.User)
+
+	_, err = handler.mailerSvc.DeleteEmailSuppression(reqDetail, reqDelete)
+
+	if err != nil {
+		if helper.IsErrNoRows(err.Error()) {
+			response := helper.APIResponseError(http.StatusNotFound, "Clear email suppression failed!", "Data not found!")
+			ctx.JSON(http.StatusNotFound, response)
+			return
+		}
+
+		response := helper.APIResponseError(http.StatusInternalServerError, "Clear email suppression failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("%v clearing email suppression id %v.", reqDelete.User.Name, reqDetail.ID))
+
+	ctx.JSON(http.StatusOK, helper.BasicAPIResponse(http.StatusOK, "Clear email suppression successfully!"))
+}
+
+// Unsubscribe serves both the website confirmation page and the one-click POST from mail clients,
+// the token comes in the query string (one-click) or in the body
+func (handler *mailerHandler) Unsubscribe(ctx *gin.Context) {
+	token := ctx.Query("token")
+
+	if token == "" {
+		token = ctx.PostForm("token")
+	}
+
+	if token == "" {
+		var body struct {
+			Token string `json:"token"`
+		}
+
+		_ = ctx.ShouldBindJSON(&body)
+		token = body.Token
+	}
+
+	userID, category, err := mailer.ParseUnsubscribeToken(token)
+
+	if err != nil {
+		response := helper.APIResponseError(http.StatusBadRequest, "Unsubscribe failed!", err.Error())
+		ctx.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	userData, err := handler.userSvc.GetUserByID(userID)
+
+	if err != nil {
+		if helper.IsErrNoRows(err.Error()) {
+			response := helper.APIResponseError(http.StatusNotFound, "Unsubscribe failed!", "Data not found!")
+			ctx.JSON(http.StatusNotFound, response)
+			return
+		}
+
+		response := helper.APIResponseError(http.StatusInternalServerError, "Unsubscribe failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	preferences, err := handler.userSvc.UpdateNotificationPreferences(user.RequestUpdateNotificationPreferences{
+		Preferences: map[string]bool{category: false},
+		User:        userData,
+	})
+
+	if err != nil {
+		response := helper.APIResponseError(http.StatusInternalServerError, "Unsubscribe failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("%v unsubscribing from %v emails.", userData.Name, category))
+
+	response := helper.APIResponse(http.StatusOK, "Unsubscribe successfully!", user.FormatNotificationPreferences(preferences))
+	ctx.JSON(http.StatusOK, response)
+}
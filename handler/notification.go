@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+	"github.com/WeAreAmazingTeam/tcd-backend/notification"
+	"github.com/WeAreAmazingTeam/tcd-backend/user"
+	"github.com/gin-gonic/gin"
+)
+
+// proxies usually drop idle connections after a minute, a comment line keeps the stream open
+const notificationStreamHeartbeat = 25 * time.Second
+
+type notificationHandler struct {
+	notificationSvc notification.Service
+}
+
+func NewNotificationHandler(notificationService notification.Service) *notificationHandler {
+	return &notificationHandler{
+		notificationSvc: notificationService,
+	}
+}
+
+func (handler *notificationHandler) GetNotifications(ctx *gin.Context) {
+	var req notification.RequestGetNotifications
+
+	err := ctx.ShouldBindQuery(&req)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Get notifications failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	req.User = ctx.MustGet("userData").(user.User)
+
+	notifications, err := handler.notificationSvc.GetNotifications(req)
+
+	if err != nil {
+		response := helper.APIResponseError(http.StatusInternalServerError, "Get notifications failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := helper.APIResponse(http.StatusOK, "Get notifications successfully!", notifications)
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (handler *notificationHandler) GetUnreadCount(ctx *gin.Context) {
+	userData := ctx.MustGet("userData").(user.User)
+
+	unreadCount, err := handler.notificationSvc.CountUnread(userData.ID)
+
+	if err != nil {
+		response := helper.APIResponseError(http.StatusInternalServerError, "Get unread notification count failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := helper.APIResponse(http.StatusOK, "Get unread notification count successfully!", notification.UnreadCountFormatter{UnreadCount: unreadCount})
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (handler *notificationHandler) MarkRead(ctx *gin.Context) {
+	var reqDetail notification.RequestGetNotificationByID
+	var req notification.RequestMarkNotificationRead
+
+	err := ctx.ShouldBindUri(&reqDetail)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Mark notification as read failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	req.User = ctx.MustGet("userData").(user.User)
+
+	updatedNotification, err := handler.notificationSvc.MarkRead(reqDetail, req)
+
+	if err != nil {
+		if helper.IsErrNoRows(err.Error()) {
+			response := helper.APIResponseError(http.StatusNotFound, "Mark notification as read failed!", "Data not found!")
+			ctx.JSON(http.StatusNotFound, response)
+			return
+		}
+
+		response := helper.APIResponseError(http.StatusInternalServerError, "Mark notification as read failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := helper.APIResponse(http.StatusOK, "Mark notification as read successfully!", notification.FormatNotificationData(updatedNotification))
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (handler *notificationHandler) MarkAllRead(ctx *gin.Context) {
+	var req notification.RequestMarkNotificationRead
+
+	req.User = ctx.MustGet("userData").(user.User)
+
+	if _, err := handler.notificationSvc.MarkAllRead(req); err != nil {
+		response := helper.APIResponseError(http.StatusInternalServerError, "Mark all notifications as read failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := helper.BasicAPIResponse(http.StatusOK, "Mark all notifications as read successfully!")
+	ctx.JSON(http.StatusOK, response)
+}
+
+// Stream is a server-sent events stream of new notifications and unread count changes,
+// it starts with the current unread count so the client does not need a separate call
+func (handler *notificationHandler) Stream(ctx *gin.Context) {
+	userData := ctx.MustGet("userData").(user.User)
+
+	unreadCount, err := handler.notificationSvc.CountUnread(userData.ID)
+
+	if err != nil {
+		response := helper.APIResponseError(http.StatusInternalServerError, "Open notification stream failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	events, unsubscribe := handler.notificationSvc.Subscribe(userData.ID)
+	defer unsubscribe()
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+
+	ctx.SSEvent(notification.EventUnreadCount, notification.UnreadCountFormatter{UnreadCount: unreadCount})
+	ctx.Writer.Flush()
+
+	heartbeat := time.NewTicker(notificationStreamHeartbeat)
+	defer heartbeat.Stop()
+
+	ctx.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Request.Context().Done():
+			return false
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			ctx.SSEvent(event.Name, event.Data)
+			return true
+		case <-heartbeat.C:
+			_, err := w.Write([]byte(": heartbeat\n\n"))
+			return err == nil
+		}
+	})
+}
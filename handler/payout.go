@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/disbursement"
+	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+	"github.com/WeAreAmazingTeam/tcd-backend/logs"
+	"github.com/WeAreAmazingTeam/tcd-backend/payout"
+	"github.com/gin-gonic/gin"
+)
+
+type payoutHandler struct {
+	disbursementSvc disbursement.Service
+	logsSvc         logs.Service
+}
+
+func NewPayoutHandler(disbursementService disbursement.Service, logsService logs.Service) *payoutHandler {
+	return &payoutHandler{
+		disbursementSvc: disbursementService,
+		logsSvc:         logsService,
+	}
+}
+
+func (handler *payoutHandler) Callback(ctx *gin.Context) {
+	body, err := ctx.GetRawData()
+
+	if err != nil {
+		response := helper.APIResponseError(http.StatusInternalServerError, "Failed to process payout callback!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	handler.logsSvc.CreateActivityWebhook(logs.RequestCreateActivityWebhook{
+		Endpoint:      ctx.Request.URL.Path,
+		TriggeredFrom: "PAYOUT",
+		Properties:    string(body),
+	})
+
+	if _, err := handler.disbursementSvc.HandleCallback(ctx.Request.Header, body); err != nil {
+		if errors.Is(err, payout.ErrInvalidCallback) || errors.Is(err, payout.ErrDisabled) {
+			response := helper.APIResponseError(http.StatusUnauthorized, "Failed to process payout callback!", err.Error())
+			ctx.JSON(http.StatusUnauthorized, response)
+			return
+		}
+
+		// anything else is answered with an error so the provider sends the callback again
+		response := helper.APIResponseError(http.StatusInternalServerError, "Failed to process payout callback!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, helper.BasicAPIResponse(http.StatusOK, "Process payout callback successfully!"))
+}
+
+func (handler *payoutHandler) AdminGetPayoutBatch(ctx *gin.Context) {
+	var req disbursement.RequestGetPayoutBatch
+
+	err := ctx.ShouldBindUri(&req)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Get payout batch failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	batch, withdrawals, err := handler.disbursementSvc.GetPayoutBatch(req)
+
+	if err != nil {
+		if helper.IsErrNoRows(err.Error()) {
+			response := helper.APIResponseError(http.StatusNotFound, "Get payout batch failed!", "Data not found!")
+			ctx.JSON(http.StatusNotFound, response)
+			return
+		}
+
+		response := helper.APIResponseError(http.StatusInternalServerError, "Get payout batch failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := helper.APIResponse(http.StatusOK, "Get payout batch successfully!", disbursement.FormatPayoutBatchData(batch, withdrawals))
+	ctx.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,242 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+	"github.com/WeAreAmazingTeam/tcd-backend/logs"
+	"github.com/WeAreAmazingTeam/tcd-backend/rbac"
+	"github.com/WeAreAmazingTeam/tcd-backend/user"
+	"github.com/gin-gonic/gin"
+)
+
+type rbacHandler struct {
+	rbacSvc rbac.Service
+	userSvc user.Service
+	logsSvc logs.Service
+}
+
+func NewRBACHandler(
+	rbacService rbac.Service,
+	userService user.Service,
+	logsService logs.Service,
+) *rbacHandler {
+	return &rbacHandler{
+		rbacSvc: rbacService,
+		userSvc: userService,
+		logsSvc: logsService,
+	}
+}
+
+func (handler *rbacHandler) GetAllPermission(ctx *gin.Context) {
+	response := helper.APIResponse(http.StatusOK, "Get permissions successfully!", rbac.AllPermissions)
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (handler *rbacHandler) GetAllRole(ctx *gin.Context) {
+	roles, err := handler.rbacSvc.GetAllRole()
+
+	if err != nil {
+		response := helper.APIResponseError(http.StatusInternalServerError, "Get roles failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	formatData := []rbac.RoleFormatter{}
+
+	for _, val := range roles {
+		permissions, err := handler.rbacSvc.GetPermissionsByRole(val.Name)
+
+		if err != nil {
+			response := helper.APIResponseError(http.StatusInternalServerError, "Get roles failed!", err.Error())
+			ctx.JSON(http.StatusInternalServerError, response)
+			return
+		}
+
+		formatData = append(formatData, rbac.FormatRoleData(val, permissions))
+	}
+
+	response := helper.APIResponse(http.StatusOK, "Get roles successfully!", formatData)
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (handler *rbacHandler) CreateRole(ctx *gin.Context) {
+	var req rbac.RequestCreateRole
+
+	err := ctx.ShouldBind(&req)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Create role failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	req.User = ctx.MustGet("userData").(user.User)
+
+	newRoleData, err := handler.rbacSvc.CreateRole(req)
+
+	if err != nil {
+		response := helper.APIResponseError(http.StatusBadRequest, "Create role failed!", err.Error())
+		ctx.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	formatData := rbac.FormatRoleData(newRoleData, req.Permissions)
+	response := helper.APIResponse(http.StatusCreated, "Create role successfully!", formatData)
+
+	handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("%v creating role %v.", req.User.Name, newRoleData.Name))
+
+	ctx.JSON(http.StatusCreated, response)
+}
+
+func (handler *rbacHandler) UpdateRole(ctx *gin.Context) {
+	var reqID rbac.RequestGetRoleByID
+
+	err := ctx.ShouldBindUri(&reqID)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Update role failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	var reqUpdate rbac.RequestUpdateRole
+
+	err = ctx.ShouldBind(&reqUpdate)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Update role failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	reqUpdate.User = ctx.MustGet("userData").(user.User)
+
+	updatedRole, err := handler.rbacSvc.UpdateRole(reqID, reqUpdate)
+
+	if err != nil {
+		if helper.IsErrNoRows(err.Error()) {
+			response := helper.APIResponseError(http.StatusNotFound, "Update role failed!", fmt.Sprintf("Role with ID %d not found!", reqID.ID))
+			ctx.JSON(http.StatusNotFound, response)
+			return
+		}
+
+		response := helper.APIResponseError(http.StatusBadRequest, "Update role failed!", err.Error())
+		ctx.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	formatData := rbac.FormatRoleData(updatedRole, reqUpdate.Permissions)
+	response := helper.APIResponse(http.StatusOK, "Update role successfully!", formatData)
+
+	handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("%v updating role id %v.", reqUpdate.User.Name, reqID.ID))
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (handler *rbacHandler) DeleteRole(ctx *gin.Context) {
+	var reqID rbac.RequestGetRoleByID
+
+	err := ctx.ShouldBindUri(&reqID)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Delete role failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	var reqDelete rbac.RequestDeleteRole
+
+	reqDelete.User = ctx.MustGet("userData").(user.User)
+
+	if _, err = handler.rbacSvc.DeleteRole(reqID, reqDelete); err != nil {
+		if helper.IsErrNoRows(err.Error()) {
+			response := helper.APIResponseError(http.StatusNotFound, "Delete role failed!", fmt.Sprintf("Role with ID %d not found!", reqID.ID))
+			ctx.JSON(http.StatusNotFound, response)
+			return
+		}
+
+		response := helper.APIResponseError(http.StatusBadRequest, "Delete role failed!", err.Error())
+		ctx.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.BasicAPIResponse(http.StatusOK, "Delete role successfully!")
+
+	handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("%v deleting role id %v.", reqDelete.User.Name, reqID.ID))
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (handler *rbacHandler) AssignUserRole(ctx *gin.Context) {
+	var reqID user.RequestGetUserByID
+
+	err := ctx.ShouldBindUri(&reqID)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Assign user role failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	var reqUpdate user.RequestUpdateUserRole
+
+	err = ctx.ShouldBind(&reqUpdate)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Assign user role failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	reqUpdate.User = ctx.MustGet("userData").(user.User)
+
+	if reqUpdate.User.ID == reqID.ID {
+		response := helper.APIResponseError(http.StatusBadRequest, "Assign user role failed!", "You cannot change your own role!")
+		ctx.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	isRoleExist, err := handler.rbacSvc.IsRoleExist(reqUpdate.Role)
+
+	if err != nil {
+		response := helper.APIResponseError(http.StatusInternalServerError, "Assign user role failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	if !isRoleExist {
+		response := helper.APIResponseError(http.StatusNotFound, "Assign user role failed!", fmt.Sprintf("Role %v not found!", reqUpdate.Role))
+		ctx.JSON(http.StatusNotFound, response)
+		return
+	}
+
+	updatedUser, err := handler.userSvc.UpdateUserRole(reqID, reqUpdate)
+
+	if err != nil {
+		if helper.IsErrNoRows(err.Error()) {
+			response := helper.APIResponseError(http.StatusNotFound, "Assign user role failed!", fmt.Sprintf("User with ID %d not found!", reqID.ID))
+			ctx.JSON(http.StatusNotFound, response)
+			return
+		}
+
+		response := helper.APIResponseError(http.StatusInternalServerError, "Assign user role failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	formatData := user.FormatUserFullData(updatedUser)
+	response := helper.APIResponse(http.StatusOK, "Assign user role successfully!", formatData)
+
+	handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("%v assigning role %v to user id %v.", reqUpdate.User.Name, reqUpdate.Role, reqID.ID))
+
+	ctx.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/campaign"
+	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+	"github.com/WeAreAmazingTeam/tcd-backend/logs"
+	"github.com/WeAreAmazingTeam/tcd-backend/user"
+	"github.com/gin-gonic/gin"
+)
+
+type rewardHandler struct {
+	campaignSvc campaign.Service
+	logsSvc     logs.Service
+}
+
+func NewRewardHandler(campaignService campaign.Service, logsService logs.Service) *rewardHandler {
+	return &rewardHandler{
+		campaignSvc: campaignService,
+		logsSvc:     logsService,
+	}
+}
+
+func (handler *rewardHandler) GetMyRewards(ctx *gin.Context) {
+	currentUser := ctx.MustGet("userData").(user.User)
+
+	rewards, err := handler.campaignSvc.GetRewardsByUserID(currentUser)
+
+	if err != nil {
+		response := helper.APIResponseError(http.StatusInternalServerError, "Get rewards failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := helper.APIResponse(http.StatusOK, "Get rewards successfully!", campaign.FormatMultipleRewardData(rewards))
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (handler *rewardHandler) ClaimReward(ctx *gin.Context) {
+	var reqDetail campaign.RequestGetRewardByID
+	var reqClaim campaign.RequestClaimReward
+
+	err := ctx.ShouldBindUri(&reqDetail)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Claim reward failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	err = ctx.ShouldBindJSON(&reqClaim)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Claim reward failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	reqClaim.User = ctx.MustGet("userData").(user.User)
+
+	reward, err := handler.campaignSvc.ClaimReward(reqDetail, reqClaim)
+
+	if err != nil {
+		if helper.IsErrNoRows(err.Error()) {
+			response := helper.APIResponseError(http.StatusNotFound, "Claim reward failed!", "Data not found!")
+			ctx.JSON(http.StatusNotFound, response)
+			return
+		}
+
+		response := helper.APIResponseError(http.StatusBadRequest, "Claim reward failed!", err.Error())
+		ctx.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse(http.StatusOK, "Claim reward successfully!", campaign.FormatRewardData(reward))
+
+	handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("%v claiming reward id %v.", reqClaim.User.Name, reqDetail.ID))
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (handler *rewardHandler) AdminGetReward(ctx *gin.Context) {
+	var req campaign.RequestGetRewardByID
+
+	err := ctx.ShouldBindUri(&req)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Get reward failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	reward, err := handler.campaignSvc.GetRewardByID(req)
+
+	if err != nil {
+		if helper.IsErrNoRows(err.Error()) {
+			response := helper.APIResponseError(http.StatusNotFound, "Get reward failed!", "Data not found!")
+			ctx.JSON(http.StatusNotFound, response)
+			return
+		}
+
+		response := helper.APIResponseError(http.StatusInternalServerError, "Get reward failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := helper.APIResponse(http.StatusOK, "Get reward successfully!", campaign.FormatRewardData(reward))
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (handler *rewardHandler) AdminDataTablesRewards(ctx *gin.Context) {
+	dataTablesRewards, err := handler.campaignSvc.AdminDataTablesRewards(ctx)
+
+	if err != nil {
+		response := helper.APIResponseError(http.StatusInternalServerError, "Get datatables rewards failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dataTablesRewards)
+}
+
+func (handler *rewardHandler) AdminUpdateRewardFulfillment(ctx *gin.Context) {
+	var reqDetail campaign.RequestGetRewardByID
+	var reqUpdate campaign.RequestUpdateRewardFulfillment
+
+	err := ctx.ShouldBindUri(&reqDetail)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Update reward fulfillment failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	err = ctx.ShouldBindJSON(&reqUpdate)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Update reward fulfillment failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	reqUpdate.User = ctx.MustGet("userData").(user.User)
+
+	reward, err := handler.campaignSvc.UpdateRewardFulfillment(reqDetail, reqUpdate)
+
+	if err != nil {
+		if helper.IsErrNoRows(err.Error()) {
+			response := helper.APIResponseError(http.StatusNotFound, "Update reward fulfillment failed!", "Data not found!")
+			ctx.JSON(http.StatusNotFound, response)
+			return
+		}
+
+		response := helper.APIResponseError(http.StatusBadRequest, "Update reward fulfillment failed!", err.Error())
+		ctx.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse(http.StatusOK, "Update reward fulfillment successfully!", campaign.FormatRewardData(reward))
+
+	handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("%v marking reward id %v as %v.", reqUpdate.User.Name, reqDetail.ID, reqUpdate.Status))
+
+	ctx.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+	"github.com/WeAreAmazingTeam/tcd-backend/logs"
+	"github.com/WeAreAmazingTeam/tcd-backend/scheduler"
+	"github.com/WeAreAmazingTeam/tcd-backend/user"
+	"github.com/gin-gonic/gin"
+)
+
+type schedulerHandler struct {
+	schedulerSvc scheduler.Service
+	logsSvc      logs.Service
+}
+
+func NewSchedulerHandler(schedulerService scheduler.Service, logsService logs.Service) *schedulerHandler {
+	return &schedulerHandler{
+		schedulerSvc: schedulerService,
+		logsSvc:      logsService,
+	}
+}
+
+func (handler *schedulerHandler) AdminGetScheduledJobs(ctx *gin.Context) {
+	scheduledJobs, err := handler.schedulerSvc.GetScheduledJobs()
+
+	if err != nil {
+		response := helper.APIResponseError(http.StatusInternalServerError, "Get scheduled jobs failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := helper.APIResponse(http.StatusOK, "Get scheduled jobs successfully!", scheduledJobs)
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (handler *schedulerHandler) AdminDataTablesJobRuns(ctx *gin.Context) {
+	dataTablesJobRun, err := handler.schedulerSvc.AdminDataTablesJobRun(ctx)
+
+	if err != nil {
+		response := helper.APIResponseError(http.StatusInternalServerError, "Get datatables job runs failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dataTablesJobRun)
+}
+
+func (handler *schedulerHandler) AdminTriggerJob(ctx *gin.Context) {
+	var reqDetail scheduler.RequestGetScheduledJobByName
+	var reqUpdate scheduler.RequestUpdateScheduledJob
+
+	err := ctx.ShouldBindUri(&reqDetail)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Trigger job failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	reqUpdate.User = ctx.MustGet("userData").(user.User)
+
+	run, err := handler.schedulerSvc.TriggerJob(reqDetail, reqUpdate)
+
+	if err != nil {
+		if helper.IsErrNoRows(err.Error()) {
+			response := helper.APIResponseError(http.StatusNotFound, "Trigger job failed!", "Data not found!")
+			ctx.JSON(http.StatusNotFound, response)
+			return
+		}
+
+		response := helper.APIResponseError(http.StatusBadRequest, "Trigger job failed!", err.Error())
+		ctx.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("%v trigger job %v.", reqUpdate.User.Name, reqDetail.Name))
+
+	response := helper.APIResponse(http.StatusOK, "Trigger job successfully!", scheduler.FormatJobRunData(run))
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (handler *schedulerHandler) AdminPauseJob(ctx *gin.Context) {
+	var reqDetail scheduler.RequestGetScheduledJobByName
+	var reqUpdate scheduler.RequestUpdateScheduledJob
+
+	err := ctx.ShouldBindUri(&reqDetail)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Pause job failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	reqUpdate.User = ctx.MustGet("userData").(user.User)
+
+	scheduledJob, err := handler.schedulerSvc.PauseJob(reqDetail, reqUpdate)
+
+	if err != nil {
+		if helper.IsErrNoRows(err.Error()) {
+			response := helper.APIResponseError(http.StatusNotFound, "Pause job failed!", "Data not found!")
+			ctx.JSON(http.StatusNotFound, response)
+			return
+		}
+
+		response := helper.APIResponseError(http.StatusBadRequest, "Pause job failed!", err.Error())
+		ctx.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("%v pause job %v.", reqUpdate.User.Name, reqDetail.Name))
+
+	response := helper.APIResponse(http.StatusOK, "Pause job successfully!", scheduledJob)
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (handler *schedulerHandler) AdminResumeJob(ctx *gin.Context) {
+	var reqDetail scheduler.RequestGetScheduledJobByName
+	var reqUpdate scheduler.RequestUpdateScheduledJob
+
+	err := ctx.ShouldBindUri(&reqDetail)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Resume job failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	reqUpdate.User = ctx.MustGet("userData").(user.User)
+
+	scheduledJob, err := handler.schedulerSvc.ResumeJob(reqDetail, reqUpdate)
+
+	if err != nil {
+		if helper.IsErrNoRows(err.Error()) {
+			response := helper.APIResponseError(http.StatusNotFound, "Resume job failed!", "Data not found!")
+			ctx.JSON(http.StatusNotFound, response)
+			return
+		}
+
+		response := helper.APIResponseError(http.StatusBadRequest, "Resume job failed!", err.Error())
+		ctx.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("%v resume job %v.", reqUpdate.User.Name, reqDetail.Name))
+
+	response := helper.APIResponse(http.StatusOK, "Resume job successfully!", scheduledJob)
+	ctx.JSON(http.StatusOK, response)
+}
@@ -4,8 +4,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"os"
-	"strconv"
 
 	"github.com/WeAreAmazingTeam/tcd-backend/campaign"
 	"github.com/WeAreAmazingTeam/tcd-backend/helper"
@@ -443,11 +441,11 @@ func (handler *transactionHandler) CreateTransactionWithEMoney(ctx *gin.Context)
 		return
 	}
 
-	user, err := handler.userSvc.GetUserByID(req.UserID)
+	user, err := handler.userSvc.GetUserByID(req.User.ID)
 
 	if err != nil {
 		if helper.IsErrNoRows(err.Error()) {
-			response := helper.APIResponseError(http.StatusNotFound, "Donate failed!", fmt.Sprintf("User with ID %d not found!", req.UserID))
+			response := helper.APIResponseError(http.StatusNotFound, "Donate failed!", fmt.Sprintf("User with ID %d not found!", req.User.ID))
 			ctx.JSON(http.StatusNotFound, response)
 			return
 		}
@@ -457,7 +455,7 @@ func (handler *transactionHandler) CreateTransactionWithEMoney(ctx *gin.Context)
 		return
 	}
 
-	if user.EMoney < float64(req.Amount) {
+	if user.AvailableEMoney() < float64(req.Amount) {
 		response := helper.APIResponseError(http.StatusBadRequest, "Donate failed!", "Your e-Money balance is not enough!")
 		ctx.JSON(http.StatusBadRequest, response)
 		return
@@ -466,20 +464,18 @@ func (handler *transactionHandler) CreateTransactionWithEMoney(ctx *gin.Context)
 	newTransactionData, err := handler.transactionSvc.CreateTransactionWithEMoney(req, campaign.Title)
 
 	if err != nil {
+		// another donation spent the balance after the check above
+		if err == transaction.ErrEMoneyNotEnough {
+			response := helper.APIResponseError(http.StatusBadRequest, "Donate failed!", "Your e-Money balance is not enough!")
+			ctx.JSON(http.StatusBadRequest, response)
+			return
+		}
+
 		response := helper.APIResponseError(http.StatusInternalServerError, "Donate failed!", err.Error())
 		ctx.JSON(http.StatusInternalServerError, response)
 		return
 	}
 
-	{
-		templateData := helper.EmailTransactionSuccess{
-			CampaignLink: os.Getenv("WEB_URL") + "/donate/" + strconv.Itoa(campaign.ID),
-			Name:         user.Name,
-			Amount:       helper.FormatRupiah(float64(req.Amount)),
-		}
-		go helper.SendMail(user.Email, "Thank You For Your Donation!", templateData, "html/transaction_success.html")
-	}
-
 	formatData := transaction.FormatTransactionData(newTransactionData)
 	response := helper.APIResponse(http.StatusCreated, "Donate successfully!", formatData)
 
@@ -2,36 +2,48 @@ package handler
 
 import (
 	"fmt"
+	"log"
+	"math"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/WeAreAmazingTeam/tcd-backend/approval"
 	"github.com/WeAreAmazingTeam/tcd-backend/auth"
-	"github.com/WeAreAmazingTeam/tcd-backend/company"
 	"github.com/WeAreAmazingTeam/tcd-backend/helper"
 	"github.com/WeAreAmazingTeam/tcd-backend/logs"
+	"github.com/WeAreAmazingTeam/tcd-backend/ratelimit"
+	"github.com/WeAreAmazingTeam/tcd-backend/rbac"
 	"github.com/WeAreAmazingTeam/tcd-backend/user"
 	"github.com/gin-gonic/gin"
 )
 
 type userHandler struct {
-	userSvc    user.Service
-	authSvc    auth.Service
-	logsSvc    logs.Service
-	companySvc company.Service
+	userSvc     user.Service
+	authSvc     auth.Service
+	logsSvc     logs.Service
+	rbacSvc     rbac.Service
+	approvalSvc approval.Service
+	limiter     *ratelimit.Limiter
 }
 
 func NewUserHandler(
 	userService user.Service,
 	authService auth.Service,
 	logsService logs.Service,
-	companyService company.Service,
+	rbacService rbac.Service,
+	approvalService approval.Service,
+	limiter *ratelimit.Limiter,
 ) *userHandler {
 	return &userHandler{
-		userSvc:    userService,
-		authSvc:    authService,
-		logsSvc:    logsService,
-		companySvc: companyService,
+		userSvc:     userService,
+		authSvc:     authService,
+		logsSvc:     logsService,
+		rbacSvc:     rbacService,
+		approvalSvc: approvalService,
+		limiter:     limiter,
 	}
 }
 
@@ -78,13 +90,19 @@ func (handler *userHandler) Register(ctx *gin.Context) {
 	}
 
 	userData := user.FormatUserData(newUserData, token)
-	response := helper.APIResponse(http.StatusOK, "Registration successfully!", userData)
+	response := helper.APIResponse(http.StatusOK, "Registration successfully, please check your email inbox or spam to verify your email!", userData)
 
 	{
 		templateData := helper.EmailWelcome{
 			Name: userData.Name,
 		}
-		go helper.SendMail(userData.Email, "Welcome to The Cloud Donation", templateData, "html/welcome.html")
+		if err := helper.SendMail(userData.Email, userData.Locale, helper.EmailTemplateWelcome, templateData); err != nil {
+			handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("Failed to send welcome email to %s: %s.", userData.Email, err.Error()))
+		}
+	}
+
+	if err := handler.sendEmailVerification(newUserData); err != nil {
+		handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("Failed to send email verification to %s: %s.", userData.Email, err.Error()))
 	}
 
 	handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("%s registered to the system.", userData.Name))
@@ -104,15 +122,23 @@ func (handler *userHandler) Login(ctx *gin.Context) {
 		return
 	}
 
+	if handler.isLoginLocked(ctx, req.Email) {
+		return
+	}
+
 	userData, err := handler.userSvc.Login(req)
 
 	if err != nil {
 		if err.Error() == "crypto/bcrypt: hashedPassword is not the hash of the given password" {
+			handler.recordLoginFailure(ctx, req.Email, userData)
+
 			response := helper.APIResponseError(http.StatusNotFound, "Login failed!", "Wrong password!")
 			ctx.JSON(http.StatusNotFound, response)
 			return
 		}
 		if err.Error() == "email not registered" {
+			handler.recordLoginFailure(ctx, req.Email, userData)
+
 			response := helper.APIResponseError(http.StatusNotFound, "Login failed!", err.Error())
 			ctx.JSON(http.StatusNotFound, response)
 			return
@@ -122,6 +148,39 @@ func (handler *userHandler) Login(ctx *gin.Context) {
 		return
 	}
 
+	isTwoFactorEnabled, err := handler.userSvc.IsTwoFactorEnabled(userData.ID)
+
+	if err != nil {
+		response := helper.APIResponseError(http.StatusInternalServerError, "Login failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	// the failures are kept until the code is right too, the password alone must not clear the count of
+	// the code guesses
+	if isTwoFactorEnabled || handler.userSvc.IsTwoFactorRequired(userData) {
+		challengeToken, err := handler.authSvc.GenerateChallengeToken(userData.ID)
+
+		if err != nil {
+			response := helper.APIResponseError(http.StatusInternalServerError, "Login failed!", err.Error())
+			ctx.JSON(http.StatusInternalServerError, response)
+			return
+		}
+
+		formatData := user.TwoFactorChallengeFormatter{
+			TwoFactorRequired:  true,
+			EnrolmentRequired:  !isTwoFactorEnabled,
+			ChallengeToken:     challengeToken,
+			ChallengeExpiresIn: int(auth.ChallengeTokenTTL.Seconds()),
+		}
+		response := helper.APIResponse(http.StatusOK, "Two-factor authentication required!", formatData)
+
+		handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("%s passed the password step of login.", userData.Name))
+
+		ctx.JSON(http.StatusOK, response)
+		return
+	}
+
 	token, err := handler.authSvc.GenerateToken(userData.ID)
 
 	if err != nil {
@@ -130,6 +189,8 @@ func (handler *userHandler) Login(ctx *gin.Context) {
 		return
 	}
 
+	handler.resetLoginFailures(userData.Email)
+
 	formatData := user.FormatUserData(userData, token)
 	response := helper.APIResponse(http.StatusOK, "Login successfully!", formatData)
 
@@ -138,6 +199,242 @@ func (handler *userHandler) Login(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, response)
 }
 
+func (handler *userHandler) LoginTwoFactorSetup(ctx *gin.Context) {
+	var req user.RequestLoginTwoFactorSetup
+
+	err := ctx.ShouldBind(&req)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Setup two-factor authentication failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	userID, err := handler.authSvc.ValidateChallengeToken(req.ChallengeToken)
+
+	if err != nil {
+		response := helper.APIResponseError(http.StatusUnauthorized, "Setup two-factor authentication failed!", "Challenge token invalid or expired, please login again!")
+		ctx.JSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	userData, err := handler.userSvc.GetUserByID(userID)
+
+	if err != nil {
+		response := helper.APIResponseError(http.StatusUnauthorized, "Setup two-factor authentication failed!", "Challenge token invalid or expired, please login again!")
+		ctx.JSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	secret, provisioningURI, err := handler.userSvc.SetupTwoFactor(userData)
+
+	if err != nil {
+		response := helper.APIResponseError(http.StatusBadRequest, "Setup two-factor authentication failed!", err.Error())
+		ctx.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	formatData := user.TwoFactorSetupFormatter{
+		Secret:          secret,
+		ProvisioningURI: provisioningURI,
+	}
+	response := helper.APIResponse(http.StatusOK, "Setup two-factor authentication successfully, please confirm with a code from your authenticator app!", formatData)
+
+	handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("%v setting up two-factor authentication while login.", userData.Name))
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (handler *userHandler) LoginTwoFactor(ctx *gin.Context) {
+	var req user.RequestLoginTwoFactor
+
+	err := ctx.ShouldBind(&req)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Login failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	userID, err := handler.authSvc.ValidateChallengeToken(req.ChallengeToken)
+
+	if err != nil {
+		response := helper.APIResponseError(http.StatusUnauthorized, "Login failed!", "Challenge token invalid or expired, please login again!")
+		ctx.JSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	userData, err := handler.userSvc.GetUserByID(userID)
+
+	if err != nil {
+		response := helper.APIResponseError(http.StatusUnauthorized, "Login failed!", "Challenge token invalid or expired, please login again!")
+		ctx.JSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	if handler.isLoginLocked(ctx, userData.Email) {
+		return
+	}
+
+	isTwoFactorEnabled, err := handler.userSvc.IsTwoFactorEnabled(userData.ID)
+
+	if err != nil {
+		response := helper.APIResponseError(http.StatusInternalServerError, "Login failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	reqCode := user.RequestTwoFactorCode{Code: req.Code, User: userData}
+	recoveryCodes := []string{}
+
+	if isTwoFactorEnabled {
+		err = handler.userSvc.VerifyTwoFactor(reqCode)
+	} else {
+		// first login of a role that must use two-factor, finish the enrolment here
+		recoveryCodes, err = handler.userSvc.EnableTwoFactor(reqCode)
+	}
+
+	if err != nil {
+		handler.recordLoginFailure(ctx, userData.Email, userData)
+
+		response := helper.APIResponseError(http.StatusUnauthorized, "Login failed!", err.Error())
+		ctx.JSON(http.StatusUnauthorized, response)
+
+		handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("%s failed the two-factor step of login.", userData.Name))
+		return
+	}
+
+	// password and code are both right, the failures of this account start over
+	handler.resetLoginFailures(userData.Email)
+
+	token, err := handler.authSvc.GenerateTwoFactorToken(userData.ID)
+
+	if err != nil {
+		response := helper.APIResponseError(http.StatusInternalServerError, "Login failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	formatData := user.TwoFactorLoginFormatter{
+		UserFormatter: user.FormatUserData(userData, token),
+		RecoveryCodes: recoveryCodes,
+	}
+	response := helper.APIResponse(http.StatusOK, "Login successfully!", formatData)
+
+	handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("%s successfully login to the system with two-factor authentication.", userData.Name))
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (handler *userHandler) SetupTwoFactor(ctx *gin.Context) {
+	userData := ctx.MustGet("userData").(user.User)
+
+	secret, provisioningURI, err := handler.userSvc.SetupTwoFactor(userData)
+
+	if err != nil {
+		response := helper.APIResponseError(http.StatusBadRequest, "Setup two-factor authentication failed!", err.Error())
+		ctx.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	formatData := user.TwoFactorSetupFormatter{
+		Secret:          secret,
+		ProvisioningURI: provisioningURI,
+	}
+	response := helper.APIResponse(http.StatusOK, "Setup two-factor authentication successfully, please confirm with a code from your authenticator app!", formatData)
+
+	handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("%v setting up two-factor authentication.", userData.Name))
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (handler *userHandler) EnableTwoFactor(ctx *gin.Context) {
+	var req user.RequestTwoFactorCode
+
+	err := ctx.ShouldBind(&req)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Enable two-factor authentication failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	req.User = ctx.MustGet("userData").(user.User)
+
+	recoveryCodes, err := handler.userSvc.EnableTwoFactor(req)
+
+	if err != nil {
+		response := helper.APIResponseError(http.StatusBadRequest, "Enable two-factor authentication failed!", err.Error())
+		ctx.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse(http.StatusOK, "Enable two-factor authentication successfully, please keep your recovery codes safe!", gin.H{"recovery_codes": recoveryCodes})
+
+	handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("%v enabling two-factor authentication.", req.User.Name))
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (handler *userHandler) DisableTwoFactor(ctx *gin.Context) {
+	var req user.RequestTwoFactorCode
+
+	err := ctx.ShouldBind(&req)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Disable two-factor authentication failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	req.User = ctx.MustGet("userData").(user.User)
+
+	if err := handler.userSvc.DisableTwoFactor(req); err != nil {
+		response := helper.APIResponseError(http.StatusBadRequest, "Disable two-factor authentication failed!", err.Error())
+		ctx.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.BasicAPIResponse(http.StatusOK, "Disable two-factor authentication successfully!")
+
+	handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("%v disabling two-factor authentication.", req.User.Name))
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (handler *userHandler) RegenerateRecoveryCodes(ctx *gin.Context) {
+	var req user.RequestTwoFactorCode
+
+	err := ctx.ShouldBind(&req)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Regenerate recovery codes failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	req.User = ctx.MustGet("userData").(user.User)
+
+	recoveryCodes, err := handler.userSvc.RegenerateRecoveryCodes(req)
+
+	if err != nil {
+		response := helper.APIResponseError(http.StatusBadRequest, "Regenerate recovery codes failed!", err.Error())
+		ctx.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse(http.StatusOK, "Regenerate recovery codes successfully, please keep your recovery codes safe!", gin.H{"recovery_codes": recoveryCodes})
+
+	handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("%v regenerating two-factor recovery codes.", req.User.Name))
+
+	ctx.JSON(http.StatusOK, response)
+}
+
 func (handler *userHandler) GetAllUser(ctx *gin.Context) {
 	users, err := handler.userSvc.GetAllUser()
 
@@ -213,6 +510,14 @@ func (handler *userHandler) CreateUser(ctx *gin.Context) {
 
 	req.User = ctx.MustGet("userData").(user.User)
 
+	if req.Role != rbac.RoleUser {
+		if ok, message := handler.canAssignRole(req.User, req.Role); !ok {
+			response := helper.APIResponseError(http.StatusForbidden, "Create user failed!", message)
+			ctx.JSON(http.StatusForbidden, response)
+			return
+		}
+	}
+
 	newUserData, err := handler.userSvc.CreateUser(req)
 
 	if err != nil {
@@ -241,100 +546,418 @@ func (handler *userHandler) UpdateUser(ctx *gin.Context) {
 		return
 	}
 
-	var reqUpdate user.RequestUpdateUser
+	var reqUpdate user.RequestUpdateUser
+
+	err = ctx.ShouldBind(&reqUpdate)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Update user failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	reqUpdate.User = ctx.MustGet("userData").(user.User)
+
+	existingUser, err := handler.userSvc.GetUserByID(reqID.ID)
+
+	if err != nil {
+		if helper.IsErrNoRows(err.Error()) {
+			response := helper.APIResponseError(http.StatusNotFound, "Update user failed!", fmt.Sprintf("User with ID %d not found!", reqID.ID))
+			ctx.JSON(http.StatusNotFound, response)
+			return
+		}
+
+		response := helper.APIResponseError(http.StatusInternalServerError, "Update user failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	if existingUser.Role != reqUpdate.Role {
+		if ok, message := handler.canAssignRole(reqUpdate.User, reqUpdate.Role); !ok {
+			response := helper.APIResponseError(http.StatusForbidden, "Update user failed!", message)
+			ctx.JSON(http.StatusForbidden, response)
+			return
+		}
+	}
+
+	updatedUser, err := handler.userSvc.UpdateUser(reqID, reqUpdate)
+
+	if err != nil {
+		if helper.IsErrNoRows(err.Error()) {
+			response := helper.APIResponseError(http.StatusNotFound, "Update user failed!", fmt.Sprintf("User with ID %d not found!", reqID.ID))
+			ctx.JSON(http.StatusNotFound, response)
+			return
+		}
+
+		response := helper.APIResponseError(http.StatusInternalServerError, "Update user failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	if !strings.EqualFold(existingUser.Email, updatedUser.Email) {
+		if err := handler.sendEmailVerification(updatedUser); err != nil {
+			handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("Failed to send email verification to %s: %s.", updatedUser.Email, err.Error()))
+		}
+	}
+
+	formatData := user.FormatUserFullData(updatedUser)
+	response := helper.APIResponse(http.StatusOK, "Update user successfully!", formatData)
+
+	handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("%v updating user id %v.", reqUpdate.User.Name, reqID.ID))
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (handler *userHandler) DeleteUser(ctx *gin.Context) {
+	var reqID user.RequestGetUserByID
+
+	err := ctx.ShouldBindUri(&reqID)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Delete user failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	var reqDelete user.RequestDeleteUser
+
+	err = ctx.ShouldBind(&reqDelete)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Delete user failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	reqDelete.User = ctx.MustGet("userData").(user.User)
+
+	if _, err = handler.userSvc.DeleteUser(reqID, reqDelete); err != nil {
+		if helper.IsErrNoRows(err.Error()) {
+			response := helper.APIResponseError(http.StatusNotFound, "Delete user failed!", fmt.Sprintf("User with ID %d not found!", reqID.ID))
+			ctx.JSON(http.StatusNotFound, response)
+			return
+		}
+
+		response := helper.APIResponseError(http.StatusInternalServerError, "Delete user failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := helper.BasicAPIResponse(http.StatusOK, "Delete user successfully!")
+
+	handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("%v deleting user id %v.", reqDelete.User.Name, reqID.ID))
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (handler *userHandler) AdminDataTablesUsers(ctx *gin.Context) {
+	dataTablesUsers, err := handler.userSvc.AdminDataTablesUsers(ctx)
+
+	if err != nil {
+		response := helper.APIResponseError(http.StatusInternalServerError, "Get datatables users failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dataTablesUsers)
+}
+
+func (handler *userHandler) GetUserData(ctx *gin.Context) {
+	formatData := user.FormatUserFullData(ctx.MustGet("userData").(user.User))
+	response := helper.APIResponse(http.StatusOK, "Get user data successfully!", formatData)
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (handler *userHandler) GetNotificationPreferences(ctx *gin.Context) {
+	userData := ctx.MustGet("userData").(user.User)
+
+	preferences, err := handler.userSvc.GetNotificationPreferences(userData.ID)
+
+	if err != nil {
+		response := helper.APIResponseError(http.StatusInternalServerError, "Get notification preferences failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := helper.APIResponse(http.StatusOK, "Get notification preferences successfully!", user.FormatNotificationPreferences(preferences))
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (handler *userHandler) UpdateNotificationPreferences(ctx *gin.Context) {
+	var req user.RequestUpdateNotificationPreferences
+
+	err := ctx.ShouldBindJSON(&req)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Update notification preferences failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	req.User = ctx.MustGet("userData").(user.User)
+
+	preferences, err := handler.userSvc.UpdateNotificationPreferences(req)
+
+	if err != nil {
+		response := helper.APIResponseError(http.StatusBadRequest, "Update notification preferences failed!", err.Error())
+		ctx.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("%v updating notification preferences.", req.User.Name))
+
+	response := helper.APIResponse(http.StatusOK, "Update notification preferences successfully!", user.FormatNotificationPreferences(preferences))
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (handler *userHandler) UpdatePhoneNumber(ctx *gin.Context) {
+	var req user.RequestUpdatePhoneNumber
+
+	err := ctx.ShouldBindJSON(&req)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Update phone number failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	req.User = ctx.MustGet("userData").(user.User)
+
+	updatedUser, err := handler.userSvc.UpdatePhoneNumber(req)
+
+	if err != nil {
+		response := helper.APIResponseError(http.StatusBadRequest, "Update phone number failed!", err.Error())
+		ctx.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("%v updating phone number.", req.User.Name))
+
+	response := helper.APIResponse(http.StatusOK, "Update phone number successfully!", gin.H{"phone_number": updatedUser.PhoneNumber})
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (handler *userHandler) GetDeviceTokens(ctx *gin.Context) {
+	userData := ctx.MustGet("userData").(user.User)
+
+	deviceTokens, err := handler.userSvc.GetDeviceTokens(userData.ID)
+
+	if err != nil {
+		response := helper.APIResponseError(http.StatusInternalServerError, "Get devices failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := helper.APIResponse(http.StatusOK, "Get devices successfully!", user.FormatListDeviceTokenData(deviceTokens))
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (handler *userHandler) RegisterDeviceToken(ctx *gin.Context) {
+	var req user.RequestRegisterDeviceToken
+
+	err := ctx.ShouldBindJSON(&req)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Register device failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	req.User = ctx.MustGet("userData").(user.User)
+
+	deviceToken, err := handler.userSvc.RegisterDeviceToken(req)
+
+	if err != nil {
+		response := helper.APIResponseError(http.StatusInternalServerError, "Register device failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := helper.APIResponse(http.StatusOK, "Register device successfully!", user.FormatListDeviceTokenData([]user.UserDeviceToken{deviceToken})[0])
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (handler *userHandler) DeleteDeviceToken(ctx *gin.Context) {
+	var reqDetail user.RequestGetDeviceTokenByID
+	var reqDelete user.RequestDeleteDeviceToken
+
+	err := ctx.ShouldBindUri(&reqDetail)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Delete device failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	reqDelete.User = ctx.MustGet("userData").(user.User)
+
+	if _, err := handler.userSvc.DeleteDeviceToken(reqDetail, reqDelete); err != nil {
+		if helper.IsErrNoRows(err.Error()) {
+			response := helper.APIResponseError(http.StatusNotFound, "Delete device failed!", "Data not found!")
+			ctx.JSON(http.StatusNotFound, response)
+			return
+		}
+
+		response := helper.APIResponseError(http.StatusInternalServerError, "Delete device failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := helper.BasicAPIResponse(http.StatusOK, "Delete device successfully!")
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (handler *userHandler) GetBankAccounts(ctx *gin.Context) {
+	userData := ctx.MustGet("userData").(user.User)
+
+	bankAccounts, err := handler.userSvc.GetBankAccounts(userData.ID)
+
+	if err != nil {
+		response := helper.APIResponseError(http.StatusInternalServerError, "Get bank accounts failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := helper.APIResponse(http.StatusOK, "Get bank accounts successfully!", user.FormatListBankAccountData(bankAccounts))
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (handler *userHandler) CreateBankAccount(ctx *gin.Context) {
+	var req user.RequestCreateBankAccount
+
+	err := ctx.ShouldBindJSON(&req)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Create bank account failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	req.User = ctx.MustGet("userData").(user.User)
+
+	bankAccount, err := handler.userSvc.CreateBankAccount(req)
+
+	if err != nil {
+		response := helper.APIResponseError(http.StatusBadRequest, "Create bank account failed!", err.Error())
+		ctx.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	response := helper.APIResponse(http.StatusCreated, "Create bank account successfully!", user.FormatBankAccountData(bankAccount))
 
-	err = ctx.ShouldBind(&reqUpdate)
+	handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("%v adding bank account id %v.", req.User.Name, bankAccount.ID))
+
+	ctx.JSON(http.StatusCreated, response)
+}
+
+func (handler *userHandler) SetDefaultBankAccount(ctx *gin.Context) {
+	var reqDetail user.RequestGetBankAccountByID
+	var reqUpdate user.RequestUpdateBankAccount
+
+	err := ctx.ShouldBindUri(&reqDetail)
 
 	if err != nil {
 		errors := helper.FormatValidationError(err)
-		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Update user failed!", errors[0])
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Set default bank account failed!", errors[0])
 		ctx.JSON(http.StatusUnprocessableEntity, response)
 		return
 	}
 
 	reqUpdate.User = ctx.MustGet("userData").(user.User)
 
-	updatedUser, err := handler.userSvc.UpdateUser(reqID, reqUpdate)
+	bankAccount, err := handler.userSvc.SetDefaultBankAccount(reqDetail, reqUpdate)
 
 	if err != nil {
 		if helper.IsErrNoRows(err.Error()) {
-			response := helper.APIResponseError(http.StatusNotFound, "Update user failed!", fmt.Sprintf("User with ID %d not found!", reqID.ID))
+			response := helper.APIResponseError(http.StatusNotFound, "Set default bank account failed!", "Data not found!")
 			ctx.JSON(http.StatusNotFound, response)
 			return
 		}
 
-		response := helper.APIResponseError(http.StatusInternalServerError, "Update user failed!", err.Error())
+		response := helper.APIResponseError(http.StatusInternalServerError, "Set default bank account failed!", err.Error())
 		ctx.JSON(http.StatusInternalServerError, response)
 		return
 	}
 
-	formatData := user.FormatUserFullData(updatedUser)
-	response := helper.APIResponse(http.StatusOK, "Update user successfully!", formatData)
-
-	handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("%v updating user id %v.", reqUpdate.User.Name, reqID.ID))
-
+	response := helper.APIResponse(http.StatusOK, "Set default bank account successfully!", user.FormatBankAccountData(bankAccount))
 	ctx.JSON(http.StatusOK, response)
 }
 
-func (handler *userHandler) DeleteUser(ctx *gin.Context) {
-	var reqID user.RequestGetUserByID
+func (handler *userHandler) VerifyBankAccount(ctx *gin.Context) {
+	var reqDetail user.RequestGetBankAccountByID
+	var reqUpdate user.RequestUpdateBankAccount
 
-	err := ctx.ShouldBindUri(&reqID)
+	err := ctx.ShouldBindUri(&reqDetail)
 
 	if err != nil {
 		errors := helper.FormatValidationError(err)
-		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Delete user failed!", errors[0])
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Verify bank account failed!", errors[0])
 		ctx.JSON(http.StatusUnprocessableEntity, response)
 		return
 	}
 
-	var reqDelete user.RequestDeleteUser
+	reqUpdate.User = ctx.MustGet("userData").(user.User)
 
-	err = ctx.ShouldBind(&reqDelete)
+	bankAccount, err := handler.userSvc.VerifyBankAccount(reqDetail, reqUpdate)
 
 	if err != nil {
-		errors := helper.FormatValidationError(err)
-		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Delete user failed!", errors[0])
-		ctx.JSON(http.StatusUnprocessableEntity, response)
-		return
-	}
-
-	reqDelete.User = ctx.MustGet("userData").(user.User)
-
-	if _, err = handler.userSvc.DeleteUser(reqID, reqDelete); err != nil {
 		if helper.IsErrNoRows(err.Error()) {
-			response := helper.APIResponseError(http.StatusNotFound, "Delete user failed!", fmt.Sprintf("User with ID %d not found!", reqID.ID))
+			response := helper.APIResponseError(http.StatusNotFound, "Verify bank account failed!", "Data not found!")
 			ctx.JSON(http.StatusNotFound, response)
 			return
 		}
 
-		response := helper.APIResponseError(http.StatusInternalServerError, "Delete user failed!", err.Error())
-		ctx.JSON(http.StatusInternalServerError, response)
+		response := helper.APIResponseError(http.StatusBadRequest, "Verify bank account failed!", err.Error())
+		ctx.JSON(http.StatusBadRequest, response)
 		return
 	}
 
-	response := helper.BasicAPIResponse(http.StatusOK, "Delete user successfully!")
+	response := helper.APIResponse(http.StatusOK, "Verify bank account successfully!", user.FormatBankAccountData(bankAccount))
 
-	handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("%v deleting user id %v.", reqDelete.User.Name, reqID.ID))
+	handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("%v verifying bank account id %v.", reqUpdate.User.Name, bankAccount.ID))
 
 	ctx.JSON(http.StatusOK, response)
 }
 
-func (handler *userHandler) AdminDataTablesUsers(ctx *gin.Context) {
-	dataTablesUsers, err := handler.userSvc.AdminDataTablesUsers(ctx)
+func (handler *userHandler) DeleteBankAccount(ctx *gin.Context) {
+	var reqDetail user.RequestGetBankAccountByID
+	var reqUpdate user.RequestUpdateBankAccount
+
+	err := ctx.ShouldBindUri(&reqDetail)
 
 	if err != nil {
-		response := helper.APIResponseError(http.StatusInternalServerError, "Get datatables users failed!", err.Error())
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Delete bank account failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	reqUpdate.User = ctx.MustGet("userData").(user.User)
+
+	if _, err := handler.userSvc.DeleteBankAccount(reqDetail, reqUpdate); err != nil {
+		if helper.IsErrNoRows(err.Error()) {
+			response := helper.APIResponseError(http.StatusNotFound, "Delete bank account failed!", "Data not found!")
+			ctx.JSON(http.StatusNotFound, response)
+			return
+		}
+
+		response := helper.APIResponseError(http.StatusInternalServerError, "Delete bank account failed!", err.Error())
 		ctx.JSON(http.StatusInternalServerError, response)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, dataTablesUsers)
-}
+	response := helper.BasicAPIResponse(http.StatusOK, "Delete bank account successfully!")
+
+	handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("%v deleting bank account id %v.", reqUpdate.User.Name, reqDetail.ID))
 
-func (handler *userHandler) GetUserData(ctx *gin.Context) {
-	formatData := user.FormatUserFullData(ctx.MustGet("userData").(user.User))
-	response := helper.APIResponse(http.StatusOK, "Get user data successfully!", formatData)
 	ctx.JSON(http.StatusOK, response)
 }
 
@@ -355,7 +978,7 @@ func (handler *userHandler) ChangeUserData(ctx *gin.Context) {
 	reqUpdate.Name = reqSelfUpdate.Name
 	reqUpdate.Email = reqSelfUpdate.Email
 	reqUpdate.Role = reqUpdate.User.Role
-	reqUpdate.EMoney = reqUpdate.User.EMoney
+	reqUpdate.Locale = reqSelfUpdate.Locale
 
 	if reqSelfUpdate.Password != "" {
 		reqUpdate.Password = reqSelfUpdate.Password
@@ -369,6 +992,12 @@ func (handler *userHandler) ChangeUserData(ctx *gin.Context) {
 		return
 	}
 
+	if !strings.EqualFold(reqUpdate.User.Email, updatedUser.Email) {
+		if err := handler.sendEmailVerification(updatedUser); err != nil {
+			handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("Failed to send email verification to %s: %s.", updatedUser.Email, err.Error()))
+		}
+	}
+
 	formatData := user.FormatUserFullData(updatedUser)
 	response := helper.APIResponse(http.StatusOK, "Update self user data successfully!", formatData)
 
@@ -438,8 +1067,8 @@ func (handler *userHandler) CreateWithdrawalRequest(ctx *gin.Context) {
 	dataCreated, err := handler.userSvc.CreateWithdrawalRequest(req)
 
 	if err != nil {
-		response := helper.APIResponseError(http.StatusInternalServerError, "Request failed!", err.Error())
-		ctx.JSON(http.StatusInternalServerError, response)
+		response := helper.APIResponseError(http.StatusBadRequest, "Request failed!", err.Error())
+		ctx.JSON(http.StatusBadRequest, response)
 		return
 	}
 
@@ -456,7 +1085,9 @@ func (handler *userHandler) CreateWithdrawalRequest(ctx *gin.Context) {
 			Name:   userData.Name,
 			Amount: helper.FormatRupiah(float64(req.Amount)),
 		}
-		go helper.SendMail(userData.Email, "Withdrawal Request", templateData, "html/withdrawal_request.html")
+		if err := helper.SendNotification(helper.NotificationRecipient{UserID: userData.ID, Email: userData.Email, Locale: userData.Locale}, helper.EmailTemplateWithdrawalRequest, templateData); err != nil {
+			handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("Failed to send withdrawal request notification to %s: %s.", userData.Email, err.Error()))
+		}
 	}
 
 	formatData := user.FormatWithdrawalRequestData(dataCreated)
@@ -517,7 +1148,7 @@ func (handler *userHandler) UpdateUserWithdrawalRequest(ctx *gin.Context) {
 
 	reqUpdate.User = ctx.MustGet("userData").(user.User)
 
-	updatedUserWithdrawalRequest, err := handler.userSvc.UpdateUserWithdrawalRequest(reqID, reqUpdate)
+	approvalData, required, err := handler.approvalSvc.SubmitWithdrawalUpdate(reqID, reqUpdate)
 
 	if err != nil {
 		if helper.IsErrNoRows(err.Error()) {
@@ -526,51 +1157,121 @@ func (handler *userHandler) UpdateUserWithdrawalRequest(ctx *gin.Context) {
 			return
 		}
 
-		response := helper.APIResponseError(http.StatusInternalServerError, "Update user withdrawal request failed!", err.Error())
-		ctx.JSON(http.StatusInternalServerError, response)
+		response := helper.APIResponseError(http.StatusBadRequest, "Update user withdrawal request failed!", err.Error())
+		ctx.JSON(http.StatusBadRequest, response)
 		return
 	}
 
-	if updatedUserWithdrawalRequest.Status == "approved" {
-		userData, err := handler.userSvc.GetUserByID(updatedUserWithdrawalRequest.UserID)
+	if required {
+		response := helper.APIResponse(http.StatusAccepted, "Update user withdrawal request is waiting for approval!", approval.FormatApprovalData(approvalData))
 
-		if err != nil {
-			response := helper.APIResponseError(http.StatusInternalServerError, "Update user withdrawal request failed!", err.Error())
-			ctx.JSON(http.StatusInternalServerError, response)
+		handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("%v asking approval id %v to update user request withdrawal id %v to %v.", reqUpdate.User.Name, approvalData.ID, reqID.ID, reqUpdate.Status))
+
+		ctx.JSON(http.StatusAccepted, response)
+		return
+	}
+
+	updatedUserWithdrawalRequest, err := handler.userSvc.UpdateUserWithdrawalRequest(reqID, reqUpdate)
+
+	if err != nil {
+		if helper.IsErrNoRows(err.Error()) {
+			response := helper.APIResponseError(http.StatusNotFound, "Update user withdrawal request failed!", fmt.Sprintf("User with ID %d not found!", reqID.ID))
+			ctx.JSON(http.StatusNotFound, response)
 			return
 		}
 
-		handler.companySvc.CreateCompanyCashFlow(company.RequestCreateCompanyCashFlow{
-			Status: "out",
-			Amount: int64(updatedUserWithdrawalRequest.Amount),
-			Note:   fmt.Sprintf("Processing withdrawal id %v.", updatedUserWithdrawalRequest.ID),
-		})
+		response := helper.APIResponseError(http.StatusBadRequest, "Update user withdrawal request failed!", err.Error())
+		ctx.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// the request is already moved, a notification that could not be queued does not undo it
+	if err := withdrawalRequestUpdated(handler.userSvc, updatedUserWithdrawalRequest); err != nil {
+		handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("Failed to notify about user request withdrawal id %v: %s.", updatedUserWithdrawalRequest.ID, err.Error()))
+	}
+
+	formatData := user.FormatWithdrawalRequestData(updatedUserWithdrawalRequest)
+	response := helper.APIResponse(http.StatusOK, "Update user withdrawal request successfully!", formatData)
+
+	handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("%v updating user request withdrawal id %v to %v.", reqUpdate.User.Name, reqID.ID, updatedUserWithdrawalRequest.Status))
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// withdrawalRequestUpdated tells the owner about a withdrawal an admin has just moved, the company cash flow
+// of a paid one is written with the payment itself
+func withdrawalRequestUpdated(userSvc user.Service, userWithdrawalRequest user.UserWithdrawalRequest) error {
+	if userWithdrawalRequest.Status == user.WithdrawalStatusApproved {
+		userData, err := userSvc.GetUserByID(userWithdrawalRequest.UserID)
+
+		if err != nil {
+			return err
+		}
 
 		templateData := helper.EmailWithdrawalRequest{
 			Name:   userData.Name,
-			Amount: helper.FormatRupiah(float64(updatedUserWithdrawalRequest.Amount)),
+			Amount: helper.FormatRupiah(float64(userWithdrawalRequest.Amount)),
+		}
+		if err := helper.SendNotification(helper.NotificationRecipient{UserID: userData.ID, Email: userData.Email, Locale: userData.Locale}, helper.EmailTemplateWithdrawalApproved, templateData); err != nil {
+			return err
 		}
-		go helper.SendMail(userData.Email, "Approved Withdrawal Request", templateData, "html/withdrawal_approved.html")
-	} else if updatedUserWithdrawalRequest.Status == "rejected" {
-		userData, err := handler.userSvc.GetUserByID(updatedUserWithdrawalRequest.UserID)
+
+		return helper.PublishWebhookEvent(helper.WebhookEventWithdrawalApproved, userData.ID, helper.WebhookWithdrawalApproved{
+			WithdrawalID: userWithdrawalRequest.ID,
+			UserID:       userData.ID,
+			Amount:       userWithdrawalRequest.Amount,
+			ApprovedAt:   time.Now(),
+		})
+	} else if userWithdrawalRequest.Status == user.WithdrawalStatusRejected || userWithdrawalRequest.Status == user.WithdrawalStatusFailed {
+		userData, err := userSvc.GetUserByID(userWithdrawalRequest.UserID)
 
 		if err != nil {
-			response := helper.APIResponseError(http.StatusInternalServerError, "Update user withdrawal request failed!", err.Error())
-			ctx.JSON(http.StatusInternalServerError, response)
-			return
+			return err
 		}
 
 		templateData := helper.EmailWithdrawalRequest{
 			Name:   userData.Name,
-			Amount: helper.FormatRupiah(float64(updatedUserWithdrawalRequest.Amount)),
+			Amount: helper.FormatRupiah(float64(userWithdrawalRequest.Amount)),
 		}
-		go helper.SendMail(userData.Email, "Rejected Withdrawal Request", templateData, "html/withdrawal_rejected.html")
+		return helper.SendNotification(helper.NotificationRecipient{UserID: userData.ID, Email: userData.Email, Locale: userData.Locale}, helper.EmailTemplateWithdrawalRejected, templateData)
 	}
 
-	formatData := user.FormatWithdrawalRequestData(updatedUserWithdrawalRequest)
-	response := helper.APIResponse(http.StatusOK, "Update user withdrawal request successfully!", formatData)
+	return nil
+}
+
+func (handler *userHandler) CancelWithdrawalRequest(ctx *gin.Context) {
+	var reqID user.RequestGetUserWithdrawalRequestByID
+
+	err := ctx.ShouldBindUri(&reqID)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Cancel withdrawal request failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	var reqCancel user.RequestCancelWithdrawalRequest
+	reqCancel.User = ctx.MustGet("userData").(user.User)
+
+	cancelledWithdrawalRequest, err := handler.userSvc.CancelWithdrawalRequest(reqID, reqCancel)
+
+	if err != nil {
+		if helper.IsErrNoRows(err.Error()) {
+			response := helper.APIResponseError(http.StatusNotFound, "Cancel withdrawal request failed!", "Data not found!")
+			ctx.JSON(http.StatusNotFound, response)
+			return
+		}
+
+		response := helper.APIResponseError(http.StatusBadRequest, "Cancel withdrawal request failed!", err.Error())
+		ctx.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	formatData := user.FormatWithdrawalRequestData(cancelledWithdrawalRequest)
+	response := helper.APIResponse(http.StatusOK, "Cancel withdrawal request successfully!", formatData)
 
-	handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("%v updating user request withdrawal id %v.", reqUpdate.User.Name, reqID.ID))
+	handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("%v cancelling withdrawal request id %v.", reqCancel.User.Name, reqID.ID))
 
 	ctx.JSON(http.StatusOK, response)
 }
@@ -607,8 +1308,8 @@ func (handler *userHandler) DeleteUserWithdrawalRequest(ctx *gin.Context) {
 			return
 		}
 
-		response := helper.APIResponseError(http.StatusInternalServerError, "Delete user withdrawal request failed!", err.Error())
-		ctx.JSON(http.StatusInternalServerError, response)
+		response := helper.APIResponseError(http.StatusBadRequest, "Delete user withdrawal request failed!", err.Error())
+		ctx.JSON(http.StatusBadRequest, response)
 		return
 	}
 
@@ -619,6 +1320,85 @@ func (handler *userHandler) DeleteUserWithdrawalRequest(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, response)
 }
 
+func (handler *userHandler) VerifyEmail(ctx *gin.Context) {
+	var req user.RequestVerifyEmail
+
+	err := ctx.ShouldBindUri(&req)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Verify email failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	userData, err := handler.userSvc.VerifyEmail(req)
+
+	if err != nil {
+		if helper.IsErrNoRows(err.Error()) {
+			response := helper.APIResponseError(http.StatusNotFound, "Verify email failed!", "Token not found!")
+			ctx.JSON(http.StatusNotFound, response)
+			return
+		}
+
+		if err.Error() == "token expired" {
+			response := helper.APIResponseError(http.StatusUnprocessableEntity, "Verify email failed!", "Token expired, please request a new verification email!")
+			ctx.JSON(http.StatusUnprocessableEntity, response)
+			return
+		}
+
+		response := helper.APIResponseError(http.StatusInternalServerError, "Verify email failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	formatData := user.FormatUserFullData(userData)
+	response := helper.APIResponse(http.StatusOK, "Verify email successfully!", formatData)
+
+	handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("%v verified the email %v.", userData.Name, userData.Email))
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (handler *userHandler) ResendEmailVerification(ctx *gin.Context) {
+	userData := ctx.MustGet("userData").(user.User)
+
+	if userData.IsEmailVerified() {
+		response := helper.APIResponseError(http.StatusBadRequest, "Resend email verification failed!", "Email already verified!")
+		ctx.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	cooldown, err := handler.userSvc.GetEmailVerificationCooldown(userData.ID)
+
+	if err != nil {
+		response := helper.APIResponseError(http.StatusInternalServerError, "Resend email verification failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	if cooldown > 0 {
+		retryAfter := int(math.Ceil(cooldown.Seconds()))
+
+		ctx.Header("Retry-After", strconv.Itoa(retryAfter))
+		response := helper.APIResponseError(http.StatusTooManyRequests, "Resend email verification failed!", fmt.Sprintf("Please wait %d seconds before requesting another verification email!", retryAfter))
+		ctx.JSON(http.StatusTooManyRequests, response)
+		return
+	}
+
+	if err := handler.sendEmailVerification(userData); err != nil {
+		response := helper.APIResponseError(http.StatusInternalServerError, "Resend email verification failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := helper.BasicAPIResponse(http.StatusOK, "Resend email verification successfully, please check your email inbox or spam!")
+
+	handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("%v requested a new email verification.", userData.Name))
+
+	ctx.JSON(http.StatusOK, response)
+}
+
 func (handler *userHandler) CreateForgotPasswordToken(ctx *gin.Context) {
 	var req user.RequestCreateForgotPasswordToken
 
@@ -631,12 +1411,14 @@ func (handler *userHandler) CreateForgotPasswordToken(ctx *gin.Context) {
 		return
 	}
 
+	// same answer for unknown emails, so this endpoint can't be used to find out who is registered
+	response := helper.BasicAPIResponse(http.StatusCreated, "Request forgot password successfully, if the email is registered please check your email inbox or spam!")
+
 	userData, err := handler.userSvc.GetUserByEmail(req.Email)
 
 	if err != nil {
 		if helper.IsErrNoRows(err.Error()) {
-			response := helper.APIResponseError(http.StatusNotFound, "Request forgot password failed!", fmt.Sprintf("User with email %s not found!", req.Email))
-			ctx.JSON(http.StatusNotFound, response)
+			ctx.JSON(http.StatusCreated, response)
 			return
 		}
 
@@ -647,7 +1429,7 @@ func (handler *userHandler) CreateForgotPasswordToken(ctx *gin.Context) {
 
 	req.User = userData
 
-	dataCreated, err := handler.userSvc.CreateUserForgotPasswordToken(req)
+	token, err := handler.userSvc.CreateUserForgotPasswordToken(req)
 
 	if err != nil {
 		response := helper.APIResponseError(http.StatusInternalServerError, "Request forgot password failed!", err.Error())
@@ -658,13 +1440,15 @@ func (handler *userHandler) CreateForgotPasswordToken(ctx *gin.Context) {
 	{
 		templateData := helper.EmailForgotPassword{
 			Name: userData.Name,
-			URL:  os.Getenv("WEB_URL") + "/auth/forgot-password/" + dataCreated.Token,
+			URL:  os.Getenv("WEB_URL") + "/auth/forgot-password/" + token,
+		}
+		if err := helper.SendMail(userData.Email, userData.Locale, helper.EmailTemplateForgotPassword, templateData); err != nil {
+			response := helper.APIResponseError(http.StatusInternalServerError, "Request forgot password failed!", err.Error())
+			ctx.JSON(http.StatusInternalServerError, response)
+			return
 		}
-		go helper.SendMail(userData.Email, "Forgot Password Request", templateData, "html/forgot_password.html")
 	}
 
-	response := helper.APIResponse(http.StatusCreated, "Request forgot password successfully, please check your email inbox or spam!", dataCreated)
-
 	handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("%v make a request token for forgot password.", req.User.Name))
 
 	ctx.JSON(http.StatusCreated, response)
@@ -682,55 +1466,152 @@ func (handler *userHandler) ProcessForgotPasswordToken(ctx *gin.Context) {
 		return
 	}
 
+	// only checks the token so the frontend can show the new password form, the reset itself is ResetPassword
 	forgotPasswordData, err := handler.userSvc.GetDataForgotPasswordByToken(req.Token)
 
 	if err != nil {
-		response := helper.APIResponseError(http.StatusInternalServerError, "Process request forgot password failed!", err.Error())
-		ctx.JSON(http.StatusInternalServerError, response)
+		handler.forgotPasswordTokenError(ctx, "Process request forgot password failed!", err)
 		return
 	}
 
-	expired := forgotPasswordData.CreatedAt.AddDate(0, 0, 1)
-	isExpired := time.Now().After(expired)
+	response := helper.APIResponse(http.StatusOK, "Process request forgot password successfully!", gin.H{"expired_at": forgotPasswordData.ExpiredAt})
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (handler *userHandler) ResetPassword(ctx *gin.Context) {
+	var req user.RequestResetPassword
+
+	err := ctx.ShouldBind(&req)
 
-	if isExpired {
-		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Process request forgot password failed!", "Token expired!")
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Reset password failed!", errors[0])
 		ctx.JSON(http.StatusUnprocessableEntity, response)
 		return
 	}
 
-	userData, err := handler.userSvc.GetUserByID(forgotPasswordData.UserID)
+	userData, err := handler.userSvc.ResetPassword(req)
 
 	if err != nil {
-		response := helper.APIResponseError(http.StatusInternalServerError, "Process request forgot password failed!", err.Error())
-		ctx.JSON(http.StatusInternalServerError, response)
+		handler.forgotPasswordTokenError(ctx, "Reset password failed!", err)
 		return
 	}
 
-	token, err := handler.authSvc.GenerateToken(userData.ID)
+	response := helper.BasicAPIResponse(http.StatusOK, "Reset password successfully, please login with your new password!")
 
-	if err != nil {
-		response := helper.APIResponseError(http.StatusInternalServerError, "Process request forgot password failed!", err.Error())
-		ctx.JSON(http.StatusInternalServerError, response)
+	handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("%v reset the account password with forgot password token.", userData.Name))
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (handler *userHandler) forgotPasswordTokenError(ctx *gin.Context, message string, err error) {
+	if helper.IsErrNoRows(err.Error()) {
+		response := helper.APIResponseError(http.StatusNotFound, message, "Token not found or already used!")
+		ctx.JSON(http.StatusNotFound, response)
 		return
 	}
 
-	if _, err = handler.userSvc.DeleteForgotPasswordToken(forgotPasswordData); err != nil {
-		if helper.IsErrNoRows(err.Error()) {
-			response := helper.APIResponseError(http.StatusNotFound, "Process request forgot password failed!", fmt.Sprintf("Forgot password data with ID %d not found!", forgotPasswordData.ID))
-			ctx.JSON(http.StatusNotFound, response)
-			return
-		}
+	if err.Error() == "token expired" {
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, message, "Token expired!")
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
 
-		response := helper.APIResponseError(http.StatusInternalServerError, "Process request forgot password failed!", err.Error())
-		ctx.JSON(http.StatusInternalServerError, response)
+	response := helper.APIResponseError(http.StatusInternalServerError, message, err.Error())
+	ctx.JSON(http.StatusInternalServerError, response)
+}
+
+func (handler *userHandler) canAssignRole(actor user.User, role string) (bool, string) {
+	allowed, err := handler.rbacSvc.HasPermission(actor.Role, rbac.PermissionRoleManage)
+
+	if err != nil {
+		return false, err.Error()
+	}
+
+	if !allowed {
+		return false, "You don't have permission to assign roles!"
+	}
+
+	isRoleExist, err := handler.rbacSvc.IsRoleExist(role)
+
+	if err != nil {
+		return false, err.Error()
+	}
+
+	if !isRoleExist {
+		return false, fmt.Sprintf("Role %v not found!", role)
+	}
+
+	return true, ""
+}
+
+func (handler *userHandler) sendEmailVerification(userData user.User) error {
+	token, err := handler.userSvc.CreateEmailVerificationToken(user.RequestCreateEmailVerificationToken{User: userData})
+
+	if err != nil {
+		return err
+	}
+
+	templateData := helper.EmailVerification{
+		Name: userData.Name,
+		URL:  os.Getenv("WEB_URL") + "/auth/verify-email/" + token,
+	}
+	return helper.SendMail(userData.Email, userData.Locale, helper.EmailTemplateEmailVerification, templateData)
+}
+
+// answers 429 when the account is locked because of too many failed logins
+func (handler *userHandler) isLoginLocked(ctx *gin.Context, email string) bool {
+	lockedFor, err := handler.limiter.LoginLockedFor(email)
+
+	if err != nil {
+		log.Println("check login lock failed, err: ", err.Error())
+		return false
+	}
+
+	if lockedFor <= 0 {
+		return false
+	}
+
+	retryAfter := int(math.Ceil(lockedFor.Seconds()))
+
+	ctx.Header("Retry-After", strconv.Itoa(retryAfter))
+	response := helper.APIResponseError(http.StatusTooManyRequests, "Login failed!", fmt.Sprintf("Account temporarily locked because of too many failed logins, please try again in %d seconds!", retryAfter))
+	ctx.JSON(http.StatusTooManyRequests, response)
+
+	return true
+}
+
+// counts a failed password or two-factor code, the owner is emailed when it locks the account
+func (handler *userHandler) recordLoginFailure(ctx *gin.Context, email string, userData user.User) {
+	lockedFor, err := handler.limiter.RecordLoginFailure(email)
+
+	if err != nil {
+		log.Println("record login failure failed, err: ", err.Error())
 		return
 	}
 
-	formatData := user.FormatUserData(userData, token)
-	response := helper.APIResponse(http.StatusOK, "Process request forgot password successfully!", formatData)
+	if lockedFor <= 0 || userData.ID == 0 {
+		return
+	}
 
-	handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("%v process request forgot password.", req.User.Name))
+	{
+		templateData := helper.EmailAccountLocked{
+			Name:      userData.Name,
+			Duration:  fmt.Sprintf("%d minutes", int(math.Ceil(lockedFor.Minutes()))),
+			IpAddress: ctx.ClientIP(),
+			URL:       os.Getenv("WEB_URL") + "/auth/forgot-password",
+		}
+		if err := helper.SendNotification(helper.NotificationRecipient{UserID: userData.ID, Email: userData.Email, Locale: userData.Locale}, helper.EmailTemplateAccountLocked, templateData); err != nil {
+			handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("Failed to send account locked notification to %s: %s.", userData.Email, err.Error()))
+		}
+	}
 
-	ctx.JSON(http.StatusOK, response)
+	handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("%s locked for %v because of too many failed logins.", userData.Name, lockedFor))
+}
+
+func (handler *userHandler) resetLoginFailures(email string) {
+	if err := handler.limiter.ResetLoginFailures(email); err != nil {
+		log.Println("reset login failures failed, err: ", err.Error())
+	}
 }
@@ -120,7 +120,7 @@ func (handler *webAndCMSHandler) GetStatisticsForAdminDashboard(ctx *gin.Context
 		return
 	}
 
-	withdrawalRequestsProcessed, err := handler.userSvc.GetTotalWithdrawalRequest("AND status != 'pending'")
+	withdrawalRequestsProcessed, err := handler.userSvc.GetTotalWithdrawalRequest("AND status NOT IN ('pending', 'requested')")
 
 	if err != nil {
 		response := helper.APIResponseError(http.StatusInternalServerError, "Get statistics for admin dashboard failed!", err.Error())
@@ -136,7 +136,7 @@ func (handler *webAndCMSHandler) GetStatisticsForAdminDashboard(ctx *gin.Context
 		return
 	}
 
-	userRegisteredRoleAdmin, err := handler.userSvc.GetUserRegistered("AND role = 'admin'")
+	userRegisteredRoleAdmin, err := handler.userSvc.GetUserRegistered("AND role <> 'user'")
 
 	if err != nil {
 		response := helper.APIResponseError(http.StatusInternalServerError, "Get statistics for admin dashboard failed!", err.Error())
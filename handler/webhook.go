@@ -0,0 +1,306 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+	"github.com/WeAreAmazingTeam/tcd-backend/logs"
+	"github.com/WeAreAmazingTeam/tcd-backend/user"
+	"github.com/WeAreAmazingTeam/tcd-backend/webhook"
+	"github.com/gin-gonic/gin"
+)
+
+type webhookHandler struct {
+	webhookSvc webhook.Service
+	logsSvc    logs.Service
+}
+
+func NewWebhookHandler(webhookService webhook.Service, logsService logs.Service) *webhookHandler {
+	return &webhookHandler{
+		webhookSvc: webhookService,
+		logsSvc:    logsService,
+	}
+}
+
+func (handler *webhookHandler) GetWebhookEvents(ctx *gin.Context) {
+	response := helper.APIResponse(http.StatusOK, "Get webhook events successfully!", helper.WebhookEvents)
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (handler *webhookHandler) GetWebhookSubscriptions(ctx *gin.Context) {
+	userData := ctx.MustGet("userData").(user.User)
+
+	subscriptions, err := handler.webhookSvc.GetWebhookSubscriptions(userData.ID)
+
+	if err != nil {
+		response := helper.APIResponseError(http.StatusInternalServerError, "Get webhook subscriptions failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := helper.APIResponse(http.StatusOK, "Get webhook subscriptions successfully!", webhook.FormatListWebhookSubscriptionData(subscriptions))
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (handler *webhookHandler) CreateWebhookSubscription(ctx *gin.Context) {
+	var req webhook.RequestCreateWebhookSubscription
+
+	err := ctx.ShouldBindJSON(&req)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Create webhook subscription failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	req.User = ctx.MustGet("userData").(user.User)
+
+	newSubscription, err := handler.webhookSvc.CreateWebhookSubscription(req)
+
+	if err != nil {
+		response := helper.APIResponseError(http.StatusBadRequest, "Create webhook subscription failed!", err.Error())
+		ctx.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("%v creating webhook subscription id %v.", req.User.Name, newSubscription.ID))
+
+	response := helper.APIResponse(http.StatusCreated, "Create webhook subscription successfully!", webhook.FormatWebhookSubscriptionWithSecretData(newSubscription))
+	ctx.JSON(http.StatusCreated, response)
+}
+
+func (handler *webhookHandler) UpdateWebhookSubscription(ctx *gin.Context) {
+	var reqDetail webhook.RequestGetWebhookSubscriptionByID
+	var reqUpdate webhook.RequestUpdateWebhookSubscription
+
+	err := ctx.ShouldBindUri(&reqDetail)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Update webhook subscription failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	err = ctx.ShouldBindJSON(&reqUpdate)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Update webhook subscription failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	reqUpdate.User = ctx.MustGet("userData").(user.User)
+
+	updatedSubscription, err := handler.webhookSvc.UpdateWebhookSubscription(reqDetail, reqUpdate)
+
+	if err != nil {
+		if helper.IsErrNoRows(err.Error()) {
+			response := helper.APIResponseError(http.StatusNotFound, "Update webhook subscription failed!", "Data not found!")
+			ctx.JSON(http.StatusNotFound, response)
+			return
+		}
+
+		response := helper.APIResponseError(http.StatusBadRequest, "Update webhook subscription failed!", err.Error())
+		ctx.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("%v updating webhook subscription id %v.", reqUpdate.User.Name, reqDetail.ID))
+
+	response := helper.APIResponse(http.StatusOK, "Update webhook subscription successfully!", webhook.FormatWebhookSubscriptionData(updatedSubscription))
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (handler *webhookHandler) DeleteWebhookSubscription(ctx *gin.Context) {
+	var reqDetail webhook.RequestGetWebhookSubscriptionByID
+	var reqDelete webhook.RequestDeleteWebhookSubscription
+
+	err := ctx.ShouldBindUri(&reqDetail)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Delete webhook subscription failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	reqDelete.User = ctx.MustGet("userData").(user.User)
+
+	if _, err := handler.webhookSvc.DeleteWebhookSubscription(reqDetail, reqDelete); err != nil {
+		if helper.IsErrNoRows(err.Error()) {
+			response := helper.APIResponseError(http.StatusNotFound, "Delete webhook subscription failed!", "Data not found!")
+			ctx.JSON(http.StatusNotFound, response)
+			return
+		}
+
+		response := helper.APIResponseError(http.StatusInternalServerError, "Delete webhook subscription failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("%v deleting webhook subscription id %v.", reqDelete.User.Name, reqDetail.ID))
+
+	response := helper.BasicAPIResponse(http.StatusOK, "Delete webhook subscription successfully!")
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (handler *webhookHandler) RotateWebhookSecret(ctx *gin.Context) {
+	var reqDetail webhook.RequestGetWebhookSubscriptionByID
+	var reqRotate webhook.RequestRotateWebhookSecret
+
+	err := ctx.ShouldBindUri(&reqDetail)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Rotate webhook secret failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	reqRotate.User = ctx.MustGet("userData").(user.User)
+
+	updatedSubscription, err := handler.webhookSvc.RotateWebhookSecret(reqDetail, reqRotate)
+
+	if err != nil {
+		if helper.IsErrNoRows(err.Error()) {
+			response := helper.APIResponseError(http.StatusNotFound, "Rotate webhook secret failed!", "Data not found!")
+			ctx.JSON(http.StatusNotFound, response)
+			return
+		}
+
+		response := helper.APIResponseError(http.StatusInternalServerError, "Rotate webhook secret failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("%v rotating secret of webhook subscription id %v.", reqRotate.User.Name, reqDetail.ID))
+
+	response := helper.APIResponse(http.StatusOK, "Rotate webhook secret successfully!", webhook.FormatWebhookSubscriptionWithSecretData(updatedSubscription))
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (handler *webhookHandler) GetWebhookSubscriptionDeliveries(ctx *gin.Context) {
+	var reqDetail webhook.RequestGetWebhookSubscriptionByID
+
+	err := ctx.ShouldBindUri(&reqDetail)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Get webhook deliveries failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	userData := ctx.MustGet("userData").(user.User)
+
+	deliveries, err := handler.webhookSvc.GetWebhookSubscriptionDeliveries(reqDetail, userData)
+
+	if err != nil {
+		if helper.IsErrNoRows(err.Error()) {
+			response := helper.APIResponseError(http.StatusNotFound, "Get webhook deliveries failed!", "Data not found!")
+			ctx.JSON(http.StatusNotFound, response)
+			return
+		}
+
+		response := helper.APIResponseError(http.StatusInternalServerError, "Get webhook deliveries failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := helper.APIResponse(http.StatusOK, "Get webhook deliveries successfully!", webhook.FormatListWebhookDeliveryData(deliveries))
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (handler *webhookHandler) AdminGetAllWebhookSubscription(ctx *gin.Context) {
+	subscriptions, err := handler.webhookSvc.GetAllWebhookSubscription()
+
+	if err != nil {
+		response := helper.APIResponseError(http.StatusInternalServerError, "Get all webhook subscription failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := helper.APIResponse(http.StatusOK, "Get all webhook subscription successfully!", webhook.FormatListWebhookSubscriptionData(subscriptions))
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (handler *webhookHandler) AdminDataTablesWebhookDelivery(ctx *gin.Context) {
+	dataTablesWebhookDelivery, err := handler.webhookSvc.AdminDataTablesWebhookDelivery(ctx)
+
+	if err != nil {
+		response := helper.APIResponseError(http.StatusInternalServerError, "Get datatables webhook delivery failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dataTablesWebhookDelivery)
+}
+
+func (handler *webhookHandler) AdminGetWebhookDelivery(ctx *gin.Context) {
+	var req webhook.RequestGetWebhookDeliveryByID
+
+	err := ctx.ShouldBindUri(&req)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Get webhook delivery failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	delivery, deliveryLogs, err := handler.webhookSvc.GetWebhookDeliveryDetail(req)
+
+	if err != nil {
+		if helper.IsErrNoRows(err.Error()) {
+			response := helper.APIResponseError(http.StatusNotFound, "Get webhook delivery failed!", "Data not found!")
+			ctx.JSON(http.StatusNotFound, response)
+			return
+		}
+
+		response := helper.APIResponseError(http.StatusInternalServerError, "Get webhook delivery failed!", err.Error())
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := helper.APIResponse(http.StatusOK, "Get webhook delivery successfully!", webhook.FormatWebhookDeliveryDetailData(delivery, deliveryLogs))
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (handler *webhookHandler) AdminRedeliverWebhook(ctx *gin.Context) {
+	var reqDetail webhook.RequestGetWebhookDeliveryByID
+	var reqRedeliver webhook.RequestRedeliverWebhook
+
+	err := ctx.ShouldBindUri(&reqDetail)
+
+	if err != nil {
+		errors := helper.FormatValidationError(err)
+		response := helper.APIResponseError(http.StatusUnprocessableEntity, "Redeliver webhook failed!", errors[0])
+		ctx.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	reqRedeliver.User = ctx.MustGet("userData").(user.User)
+
+	delivery, err := handler.webhookSvc.RedeliverWebhook(reqDetail, reqRedeliver)
+
+	if err != nil {
+		if helper.IsErrNoRows(err.Error()) {
+			response := helper.APIResponseError(http.StatusNotFound, "Redeliver webhook failed!", "Data not found!")
+			ctx.JSON(http.StatusNotFound, response)
+			return
+		}
+
+		response := helper.APIResponseError(http.StatusBadRequest, "Redeliver webhook failed!", err.Error())
+		ctx.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	handler.logsSvc.CreateActivityLog(ctx, fmt.Sprintf("%v requeue webhook delivery id %v.", reqRedeliver.User.Name, delivery.ID))
+
+	response := helper.APIResponse(http.StatusOK, "Redeliver webhook successfully!", webhook.FormatWebhookDeliveryData(delivery))
+	ctx.JSON(http.StatusOK, response)
+}
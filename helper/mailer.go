@@ -1,16 +1,21 @@
 package helper
 
-import (
-	"bytes"
-	"context"
-	"fmt"
-	"os"
-	"strconv"
-	"text/template"
-	"time"
-
-	"github.com/mailgun/mailgun-go/v4"
-	"gopkg.in/gomail.v2"
+import "fmt"
+
+// template keys, the content lives in html/<locale>/<key>.html unless an admin published a newer version
+const (
+	EmailTemplateWelcome            = "welcome"
+	EmailTemplateForgotPassword     = "forgot_password"
+	EmailTemplateEmailVerification  = "email_verification"
+	EmailTemplateAccountLocked      = "account_locked"
+	EmailTemplateCampaignActive     = "campaign_active"
+	EmailTemplateCampaignFinished   = "campaign_finished"
+	EmailTemplateEarnReward         = "earn_reward"
+	EmailTemplateRewardUpdate       = "reward_update"
+	EmailTemplateTransactionSuccess = "transaction_success"
+	EmailTemplateWithdrawalRequest  = "withdrawal_request"
+	EmailTemplateWithdrawalApproved = "withdrawal_approved"
+	EmailTemplateWithdrawalRejected = "withdrawal_rejected"
 )
 
 type EmailWelcome struct {
@@ -22,6 +27,18 @@ type EmailForgotPassword struct {
 	URL  string
 }
 
+type EmailVerification struct {
+	Name string
+	URL  string
+}
+
+type EmailAccountLocked struct {
+	Name      string
+	Duration  string
+	IpAddress string
+	URL       string
+}
+
 type EmailCampaignFinished struct {
 	Campaign       any
 	Name           string
@@ -73,78 +90,28 @@ type EmailCampaignActive struct {
 	CampaignLink string
 }
 
-func ParseTemplate(templateFileName string, data any) (string, error) {
-	t, err := template.ParseFiles(templateFileName)
-	if err != nil {
-		return "", err
-	}
-
-	buf := new(bytes.Buffer)
-	if err = t.Execute(buf, data); err != nil {
-		return "", err
-	}
-
-	return buf.String(), nil
+// MailQueue renders a template and stores the email until a worker delivers it, implemented by the mailer package
+type MailQueue interface {
+	QueueTemplateMail(to, locale, templateKey string, data any) error
 }
 
-func SendMail(to string, subject string, data any, templateFile string) {
-	notYetSuccess := true
-	html, err := ParseTemplate(templateFile, data)
+var mailQueue MailQueue
 
-	if err != nil {
-		fmt.Printf("[MAIL] email failed to send to %v while parse HTML template, template: %v, [err: %v]\n", to, templateFile, err.Error())
-		return
-	}
-
-	mailer := gomail.NewMessage()
-	mailer.SetHeader("From", os.Getenv("MAIL_ADDR_HEADER"))
-	mailer.SetHeader("To", to)
-	mailer.SetHeader("Subject", subject)
-	mailer.SetBody("text/html", html)
-
-	p, _ := strconv.Atoi(os.Getenv("MAIL_SMTP_PORT"))
-	dial := gomail.NewDialer(os.Getenv("MAIL_SMTP_HOST"), p, os.Getenv("MAIL_ADDR"), os.Getenv("MAIL_PASS"))
+func SetMailQueue(queue MailQueue) {
+	mailQueue = queue
+}
 
-	if err := dial.DialAndSend(mailer); err == nil {
-		fmt.Printf("[MAIL] email sent successfully to %v, subject: %v, template: %v.\n", to, subject, templateFile)
-		notYetSuccess = false
-	} else {
-		fmt.Printf("[MAIL] email failed to send to %v while dial and send, [err: %v]\n", to, err.Error())
+// SendMail hands the email to the outbox, the subject and body come from the localized template
+// and delivery and retries happen in the mailer worker. An empty locale uses the default locale.
+// The email is stored when it returns, an error means it was not and the caller decides what that costs.
+func SendMail(to string, locale string, templateKey string, data any) error {
+	if mailQueue == nil {
+		return fmt.Errorf("mail queue is not configured, template: %v", templateKey)
 	}
 
-	if notYetSuccess {
-		mailer := gomail.NewMessage()
-		mailer.SetHeader("From", os.Getenv("BACKUP_MAIL_ADDR_HEADER"))
-		mailer.SetHeader("To", to)
-		mailer.SetHeader("Subject", subject)
-		mailer.SetBody("text/html", html)
-
-		p, _ := strconv.Atoi(os.Getenv("BACKUP_MAIL_SMTP_PORT"))
-		dial := gomail.NewDialer(os.Getenv("BACKUP_MAIL_SMTP_HOST"), p, os.Getenv("BACKUP_MAIL_ADDR"), os.Getenv("BACKUP_MAIL_PASS"))
-
-		if err := dial.DialAndSend(mailer); err == nil {
-			fmt.Printf("[MAIL FIRST BACKUP] email sent successfully to %v, subject: %v, template: %v.\n", to, subject, templateFile)
-			notYetSuccess = false
-		} else {
-			fmt.Printf("[MAIL FIRST BACKUP] email failed to send to %v while dial and send, [err: %v]\n", to, err.Error())
-		}
+	if err := mailQueue.QueueTemplateMail(to, locale, templateKey, data); err != nil {
+		return fmt.Errorf("email failed to queue, template: %v, err: %w", templateKey, err)
 	}
 
-	if notYetSuccess {
-		mg := mailgun.NewMailgun(os.Getenv("MAILGUN_DOMAIN"), os.Getenv("MAILGUN_PRIKEY"))
-		msg := mg.NewMessage(os.Getenv("MAILGUN_SENDER"), subject, "", to)
-
-		msg.SetHtml(html)
-
-		ctx, cancel := context.WithTimeout(context.Background(), time.Second*60)
-		defer cancel()
-
-		resp, id, err := mg.Send(ctx, msg)
-
-		if err != nil {
-			fmt.Printf("[MAIL LAST BACKUP] email failed to send to %v, [err: %v]\n", to, err.Error())
-		} else {
-			fmt.Printf("[MAIL LAST BACKUP] email sent successfully to %v, subject: %v, template: %v. [ID: %s Resp: %s]\n", to, subject, templateFile, id, resp)
-		}
-	}
+	return nil
 }
@@ -0,0 +1,35 @@
+package helper
+
+import "fmt"
+
+// Notifier sends one event to every channel its type is declared for (email, in-app, sms, whatsapp, push),
+// implemented by the notification package
+type Notifier interface {
+	Notify(recipient NotificationRecipient, notificationType string, data any) error
+}
+
+var notifier Notifier
+
+func SetNotifier(n Notifier) {
+	notifier = n
+}
+
+type NotificationRecipient struct {
+	UserID int
+	Email  string
+	Locale string
+}
+
+// SendNotification sends the event on all of its channels, the template key doubles as the notification type.
+// Without a notifier it falls back to the email only.
+func SendNotification(recipient NotificationRecipient, templateKey string, data any) error {
+	if notifier == nil {
+		return SendMail(recipient.Email, recipient.Locale, templateKey, data)
+	}
+
+	if err := notifier.Notify(recipient, templateKey, data); err != nil {
+		return fmt.Errorf("notification failed, type: %v, err: %w", templateKey, err)
+	}
+
+	return nil
+}
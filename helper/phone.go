@@ -0,0 +1,22 @@
+package helper
+
+import (
+	"regexp"
+	"strings"
+)
+
+var e164Pattern = regexp.MustCompile(`^\+[1-9][0-9]{7,14}$`)
+
+// NormalizePhoneNumber turns the common indonesian spellings (0812..., 62812..., +62 812-...) into E.164
+func NormalizePhoneNumber(phoneNumber string) (string, bool) {
+	phoneNumber = strings.NewReplacer(" ", "", "-", "", "(", "", ")", "", ".", "").Replace(phoneNumber)
+
+	switch {
+	case strings.HasPrefix(phoneNumber, "0"):
+		phoneNumber = "+62" + strings.TrimPrefix(phoneNumber, "0")
+	case strings.HasPrefix(phoneNumber, "62"):
+		phoneNumber = "+" + phoneNumber
+	}
+
+	return phoneNumber, e164Pattern.MatchString(phoneNumber)
+}
@@ -0,0 +1,20 @@
+package helper
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/thanhpk/randstr"
+)
+
+// one way hash for random tokens that are looked up by value
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func GenerateRecoveryCode() string {
+	code := strings.ToLower(randstr.Hex(5))
+	return code[:5] + "-" + code[5:]
+}
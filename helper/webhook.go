@@ -0,0 +1,94 @@
+package helper
+
+import (
+	"fmt"
+	"time"
+)
+
+// event types partners can subscribe to
+const (
+	WebhookEventDonationPaid            = "donation.paid"
+	WebhookEventCampaignGoalReached     = "campaign.goal_reached"
+	WebhookEventCampaignFinished        = "campaign.finished"
+	WebhookEventWithdrawalApproved      = "withdrawal.approved"
+	WebhookEventExclusiveWinnerSelected = "exclusive.winner_selected"
+)
+
+var WebhookEvents = []string{
+	WebhookEventDonationPaid,
+	WebhookEventCampaignGoalReached,
+	WebhookEventCampaignFinished,
+	WebhookEventWithdrawalApproved,
+	WebhookEventExclusiveWinnerSelected,
+}
+
+type WebhookDonationPaid struct {
+	TransactionID int       `json:"transaction_id"`
+	Code          string    `json:"code"`
+	CampaignID    int       `json:"campaign_id"`
+	Amount        int64     `json:"amount"`
+	Anonymous     bool      `json:"anonymous"`
+	PaidWith      string    `json:"paid_with"`
+	PaidAt        time.Time `json:"paid_at"`
+}
+
+type WebhookCampaignGoalReached struct {
+	CampaignID      int       `json:"campaign_id"`
+	Title           string    `json:"title"`
+	GoalAmount      int64     `json:"goal_amount"`
+	CollectedAmount int64     `json:"collected_amount"`
+	ReachedAt       time.Time `json:"reached_at"`
+}
+
+type WebhookCampaignFinished struct {
+	CampaignID      int       `json:"campaign_id"`
+	Title           string    `json:"title"`
+	GoalAmount      int64     `json:"goal_amount"`
+	CollectedAmount int64     `json:"collected_amount"`
+	AdminFee        int64     `json:"admin_fee"`
+	DisbursedAmount int64     `json:"disbursed_amount"`
+	FinishedAt      time.Time `json:"finished_at"`
+}
+
+type WebhookWithdrawalApproved struct {
+	WithdrawalID int       `json:"withdrawal_id"`
+	UserID       int       `json:"user_id"`
+	Amount       int64     `json:"amount"`
+	ApprovedAt   time.Time `json:"approved_at"`
+}
+
+type WebhookExclusiveWinnerSelected struct {
+	ExclusiveCampaignID int       `json:"exclusive_campaign_id"`
+	CampaignID          int       `json:"campaign_id"`
+	WinnerUserID        int       `json:"winner_user_id"`
+	PrizeTier           int       `json:"prize_tier"`
+	PrizeName           string    `json:"prize_name"`
+	Reward              string    `json:"reward"`
+	IsRewardMoney       bool      `json:"is_reward_money"`
+	SelectedAt          time.Time `json:"selected_at"`
+}
+
+// WebhookPublisher stores one delivery per matching subscription, implemented by the webhook package
+type WebhookPublisher interface {
+	PublishEvent(eventType string, ownerUserID int, data any) error
+}
+
+var webhookPublisher WebhookPublisher
+
+func SetWebhookPublisher(publisher WebhookPublisher) {
+	webhookPublisher = publisher
+}
+
+// PublishWebhookEvent queues the event for the subscriptions of ownerUserID, the user the event is about
+// (campaign owner, withdrawal requester). Delivery and retries happen in the webhook worker.
+func PublishWebhookEvent(eventType string, ownerUserID int, data any) error {
+	if webhookPublisher == nil {
+		return nil
+	}
+
+	if err := webhookPublisher.PublishEvent(eventType, ownerUserID, data); err != nil {
+		return fmt.Errorf("webhook event %v failed to queue, err: %w", eventType, err)
+	}
+
+	return nil
+}
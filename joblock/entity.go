@@ -0,0 +1,10 @@
+package joblock
+
+import "time"
+
+// JobLockFence keeps the last fencing token given out for a job, every new lease of the job takes the next one
+type JobLockFence struct {
+	Name      string    `json:"name" gorm:"primaryKey"`
+	Token     int64     `json:"token"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
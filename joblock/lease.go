@@ -0,0 +1,164 @@
+package joblock
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Fence guards the writes of a job, Check runs inside the write database transaction
+// and fails when a newer lease of the job was given out, even if this process has not noticed yet
+type Fence interface {
+	Err() error
+	Check(tx *gorm.DB) error
+}
+
+// NoFence is for the callers that are not a scheduled job, like an admin action
+type NoFence struct{}
+
+func (NoFence) Err() error              { return nil }
+func (NoFence) Check(tx *gorm.DB) error { return nil }
+
+type Lease struct {
+	name     string
+	token    int64
+	lost     chan struct{}
+	lostOnce sync.Once
+}
+
+func (lease *Lease) Name() string {
+	return lease.name
+}
+
+// Token is the fencing token, it only grows between leases of the same job
+func (lease *Lease) Token() int64 {
+	return lease.token
+}
+
+// Lost is closed when the heartbeat could not extend the lease, the job should stop as soon as it sees it
+func (lease *Lease) Lost() <-chan struct{} {
+	return lease.lost
+}
+
+func (lease *Lease) Err() error {
+	select {
+	case <-lease.lost:
+		return ErrLeaseLost
+	default:
+		return nil
+	}
+}
+
+func (lease *Lease) Check(tx *gorm.DB) error {
+	if err := lease.Err(); err != nil {
+		return err
+	}
+
+	var fence JobLockFence
+
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("name = ?", lease.name).First(&fence).Error; err != nil {
+		return err
+	}
+
+	if fence.Token != lease.token {
+		lease.markLost()
+		return ErrLeaseLost
+	}
+
+	return nil
+}
+
+func (lease *Lease) markLost() {
+	lease.lostOnce.Do(func() {
+		close(lease.lost)
+	})
+}
+
+type Runner struct {
+	locker Locker
+	db     *gorm.DB
+	ttl    time.Duration
+}
+
+// NewRunner runs jobs under a lease of ttl, the heartbeat extends it every third of ttl
+func NewRunner(locker Locker, db *gorm.DB, ttl time.Duration) *Runner {
+	return &Runner{locker: locker, db: db, ttl: ttl}
+}
+
+// Run runs job only when this process gets the lease of name, ran is false when another process holds it.
+// A lease lost while the job runs is returned as ErrLeaseLost.
+func (runner *Runner) Run(name string, job func(lease *Lease) error) (ran bool, err error) {
+	lock, err := runner.locker.Acquire(name, runner.ttl)
+
+	if err == ErrNotAcquired {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	defer func() {
+		if err := lock.Release(); err != nil {
+			log.Printf("[JOB LOCK] %s not released, err: %s", name, err.Error())
+		}
+	}()
+
+	token, err := runner.nextToken(name)
+
+	if err != nil {
+		return false, err
+	}
+
+	lease := &Lease{name: name, token: token, lost: make(chan struct{})}
+	stop := make(chan struct{})
+
+	go runner.heartbeat(lease, lock, stop)
+	defer close(stop)
+
+	err = job(lease)
+
+	if err == nil {
+		err = lease.Err()
+	}
+
+	return true, err
+}
+
+func (runner *Runner) heartbeat(lease *Lease, lock Lock, stop <-chan struct{}) {
+	ticker := time.NewTicker(runner.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-lease.lost:
+			return
+		case <-ticker.C:
+			if err := lock.Refresh(runner.ttl); err != nil {
+				log.Printf("[JOB LOCK] %s lease lost, err: %s", lease.name, err.Error())
+				lease.markLost()
+				return
+			}
+		}
+	}
+}
+
+// nextToken bumps the fence of name, a job still running on an old lease fails its next Check
+func (runner *Runner) nextToken(name string) (int64, error) {
+	var fence JobLockFence
+
+	err := runner.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("INSERT INTO job_lock_fences (name, token, updated_at) VALUES (?, 1, NOW()) ON DUPLICATE KEY UPDATE token = token + 1, updated_at = NOW()", name).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("name = ?", name).First(&fence).Error
+	})
+
+	return fence.Token, err
+}
@@ -0,0 +1,172 @@
+package joblock
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/go-redis/redis"
+	"github.com/thanhpk/randstr"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNotAcquired = errors.New("job lock is held by another process")
+	ErrLeaseLost   = errors.New("job lease lost")
+)
+
+// Locker gives a job to one process at a time across every instance of the server
+type Locker interface {
+	// Acquire returns ErrNotAcquired when another process holds name
+	Acquire(name string, ttl time.Duration) (Lock, error)
+}
+
+type Lock interface {
+	// Refresh extends the lock for ttl, ErrLeaseLost when the lock is not ours anymore
+	Refresh(ttl time.Duration) error
+	Release() error
+}
+
+// the lock value is checked before refresh and release, so a process never touches a lock someone else took over
+const (
+	redisRefreshScript = `if redis.call("get", KEYS[1]) == ARGV[1] then return redis.call("pexpire", KEYS[1], ARGV[2]) else return 0 end`
+	redisReleaseScript = `if redis.call("get", KEYS[1]) == ARGV[1] then return redis.call("del", KEYS[1]) else return 0 end`
+)
+
+type redisLocker struct {
+	client *redis.Client
+	prefix string
+	owner  string
+}
+
+func NewRedisLocker(client *redis.Client) *redisLocker {
+	hostname, _ := os.Hostname()
+
+	return &redisLocker{client: client, prefix: "tcd:joblock:", owner: fmt.Sprintf("%s:%d", hostname, os.Getpid())}
+}
+
+func (locker *redisLocker) Acquire(name string, ttl time.Duration) (Lock, error) {
+	lock := &redisLock{client: locker.client, key: locker.prefix + name, value: locker.owner + ":" + randstr.Hex(8)}
+
+	ok, err := locker.client.SetNX(lock.key, lock.value, ttl).Result()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, ErrNotAcquired
+	}
+
+	return lock, nil
+}
+
+type redisLock struct {
+	client *redis.Client
+	key    string
+	value  string
+}
+
+func (lock *redisLock) Refresh(ttl time.Duration) error {
+	refreshed, err := lock.client.Eval(redisRefreshScript, []string{lock.key}, lock.value, ttl.Milliseconds()).Int64()
+
+	if err != nil {
+		return err
+	}
+
+	if refreshed == 0 {
+		return ErrLeaseLost
+	}
+
+	return nil
+}
+
+func (lock *redisLock) Release() error {
+	return lock.client.Eval(redisReleaseScript, []string{lock.key}, lock.value).Err()
+}
+
+type mysqlLocker struct {
+	db     *gorm.DB
+	prefix string
+}
+
+// NewMySQLLocker uses GET_LOCK, the lock lives as long as the connection that took it,
+// so each lock keeps its own connection out of the pool until it is released
+func NewMySQLLocker(db *gorm.DB) *mysqlLocker {
+	return &mysqlLocker{db: db, prefix: "tcd:joblock:"}
+}
+
+func (locker *mysqlLocker) Acquire(name string, ttl time.Duration) (Lock, error) {
+	sqlDB, err := locker.db.DB()
+
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ttl)
+	defer cancel()
+
+	conn, err := sqlDB.Conn(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	lock := &mysqlLock{conn: conn, name: locker.prefix + name}
+
+	var acquired sql.NullInt64
+
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, 0)", lock.name).Scan(&acquired); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if !acquired.Valid {
+		conn.Close()
+		return nil, fmt.Errorf("GET_LOCK %s failed", lock.name)
+	}
+
+	if acquired.Int64 != 1 {
+		conn.Close()
+		return nil, ErrNotAcquired
+	}
+
+	return lock, nil
+}
+
+type mysqlLock struct {
+	conn *sql.Conn
+	name string
+}
+
+// Refresh only checks the lock is still held by this connection, a dropped connection loses the lock on the server
+func (lock *mysqlLock) Refresh(ttl time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), ttl)
+	defer cancel()
+
+	var owned sql.NullInt64
+
+	if err := lock.conn.QueryRowContext(ctx, "SELECT IS_USED_LOCK(?) = CONNECTION_ID()", lock.name).Scan(&owned); err != nil {
+		return err
+	}
+
+	if !owned.Valid || owned.Int64 != 1 {
+		return ErrLeaseLost
+	}
+
+	return nil
+}
+
+func (lock *mysqlLock) Release() error {
+	defer lock.conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := lock.conn.ExecContext(ctx, "DO RELEASE_LOCK(?)", lock.name)
+
+	return err
+}
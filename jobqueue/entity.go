@@ -0,0 +1,38 @@
+package jobqueue
+
+import (
+	"database/sql"
+	"time"
+)
+
+const (
+	StatusPending    = "pending"
+	StatusProcessing = "processing"
+	StatusSucceeded  = "succeeded"
+	StatusDead       = "dead"
+)
+
+// Job is work a service hands to the queue, the type names the handler and the job is its payload
+type Job interface {
+	JobType() string
+}
+
+// QueuedJob is a job waiting for, or done by, a worker. A job out of attempts is dead and only an admin retry runs it again.
+type QueuedJob struct {
+	ID            int            `json:"id"`
+	JobID         string         `json:"job_id"`
+	JobType       string         `json:"job_type"`
+	Payload       string         `json:"payload"`
+	Status        string         `json:"status"`
+	Attempts      int            `json:"attempts"`
+	NextAttemptAt time.Time      `json:"next_attempt_at"`
+	LastError     sql.NullString `json:"last_error"`
+	FinishedAt    sql.NullTime   `json:"finished_at"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+}
+
+type QueueStats struct {
+	Status string `json:"status"`
+	Count  int64  `json:"count"`
+}
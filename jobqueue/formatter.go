@@ -0,0 +1,48 @@
+package jobqueue
+
+import "time"
+
+type (
+	QueuedJobFormatter struct {
+		ID            int        `json:"id"`
+		JobID         string     `json:"job_id"`
+		JobType       string     `json:"job_type"`
+		Status        string     `json:"status"`
+		Attempts      int        `json:"attempts"`
+		NextAttemptAt time.Time  `json:"next_attempt_at"`
+		LastError     string     `json:"last_error"`
+		FinishedAt    *time.Time `json:"finished_at"`
+		CreatedAt     time.Time  `json:"created_at"`
+	}
+
+	QueuedJobDetailFormatter struct {
+		QueuedJobFormatter
+		Payload string `json:"payload"`
+	}
+)
+
+func FormatQueuedJobData(job QueuedJob) QueuedJobFormatter {
+	formatData := QueuedJobFormatter{
+		ID:            job.ID,
+		JobID:         job.JobID,
+		JobType:       job.JobType,
+		Status:        job.Status,
+		Attempts:      job.Attempts,
+		NextAttemptAt: job.NextAttemptAt,
+		LastError:     job.LastError.String,
+		CreatedAt:     job.CreatedAt,
+	}
+
+	if job.FinishedAt.Valid {
+		formatData.FinishedAt = &job.FinishedAt.Time
+	}
+
+	return formatData
+}
+
+func FormatQueuedJobDetailData(job QueuedJob) QueuedJobDetailFormatter {
+	return QueuedJobDetailFormatter{
+		QueuedJobFormatter: FormatQueuedJobData(job),
+		Payload:            job.Payload,
+	}
+}
@@ -0,0 +1,88 @@
+package jobqueue
+
+import (
+	"log"
+	"time"
+)
+
+// StaleAfter is how long a row may stay claimed, after it the row belongs to a worker that died
+const StaleAfter = 10 * time.Minute
+
+// Poller runs a table of rows that are claimed and processed one at a time, like the email, event and webhook
+// outboxes. The rows keep their own table so their admin pages and retry rules stay their own.
+type Poller[T any] struct {
+	// Name prefixes the log lines
+	Name         string
+	BatchSize    int
+	ReleaseStale func(before time.Time) (int64, error)
+	GetDue       func(now time.Time, limit int) ([]T, error)
+	Claim        func(T) (bool, error)
+	// Process saves the result of the row itself, a failure is retried on the next attempt of the row
+	Process func(T)
+}
+
+// ProcessDue puts back the rows of dead workers, then claims and processes the due rows
+func (poller Poller[T]) ProcessDue() (int, error) {
+	if released, err := poller.ReleaseStale(time.Now().Add(-StaleAfter)); err != nil {
+		return 0, err
+	} else if released > 0 {
+		log.Printf("[%s] %d stale row(s) put back to the queue", poller.Name, released)
+	}
+
+	rows, err := poller.GetDue(time.Now(), poller.BatchSize)
+
+	if err != nil {
+		return 0, err
+	}
+
+	processed := 0
+
+	for _, row := range rows {
+		claimed, err := poller.Claim(row)
+
+		if err != nil {
+			return processed, err
+		}
+
+		if !claimed {
+			continue
+		}
+
+		poller.Process(row)
+		processed++
+	}
+
+	return processed, nil
+}
+
+// Run blocks, call it in its own goroutine. Besides the interval it runs whenever wake receives, wake may be nil.
+func (poller Poller[T]) Run(interval time.Duration, wake <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := poller.ProcessDue(); err != nil {
+			log.Printf("[%s] worker failed, err: %s", poller.Name, err.Error())
+		}
+
+		select {
+		case <-ticker.C:
+		case <-wake:
+		}
+	}
+}
+
+// Backoff doubles from the base delay after every failed attempt, capped at the max delay
+func Backoff(attempts int, base, max time.Duration) time.Duration {
+	delay := base
+
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+
+		if delay >= max {
+			return max
+		}
+	}
+
+	return delay
+}
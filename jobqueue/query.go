@@ -0,0 +1,39 @@
+package jobqueue
+
+const (
+	QueryAdminDataTablesQueuedJob = `
+		SELECT
+			id,
+			job_id,
+			job_type,
+			status,
+			attempts,
+			next_attempt_at,
+			last_error,
+			finished_at,
+			created_at
+		FROM
+			queued_jobs
+		WHERE
+			1 = 1
+	`
+
+	QueryCountAllAdminDataTablesQueuedJob = `
+		SELECT
+			COUNT(id) AS count_id
+		FROM
+			queued_jobs
+		WHERE
+			1 = 1
+	`
+
+	QueryQueuedJobStats = `
+		SELECT
+			status,
+			COUNT(id) AS count
+		FROM
+			queued_jobs
+		GROUP BY
+			status
+	`
+)
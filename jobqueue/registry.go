@@ -0,0 +1,55 @@
+package jobqueue
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+type Handler func(payload json.RawMessage) error
+
+// Registry keeps the handler of every job type, the workers are the only ones calling them
+type Registry struct {
+	mu       sync.RWMutex
+	handlers map[string]Handler
+}
+
+func NewRegistry() *Registry {
+	return &Registry{handlers: map[string]Handler{}}
+}
+
+// Register panics on a job type that already has a handler, a job runs one handler only
+func (registry *Registry) Register(jobType string, handler Handler) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	if _, ok := registry.handlers[jobType]; ok {
+		panic(fmt.Sprintf("job handler for %s already registered", jobType))
+	}
+
+	registry.handlers[jobType] = handler
+}
+
+func (registry *Registry) handlerOf(jobType string) (Handler, bool) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	handler, ok := registry.handlers[jobType]
+
+	return handler, ok
+}
+
+// Handle registers a handler that receives the decoded job instead of the payload
+func Handle[T Job](registry *Registry, handler func(T) error) {
+	var zero T
+
+	registry.Register(zero.JobType(), func(payload json.RawMessage) error {
+		var job T
+
+		if err := json.Unmarshal(payload, &job); err != nil {
+			return err
+		}
+
+		return handler(job)
+	})
+}
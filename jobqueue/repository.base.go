@@ -0,0 +1,29 @@
+package jobqueue
+
+import (
+	"time"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	GetQueuedJobByID(int) (QueuedJob, error)
+	GetDueQueuedJobs(now time.Time, limit int) ([]QueuedJob, error)
+	ClaimQueuedJob(QueuedJob) (bool, error)
+	ReleaseStaleQueuedJobs(before time.Time) (int64, error)
+	SaveQueuedJobs([]QueuedJob) ([]QueuedJob, error)
+	UpdateQueuedJob(QueuedJob) (QueuedJob, error)
+	GetQueuedJobStats() ([]QueueStats, error)
+
+	AdminDataTablesQueuedJob(ctx *gin.Context) (helper.DataTables, error)
+}
+
+type repository struct {
+	DB *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *repository {
+	return &repository{DB: db}
+}
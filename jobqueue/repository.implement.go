@@ -0,0 +1,207 @@
+package jobqueue
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+	"github.com/gin-gonic/gin"
+)
+
+func (repo *repository) GetQueuedJobByID(id int) (job QueuedJob, err error) {
+	if err := repo.DB.Where("id = ?", id).Find(&job).Error; err != nil {
+		return job, err
+	}
+
+	if job.ID == 0 {
+		return job, errors.New("sql: no rows in result set")
+	}
+
+	return job, nil
+}
+
+func (repo *repository) GetDueQueuedJobs(now time.Time, limit int) (jobs []QueuedJob, err error) {
+	if err := repo.DB.Where("status = ? AND next_attempt_at <= ?", StatusPending, now).Order("next_attempt_at ASC, id ASC").Limit(limit).Find(&jobs).Error; err != nil {
+		return jobs, err
+	}
+	return jobs, nil
+}
+
+// ClaimQueuedJob flips pending to processing, false means another worker got there first
+func (repo *repository) ClaimQueuedJob(job QueuedJob) (bool, error) {
+	result := repo.DB.Model(&QueuedJob{}).
+		Where("id = ? AND status = ?", job.ID, StatusPending).
+		Updates(map[string]any{"status": StatusProcessing, "updated_at": time.Now()})
+
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+// ReleaseStaleQueuedJobs puts back jobs left in processing by a process that died mid run
+func (repo *repository) ReleaseStaleQueuedJobs(before time.Time) (int64, error) {
+	result := repo.DB.Model(&QueuedJob{}).
+		Where("status = ? AND updated_at < ?", StatusProcessing, before).
+		Updates(map[string]any{"status": StatusPending, "updated_at": time.Now()})
+
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
+
+func (repo *repository) SaveQueuedJobs(jobs []QueuedJob) ([]QueuedJob, error) {
+	if err := repo.DB.Create(&jobs).Error; err != nil {
+		return jobs, err
+	}
+	return jobs, nil
+}
+
+func (repo *repository) UpdateQueuedJob(job QueuedJob) (QueuedJob, error) {
+	if err := repo.DB.Save(&job).Error; err != nil {
+		return job, err
+	}
+	return job, nil
+}
+
+func (repo *repository) GetQueuedJobStats() (stats []QueueStats, err error) {
+	if err := repo.DB.Raw(helper.ConvertToInLineQuery(QueryQueuedJobStats)).Scan(&stats).Error; err != nil {
+		return stats, err
+	}
+	return stats, nil
+}
+
+// besides the datatables params it filters by the status and job_type query params
+func (repo *repository) AdminDataTablesQueuedJob(ctx *gin.Context) (result helper.DataTables, err error) {
+	var (
+		query string = QueryAdminDataTablesQueuedJob
+		where string = ""
+		order string = ""
+		limit string = ""
+	)
+
+	var (
+		no       int = 1
+		total    int = 0
+		filtered int = 0
+	)
+
+	var (
+		data []map[string]any
+		args []any
+	)
+
+	listOrder := []string{"", "job_id", "job_type", "status", "attempts", "next_attempt_at", "finished_at", "created_at", ""}
+
+	if status := ctx.Query("status"); status != "" {
+		where = fmt.Sprintf("%s AND status = ?", where)
+		args = append(args, status)
+	}
+
+	if jobType := ctx.Query("job_type"); jobType != "" {
+		where = fmt.Sprintf("%s AND job_type = ?", where)
+		args = append(args, jobType)
+	}
+
+	if searchValue := ctx.Query("search[value]"); searchValue != "" {
+		where = fmt.Sprintf("%s AND (job_id LIKE ? OR job_type LIKE ? OR payload LIKE ? OR last_error LIKE ?)", where)
+		for i := 0; i < 4; i++ {
+			args = append(args, "%"+searchValue+"%")
+		}
+	}
+
+	orderColumn := ctx.Query("order[0][column]")
+	starting, _ := strconv.Atoi(ctx.Query("start"))
+
+	if orderColumn != "" {
+		orderType := "ASC"
+		orderColumn, _ := strconv.Atoi(orderColumn)
+
+		if strings.ToUpper(ctx.Query("order[0][dir]")) == "DESC" {
+			orderType = "DESC"
+		}
+
+		if orderColumn > 0 && orderColumn < len(listOrder) && listOrder[orderColumn] != "" {
+			order = fmt.Sprintf("ORDER BY %s %s", listOrder[orderColumn], orderType)
+		} else {
+			order = "ORDER BY id DESC"
+		}
+	} else {
+		order = "ORDER BY id DESC"
+	}
+
+	if starting != -1 {
+		length, _ := strconv.Atoi(ctx.Query("length"))
+		limit = fmt.Sprintf("LIMIT %v OFFSET %v", length, starting)
+		no = starting + 1
+	}
+
+	if err := repo.DB.Raw(helper.ConvertToInLineQuery(QueryCountAllAdminDataTablesQueuedJob)).Scan(&total).Error; err != nil {
+		return result, err
+	}
+
+	if where != "" {
+		query = query + where
+
+		if err := repo.DB.Raw(helper.ConvertToInLineQuery(QueryCountAllAdminDataTablesQueuedJob)+where, args...).Scan(&filtered).Error; err != nil {
+			return result, err
+		}
+	} else {
+		filtered = total
+	}
+
+	query = fmt.Sprintf("%s %s %s", query, order, limit)
+
+	rows, err := repo.DB.Raw(helper.ConvertToInLineQuery(query), args...).Rows()
+
+	if err != nil {
+		return result, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		tmp := QueuedJob{}
+
+		err := rows.Scan(
+			&tmp.ID,
+			&tmp.JobID,
+			&tmp.JobType,
+			&tmp.Status,
+			&tmp.Attempts,
+			&tmp.NextAttemptAt,
+			&tmp.LastError,
+			&tmp.FinishedAt,
+			&tmp.CreatedAt,
+		)
+
+		if err != nil {
+			return result, err
+		}
+
+		formatData := FormatQueuedJobData(tmp)
+
+		data = append(data, map[string]any{
+			"no":              no,
+			"id":              formatData.ID,
+			"job_id":          formatData.JobID,
+			"job_type":        formatData.JobType,
+			"status":          formatData.Status,
+			"attempts":        formatData.Attempts,
+			"next_attempt_at": formatData.NextAttemptAt,
+			"last_error":      formatData.LastError,
+			"finished_at":     formatData.FinishedAt,
+			"created_at":      formatData.CreatedAt,
+		})
+
+		no++
+	}
+
+	return helper.BuildDatatTables(data, filtered, total), nil
+}
@@ -0,0 +1,13 @@
+package jobqueue
+
+import "github.com/WeAreAmazingTeam/tcd-backend/user"
+
+type (
+	RequestGetQueuedJobByID struct {
+		ID int `uri:"id" binding:"required"`
+	}
+
+	RequestRetryQueuedJob struct {
+		User user.User
+	}
+)
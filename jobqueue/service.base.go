@@ -0,0 +1,43 @@
+package jobqueue
+
+import (
+	"time"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/audit"
+	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+	"github.com/gin-gonic/gin"
+)
+
+type Service interface {
+	Enqueue(jobs ...Job) error
+	RunWorkers(interval time.Duration)
+
+	GetQueuedJobDetail(RequestGetQueuedJobByID) (QueuedJobDetailFormatter, error)
+	GetQueueStats() ([]QueueStats, error)
+	RetryQueuedJob(RequestGetQueuedJobByID, RequestRetryQueuedJob) (QueuedJob, error)
+	AdminDataTablesQueuedJob(*gin.Context) (helper.DataTables, error)
+}
+
+type Config struct {
+	// Concurrency is how many jobs this process runs at the same time
+	Concurrency int
+	MaxAttempts int
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+}
+
+type service struct {
+	repo     Repository
+	registry *Registry
+	config   Config
+	auditSvc audit.Service
+}
+
+func NewService(repository Repository, registry *Registry, config Config, auditService audit.Service) *service {
+	return &service{
+		repo:     repository,
+		registry: registry,
+		config:   config,
+		auditSvc: auditService,
+	}
+}
@@ -0,0 +1,229 @@
+package jobqueue
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/audit"
+	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+	"github.com/gin-gonic/gin"
+	"github.com/thanhpk/randstr"
+)
+
+var wake = make(chan struct{}, 1)
+
+// Enqueue stores the jobs and returns, a worker runs them as soon as it has a free slot
+func (svc *service) Enqueue(jobs ...Job) error {
+	queuedJobs := []QueuedJob{}
+
+	for _, job := range jobs {
+		payload, err := json.Marshal(job)
+
+		if err != nil {
+			return err
+		}
+
+		queuedJobs = append(queuedJobs, QueuedJob{
+			JobID:         "job_" + randstr.Hex(12),
+			JobType:       job.JobType(),
+			Payload:       string(payload),
+			Status:        StatusPending,
+			NextAttemptAt: time.Now(),
+		})
+	}
+
+	if len(queuedJobs) == 0 {
+		return nil
+	}
+
+	if _, err := svc.repo.SaveQueuedJobs(queuedJobs); err != nil {
+		return err
+	}
+
+	Wake()
+
+	return nil
+}
+
+// Wake lets the workers pick up new jobs now instead of on their next tick
+func Wake() {
+	select {
+	case wake <- struct{}{}:
+	default:
+	}
+}
+
+// RunWorkers blocks, call it in its own goroutine. It claims due jobs while fewer than
+// Concurrency are running and looks again on the interval, on Wake and when a job is done.
+func (svc *service) RunWorkers(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	slots := make(chan struct{}, svc.config.Concurrency)
+
+	for {
+		if err := svc.fill(slots); err != nil {
+			log.Printf("[JOB QUEUE] worker failed, err: %s", err.Error())
+		}
+
+		select {
+		case <-ticker.C:
+		case <-wake:
+		}
+	}
+}
+
+func (svc *service) fill(slots chan struct{}) error {
+	if released, err := svc.repo.ReleaseStaleQueuedJobs(time.Now().Add(-StaleAfter)); err != nil {
+		return err
+	} else if released > 0 {
+		log.Printf("[JOB QUEUE] %d stale job(s) put back to the queue", released)
+	}
+
+	free := cap(slots) - len(slots)
+
+	if free <= 0 {
+		return nil
+	}
+
+	jobs, err := svc.repo.GetDueQueuedJobs(time.Now(), free)
+
+	if err != nil {
+		return err
+	}
+
+	for _, job := range jobs {
+		claimed, err := svc.repo.ClaimQueuedJob(job)
+
+		if err != nil {
+			return err
+		}
+
+		if !claimed {
+			continue
+		}
+
+		slots <- struct{}{}
+
+		go func(job QueuedJob) {
+			defer func() {
+				<-slots
+				Wake()
+			}()
+
+			svc.process(job)
+		}(job)
+	}
+
+	return nil
+}
+
+func (svc *service) GetQueuedJobDetail(req RequestGetQueuedJobByID) (QueuedJobDetailFormatter, error) {
+	job, err := svc.repo.GetQueuedJobByID(req.ID)
+
+	if err != nil {
+		return QueuedJobDetailFormatter{}, err
+	}
+
+	return FormatQueuedJobDetailData(job), nil
+}
+
+func (svc *service) GetQueueStats() ([]QueueStats, error) {
+	stats, err := svc.repo.GetQueuedJobStats()
+
+	if err != nil {
+		return stats, err
+	}
+
+	return stats, nil
+}
+
+// RetryQueuedJob takes a dead job out of the dead letter with a fresh set of attempts
+func (svc *service) RetryQueuedJob(reqDetail RequestGetQueuedJobByID, reqRetry RequestRetryQueuedJob) (QueuedJob, error) {
+	job, err := svc.repo.GetQueuedJobByID(reqDetail.ID)
+
+	if err != nil {
+		return job, err
+	}
+
+	if job.Status != StatusDead {
+		return job, errors.New("only dead jobs can be retried")
+	}
+
+	before := job
+	job.Status = StatusPending
+	job.Attempts = 0
+	job.NextAttemptAt = time.Now()
+	job.LastError = sql.NullString{}
+	job.FinishedAt = sql.NullTime{}
+
+	job, err = svc.repo.UpdateQueuedJob(job)
+
+	if err != nil {
+		return job, err
+	}
+
+	audit.Record(svc.auditSvc, reqRetry.User, audit.ActionUpdate, audit.EntityQueuedJob, job.ID, before, job)
+
+	Wake()
+
+	return job, nil
+}
+
+func (svc *service) AdminDataTablesQueuedJob(ctx *gin.Context) (helper.DataTables, error) {
+	dataTablesQueuedJob, err := svc.repo.AdminDataTablesQueuedJob(ctx)
+
+	if err != nil {
+		return dataTablesQueuedJob, err
+	}
+
+	return dataTablesQueuedJob, nil
+}
+
+// process runs the handler of a claimed job, a failure is retried with backoff until the job is dead.
+// A type without a handler is a failure too, another instance of a newer version may have one.
+func (svc *service) process(job QueuedJob) {
+	err := errors.New("no handler registered for " + job.JobType)
+
+	if handler, ok := svc.registry.handlerOf(job.JobType); ok {
+		err = call(handler, json.RawMessage(job.Payload))
+	}
+
+	job.Attempts++
+
+	if err == nil {
+		job.Status = StatusSucceeded
+		job.LastError = sql.NullString{}
+		job.FinishedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	} else {
+		log.Printf("[JOB QUEUE] %v %v failed, err: %s", job.JobType, job.JobID, err.Error())
+
+		job.Status = StatusPending
+		job.LastError = helper.SetNS(err.Error())
+		job.NextAttemptAt = time.Now().Add(Backoff(job.Attempts, svc.config.BackoffBase, svc.config.BackoffMax))
+
+		if job.Attempts >= svc.config.MaxAttempts {
+			job.Status = StatusDead
+			job.FinishedAt = sql.NullTime{Time: time.Now(), Valid: true}
+		}
+	}
+
+	if _, err := svc.repo.UpdateQueuedJob(job); err != nil {
+		log.Printf("[JOB QUEUE] job %d status not saved, err: %s", job.ID, err.Error())
+	}
+}
+
+// a panicking handler is a failed attempt, not a dead worker
+func call(handler Handler, payload json.RawMessage) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("panic: %v", recovered)
+		}
+	}()
+
+	return handler(payload)
+}
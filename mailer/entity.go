@@ -0,0 +1,74 @@
+package mailer
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/constant"
+)
+
+const (
+	StatusPending = "pending"
+	StatusSending = "sending"
+	StatusSent    = "sent"
+	StatusFailed  = "failed"
+	// the recipient is on the suppression list, the message is kept but never sent
+	StatusSuppressed = "suppressed"
+)
+
+const (
+	SuppressionReasonHardBounce = "hard_bounce"
+	SuppressionReasonComplaint  = "complaint"
+
+	DeliverabilityDeliverable = "deliverable"
+	DeliverabilityFailing     = "failing"
+	DeliverabilitySuppressed  = "suppressed"
+)
+
+type (
+	// EmailOutbox keeps the rendered email, so a queued message survives a restart even if its template changes
+	EmailOutbox struct {
+		ID              int            `json:"id"`
+		Recipient       string         `json:"recipient"`
+		Subject         string         `json:"subject"`
+		Template        string         `json:"template"`
+		TemplateVersion int            `json:"template_version"`
+		Locale          string         `json:"locale"`
+		Body            string         `json:"-"`
+		TextBody        string         `json:"-"`
+		ListUnsubscribe string         `json:"-"`
+		Status          string         `json:"status"`
+		Attempts        int            `json:"attempts"`
+		MaxAttempts     int            `json:"max_attempts"`
+		NextAttemptAt   time.Time      `json:"next_attempt_at"`
+		LastError       sql.NullString `json:"last_error"`
+		Provider        sql.NullString `json:"provider"`
+		SentAt          sql.NullTime   `json:"sent_at"`
+		CreatedAt       time.Time      `json:"created_at"`
+		UpdatedAt       time.Time      `json:"updated_at"`
+	}
+
+	// EmailTemplate is one published version of a template for one locale, at most one version per
+	// template_key and locale is active. Without an active row the file in html/<locale>/ is used as version 0.
+	EmailTemplate struct {
+		ID          int    `json:"id"`
+		TemplateKey string `json:"template_key"`
+		Locale      string `json:"locale"`
+		Version     int    `json:"version"`
+		Subject     string `json:"subject"`
+		HTMLBody    string `json:"html_body" gorm:"column:html_body"`
+		TextBody    string `json:"text_body"`
+		IsActive    bool   `json:"is_active"`
+		constant.CreatedDeleted
+	}
+
+	// EmailSuppression stops every email to the address, clearing it is a soft delete so the history stays
+	EmailSuppression struct {
+		ID          int    `json:"id"`
+		Email       string `json:"email"`
+		Reason      string `json:"reason"`
+		Description string `json:"description"`
+		Source      string `json:"source"`
+		constant.CreatedDeleted
+	}
+)
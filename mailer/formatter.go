@@ -0,0 +1,138 @@
+package mailer
+
+import "time"
+
+type EmailOutboxFormatter struct {
+	ID            int        `json:"id"`
+	Recipient     string     `json:"recipient"`
+	Subject       string     `json:"subject"`
+	Template      string     `json:"template"`
+	Version       int        `json:"template_version"`
+	Locale        string     `json:"locale"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	MaxAttempts   int        `json:"max_attempts"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	LastError     string     `json:"last_error"`
+	Provider      string     `json:"provider"`
+	SentAt        *time.Time `json:"sent_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+func FormatEmailOutboxData(outbox EmailOutbox) EmailOutboxFormatter {
+	formatData := EmailOutboxFormatter{
+		ID:            outbox.ID,
+		Recipient:     outbox.Recipient,
+		Subject:       outbox.Subject,
+		Template:      outbox.Template,
+		Version:       outbox.TemplateVersion,
+		Locale:        outbox.Locale,
+		Status:        outbox.Status,
+		Attempts:      outbox.Attempts,
+		MaxAttempts:   outbox.MaxAttempts,
+		NextAttemptAt: outbox.NextAttemptAt,
+		LastError:     outbox.LastError.String,
+		Provider:      outbox.Provider.String,
+		CreatedAt:     outbox.CreatedAt,
+	}
+
+	if outbox.SentAt.Valid {
+		formatData.SentAt = &outbox.SentAt.Time
+	}
+
+	return formatData
+}
+
+type (
+	EmailTemplateFormatter struct {
+		ID          int    `json:"id"`
+		TemplateKey string `json:"template_key"`
+		Locale      string `json:"locale"`
+		Version     int    `json:"version"`
+		Subject     string `json:"subject"`
+		HTMLBody    string `json:"html_body"`
+		TextBody    string `json:"text_body"`
+		IsActive    bool   `json:"is_active"`
+		CreatedBy   string `json:"created_by"`
+	}
+
+	// EmailTemplateSummaryFormatter tells which version is used per locale, version 0 is the file in html/
+	EmailTemplateSummaryFormatter struct {
+		TemplateKey    string         `json:"template_key"`
+		ActiveVersions map[string]int `json:"active_versions"`
+	}
+
+	EmailTemplatePreviewFormatter struct {
+		Version int    `json:"version"`
+		Subject string `json:"subject"`
+		HTML    string `json:"html"`
+		Text    string `json:"text"`
+	}
+)
+
+func FormatEmailTemplateData(tpl EmailTemplate) EmailTemplateFormatter {
+	return EmailTemplateFormatter{
+		ID:          tpl.ID,
+		TemplateKey: tpl.TemplateKey,
+		Locale:      tpl.Locale,
+		Version:     tpl.Version,
+		Subject:     tpl.Subject,
+		HTMLBody:    tpl.HTMLBody,
+		TextBody:    tpl.TextBody,
+		IsActive:    tpl.IsActive,
+		CreatedBy:   tpl.CreatedBy.String,
+	}
+}
+
+func FormatListEmailTemplateData(templates []EmailTemplate) (response []EmailTemplateFormatter) {
+	for _, val := range templates {
+		response = append(response, FormatEmailTemplateData(val))
+	}
+
+	if len(response) == 0 {
+		return []EmailTemplateFormatter{}
+	}
+
+	return response
+}
+
+func FormatEmailTemplatePreview(rendered RenderedEmail) EmailTemplatePreviewFormatter {
+	return EmailTemplatePreviewFormatter{
+		Version: rendered.Version,
+		Subject: rendered.Subject,
+		HTML:    rendered.HTML,
+		Text:    rendered.Text,
+	}
+}
+
+type (
+	EmailSuppressionFormatter struct {
+		ID          int       `json:"id"`
+		Email       string    `json:"email"`
+		Reason      string    `json:"reason"`
+		Description string    `json:"description"`
+		Source      string    `json:"source"`
+		CreatedAt   time.Time `json:"created_at"`
+	}
+
+	// DeliverabilityFormatter tells a user whether our emails reach them, status is deliverable, failing or suppressed
+	DeliverabilityFormatter struct {
+		Email           string     `json:"email"`
+		Status          string     `json:"status"`
+		Reason          string     `json:"reason"`
+		SuppressedAt    *time.Time `json:"suppressed_at"`
+		LastEmailStatus string     `json:"last_email_status"`
+		LastEmailAt     *time.Time `json:"last_email_at"`
+	}
+)
+
+func FormatEmailSuppressionData(suppression EmailSuppression) EmailSuppressionFormatter {
+	return EmailSuppressionFormatter{
+		ID:          suppression.ID,
+		Email:       suppression.Email,
+		Reason:      suppression.Reason,
+		Description: suppression.Description,
+		Source:      suppression.Source,
+		CreatedAt:   suppression.CreatedAt.Time,
+	}
+}
@@ -0,0 +1,175 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/mailgun/mailgun-go/v4"
+	"gopkg.in/gomail.v2"
+)
+
+const (
+	ProviderSMTP       = "smtp"
+	ProviderBackupSMTP = "backup_smtp"
+	ProviderMailgun    = "mailgun"
+	ProviderFile       = "file"
+)
+
+type (
+	Message struct {
+		To      string
+		Subject string
+		HTML    string
+		Text    string
+		// one-click unsubscribe url, empty for transactional emails
+		ListUnsubscribe string
+	}
+
+	// Provider delivers one message, an error means the message was not accepted and can be retried elsewhere
+	Provider interface {
+		Name() string
+		Send(ctx context.Context, msg Message) error
+	}
+
+	smtpProvider struct {
+		name     string
+		header   string
+		host     string
+		port     int
+		username string
+		password string
+	}
+
+	mailgunProvider struct {
+		domain string
+		key    string
+		sender string
+	}
+
+	fileProvider struct {
+		dir string
+	}
+)
+
+// NewProvider builds a provider by name, the smtp and mailgun credentials are the same env keys SendMail always used
+func NewProvider(name string, fileSinkDir string) (Provider, error) {
+	switch name {
+	case ProviderSMTP:
+		port, _ := strconv.Atoi(os.Getenv("MAIL_SMTP_PORT"))
+		return &smtpProvider{
+			name:     name,
+			header:   os.Getenv("MAIL_ADDR_HEADER"),
+			host:     os.Getenv("MAIL_SMTP_HOST"),
+			port:     port,
+			username: os.Getenv("MAIL_ADDR"),
+			password: os.Getenv("MAIL_PASS"),
+		}, nil
+	case ProviderBackupSMTP:
+		port, _ := strconv.Atoi(os.Getenv("BACKUP_MAIL_SMTP_PORT"))
+		return &smtpProvider{
+			name:     name,
+			header:   os.Getenv("BACKUP_MAIL_ADDR_HEADER"),
+			host:     os.Getenv("BACKUP_MAIL_SMTP_HOST"),
+			port:     port,
+			username: os.Getenv("BACKUP_MAIL_ADDR"),
+			password: os.Getenv("BACKUP_MAIL_PASS"),
+		}, nil
+	case ProviderMailgun:
+		return &mailgunProvider{
+			domain: os.Getenv("MAILGUN_DOMAIN"),
+			key:    os.Getenv("MAILGUN_PRIKEY"),
+			sender: os.Getenv("MAILGUN_SENDER"),
+		}, nil
+	case ProviderFile:
+		return &fileProvider{dir: fileSinkDir}, nil
+	}
+
+	return nil, fmt.Errorf("unknown mail provider %q", name)
+}
+
+func (provider *smtpProvider) Name() string {
+	return provider.name
+}
+
+func (provider *smtpProvider) Send(ctx context.Context, msg Message) error {
+	if provider.host == "" {
+		return errors.New("smtp host is not configured")
+	}
+
+	mailer := gomail.NewMessage()
+	mailer.SetHeader("From", provider.header)
+	mailer.SetHeader("To", msg.To)
+	mailer.SetHeader("Subject", msg.Subject)
+
+	if msg.ListUnsubscribe != "" {
+		mailer.SetHeader("List-Unsubscribe", "<"+msg.ListUnsubscribe+">")
+		mailer.SetHeader("List-Unsubscribe-Post", "List-Unsubscribe=One-Click")
+	}
+
+	mailer.SetBody("text/plain", msg.Text)
+	mailer.AddAlternative("text/html", msg.HTML)
+
+	dial := gomail.NewDialer(provider.host, provider.port, provider.username, provider.password)
+
+	return dial.DialAndSend(mailer)
+}
+
+func (provider *mailgunProvider) Name() string {
+	return ProviderMailgun
+}
+
+func (provider *mailgunProvider) Send(ctx context.Context, msg Message) error {
+	if provider.domain == "" {
+		return errors.New("mailgun domain is not configured")
+	}
+
+	mg := mailgun.NewMailgun(provider.domain, provider.key)
+	message := mg.NewMessage(provider.sender, msg.Subject, msg.Text, msg.To)
+	message.SetHtml(msg.HTML)
+
+	if msg.ListUnsubscribe != "" {
+		message.AddHeader("List-Unsubscribe", "<"+msg.ListUnsubscribe+">")
+		message.AddHeader("List-Unsubscribe-Post", "List-Unsubscribe=One-Click")
+	}
+
+	_, _, err := mg.Send(ctx, message)
+
+	return err
+}
+
+func (provider *fileProvider) Name() string {
+	return ProviderFile
+}
+
+const fileSinkBoundary = "tcd-mail-boundary"
+
+var unsafeFileChars = regexp.MustCompile(`[^a-zA-Z0-9@._-]`)
+
+// Send writes the message as an .eml file, handy for development where nothing should leave the machine
+func (provider *fileProvider) Send(ctx context.Context, msg Message) error {
+	if err := os.MkdirAll(provider.dir, 0o755); err != nil {
+		return err
+	}
+
+	fileName := fmt.Sprintf("%d_%s.eml", time.Now().UnixNano(), unsafeFileChars.ReplaceAllString(msg.To, "_"))
+	headers := fmt.Sprintf("To: %s\r\nSubject: %s\r\n", msg.To, msg.Subject)
+
+	if msg.ListUnsubscribe != "" {
+		headers += fmt.Sprintf("List-Unsubscribe: <%s>\r\nList-Unsubscribe-Post: List-Unsubscribe=One-Click\r\n", msg.ListUnsubscribe)
+	}
+
+	content := headers + fmt.Sprintf(
+		"MIME-Version: 1.0\r\nContent-Type: multipart/alternative; boundary=%q\r\n\r\n"+
+			"--%[1]s\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%[2]s\r\n"+
+			"--%[1]s\r\nContent-Type: text/html; charset=UTF-8\r\n\r\n%[3]s\r\n--%[1]s--\r\n",
+		fileSinkBoundary, msg.Text, msg.HTML,
+	)
+
+	return os.WriteFile(filepath.Join(provider.dir, fileName), []byte(content), 0o644)
+}
@@ -0,0 +1,59 @@
+package mailer
+
+const (
+	QueryAdminDataTablesEmailOutbox = `
+		SELECT
+			id,
+			recipient,
+			subject,
+			template,
+			template_version,
+			locale,
+			status,
+			attempts,
+			max_attempts,
+			next_attempt_at,
+			last_error,
+			provider,
+			sent_at,
+			created_at
+		FROM
+			email_outboxes
+		WHERE
+			1 = 1
+	`
+
+	QueryCountAllAdminDataTablesEmailOutbox = `
+		SELECT
+			COUNT(id) AS count_id
+		FROM
+			email_outboxes
+		WHERE
+			1 = 1
+	`
+)
+
+const (
+	QueryAdminDataTablesEmailSuppression = `
+		SELECT
+			id,
+			email,
+			reason,
+			description,
+			source,
+			created_at
+		FROM
+			email_suppressions
+		WHERE
+			deleted_at IS NULL
+	`
+
+	QueryCountAllAdminDataTablesEmailSuppression = `
+		SELECT
+			COUNT(id) AS count_id
+		FROM
+			email_suppressions
+		WHERE
+			deleted_at IS NULL
+	`
+)
@@ -0,0 +1,44 @@
+package mailer
+
+import (
+	"time"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	GetEmailOutboxByID(int) (EmailOutbox, error)
+	GetDueEmailOutbox(now time.Time, limit int) ([]EmailOutbox, error)
+	ClaimEmailOutbox(EmailOutbox) (bool, error)
+	ReleaseStaleEmailOutbox(before time.Time) (int64, error)
+	SaveEmailOutbox(EmailOutbox) (EmailOutbox, error)
+	UpdateEmailOutbox(EmailOutbox) (EmailOutbox, error)
+	GetLatestEmailOutboxByRecipient(email string) (EmailOutbox, error)
+
+	GetEmailTemplateByID(int) (EmailTemplate, error)
+	GetActiveEmailTemplate(key, locale string) (EmailTemplate, error)
+	GetAllActiveEmailTemplate() ([]EmailTemplate, error)
+	GetEmailTemplateVersions(key, locale string) ([]EmailTemplate, error)
+	GetEmailTemplateByVersion(key, locale string, version int) (EmailTemplate, error)
+	GetLatestEmailTemplateVersion(key, locale string) (int, error)
+	SaveEmailTemplate(EmailTemplate) (EmailTemplate, error)
+	ActivateEmailTemplate(EmailTemplate) (EmailTemplate, error)
+
+	GetEmailSuppressionByID(int) (EmailSuppression, error)
+	GetEmailSuppressionByEmail(email string) (EmailSuppression, error)
+	SaveEmailSuppression(EmailSuppression) (EmailSuppression, error)
+	DeleteEmailSuppression(EmailSuppression) (bool, error)
+
+	AdminDataTablesEmailOutbox(ctx *gin.Context) (helper.DataTables, error)
+	AdminDataTablesEmailSuppression(ctx *gin.Context) (helper.DataTables, error)
+}
+
+type repository struct {
+	DB *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *repository {
+	return &repository{DB: db}
+}
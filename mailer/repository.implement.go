@@ -0,0 +1,473 @@
+package mailer
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/constant"
+	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func (repo *repository) GetEmailOutboxByID(id int) (outbox EmailOutbox, err error) {
+	if err := repo.DB.Where("id = ?", id).Find(&outbox).Error; err != nil {
+		return outbox, err
+	}
+
+	if outbox.ID == 0 {
+		return outbox, errors.New("sql: no rows in result set")
+	}
+
+	return outbox, nil
+}
+
+func (repo *repository) GetDueEmailOutbox(now time.Time, limit int) (outboxes []EmailOutbox, err error) {
+	if err := repo.DB.Where("status = ? AND next_attempt_at <= ?", StatusPending, now).Order("next_attempt_at ASC").Limit(limit).Find(&outboxes).Error; err != nil {
+		return outboxes, err
+	}
+	return outboxes, nil
+}
+
+// ClaimEmailOutbox flips pending to sending, false means another worker got there first
+func (repo *repository) ClaimEmailOutbox(outbox EmailOutbox) (bool, error) {
+	result := repo.DB.Model(&EmailOutbox{}).
+		Where("id = ? AND status = ?", outbox.ID, StatusPending).
+		Updates(map[string]any{"status": StatusSending, "updated_at": time.Now()})
+
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+// ReleaseStaleEmailOutbox puts back messages left in sending by a process that died mid delivery
+func (repo *repository) ReleaseStaleEmailOutbox(before time.Time) (int64, error) {
+	result := repo.DB.Model(&EmailOutbox{}).
+		Where("status = ? AND updated_at < ?", StatusSending, before).
+		Updates(map[string]any{"status": StatusPending, "updated_at": time.Now()})
+
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
+
+func (repo *repository) SaveEmailOutbox(outbox EmailOutbox) (EmailOutbox, error) {
+	if err := repo.DB.Create(&outbox).Error; err != nil {
+		return outbox, err
+	}
+	return outbox, nil
+}
+
+func (repo *repository) UpdateEmailOutbox(outbox EmailOutbox) (EmailOutbox, error) {
+	if err := repo.DB.Save(&outbox).Error; err != nil {
+		return outbox, err
+	}
+	return outbox, nil
+}
+
+func (repo *repository) GetLatestEmailOutboxByRecipient(email string) (outbox EmailOutbox, err error) {
+	if err := repo.DB.Where("recipient = ?", email).Order("id DESC").Limit(1).Find(&outbox).Error; err != nil {
+		return outbox, err
+	}
+
+	if outbox.ID == 0 {
+		return outbox, errors.New("sql: no rows in result set")
+	}
+
+	return outbox, nil
+}
+
+func (repo *repository) GetEmailTemplateByID(id int) (tpl EmailTemplate, err error) {
+	if err := repo.DB.Where("id = ?", id).Find(&tpl).Error; err != nil {
+		return tpl, err
+	}
+
+	if tpl.ID == 0 {
+		return tpl, errors.New("sql: no rows in result set")
+	}
+
+	return tpl, nil
+}
+
+func (repo *repository) GetActiveEmailTemplate(key, locale string) (tpl EmailTemplate, err error) {
+	if err := repo.DB.Where("template_key = ? AND locale = ? AND is_active = ?", key, locale, true).Find(&tpl).Error; err != nil {
+		return tpl, err
+	}
+
+	if tpl.ID == 0 {
+		return tpl, errors.New("sql: no rows in result set")
+	}
+
+	return tpl, nil
+}
+
+func (repo *repository) GetAllActiveEmailTemplate() (templates []EmailTemplate, err error) {
+	if err := repo.DB.Where("is_active = ?", true).Find(&templates).Error; err != nil {
+		return templates, err
+	}
+	return templates, nil
+}
+
+func (repo *repository) GetEmailTemplateVersions(key, locale string) (templates []EmailTemplate, err error) {
+	if err := repo.DB.Where("template_key = ? AND locale = ?", key, locale).Order("version DESC").Find(&templates).Error; err != nil {
+		return templates, err
+	}
+	return templates, nil
+}
+
+func (repo *repository) GetEmailTemplateByVersion(key, locale string, version int) (tpl EmailTemplate, err error) {
+	if err := repo.DB.Where("template_key = ? AND locale = ? AND version = ?", key, locale, version).Find(&tpl).Error; err != nil {
+		return tpl, err
+	}
+
+	if tpl.ID == 0 {
+		return tpl, errors.New("sql: no rows in result set")
+	}
+
+	return tpl, nil
+}
+
+func (repo *repository) GetLatestEmailTemplateVersion(key, locale string) (version int, err error) {
+	if err := repo.DB.Model(&EmailTemplate{}).Unscoped().Where("template_key = ? AND locale = ?", key, locale).Select("COALESCE(MAX(version), 0)").Scan(&version).Error; err != nil {
+		return version, err
+	}
+	return version, nil
+}
+
+func (repo *repository) SaveEmailTemplate(tpl EmailTemplate) (EmailTemplate, error) {
+	if err := repo.DB.Create(&tpl).Error; err != nil {
+		return tpl, err
+	}
+	return tpl, nil
+}
+
+// ActivateEmailTemplate switches the active version in one transaction so sending never sees two or none
+func (repo *repository) ActivateEmailTemplate(tpl EmailTemplate) (EmailTemplate, error) {
+	err := repo.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&EmailTemplate{}).Where("template_key = ? AND locale = ? AND id <> ?", tpl.TemplateKey, tpl.Locale, tpl.ID).Update("is_active", false).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&EmailTemplate{}).Where("id = ?", tpl.ID).Update("is_active", true).Error
+	})
+
+	if err != nil {
+		return tpl, err
+	}
+
+	tpl.IsActive = true
+
+	return tpl, nil
+}
+
+func (repo *repository) GetEmailSuppressionByID(id int) (suppression EmailSuppression, err error) {
+	if err := repo.DB.Where("id = ?", id).Find(&suppression).Error; err != nil {
+		return suppression, err
+	}
+
+	if suppression.ID == 0 {
+		return suppression, errors.New("sql: no rows in result set")
+	}
+
+	return suppression, nil
+}
+
+func (repo *repository) GetEmailSuppressionByEmail(email string) (suppression EmailSuppression, err error) {
+	if err := repo.DB.Where("email = ?", strings.ToLower(email)).Find(&suppression).Error; err != nil {
+		return suppression, err
+	}
+
+	if suppression.ID == 0 {
+		return suppression, errors.New("sql: no rows in result set")
+	}
+
+	return suppression, nil
+}
+
+func (repo *repository) SaveEmailSuppression(suppression EmailSuppression) (EmailSuppression, error) {
+	suppression.Email = strings.ToLower(suppression.Email)
+
+	if err := repo.DB.Create(&suppression).Error; err != nil {
+		return suppression, err
+	}
+	return suppression, nil
+}
+
+func (repo *repository) DeleteEmailSuppression(suppression EmailSuppression) (bool, error) {
+	tmpSuppression := EmailSuppression{}
+
+	if err := repo.DB.Where("id = ?", suppression.ID).Find(&tmpSuppression).Error; err != nil {
+		return false, err
+	}
+
+	if tmpSuppression.ID == 0 {
+		return false, errors.New("sql: no rows in result set")
+	}
+
+	if constant.DELETED_BY {
+		if err := repo.DB.Save(&suppression).Error; err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	if err := repo.DB.Delete(&suppression).Error; err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// besides the datatables params it filters by the status query param, e.g. status=failed for the failed emails view
+func (repo *repository) AdminDataTablesEmailOutbox(ctx *gin.Context) (result helper.DataTables, err error) {
+	var (
+		query string = QueryAdminDataTablesEmailOutbox
+		where string = ""
+		order string = ""
+		limit string = ""
+	)
+
+	var (
+		no       int = 1
+		total    int = 0
+		filtered int = 0
+	)
+
+	var (
+		data []map[string]any
+		args []any
+	)
+
+	listOrder := []string{"", "recipient", "subject", "template", "locale", "status", "attempts", "next_attempt_at", "provider", "sent_at", "created_at", ""}
+
+	if status := ctx.Query("status"); status != "" {
+		where = fmt.Sprintf("%s AND status = ?", where)
+		args = append(args, status)
+	}
+
+	if searchValue := ctx.Query("search[value]"); searchValue != "" {
+		where = fmt.Sprintf("%s AND (recipient LIKE ? OR subject LIKE ? OR template LIKE ? OR last_error LIKE ?)", where)
+		for i := 0; i < 4; i++ {
+			args = append(args, "%"+searchValue+"%")
+		}
+	}
+
+	orderColumn := ctx.Query("order[0][column]")
+	starting, _ := strconv.Atoi(ctx.Query("start"))
+
+	if orderColumn != "" {
+		orderType := "ASC"
+		orderColumn, _ := strconv.Atoi(orderColumn)
+
+		if strings.ToUpper(ctx.Query("order[0][dir]")) == "DESC" {
+			orderType = "DESC"
+		}
+
+		if orderColumn > 0 && orderColumn < len(listOrder) && listOrder[orderColumn] != "" {
+			order = fmt.Sprintf("ORDER BY %s %s", listOrder[orderColumn], orderType)
+		} else {
+			order = "ORDER BY id DESC"
+		}
+	} else {
+		order = "ORDER BY id DESC"
+	}
+
+	if starting != -1 {
+		length, _ := strconv.Atoi(ctx.Query("length"))
+		limit = fmt.Sprintf("LIMIT %v OFFSET %v", length, starting)
+		no = starting + 1
+	}
+
+	if err := repo.DB.Raw(helper.ConvertToInLineQuery(QueryCountAllAdminDataTablesEmailOutbox)).Scan(&total).Error; err != nil {
+		return result, err
+	}
+
+	if where != "" {
+		query = query + where
+
+		if err := repo.DB.Raw(helper.ConvertToInLineQuery(QueryCountAllAdminDataTablesEmailOutbox)+where, args...).Scan(&filtered).Error; err != nil {
+			return result, err
+		}
+	} else {
+		filtered = total
+	}
+
+	query = fmt.Sprintf("%s %s %s", query, order, limit)
+
+	rows, err := repo.DB.Raw(helper.ConvertToInLineQuery(query), args...).Rows()
+
+	if err != nil {
+		return result, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		tmp := EmailOutbox{}
+		err := rows.Scan(
+			&tmp.ID,
+			&tmp.Recipient,
+			&tmp.Subject,
+			&tmp.Template,
+			&tmp.TemplateVersion,
+			&tmp.Locale,
+			&tmp.Status,
+			&tmp.Attempts,
+			&tmp.MaxAttempts,
+			&tmp.NextAttemptAt,
+			&tmp.LastError,
+			&tmp.Provider,
+			&tmp.SentAt,
+			&tmp.CreatedAt,
+		)
+
+		if err != nil {
+			return result, err
+		}
+
+		formatData := FormatEmailOutboxData(tmp)
+
+		data = append(data, map[string]any{
+			"no":               no,
+			"id":               formatData.ID,
+			"recipient":        formatData.Recipient,
+			"subject":          formatData.Subject,
+			"template":         formatData.Template,
+			"template_version": formatData.Version,
+			"locale":           formatData.Locale,
+			"status":           formatData.Status,
+			"attempts":         formatData.Attempts,
+			"max_attempts":     formatData.MaxAttempts,
+			"next_attempt_at":  formatData.NextAttemptAt,
+			"last_error":       formatData.LastError,
+			"provider":         formatData.Provider,
+			"sent_at":          formatData.SentAt,
+			"created_at":       formatData.CreatedAt,
+		})
+
+		no++
+	}
+
+	return helper.BuildDatatTables(data, filtered, total), nil
+}
+
+// besides the datatables params it filters by the reason query param
+func (repo *repository) AdminDataTablesEmailSuppression(ctx *gin.Context) (result helper.DataTables, err error) {
+	var (
+		query string = QueryAdminDataTablesEmailSuppression
+		where string = ""
+		order string = ""
+		limit string = ""
+	)
+
+	var (
+		no       int = 1
+		total    int = 0
+		filtered int = 0
+	)
+
+	var (
+		data []map[string]any
+		args []any
+	)
+
+	listOrder := []string{"", "email", "reason", "description", "source", "created_at", ""}
+
+	if reason := ctx.Query("reason"); reason != "" {
+		where = fmt.Sprintf("%s AND reason = ?", where)
+		args = append(args, reason)
+	}
+
+	if searchValue := ctx.Query("search[value]"); searchValue != "" {
+		where = fmt.Sprintf("%s AND (email LIKE ? OR description LIKE ?)", where)
+		args = append(args, "%"+searchValue+"%", "%"+searchValue+"%")
+	}
+
+	orderColumn := ctx.Query("order[0][column]")
+	starting, _ := strconv.Atoi(ctx.Query("start"))
+
+	if orderColumn != "" {
+		orderType := "ASC"
+		orderColumn, _ := strconv.Atoi(orderColumn)
+
+		if strings.ToUpper(ctx.Query("order[0][dir]")) == "DESC" {
+			orderType = "DESC"
+		}
+
+		if orderColumn > 0 && orderColumn < len(listOrder) && listOrder[orderColumn] != "" {
+			order = fmt.Sprintf("ORDER BY %s %s", listOrder[orderColumn], orderType)
+		} else {
+			order = "ORDER BY id DESC"
+		}
+	} else {
+		order = "ORDER BY id DESC"
+	}
+
+	if starting != -1 {
+		length, _ := strconv.Atoi(ctx.Query("length"))
+		limit = fmt.Sprintf("LIMIT %v OFFSET %v", length, starting)
+		no = starting + 1
+	}
+
+	if err := repo.DB.Raw(helper.ConvertToInLineQuery(QueryCountAllAdminDataTablesEmailSuppression)).Scan(&total).Error; err != nil {
+		return result, err
+	}
+
+	if where != "" {
+		query = query + where
+
+		if err := repo.DB.Raw(helper.ConvertToInLineQuery(QueryCountAllAdminDataTablesEmailSuppression)+where, args...).Scan(&filtered).Error; err != nil {
+			return result, err
+		}
+	} else {
+		filtered = total
+	}
+
+	query = fmt.Sprintf("%s %s %s", query, order, limit)
+
+	rows, err := repo.DB.Raw(helper.ConvertToInLineQuery(query), args...).Rows()
+
+	if err != nil {
+		return result, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		tmp := EmailSuppression{}
+		err := rows.Scan(
+			&tmp.ID,
+			&tmp.Email,
+			&tmp.Reason,
+			&tmp.Description,
+			&tmp.Source,
+			&tmp.CreatedAt,
+		)
+
+		if err != nil {
+			return result, err
+		}
+
+		formatData := FormatEmailSuppressionData(tmp)
+
+		data = append(data, map[string]any{
+			"no":          no,
+			"id":          formatData.ID,
+			"email":       formatData.Email,
+			"reason":      formatData.Reason,
+			"description": formatData.Description,
+			"source":      formatData.Source,
+			"created_at":  formatData.CreatedAt,
+		})
+
+		no++
+	}
+
+	return helper.BuildDatatTables(data, filtered, total), nil
+}
@@ -0,0 +1,60 @@
+package mailer
+
+import "github.com/WeAreAmazingTeam/tcd-backend/user"
+
+type (
+	RequestGetEmailOutboxByID struct {
+		ID int `uri:"id" binding:"required"`
+	}
+)
+
+type (
+	RequestGetEmailTemplateVersions struct {
+		TemplateKey string `uri:"key" binding:"required"`
+		Locale      string `uri:"locale" binding:"required"`
+	}
+
+	RequestGetEmailTemplateByID struct {
+		ID int `uri:"id" binding:"required"`
+	}
+
+	// RequestCreateEmailTemplate publishes a new version, text_body is optional and derived from the html when empty
+	RequestCreateEmailTemplate struct {
+		TemplateKey string `json:"template_key" binding:"required"`
+		Locale      string `json:"locale" binding:"required"`
+		Subject     string `json:"subject" binding:"required"`
+		HTMLBody    string `json:"html_body" binding:"required"`
+		TextBody    string `json:"text_body"`
+		Activate    bool   `json:"activate"`
+		User        user.User
+	}
+
+	RequestActivateEmailTemplate struct {
+		User user.User
+	}
+
+	// RequestPreviewEmailTemplate renders with sample data: the draft when html_body is sent,
+	// otherwise the given version, otherwise the version that is used for sending right now
+	RequestPreviewEmailTemplate struct {
+		TemplateKey string `json:"template_key" binding:"required"`
+		Locale      string `json:"locale" binding:"required"`
+		Version     int    `json:"version"`
+		Subject     string `json:"subject"`
+		HTMLBody    string `json:"html_body"`
+		TextBody    string `json:"text_body"`
+	}
+)
+
+type (
+	RequestGetEmailSuppressionByID struct {
+		ID int `uri:"id" binding:"required"`
+	}
+
+	RequestDeleteEmailSuppression struct {
+		User user.User
+	}
+
+	RequestGetDeliverability struct {
+		Email string
+	}
+)
@@ -0,0 +1,73 @@
+package mailer
+
+import (
+	"time"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/audit"
+	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+	"github.com/WeAreAmazingTeam/tcd-backend/user"
+	"github.com/gin-gonic/gin"
+)
+
+type Service interface {
+	QueueTemplateMail(to, locale, templateKey string, data any) error
+	ProcessDueEmails() (int, error)
+	RunWorker(interval time.Duration)
+	ResendEmail(RequestGetEmailOutboxByID) (EmailOutbox, error)
+	GetProviderStates() map[string]string
+
+	RenderEmailTemplate(key, locale string, data any) (RenderedEmail, error)
+	GetAllEmailTemplate() ([]EmailTemplateSummaryFormatter, error)
+	GetEmailTemplateVersions(RequestGetEmailTemplateVersions) ([]EmailTemplate, error)
+	CreateEmailTemplate(RequestCreateEmailTemplate) (EmailTemplate, error)
+	ActivateEmailTemplate(RequestGetEmailTemplateByID, RequestActivateEmailTemplate) (EmailTemplate, error)
+	PreviewEmailTemplate(RequestPreviewEmailTemplate) (RenderedEmail, error)
+
+	HandleMailgunWebhook(MailgunWebhook) error
+	GetDeliverability(RequestGetDeliverability) (DeliverabilityFormatter, error)
+	DeleteEmailSuppression(RequestGetEmailSuppressionByID, RequestDeleteEmailSuppression) (bool, error)
+
+	AdminDataTablesEmailOutbox(*gin.Context) (helper.DataTables, error)
+	AdminDataTablesEmailSuppression(*gin.Context) (helper.DataTables, error)
+}
+
+type Config struct {
+	BatchSize        int
+	MaxAttempts      int
+	BackoffBase      time.Duration
+	BackoffMax       time.Duration
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+}
+
+type service struct {
+	repo      Repository
+	userRepo  user.Repository
+	providers []Provider
+	breakers  map[string]*breaker
+	config    Config
+	auditSvc  audit.Service
+}
+
+func NewService(
+	repository Repository,
+	userRepository user.Repository,
+	providers []Provider,
+	config Config,
+	auditService audit.Service,
+) *service {
+	breakers := map[string]*breaker{}
+
+	for _, provider := range providers {
+		breakers[provider.Name()] = newBreaker(config.BreakerThreshold, config.BreakerCooldown)
+	}
+
+	return &service{
+		repo:      repository,
+		userRepo:  userRepository,
+		providers: providers,
+		breakers:  breakers,
+		config:    config,
+		auditSvc:  auditService,
+	}
+}
@@ -0,0 +1,535 @@
+package mailer
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/audit"
+	"github.com/WeAreAmazingTeam/tcd-backend/constant"
+	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+	"github.com/WeAreAmazingTeam/tcd-backend/jobqueue"
+	"github.com/WeAreAmazingTeam/tcd-backend/user"
+	"github.com/gin-gonic/gin"
+)
+
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+func (svc *service) QueueTemplateMail(to, locale, templateKey string, data any) error {
+	definition, ok := templateDefinitions[templateKey]
+
+	if !ok {
+		return errors.New("unknown email template")
+	}
+
+	// optional categories honour the recipient preferences and carry an unsubscribe link,
+	// recipients without an account (anonymous donors) have no preferences
+	unsubscribeToken := ""
+
+	if user.IsOptionalNotificationCategory(definition.Category) {
+		recipient, err := svc.userRepo.GetUserByEmail(to)
+
+		if err != nil && !helper.IsErrNoRows(err.Error()) {
+			return err
+		}
+
+		if err == nil {
+			enabled, err := svc.isEmailNotificationEnabled(recipient.ID, definition.Category)
+
+			if err != nil {
+				return err
+			}
+
+			if !enabled {
+				log.Printf("[MAIL] email %s to %v skipped, %s emails are turned off", templateKey, to, definition.Category)
+				return nil
+			}
+
+			unsubscribeToken = NewUnsubscribeToken(recipient.ID, definition.Category)
+		}
+	}
+
+	tpl, err := svc.resolveEmailTemplate(templateKey, normalizeLocale(locale))
+
+	if err != nil {
+		return err
+	}
+
+	unsubscribeURL := ""
+
+	if unsubscribeToken != "" {
+		unsubscribeURL = unsubscribePageURL(unsubscribeToken)
+	}
+
+	rendered, err := renderEmailTemplate(tpl, data, unsubscribeURL)
+
+	if err != nil {
+		return err
+	}
+
+	outbox := EmailOutbox{}
+	outbox.Recipient = to
+	outbox.Subject = rendered.Subject
+	outbox.Template = templateKey
+	outbox.TemplateVersion = rendered.Version
+	outbox.Locale = normalizeLocale(locale)
+	outbox.Body = rendered.HTML
+	outbox.TextBody = rendered.Text
+	outbox.Status = StatusPending
+
+	if unsubscribeToken != "" {
+		outbox.ListUnsubscribe = unsubscribeOneClickURL(unsubscribeToken)
+	}
+
+	outbox.MaxAttempts = svc.config.MaxAttempts
+	outbox.NextAttemptAt = time.Now()
+
+	// kept in the outbox so the admin can see what was held back and resend it after clearing the suppression
+	if suppressed, err := svc.isSuppressed(to); err != nil {
+		return err
+	} else if suppressed {
+		outbox.Status = StatusSuppressed
+		outbox.LastError = helper.SetNS("recipient is on the suppression list")
+	}
+
+	if _, err := svc.repo.SaveEmailOutbox(outbox); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (svc *service) ProcessDueEmails() (int, error) {
+	return svc.poller().ProcessDue()
+}
+
+// RunWorker blocks, call it in its own goroutine
+func (svc *service) RunWorker(interval time.Duration) {
+	svc.poller().Run(interval, nil)
+}
+
+func (svc *service) poller() jobqueue.Poller[EmailOutbox] {
+	return jobqueue.Poller[EmailOutbox]{
+		Name:         "MAIL",
+		BatchSize:    svc.config.BatchSize,
+		ReleaseStale: svc.repo.ReleaseStaleEmailOutbox,
+		GetDue:       svc.repo.GetDueEmailOutbox,
+		Claim:        svc.repo.ClaimEmailOutbox,
+		Process:      svc.deliver,
+	}
+}
+
+func (svc *service) ResendEmail(req RequestGetEmailOutboxByID) (EmailOutbox, error) {
+	outbox, err := svc.repo.GetEmailOutboxByID(req.ID)
+
+	if err != nil {
+		return outbox, err
+	}
+
+	if outbox.Status == StatusPending || outbox.Status == StatusSending {
+		return outbox, errors.New("email is already queued")
+	}
+
+	outbox.Status = StatusPending
+	outbox.Attempts = 0
+	outbox.NextAttemptAt = time.Now()
+	outbox.LastError = sql.NullString{}
+
+	outbox, err = svc.repo.UpdateEmailOutbox(outbox)
+
+	if err != nil {
+		return outbox, err
+	}
+
+	return outbox, nil
+}
+
+func (svc *service) GetProviderStates() map[string]string {
+	states := map[string]string{}
+
+	for _, provider := range svc.providers {
+		states[provider.Name()] = svc.breakers[provider.Name()].State()
+	}
+
+	return states
+}
+
+func (svc *service) AdminDataTablesEmailOutbox(ctx *gin.Context) (helper.DataTables, error) {
+	dataTablesEmailOutbox, err := svc.repo.AdminDataTablesEmailOutbox(ctx)
+
+	if err != nil {
+		return dataTablesEmailOutbox, err
+	}
+
+	return dataTablesEmailOutbox, nil
+}
+
+// RenderEmailTemplate uses the active version from the database, or the file when no version was published
+func (svc *service) RenderEmailTemplate(key, locale string, data any) (RenderedEmail, error) {
+	tpl, err := svc.resolveEmailTemplate(key, normalizeLocale(locale))
+
+	if err != nil {
+		return RenderedEmail{}, err
+	}
+
+	return renderEmailTemplate(tpl, data, "")
+}
+
+func (svc *service) GetAllEmailTemplate() ([]EmailTemplateSummaryFormatter, error) {
+	activeTemplates, err := svc.repo.GetAllActiveEmailTemplate()
+
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := []EmailTemplateSummaryFormatter{}
+
+	for key := range templateDefinitions {
+		summary := EmailTemplateSummaryFormatter{TemplateKey: key, ActiveVersions: map[string]int{}}
+
+		for _, locale := range constant.MAIL_LOCALES {
+			summary.ActiveVersions[locale] = 0
+		}
+
+		for _, tpl := range activeTemplates {
+			if tpl.TemplateKey == key {
+				summary.ActiveVersions[tpl.Locale] = tpl.Version
+			}
+		}
+
+		summaries = append(summaries, summary)
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].TemplateKey < summaries[j].TemplateKey
+	})
+
+	return summaries, nil
+}
+
+func (svc *service) GetEmailTemplateVersions(req RequestGetEmailTemplateVersions) ([]EmailTemplate, error) {
+	if !IsValidTemplateKey(req.TemplateKey) || !IsValidLocale(req.Locale) {
+		return nil, errors.New("unknown email template or locale")
+	}
+
+	templates, err := svc.repo.GetEmailTemplateVersions(req.TemplateKey, req.Locale)
+
+	if err != nil {
+		return templates, err
+	}
+
+	return templates, nil
+}
+
+// CreateEmailTemplate publishes a new version after rendering it with the sample data, so a broken template never reaches the outbox
+func (svc *service) CreateEmailTemplate(req RequestCreateEmailTemplate) (EmailTemplate, error) {
+	if !IsValidTemplateKey(req.TemplateKey) || !IsValidLocale(req.Locale) {
+		return EmailTemplate{}, errors.New("unknown email template or locale")
+	}
+
+	tpl := EmailTemplate{}
+	tpl.TemplateKey = req.TemplateKey
+	tpl.Locale = req.Locale
+	tpl.Subject = req.Subject
+	tpl.HTMLBody = req.HTMLBody
+	tpl.TextBody = req.TextBody
+	tpl.CreatedBy = helper.SetNS(strconv.Itoa(req.User.ID))
+
+	if _, err := renderEmailTemplate(tpl, templateDefinitions[req.TemplateKey].Sample, sampleUnsubscribeURL(req.TemplateKey)); err != nil {
+		return tpl, fmt.Errorf("invalid template: %s", err.Error())
+	}
+
+	latestVersion, err := svc.repo.GetLatestEmailTemplateVersion(req.TemplateKey, req.Locale)
+
+	if err != nil {
+		return tpl, err
+	}
+
+	tpl.Version = latestVersion + 1
+
+	newTemplate, err := svc.repo.SaveEmailTemplate(tpl)
+
+	if err != nil {
+		return newTemplate, err
+	}
+
+	audit.Record(svc.auditSvc, req.User, audit.ActionCreate, audit.EntityEmailTemplate, newTemplate.ID, nil, newTemplate)
+
+	if !req.Activate {
+		return newTemplate, nil
+	}
+
+	return svc.ActivateEmailTemplate(RequestGetEmailTemplateByID{ID: newTemplate.ID}, RequestActivateEmailTemplate{User: req.User})
+}
+
+// ActivateEmailTemplate also serves as rollback, activating an older version makes it the one used for sending
+func (svc *service) ActivateEmailTemplate(reqDetail RequestGetEmailTemplateByID, reqActivate RequestActivateEmailTemplate) (EmailTemplate, error) {
+	tpl, err := svc.repo.GetEmailTemplateByID(reqDetail.ID)
+
+	if err != nil {
+		return tpl, err
+	}
+
+	before := tpl
+
+	activatedTemplate, err := svc.repo.ActivateEmailTemplate(tpl)
+
+	if err != nil {
+		return activatedTemplate, err
+	}
+
+	audit.Record(svc.auditSvc, reqActivate.User, audit.ActionUpdate, audit.EntityEmailTemplate, activatedTemplate.ID, before, activatedTemplate)
+
+	return activatedTemplate, nil
+}
+
+func (svc *service) PreviewEmailTemplate(req RequestPreviewEmailTemplate) (RenderedEmail, error) {
+	if !IsValidTemplateKey(req.TemplateKey) || !IsValidLocale(req.Locale) {
+		return RenderedEmail{}, errors.New("unknown email template or locale")
+	}
+
+	var (
+		tpl EmailTemplate
+		err error
+	)
+
+	switch {
+	case req.HTMLBody != "":
+		tpl = EmailTemplate{TemplateKey: req.TemplateKey, Locale: req.Locale, Subject: req.Subject, HTMLBody: req.HTMLBody, TextBody: req.TextBody}
+
+		if tpl.Subject == "" {
+			tpl.Subject = templateDefinitions[req.TemplateKey].Subjects[req.Locale]
+		}
+	case req.Version > 0:
+		tpl, err = svc.repo.GetEmailTemplateByVersion(req.TemplateKey, req.Locale, req.Version)
+	default:
+		tpl, err = svc.resolveEmailTemplate(req.TemplateKey, req.Locale)
+	}
+
+	if err != nil {
+		return RenderedEmail{}, err
+	}
+
+	return renderEmailTemplate(tpl, templateDefinitions[req.TemplateKey].Sample, sampleUnsubscribeURL(req.TemplateKey))
+}
+
+// HandleMailgunWebhook adds permanent failures and spam complaints to the suppression list, other events are ignored
+func (svc *service) HandleMailgunWebhook(req MailgunWebhook) error {
+	if !VerifyMailgunSignature(constant.MAILGUN_WEBHOOK_SIGNING_KEY, req.Signature, time.Now()) {
+		return ErrInvalidSignature
+	}
+
+	reason := req.EventData.suppressionReason()
+
+	if reason == "" || req.EventData.Recipient == "" {
+		return nil
+	}
+
+	if suppressed, err := svc.isSuppressed(req.EventData.Recipient); err != nil || suppressed {
+		return err
+	}
+
+	suppression := EmailSuppression{}
+	suppression.Email = req.EventData.Recipient
+	suppression.Reason = reason
+	suppression.Description = req.EventData.description()
+	suppression.Source = ProviderMailgun
+
+	newSuppression, err := svc.repo.SaveEmailSuppression(suppression)
+
+	if err != nil {
+		return err
+	}
+
+	audit.Record(svc.auditSvc, user.User{}, audit.ActionCreate, audit.EntityEmailSuppression, newSuppression.ID, nil, newSuppression)
+
+	return nil
+}
+
+func (svc *service) GetDeliverability(req RequestGetDeliverability) (DeliverabilityFormatter, error) {
+	deliverability := DeliverabilityFormatter{Email: req.Email, Status: DeliverabilityDeliverable}
+
+	suppression, err := svc.repo.GetEmailSuppressionByEmail(req.Email)
+
+	if err != nil && !helper.IsErrNoRows(err.Error()) {
+		return deliverability, err
+	}
+
+	if err == nil {
+		deliverability.Status = DeliverabilitySuppressed
+		deliverability.Reason = suppression.Reason
+		deliverability.SuppressedAt = &suppression.CreatedAt.Time
+	}
+
+	outbox, err := svc.repo.GetLatestEmailOutboxByRecipient(req.Email)
+
+	if err != nil && !helper.IsErrNoRows(err.Error()) {
+		return deliverability, err
+	}
+
+	if err == nil {
+		deliverability.LastEmailStatus = outbox.Status
+		deliverability.LastEmailAt = &outbox.CreatedAt
+
+		if deliverability.Status == DeliverabilityDeliverable && outbox.Status == StatusFailed {
+			deliverability.Status = DeliverabilityFailing
+			deliverability.Reason = outbox.LastError.String
+		}
+	}
+
+	return deliverability, nil
+}
+
+func (svc *service) DeleteEmailSuppression(reqDetail RequestGetEmailSuppressionByID, reqDelete RequestDeleteEmailSuppression) (bool, error) {
+	suppression, err := svc.repo.GetEmailSuppressionByID(reqDetail.ID)
+
+	if err != nil {
+		return false, err
+	}
+
+	before := suppression
+
+	if constant.DELETED_BY {
+		suppression.DeletedAt = *helper.SetNowNT()
+		suppression.DeletedBy = helper.SetNS(strconv.Itoa(reqDelete.User.ID))
+	}
+
+	status, err := svc.repo.DeleteEmailSuppression(suppression)
+
+	if err != nil {
+		return status, err
+	}
+
+	audit.Record(svc.auditSvc, reqDelete.User, audit.ActionDelete, audit.EntityEmailSuppression, suppression.ID, before, nil)
+
+	return status, nil
+}
+
+func (svc *service) AdminDataTablesEmailSuppression(ctx *gin.Context) (helper.DataTables, error) {
+	dataTablesEmailSuppression, err := svc.repo.AdminDataTablesEmailSuppression(ctx)
+
+	if err != nil {
+		return dataTablesEmailSuppression, err
+	}
+
+	return dataTablesEmailSuppression, nil
+}
+
+func (svc *service) isEmailNotificationEnabled(userID int, category string) (bool, error) {
+	preference, err := svc.userRepo.GetNotificationPreference(userID, category)
+
+	if err == nil {
+		return preference.EmailEnabled, nil
+	}
+
+	if helper.IsErrNoRows(err.Error()) {
+		return true, nil
+	}
+
+	return false, err
+}
+
+func (svc *service) isSuppressed(email string) (bool, error) {
+	_, err := svc.repo.GetEmailSuppressionByEmail(email)
+
+	if err == nil {
+		return true, nil
+	}
+
+	if helper.IsErrNoRows(err.Error()) {
+		return false, nil
+	}
+
+	return false, err
+}
+
+func (svc *service) resolveEmailTemplate(key, locale string) (EmailTemplate, error) {
+	tpl, err := svc.repo.GetActiveEmailTemplate(key, locale)
+
+	if err == nil {
+		return tpl, nil
+	}
+
+	if !helper.IsErrNoRows(err.Error()) {
+		return tpl, err
+	}
+
+	return fileTemplate(key, locale)
+}
+
+// deliver tries every provider in order, skipping the ones whose breaker is open
+func (svc *service) deliver(outbox EmailOutbox) {
+	msg := Message{To: outbox.Recipient, Subject: outbox.Subject, HTML: outbox.Body, Text: outbox.TextBody}
+	failures := []string{}
+
+	// the address may have bounced after the message was queued
+	if suppressed, err := svc.isSuppressed(outbox.Recipient); err == nil && suppressed {
+		outbox.Status = StatusSuppressed
+		outbox.LastError = helper.SetNS("recipient is on the suppression list")
+
+		if _, err := svc.repo.UpdateEmailOutbox(outbox); err != nil {
+			log.Printf("[MAIL] email %d status not saved, err: %s", outbox.ID, err.Error())
+		}
+
+		return
+	}
+
+	for _, provider := range svc.providers {
+		cb := svc.breakers[provider.Name()]
+
+		if !cb.Allow() {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*60)
+		err := provider.Send(ctx, msg)
+		cancel()
+
+		if err != nil {
+			cb.Failure()
+			failures = append(failures, fmt.Sprintf("%s: %s", provider.Name(), err.Error()))
+			log.Printf("[MAIL] email %d failed to send to %v through %s, [err: %v]", outbox.ID, outbox.Recipient, provider.Name(), err.Error())
+			continue
+		}
+
+		cb.Success()
+
+		outbox.Status = StatusSent
+		outbox.Attempts++
+		outbox.Provider = helper.SetNS(provider.Name())
+		outbox.SentAt = sql.NullTime{Time: time.Now(), Valid: true}
+		outbox.LastError = sql.NullString{}
+
+		if _, err := svc.repo.UpdateEmailOutbox(outbox); err != nil {
+			log.Printf("[MAIL] email %d sent but status not saved, err: %s", outbox.ID, err.Error())
+		}
+
+		return
+	}
+
+	outbox.Status = StatusPending
+
+	if len(failures) == 0 {
+		// every breaker is open, nothing was tried so the attempt is not counted
+		outbox.LastError = helper.SetNS("all mail providers are unavailable")
+		outbox.NextAttemptAt = time.Now().Add(svc.config.BreakerCooldown)
+	} else {
+		outbox.Attempts++
+		outbox.LastError = helper.SetNS(strings.Join(failures, "; "))
+		outbox.NextAttemptAt = time.Now().Add(jobqueue.Backoff(outbox.Attempts, svc.config.BackoffBase, svc.config.BackoffMax))
+
+		if outbox.Attempts >= outbox.MaxAttempts {
+			outbox.Status = StatusFailed
+		}
+	}
+
+	if _, err := svc.repo.UpdateEmailOutbox(outbox); err != nil {
+		log.Printf("[MAIL] email %d status not saved, err: %s", outbox.ID, err.Error())
+	}
+}
@@ -0,0 +1,281 @@
+package mailer
+
+import (
+	"bytes"
+	"errors"
+	htmlTemplate "html/template"
+	"os"
+	"path/filepath"
+	textTemplate "text/template"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/constant"
+	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+	"github.com/WeAreAmazingTeam/tcd-backend/user"
+)
+
+const (
+	templateDir    = "html"
+	layoutTemplate = "html/layout.html"
+)
+
+type (
+	// templateDefinition holds what is not in the html files: the notification category,
+	// the subject per locale and the data used by the admin preview
+	templateDefinition struct {
+		Category string
+		Subjects map[string]string
+		Sample   any
+	}
+
+	RenderedEmail struct {
+		Subject string
+		HTML    string
+		Text    string
+		Version int
+	}
+
+	layoutData struct {
+		Locale         string
+		Subject        string
+		Content        htmlTemplate.HTML
+		UnsubscribeURL string
+	}
+
+	sampleCampaign struct {
+		Title string
+	}
+)
+
+var templateDefinitions = map[string]templateDefinition{
+	helper.EmailTemplateWelcome: {
+		Category: user.NotificationCategoryAccount,
+		Subjects: map[string]string{
+			"en": "Welcome to The Cloud Donation",
+			"id": "Selamat Datang di The Cloud Donation",
+		},
+		Sample: helper.EmailWelcome{Name: "Budi"},
+	},
+	helper.EmailTemplateForgotPassword: {
+		Category: user.NotificationCategorySecurity,
+		Subjects: map[string]string{
+			"en": "Forgot Password Request",
+			"id": "Permintaan Lupa Password",
+		},
+		Sample: helper.EmailForgotPassword{Name: "Budi", URL: "https://thedonation.cloud/auth/forgot-password/sample-token"},
+	},
+	helper.EmailTemplateEmailVerification: {
+		Category: user.NotificationCategorySecurity,
+		Subjects: map[string]string{
+			"en": "Verify Your Email Address",
+			"id": "Verifikasi Alamat Email Kamu",
+		},
+		Sample: helper.EmailVerification{Name: "Budi", URL: "https://thedonation.cloud/auth/verify-email/sample-token"},
+	},
+	helper.EmailTemplateAccountLocked: {
+		Category: user.NotificationCategorySecurity,
+		Subjects: map[string]string{
+			"en": "Your Account Has Been Temporarily Locked",
+			"id": "Akun Kamu Dikunci Sementara",
+		},
+		Sample: helper.EmailAccountLocked{Name: "Budi", Duration: "15m0s", IpAddress: "127.0.0.1", URL: "https://thedonation.cloud/auth/forgot-password"},
+	},
+	helper.EmailTemplateCampaignActive: {
+		Category: user.NotificationCategoryCampaign,
+		Subjects: map[string]string{
+			"en": "Your Donation Campaign Now Active!",
+			"id": "Kampanye Donasi Kamu Sudah Aktif!",
+		},
+		Sample: helper.EmailCampaignActive{Name: "Budi", Campaign: sampleCampaign{Title: "Bantu Korban Banjir"}, GoalAmount: "Rp 10.000.000", CampaignLink: "https://thedonation.cloud/campaign/bantu-korban-banjir"},
+	},
+	helper.EmailTemplateCampaignFinished: {
+		Category: user.NotificationCategoryCampaign,
+		Subjects: map[string]string{
+			"en": "Your Campaign ({{.Campaign.Title}}) Has Finished",
+			"id": "Kampanye Kamu ({{.Campaign.Title}}) Telah Selesai",
+		},
+		Sample: helper.EmailCampaignFinished{Name: "Budi", Campaign: sampleCampaign{Title: "Bantu Korban Banjir"}, GoalAmount: "Rp 10.000.000", CollectedFunds: "Rp 12.000.000", AdminFee: "Rp 600.000", FinalAmount: "Rp 11.400.000"},
+	},
+	helper.EmailTemplateEarnReward: {
+		Category: user.NotificationCategoryReward,
+		Subjects: map[string]string{
+			"en": "Congratulations, You Get Rewards From Exclusive Campaign!",
+			"id": "Selamat, Kamu Mendapatkan Hadiah Dari Kampanye Eksklusif!",
+		},
+		Sample: helper.EmailEarningRewardFromExclusiveCampaign{Name: "Budi", CampaignLink: "https://thedonation.cloud/campaign/bantu-korban-banjir", Reward: "Smartphone", Status: "pending"},
+	},
+	helper.EmailTemplateRewardUpdate: {
+		Category: user.NotificationCategoryReward,
+		Subjects: map[string]string{
+			"en": "Information Update For Your Reward",
+			"id": "Informasi Terbaru Untuk Hadiah Kamu",
+		},
+		Sample: helper.EmailRewardUpdate{Name: "Budi", CampaignLink: "https://thedonation.cloud/campaign/bantu-korban-banjir", Reward: "Smartphone", Status: "shipped"},
+	},
+	helper.EmailTemplateTransactionSuccess: {
+		Category: user.NotificationCategoryDonation,
+		Subjects: map[string]string{
+			"en": "Thank You For Your Donation!",
+			"id": "Terima Kasih Atas Donasi Kamu!",
+		},
+		Sample: helper.EmailTransactionSuccess{Name: "Budi", CampaignLink: "https://thedonation.cloud/campaign/bantu-korban-banjir", Amount: "Rp 50.000"},
+	},
+	helper.EmailTemplateWithdrawalRequest: {
+		Category: user.NotificationCategoryAccount,
+		Subjects: map[string]string{
+			"en": "Withdrawal Request",
+			"id": "Permintaan Penarikan Dana",
+		},
+		Sample: helper.EmailWithdrawalRequest{Name: "Budi", Amount: "Rp 1.000.000"},
+	},
+	helper.EmailTemplateWithdrawalApproved: {
+		Category: user.NotificationCategoryAccount,
+		Subjects: map[string]string{
+			"en": "Approved Withdrawal Request",
+			"id": "Permintaan Penarikan Dana Disetujui",
+		},
+		Sample: helper.EmailWithdrawalApproved{Name: "Budi", Amount: "Rp 1.000.000"},
+	},
+	helper.EmailTemplateWithdrawalRejected: {
+		Category: user.NotificationCategoryAccount,
+		Subjects: map[string]string{
+			"en": "Rejected Withdrawal Request",
+			"id": "Permintaan Penarikan Dana Ditolak",
+		},
+		Sample: helper.EmailWithdrawalRejected{Name: "Budi", Amount: "Rp 1.000.000"},
+	},
+}
+
+func IsValidTemplateKey(key string) bool {
+	_, ok := templateDefinitions[key]
+	return ok
+}
+
+func IsValidLocale(locale string) bool {
+	for _, val := range constant.MAIL_LOCALES {
+		if val == locale {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeLocale(locale string) string {
+	if IsValidLocale(locale) {
+		return locale
+	}
+	return constant.MAIL_DEFAULT_LOCALE
+}
+
+// fileTemplate is version 0 of a template, the one shipped in html/<locale>/<key>.html
+func fileTemplate(key, locale string) (EmailTemplate, error) {
+	definition, ok := templateDefinitions[key]
+
+	if !ok {
+		return EmailTemplate{}, errors.New("unknown email template")
+	}
+
+	content, err := os.ReadFile(filepath.Join(templateDir, locale, key+".html"))
+
+	if err != nil {
+		return EmailTemplate{}, err
+	}
+
+	return EmailTemplate{
+		TemplateKey: key,
+		Locale:      locale,
+		Subject:     definition.Subjects[locale],
+		HTMLBody:    string(content),
+	}, nil
+}
+
+// renderEmailTemplate escapes the html body with html/template and wraps it in the shared layout,
+// the subject and the plain text alternative are not html so they use text/template
+// an empty unsubscribeURL leaves the unsubscribe footer out, it is only set for optional categories
+func renderEmailTemplate(tpl EmailTemplate, data any, unsubscribeURL string) (rendered RenderedEmail, err error) {
+	rendered.Version = tpl.Version
+
+	if rendered.Subject, err = renderText(tpl.Subject, data); err != nil {
+		return rendered, err
+	}
+
+	content, err := htmlTemplate.New("content").Parse(tpl.HTMLBody)
+
+	if err != nil {
+		return rendered, err
+	}
+
+	contentBuf := new(bytes.Buffer)
+
+	if err := content.Execute(contentBuf, data); err != nil {
+		return rendered, err
+	}
+
+	layout, err := htmlTemplate.ParseFiles(layoutTemplate)
+
+	if err != nil {
+		return rendered, err
+	}
+
+	layoutBuf := new(bytes.Buffer)
+
+	err = layout.Execute(layoutBuf, layoutData{
+		Locale:         tpl.Locale,
+		Subject:        rendered.Subject,
+		Content:        htmlTemplate.HTML(contentBuf.String()),
+		UnsubscribeURL: unsubscribeURL,
+	})
+
+	if err != nil {
+		return rendered, err
+	}
+
+	rendered.HTML = layoutBuf.String()
+
+	if tpl.TextBody != "" {
+		if rendered.Text, err = renderText(tpl.TextBody, data); err != nil {
+			return rendered, err
+		}
+	} else {
+		rendered.Text = htmlToText(contentBuf.String())
+	}
+
+	if unsubscribeURL != "" {
+		rendered.Text += "\n\n" + unsubscribeText(tpl.Locale) + " " + unsubscribeURL
+	}
+
+	return rendered, nil
+}
+
+func renderText(source string, data any) (string, error) {
+	tpl, err := textTemplate.New("text").Parse(source)
+
+	if err != nil {
+		return "", err
+	}
+
+	buf := new(bytes.Buffer)
+
+	if err := tpl.Execute(buf, data); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// the preview shows the unsubscribe footer the way a real recipient sees it
+func sampleUnsubscribeURL(key string) string {
+	category := templateDefinitions[key].Category
+
+	if !user.IsOptionalNotificationCategory(category) {
+		return ""
+	}
+
+	return unsubscribePageURL(NewUnsubscribeToken(0, category))
+}
+
+func unsubscribeText(locale string) string {
+	if locale == "id" {
+		return "Berhenti menerima email seperti ini:"
+	}
+	return "Unsubscribe from emails like this:"
+}
@@ -0,0 +1,46 @@
+package mailer
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	htmlIndentPattern    = regexp.MustCompile(`>\s+<`)
+	htmlLinkPattern      = regexp.MustCompile(`(?is)<a\s[^>]*href="([^"]*)"[^>]*>(.*?)</a>`)
+	htmlParagraphPattern = regexp.MustCompile(`(?i)</p>|</ul>`)
+	htmlBreakPattern     = regexp.MustCompile(`(?i)<br\s*/?>|</li>`)
+	htmlListItemPattern  = regexp.MustCompile(`(?i)<li[^>]*>`)
+	htmlTagPattern       = regexp.MustCompile(`<[^>]*>`)
+	repeatedSpacePattern = regexp.MustCompile(`[ \t]+`)
+	blankLinesPattern    = regexp.MustCompile(`\n{3,}`)
+)
+
+// htmlToText is the plain text alternative for templates without their own text body
+func htmlToText(source string) string {
+	text := htmlIndentPattern.ReplaceAllString(source, "><")
+	text = htmlLinkPattern.ReplaceAllStringFunc(text, func(link string) string {
+		match := htmlLinkPattern.FindStringSubmatch(link)
+		label := strings.TrimSpace(htmlTagPattern.ReplaceAllString(match[2], ""))
+
+		if label == "" || label == match[1] {
+			return match[1]
+		}
+
+		return label + " (" + match[1] + ")"
+	})
+
+	text = htmlParagraphPattern.ReplaceAllString(text, "\n\n")
+	text = htmlBreakPattern.ReplaceAllString(text, "\n")
+	text = htmlListItemPattern.ReplaceAllString(text, "- ")
+	text = html.UnescapeString(htmlTagPattern.ReplaceAllString(text, ""))
+
+	lines := strings.Split(text, "\n")
+
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(repeatedSpacePattern.ReplaceAllString(line, " "))
+	}
+
+	return strings.TrimSpace(blankLinesPattern.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
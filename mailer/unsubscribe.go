@@ -0,0 +1,75 @@
+package mailer
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/constant"
+	"github.com/WeAreAmazingTeam/tcd-backend/user"
+)
+
+var ErrInvalidUnsubscribeToken = errors.New("invalid unsubscribe token")
+
+// NewUnsubscribeToken signs user id and category, the token never expires so old emails keep working
+func NewUnsubscribeToken(userID int, category string) string {
+	payload := fmt.Sprintf("%d:%s", userID, category)
+	return base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." + base64.RawURLEncoding.EncodeToString(unsubscribeSignature(payload))
+}
+
+func ParseUnsubscribeToken(token string) (userID int, category string, err error) {
+	encodedPayload, encodedSignature, found := strings.Cut(token, ".")
+
+	if !found {
+		return 0, "", ErrInvalidUnsubscribeToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+
+	if err != nil {
+		return 0, "", ErrInvalidUnsubscribeToken
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+
+	if err != nil || !hmac.Equal(signature, unsubscribeSignature(string(payload))) {
+		return 0, "", ErrInvalidUnsubscribeToken
+	}
+
+	rawUserID, category, found := strings.Cut(string(payload), ":")
+
+	if !found || !user.IsOptionalNotificationCategory(category) {
+		return 0, "", ErrInvalidUnsubscribeToken
+	}
+
+	if userID, err = strconv.Atoi(rawUserID); err != nil {
+		return 0, "", ErrInvalidUnsubscribeToken
+	}
+
+	return userID, category, nil
+}
+
+// the link in the email footer opens a confirmation page on the website
+func unsubscribePageURL(token string) string {
+	return os.Getenv("WEB_URL") + "/unsubscribe?token=" + url.QueryEscape(token)
+}
+
+// the List-Unsubscribe header points straight at the api so mail clients can do a one-click POST (RFC 8058)
+func unsubscribeOneClickURL(token string) string {
+	if constant.API_URL == "" {
+		return ""
+	}
+	return constant.API_URL + "/api/v1/notifications/unsubscribe?token=" + url.QueryEscape(token)
+}
+
+func unsubscribeSignature(payload string) []byte {
+	mac := hmac.New(sha256.New, constant.SecretKey)
+	mac.Write([]byte("unsubscribe:" + payload))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,91 @@
+package mailer
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"strconv"
+	"time"
+)
+
+// a signed webhook older than this is treated as a replay
+const mailgunWebhookTolerance = 5 * time.Minute
+
+type (
+	MailgunSignature struct {
+		Timestamp string `json:"timestamp"`
+		Token     string `json:"token"`
+		Signature string `json:"signature"`
+	}
+
+	MailgunDeliveryStatus struct {
+		Code        int    `json:"code"`
+		Message     string `json:"message"`
+		Description string `json:"description"`
+	}
+
+	MailgunEventData struct {
+		Event          string                `json:"event"`
+		Severity       string                `json:"severity"`
+		Reason         string                `json:"reason"`
+		Recipient      string                `json:"recipient"`
+		DeliveryStatus MailgunDeliveryStatus `json:"delivery-status"`
+	}
+
+	// MailgunWebhook is the body mailgun posts for the failed and complained events
+	MailgunWebhook struct {
+		Signature MailgunSignature `json:"signature"`
+		EventData MailgunEventData `json:"event-data"`
+	}
+)
+
+// VerifyMailgunSignature checks the hmac of timestamp+token with the webhook signing key
+func VerifyMailgunSignature(signingKey string, signature MailgunSignature, now time.Time) bool {
+	if signingKey == "" || signature.Signature == "" {
+		return false
+	}
+
+	timestamp, err := strconv.ParseInt(signature.Timestamp, 10, 64)
+
+	if err != nil || math.Abs(now.Sub(time.Unix(timestamp, 0)).Seconds()) > mailgunWebhookTolerance.Seconds() {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(signingKey))
+	mac.Write([]byte(signature.Timestamp + signature.Token))
+
+	expected, err := hex.DecodeString(signature.Signature)
+
+	if err != nil {
+		return false
+	}
+
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+// suppressionReason maps a webhook event to a suppression reason, empty means the event is not a reason to stop sending
+func (data MailgunEventData) suppressionReason() string {
+	switch data.Event {
+	case "complained":
+		return SuppressionReasonComplaint
+	case "failed":
+		if data.Severity == "permanent" {
+			return SuppressionReasonHardBounce
+		}
+	}
+
+	return ""
+}
+
+func (data MailgunEventData) description() string {
+	if data.DeliveryStatus.Description != "" {
+		return data.DeliveryStatus.Description
+	}
+
+	if data.DeliveryStatus.Message != "" {
+		return data.DeliveryStatus.Message
+	}
+
+	return data.Reason
+}
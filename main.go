@@ -9,19 +9,34 @@ import (
 	"runtime"
 	"time"
 
+	"github.com/WeAreAmazingTeam/tcd-backend/approval"
+	"github.com/WeAreAmazingTeam/tcd-backend/audit"
 	"github.com/WeAreAmazingTeam/tcd-backend/auth"
 	"github.com/WeAreAmazingTeam/tcd-backend/campaign"
 	"github.com/WeAreAmazingTeam/tcd-backend/chart"
 	"github.com/WeAreAmazingTeam/tcd-backend/company"
 	theCloudConfig "github.com/WeAreAmazingTeam/tcd-backend/config"
 	"github.com/WeAreAmazingTeam/tcd-backend/constant"
+	"github.com/WeAreAmazingTeam/tcd-backend/disbursement"
+	"github.com/WeAreAmazingTeam/tcd-backend/draw"
+	"github.com/WeAreAmazingTeam/tcd-backend/event"
+	"github.com/WeAreAmazingTeam/tcd-backend/finalization"
 	"github.com/WeAreAmazingTeam/tcd-backend/handler"
 	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+	"github.com/WeAreAmazingTeam/tcd-backend/joblock"
+	"github.com/WeAreAmazingTeam/tcd-backend/jobqueue"
 	"github.com/WeAreAmazingTeam/tcd-backend/logs"
+	"github.com/WeAreAmazingTeam/tcd-backend/mailer"
 	"github.com/WeAreAmazingTeam/tcd-backend/middleware"
+	"github.com/WeAreAmazingTeam/tcd-backend/notification"
 	"github.com/WeAreAmazingTeam/tcd-backend/payment"
+	"github.com/WeAreAmazingTeam/tcd-backend/payout"
+	"github.com/WeAreAmazingTeam/tcd-backend/ratelimit"
+	"github.com/WeAreAmazingTeam/tcd-backend/rbac"
+	"github.com/WeAreAmazingTeam/tcd-backend/scheduler"
 	"github.com/WeAreAmazingTeam/tcd-backend/transaction"
 	"github.com/WeAreAmazingTeam/tcd-backend/user"
+	"github.com/WeAreAmazingTeam/tcd-backend/webhook"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-contrib/gzip"
 	"github.com/gin-gonic/gin"
@@ -45,15 +60,52 @@ func main() {
 	}
 
 	// initial constants
+	constant.InitHTTPConstant()
 	constant.InitDBConstant()
 	constant.InitAuthConstant()
 	constant.InitRedisConstant()
+	constant.InitRateLimitConstant()
+	constant.InitMailConstant()
+	constant.InitNotificationConstant()
+	constant.InitWebhookConstant()
+	constant.InitEventConstant()
+	constant.InitFinalizationConstant()
+	constant.InitRewardConstant()
+	constant.InitWithdrawalConstant()
+	constant.InitPayoutConstant()
+	constant.InitApprovalConstant()
+	constant.InitJobLockConstant()
+	constant.InitJobQueueConstant()
 
 	// initial database
 	db := theCloudConfig.InitDB(*isProduction)
 
-	// initial scheduler
-	theCloudConfig.InitScheduler(db)
+	// rate limiter, counters live in redis and fall back to this process memory while redis is unreachable
+	redisClient, err := theCloudConfig.NewRedisClient(*isProduction)
+
+	if err != nil {
+		log.Println("redis not available for rate limiter, using in-memory fallback, err: ", err.Error())
+	}
+
+	limiter := ratelimit.NewLimiter(
+		ratelimit.NewFallbackStore(ratelimit.NewRedisStore(redisClient), ratelimit.NewMemoryStore()),
+		ratelimit.LockoutConfig{
+			Threshold:    constant.LOGIN_LOCKOUT_THRESHOLD,
+			Window:       constant.LOGIN_LOCKOUT_WINDOW,
+			BaseDuration: constant.LOGIN_LOCKOUT_BASE,
+			MaxDuration:  constant.LOGIN_LOCKOUT_MAX,
+		},
+	)
+
+	// scheduled jobs run under a lease, so only one instance runs each of them at a time
+	var jobLocker joblock.Locker = joblock.NewRedisLocker(redisClient)
+
+	if constant.JOB_LOCK_DRIVER == "mysql" || err != nil {
+		log.Println("job lock using mysql GET_LOCK")
+		jobLocker = joblock.NewMySQLLocker(db)
+	}
+
+	jobRunner := joblock.NewRunner(jobLocker, db, constant.JOB_LOCK_TTL)
 
 	// repositories
 	userRepository := user.NewRepository(db)
@@ -62,25 +114,183 @@ func main() {
 	campaignRepository := campaign.NewRepository(db)
 	transactionRepository := transaction.NewRepository(db)
 	logsRepository := logs.NewRepository(db)
+	rbacRepository := rbac.NewRepository(db)
+	auditRepository := audit.NewRepository(db)
+	mailerRepository := mailer.NewRepository(db)
+	notificationRepository := notification.NewRepository(db)
+	webhookRepository := webhook.NewRepository(db)
+	eventRepository := event.NewRepository(db)
+	finalizationRepository := finalization.NewRepository(db)
+	schedulerRepository := scheduler.NewRepository(db)
+	jobQueueRepository := jobqueue.NewRepository(db)
+	drawRepository := draw.NewRepository(db)
+	disbursementRepository := disbursement.NewRepository(db)
+	approvalRepository := approval.NewRepository(db)
+
+	payoutProvider, err := payout.NewProvider(constant.PAYOUT_PROVIDER)
+
+	if err != nil {
+		log.Fatal("error while init payout provider, err: ", err.Error())
+	}
 
 	// services
-	userSvc := user.NewService(userRepository)
+	auditSvc := audit.NewService(auditRepository)
+	userSvc := user.NewService(userRepository, payoutProvider, auditSvc)
+
+	// accounts older than email verification were never asked to verify, they keep access to the verified routes
+	if verified, err := userSvc.VerifyEmailOfExistingUsers(constant.EmailVerificationRequiredSince); err != nil {
+		log.Fatal("error while verifying the email of existing users, err: ", err.Error())
+	} else if verified > 0 {
+		log.Printf("%v existing users marked as email verified", verified)
+	}
 	authSvc := auth.NewService()
 	chartSvc := chart.NewService(chartRepository)
 	paymentSvc := payment.NewService()
-	campaignSvc := campaign.NewService(campaignRepository, userRepository, companyRepository)
-	companySvc := company.NewService(companyRepository)
-	transactionSvc := transaction.NewService(transactionRepository, campaignRepository, userRepository, companyRepository, campaignSvc, paymentSvc)
+	drawSvc := draw.NewService(drawRepository, auditSvc)
+	campaignSvc := campaign.NewService(campaignRepository, userRepository, drawSvc, auditSvc)
+	companySvc := company.NewService(companyRepository, auditSvc)
+	transactionSvc := transaction.NewService(transactionRepository, campaignRepository, userRepository, companyRepository, paymentSvc, auditSvc)
 	logsSvc := logs.NewService(logsRepository)
+	rbacSvc := rbac.NewService(rbacRepository, auditSvc)
+
+	// maker-checker, large withdrawal approvals and cash flows wait for a second admin
+	approvalSvc := approval.NewService(approvalRepository, userSvc, companySvc, rbacSvc, approval.Config{
+		WithdrawalThreshold: constant.APPROVAL_WITHDRAWAL_THRESHOLD,
+		CashFlowThreshold:   constant.APPROVAL_CASH_FLOW_THRESHOLD,
+	}, auditSvc)
+
+	// background jobs, services enqueue and return, the workers run the handlers registered on the registry
+	jobRegistry := jobqueue.NewRegistry()
+	jobQueueSvc := jobqueue.NewService(jobQueueRepository, jobRegistry, jobqueue.Config{
+		Concurrency: constant.JOB_QUEUE_CONCURRENCY,
+		MaxAttempts: constant.JOB_QUEUE_MAX_ATTEMPTS,
+		BackoffBase: constant.JOB_QUEUE_BACKOFF_BASE,
+		BackoffMax:  constant.JOB_QUEUE_BACKOFF_MAX,
+	}, auditSvc)
+
+	// email outbox, every helper.SendMail call is persisted and delivered by the worker
+	mailProviders := []mailer.Provider{}
+
+	for _, name := range constant.MAIL_PROVIDERS {
+		provider, err := mailer.NewProvider(name, constant.MAIL_FILE_SINK_DIR)
+
+		if err != nil {
+			log.Fatal("error while init mail provider, err: ", err.Error())
+		}
+
+		mailProviders = append(mailProviders, provider)
+	}
+
+	mailerSvc := mailer.NewService(mailerRepository, userRepository, mailProviders, mailer.Config{
+		BatchSize:        constant.MAIL_OUTBOX_BATCH_SIZE,
+		MaxAttempts:      constant.MAIL_OUTBOX_MAX_ATTEMPTS,
+		BackoffBase:      constant.MAIL_OUTBOX_BACKOFF_BASE,
+		BackoffMax:       constant.MAIL_OUTBOX_BACKOFF_MAX,
+		BreakerThreshold: constant.MAIL_BREAKER_THRESHOLD,
+		BreakerCooldown:  constant.MAIL_BREAKER_COOLDOWN,
+	}, auditSvc)
+
+	helper.SetMailQueue(mailerSvc)
+
+	go mailerSvc.RunWorker(constant.MAIL_OUTBOX_POLL_INTERVAL)
+
+	// notification channels, helper.SendNotification sends an event to every channel its type declares
+	notificationChannels := []notification.Channel{notification.NewEmailChannel(mailerSvc)}
+
+	for name, provider := range map[string]string{
+		notification.ChannelSMS:      constant.SMS_PROVIDER,
+		notification.ChannelWhatsApp: constant.WHATSAPP_PROVIDER,
+		notification.ChannelPush:     constant.PUSH_PROVIDER,
+	} {
+		if provider == "" {
+			continue
+		}
+
+		channel, err := notification.NewChannel(name, provider)
+
+		if err != nil {
+			log.Fatal("error while init notification channel, err: ", err.Error())
+		}
+
+		notificationChannels = append(notificationChannels, channel)
+	}
+
+	notificationSvc := notification.NewService(notificationRepository, userRepository, notification.NewHub(), notificationChannels, jobQueueSvc)
+
+	helper.SetNotifier(notificationSvc)
+
+	// outbound webhooks, helper.PublishWebhookEvent queues a delivery per subscription and the worker sends it
+	webhookSvc := webhook.NewService(webhookRepository, webhook.Config{
+		BatchSize:           constant.WEBHOOK_BATCH_SIZE,
+		MaxAttempts:         constant.WEBHOOK_MAX_ATTEMPTS,
+		BackoffBase:         constant.WEBHOOK_BACKOFF_BASE,
+		BackoffMax:          constant.WEBHOOK_BACKOFF_MAX,
+		Timeout:             constant.WEBHOOK_TIMEOUT,
+		AllowHTTP:           constant.WEBHOOK_ALLOW_HTTP,
+		AllowPrivateNetwork: constant.WEBHOOK_ALLOW_PRIVATE_NETWORK,
+	}, auditSvc)
+
+	helper.SetWebhookPublisher(webhookSvc)
+
+	go webhookSvc.RunWorker(constant.WEBHOOK_POLL_INTERVAL)
+
+	// domain events, stored in the outbox with the change they describe and handed to the subscribers by the worker
+	eventBus := event.NewBus()
+	eventSvc := event.NewService(eventRepository, eventBus, event.Config{
+		BatchSize:   constant.EVENT_BATCH_SIZE,
+		MaxAttempts: constant.EVENT_MAX_ATTEMPTS,
+		BackoffBase: constant.EVENT_BACKOFF_BASE,
+		BackoffMax:  constant.EVENT_BACKOFF_MAX,
+	}, auditSvc)
+
+	// campaign finalization, started by the CampaignFinished event and by the scheduler for expired campaigns
+	finalizationSvc := finalization.NewService(finalizationRepository, campaignRepository, userRepository, jobQueueSvc, finalization.Config{
+		BatchSize:   constant.FINALIZATION_BATCH_SIZE,
+		MaxAttempts: constant.FINALIZATION_MAX_ATTEMPTS,
+	}, auditSvc)
+
+	// payouts of approved withdrawals, batched by the scheduler and settled by the provider callbacks
+	disbursementSvc := disbursement.NewService(disbursementRepository, userRepository, userSvc, payoutProvider, disbursement.Config{
+		BatchSize:   constant.PAYOUT_BATCH_SIZE,
+		MaxAttempts: constant.PAYOUT_MAX_ATTEMPTS,
+	}, auditSvc)
+
+	transactionSvc.RegisterSubscribers(eventBus)
+	campaignSvc.RegisterSubscribers(eventBus)
+	finalizationSvc.RegisterSubscribers(eventBus)
+
+	go eventSvc.RunWorker(constant.EVENT_POLL_INTERVAL)
+
+	campaignSvc.RegisterJobHandlers(jobRegistry)
+	notificationSvc.RegisterJobHandlers(jobRegistry)
+
+	go jobQueueSvc.RunWorkers(constant.JOB_QUEUE_POLL_INTERVAL)
+
+	// initial scheduler, the jobs are declared in config and run under a job lease
+	schedulerSvc := scheduler.NewService(schedulerRepository, jobRunner, auditSvc)
+	theCloudConfig.InitScheduler(schedulerSvc, finalizationSvc, campaignSvc, userSvc, disbursementSvc)
 
 	// handlers
-	userHandler := handler.NewUserHandler(userSvc, authSvc, logsSvc, companySvc)
+	userHandler := handler.NewUserHandler(userSvc, authSvc, logsSvc, rbacSvc, approvalSvc, limiter)
 	chartHandler := handler.NewChartHandler(chartSvc)
-	campaignHandler := handler.NewCampaignHandler(campaignSvc, userSvc, logsSvc)
-	companyHandler := handler.NewCompanyHandler(companySvc, logsSvc)
+	campaignHandler := handler.NewCampaignHandler(campaignSvc, userSvc, logsSvc, rbacSvc)
+	companyHandler := handler.NewCompanyHandler(companySvc, approvalSvc, logsSvc)
 	transactionHandler := handler.NewTransactionHandler(transactionSvc, campaignSvc, paymentSvc, userSvc, logsSvc)
 	logsHandler := handler.NewLogsHandler(logsSvc)
 	webAndCMSHandler := handler.NewWebAndCMSHandler(transactionSvc, campaignSvc, paymentSvc, userSvc, logsSvc)
+	rbacHandler := handler.NewRBACHandler(rbacSvc, userSvc, logsSvc)
+	auditHandler := handler.NewAuditHandler(auditSvc)
+	mailerHandler := handler.NewMailerHandler(mailerSvc, userSvc, logsSvc)
+	notificationHandler := handler.NewNotificationHandler(notificationSvc)
+	webhookHandler := handler.NewWebhookHandler(webhookSvc, logsSvc)
+	eventHandler := handler.NewEventHandler(eventSvc, logsSvc)
+	finalizationHandler := handler.NewFinalizationHandler(finalizationSvc, logsSvc)
+	schedulerHandler := handler.NewSchedulerHandler(schedulerSvc, logsSvc)
+	payoutHandler := handler.NewPayoutHandler(disbursementSvc, logsSvc)
+	approvalHandler := handler.NewApprovalHandler(approvalSvc, userSvc, logsSvc)
+	jobQueueHandler := handler.NewJobQueueHandler(jobQueueSvc, logsSvc)
+	drawHandler := handler.NewDrawHandler(drawSvc)
+	rewardHandler := handler.NewRewardHandler(campaignSvc, logsSvc)
 
 	// for activate release mode
 	if *isProduction {
@@ -89,9 +299,20 @@ func main() {
 
 	// gin app configuration
 	app := gin.Default()
-	app.SetTrustedProxies(nil)
+
+	// the per ip rate limits and the logs need the address of the client, not of the proxy in front of the app
+	if err := app.SetTrustedProxies(constant.TRUSTED_PROXIES); err != nil {
+		log.Fatal("error while setting the trusted proxies, err: ", err.Error())
+	}
+
+	app.TrustedPlatform = constant.TRUSTED_PLATFORM
+
+	if len(constant.TRUSTED_PROXIES) == 0 && constant.TRUSTED_PLATFORM == "" {
+		log.Println("no trusted proxy or platform set, behind a proxy every client shares the per ip rate limits")
+	}
+
 	app.Static("/images", "./images")
-	app.Use(gzip.Gzip(gzip.DefaultCompression))
+	app.Use(gzip.Gzip(gzip.DefaultCompression, gzip.WithExcludedPaths([]string{"/api/v1/notifications/stream"})))
 	app.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "POST"},
@@ -104,6 +325,28 @@ func main() {
 	// middleware
 	mAuth := middleware.Auth(authSvc, userSvc)
 	mAdminAuth := middleware.AdminAuth(authSvc, userSvc)
+	mEmailVerified := middleware.EmailVerified()
+	mPermission := func(permissions ...string) gin.HandlerFunc {
+		return middleware.RequirePermission(rbacSvc, permissions...)
+	}
+
+	// rate limit policies (per ip)
+	mRateLimitLogin := middleware.RateLimit(limiter, ratelimit.ParsePolicy(ratelimit.PolicyLogin, constant.RATE_LIMIT_LOGIN, ratelimit.Policy{Limit: 10, Window: time.Minute}))
+	mRateLimitTwoFactor := middleware.RateLimit(limiter, ratelimit.ParsePolicy(ratelimit.PolicyTwoFactor, constant.RATE_LIMIT_TWO_FACTOR, ratelimit.Policy{Limit: 10, Window: time.Minute}))
+	mRateLimitRegister := middleware.RateLimit(limiter, ratelimit.ParsePolicy(ratelimit.PolicyRegister, constant.RATE_LIMIT_REGISTER, ratelimit.Policy{Limit: 5, Window: time.Hour}))
+	mRateLimitVerifyEmail := middleware.RateLimit(limiter, ratelimit.ParsePolicy(ratelimit.PolicyVerifyEmail, constant.RATE_LIMIT_VERIFY_EMAIL, ratelimit.Policy{Limit: 10, Window: 15 * time.Minute}))
+	mRateLimitForgotPassword := middleware.RateLimit(limiter, ratelimit.ParsePolicy(ratelimit.PolicyForgotPassword, constant.RATE_LIMIT_FORGOT_PASSWORD, ratelimit.Policy{Limit: 5, Window: 15 * time.Minute}))
+	mRateLimitForgotPasswordToken := middleware.RateLimit(limiter, ratelimit.ParsePolicy(ratelimit.PolicyForgotPasswordToken, constant.RATE_LIMIT_FORGOT_PASSWORD_TOKEN, ratelimit.Policy{Limit: 10, Window: 15 * time.Minute}))
+	mRateLimitResetPassword := middleware.RateLimit(limiter, ratelimit.ParsePolicy(ratelimit.PolicyResetPassword, constant.RATE_LIMIT_RESET_PASSWORD, ratelimit.Policy{Limit: 5, Window: 15 * time.Minute}))
+	mRateLimitAnonymousTransaction := middleware.RateLimit(limiter, ratelimit.ParsePolicy(ratelimit.PolicyAnonymousTransaction, constant.RATE_LIMIT_ANONYMOUS_TRANSACTION, ratelimit.Policy{Limit: 10, Window: time.Minute}))
+	mRateLimitActivityLog := middleware.RateLimit(limiter, ratelimit.ParsePolicy(ratelimit.PolicyActivityLog, constant.RATE_LIMIT_ACTIVITY_LOG, ratelimit.Policy{Limit: 60, Window: time.Minute}))
+
+	// rate limit policies (per account)
+	twoFactorAccountPolicy := ratelimit.ParsePolicy(ratelimit.PolicyTwoFactorAccount, constant.RATE_LIMIT_TWO_FACTOR_ACCOUNT, ratelimit.Policy{Limit: 5, Window: 5 * time.Minute})
+	mRateLimitLoginAccount := middleware.RateLimitByRequestedAccount(limiter, ratelimit.ParsePolicy(ratelimit.PolicyLoginAccount, constant.RATE_LIMIT_LOGIN_ACCOUNT, ratelimit.Policy{Limit: 20, Window: 15 * time.Minute}), middleware.AccountByEmail)
+	mRateLimitLoginTwoFactorAccount := middleware.RateLimitByRequestedAccount(limiter, twoFactorAccountPolicy, middleware.AccountByChallengeToken(authSvc))
+	mRateLimitTwoFactorAccount := middleware.RateLimitByAccount(limiter, twoFactorAccountPolicy)
+	mRateLimitForgotPasswordAccount := middleware.RateLimitByRequestedAccount(limiter, ratelimit.ParsePolicy(ratelimit.PolicyForgotPasswordAccount, constant.RATE_LIMIT_FORGOT_PASSWORD_ACCOUNT, ratelimit.Policy{Limit: 3, Window: time.Hour}), middleware.AccountByEmail)
 
 	// routing
 	api := app.Group("/api/v1")
@@ -113,20 +356,68 @@ func main() {
 		// account settings
 		api.GET("/users/data", mAuth, userHandler.GetUserData)
 		api.PUT("/users/data/change", mAuth, userHandler.ChangeUserData)
-		api.POST("/users/withdraw", mAuth, userHandler.CreateWithdrawalRequest)
+		api.POST("/users/withdraw", mAuth, mEmailVerified, userHandler.CreateWithdrawalRequest)
+		api.POST("/users/withdrawal/:id/cancel", mAuth, userHandler.CancelWithdrawalRequest)
+		api.POST("/users/verify-email/resend", mAuth, userHandler.ResendEmailVerification)
+		api.GET("/users/data/deliverability", mAuth, mailerHandler.GetDeliverability)
+
+		// account settings -> notification preferences
+		api.GET("/users/notifications/preferences", mAuth, userHandler.GetNotificationPreferences)
+		api.PUT("/users/notifications/preferences", mAuth, userHandler.UpdateNotificationPreferences)
+
+		// notification center
+		api.GET("/notifications", mAuth, notificationHandler.GetNotifications)
+		api.GET("/notifications/unread-count", mAuth, notificationHandler.GetUnreadCount)
+		api.GET("/notifications/stream", mAuth, notificationHandler.Stream)
+		api.PUT("/notifications/read-all", mAuth, notificationHandler.MarkAllRead)
+		api.PUT("/notifications/:id/read", mAuth, notificationHandler.MarkRead)
+
+		// account settings -> phone number and push devices for sms, whatsapp and push notifications
+		api.PUT("/users/phone", mAuth, userHandler.UpdatePhoneNumber)
+		api.GET("/users/devices", mAuth, userHandler.GetDeviceTokens)
+		api.POST("/users/devices", mAuth, userHandler.RegisterDeviceToken)
+		api.DELETE("/users/devices/:id", mAuth, userHandler.DeleteDeviceToken)
+		api.GET("/users/bank-accounts", mAuth, userHandler.GetBankAccounts)
+		api.POST("/users/bank-accounts", mAuth, mEmailVerified, userHandler.CreateBankAccount)
+		api.PUT("/users/bank-accounts/:id/default", mAuth, userHandler.SetDefaultBankAccount)
+		api.POST("/users/bank-accounts/:id/verify", mAuth, userHandler.VerifyBankAccount)
+		api.DELETE("/users/bank-accounts/:id", mAuth, userHandler.DeleteBankAccount)
+
+		// account settings -> webhooks for partner integrations, events about the user own campaigns and withdrawals
+		api.GET("/users/webhooks/events", mAuth, webhookHandler.GetWebhookEvents)
+		api.GET("/users/webhooks", mAuth, webhookHandler.GetWebhookSubscriptions)
+		api.POST("/users/webhooks", mAuth, mEmailVerified, webhookHandler.CreateWebhookSubscription)
+		api.PUT("/users/webhooks/:id", mAuth, mEmailVerified, webhookHandler.UpdateWebhookSubscription)
+		api.DELETE("/users/webhooks/:id", mAuth, webhookHandler.DeleteWebhookSubscription)
+		api.POST("/users/webhooks/:id/rotate-secret", mAuth, webhookHandler.RotateWebhookSecret)
+		api.GET("/users/webhooks/:id/deliveries", mAuth, webhookHandler.GetWebhookSubscriptionDeliveries)
+
+		// account settings -> two-factor authentication
+		api.POST("/users/2fa/setup", mAuth, userHandler.SetupTwoFactor)
+		api.POST("/users/2fa/enable", mAuth, mRateLimitTwoFactorAccount, userHandler.EnableTwoFactor)
+		api.POST("/users/2fa/disable", mAuth, mRateLimitTwoFactorAccount, userHandler.DisableTwoFactor)
+		api.POST("/users/2fa/recovery-codes", mAuth, mRateLimitTwoFactorAccount, userHandler.RegenerateRecoveryCodes)
 
 		// users (for admin only)
-		api.GET("/users", mAdminAuth, userHandler.GetAllUser)
-		api.GET("/users/:id", mAdminAuth, userHandler.GetUserByID)
-		api.PUT("/users/withdrawal/:id", mAdminAuth, userHandler.UpdateUserWithdrawalRequest)
-		api.PUT("/users/:id", mAdminAuth, userHandler.UpdateUser)
-		api.POST("/users", mAdminAuth, userHandler.CreateUser)
-		api.DELETE("/users/withdrawal/:id", mAdminAuth, userHandler.DeleteUserWithdrawalRequest)
-		api.DELETE("/users/:id", mAdminAuth, userHandler.DeleteUser)
+		api.GET("/users", mAdminAuth, mPermission(rbac.PermissionUserView), userHandler.GetAllUser)
+		api.GET("/users/:id", mAdminAuth, mPermission(rbac.PermissionUserView), userHandler.GetUserByID)
+		api.PUT("/users/withdrawal/:id", mAdminAuth, mPermission(rbac.PermissionWithdrawalApprove), userHandler.UpdateUserWithdrawalRequest)
+		api.PUT("/users/role/:id", mAdminAuth, mPermission(rbac.PermissionRoleManage), rbacHandler.AssignUserRole)
+		api.PUT("/users/:id", mAdminAuth, mPermission(rbac.PermissionUserManage), userHandler.UpdateUser)
+		api.POST("/users", mAdminAuth, mPermission(rbac.PermissionUserManage), userHandler.CreateUser)
+		api.DELETE("/users/withdrawal/:id", mAdminAuth, mPermission(rbac.PermissionWithdrawalDelete), userHandler.DeleteUserWithdrawalRequest)
+		api.DELETE("/users/:id", mAdminAuth, mPermission(rbac.PermissionUserDelete), userHandler.DeleteUser)
+
+		// roles and permissions (for admin only)
+		api.GET("admin/permissions", mAdminAuth, mPermission(rbac.PermissionRoleManage), rbacHandler.GetAllPermission)
+		api.GET("admin/roles", mAdminAuth, mPermission(rbac.PermissionRoleManage), rbacHandler.GetAllRole)
+		api.PUT("admin/roles/:id", mAdminAuth, mPermission(rbac.PermissionRoleManage), rbacHandler.UpdateRole)
+		api.POST("admin/roles", mAdminAuth, mPermission(rbac.PermissionRoleManage), rbacHandler.CreateRole)
+		api.DELETE("admin/roles/:id", mAdminAuth, mPermission(rbac.PermissionRoleManage), rbacHandler.DeleteRole)
 
 		// campaigns
 		api.PUT("/campaigns/:id", mAuth, campaignHandler.UpdateCampaign)
-		api.POST("/campaigns", mAuth, campaignHandler.CreateCampaign)
+		api.POST("/campaigns", mAuth, mEmailVerified, campaignHandler.CreateCampaign)
 		api.DELETE("/campaigns/:id", mAuth, campaignHandler.DeleteCampaign)
 
 		// campaigns -> images
@@ -134,38 +425,57 @@ func main() {
 		api.DELETE("/campaigns/images/:id", mAuth, campaignHandler.DeleteCampaignImage)
 
 		// campaigns -> categories (for admin only)
-		api.PUT("/campaigns/categories/:id", mAdminAuth, campaignHandler.UpdateCampaignCategory)
-		api.POST("/campaigns/categories", mAdminAuth, campaignHandler.CreateCampaignCategory)
-		api.DELETE("/campaigns/categories/:id", mAdminAuth, campaignHandler.DeleteCampaignCategory)
+		api.PUT("/campaigns/categories/:id", mAdminAuth, mPermission(rbac.PermissionCampaignModerate), campaignHandler.UpdateCampaignCategory)
+		api.POST("/campaigns/categories", mAdminAuth, mPermission(rbac.PermissionCampaignModerate), campaignHandler.CreateCampaignCategory)
+		api.DELETE("/campaigns/categories/:id", mAdminAuth, mPermission(rbac.PermissionCampaignModerate), campaignHandler.DeleteCampaignCategory)
 
 		// for get exclusive campaign by user id
 		api.GET("/campaigns/exclusive/user", mAuth, campaignHandler.GetCampaignExclusiveByWinnerUserID)
 
+		// rewards won in exclusive campaigns, a non-money reward is claimed with the details to hand it over
+		api.GET("/campaigns/exclusive/rewards", mAuth, rewardHandler.GetMyRewards)
+		api.POST("/campaigns/exclusive/rewards/:id/claim", mAuth, rewardHandler.ClaimReward)
+
 		// campaigns exclusive (for admin only)
-		api.GET("/campaigns/exclusive", mAdminAuth, campaignHandler.GetAllCampaignExclusive)
-		api.GET("/campaigns/exclusive/:id", mAdminAuth, campaignHandler.GetCampaignExclusiveByID)
-		api.PUT("/campaigns/exclusive/:id", mAdminAuth, campaignHandler.UpdateCampaignExclusive)
-		api.POST("/campaigns/exclusive", mAdminAuth, campaignHandler.CreateCampaignExclusive)
-		api.DELETE("/campaigns/exclusive/:id", mAdminAuth, campaignHandler.DeleteCampaignExclusive)
+		api.GET("/campaigns/exclusive", mAdminAuth, mPermission(rbac.PermissionCampaignView), campaignHandler.GetAllCampaignExclusive)
+		api.GET("/campaigns/exclusive/:id", mAdminAuth, mPermission(rbac.PermissionCampaignView), campaignHandler.GetCampaignExclusiveByID)
+		api.PUT("/campaigns/exclusive/:id", mAdminAuth, mPermission(rbac.PermissionCampaignModerate), campaignHandler.UpdateCampaignExclusive)
+		api.POST("/campaigns/exclusive", mAdminAuth, mPermission(rbac.PermissionCampaignModerate), campaignHandler.CreateCampaignExclusive)
+		api.DELETE("/campaigns/exclusive/:id", mAdminAuth, mPermission(rbac.PermissionCampaignModerate), campaignHandler.DeleteCampaignExclusive)
 
 		// transactions
 		api.POST("/transactions", mAuth, transactionHandler.CreateTransaction)
-		api.POST("/transactions/emoney", mAuth, transactionHandler.CreateTransactionWithEMoney)
+		api.POST("/transactions/emoney", mAuth, mEmailVerified, transactionHandler.CreateTransactionWithEMoney)
 		api.DELETE("/transactions/:id", mAuth, transactionHandler.DeleteTransaction)
 
+		// campaign finalization (for admin only), finalize finishes an active campaign now or resumes a stuck finalization
+		api.GET("admin/campaigns/:id/finalization", mAdminAuth, mPermission(rbac.PermissionCampaignView), finalizationHandler.AdminGetCampaignFinalization)
+		api.POST("admin/campaigns/:id/finalize", mAdminAuth, mPermission(rbac.PermissionCampaignModerate), finalizationHandler.AdminFinalizeCampaign)
+
+		// reward fulfillment (for admin only), a claimed reward is shipped with a tracking number and then delivered
+		api.GET("admin/rewards/:id", mAdminAuth, mPermission(rbac.PermissionCampaignView), rewardHandler.AdminGetReward)
+		api.PUT("admin/rewards/:id/fulfillment", mAdminAuth, mPermission(rbac.PermissionCampaignModerate), rewardHandler.AdminUpdateRewardFulfillment)
+
 		// company -> cash flow
-		api.POST("/company/cashflow", mAdminAuth, companyHandler.CreateCompanyCashFlow)
-		api.DELETE("/company/cashflow/:id", mAdminAuth, companyHandler.DeleteCompanyCashFlow)
+		api.POST("/company/cashflow", mAdminAuth, mPermission(rbac.PermissionCashFlowManage), companyHandler.CreateCompanyCashFlow)
+		api.DELETE("/company/cashflow/:id", mAdminAuth, mPermission(rbac.PermissionCashFlowManage), companyHandler.DeleteCompanyCashFlow)
 
 		// admin datatables
-		api.GET("admin/datatables/users", mAdminAuth, userHandler.AdminDataTablesUsers)
-		api.GET("admin/datatables/categories", mAdminAuth, campaignHandler.AdminDataTablesCategories)
-		api.GET("admin/datatables/campaigns", mAdminAuth, campaignHandler.AdminDataTablesCampaigns)
-		api.GET("admin/datatables/transactions", mAdminAuth, transactionHandler.AdminDataTablesTransactions)
-		api.GET("admin/datatables/logs/activity", mAdminAuth, logsHandler.AdminDataTablesActivityLogs)
-		api.GET("admin/datatables/campaigns/exclusive", mAdminAuth, campaignHandler.AdminDataTablesWinnersExclusiveCampaigns)
-		api.GET("admin/datatables/withdrawal", mAdminAuth, userHandler.AdminDatatablesWithdrawalRequest)
-		api.GET("admin/datatables/company/cashflow", mAdminAuth, companyHandler.AdminDataTablesCompanyCashFlow)
+		api.GET("admin/datatables/users", mAdminAuth, mPermission(rbac.PermissionUserView), userHandler.AdminDataTablesUsers)
+		api.GET("admin/datatables/categories", mAdminAuth, mPermission(rbac.PermissionCampaignView), campaignHandler.AdminDataTablesCategories)
+		api.GET("admin/datatables/campaigns", mAdminAuth, mPermission(rbac.PermissionCampaignView), campaignHandler.AdminDataTablesCampaigns)
+		api.GET("admin/datatables/transactions", mAdminAuth, mPermission(rbac.PermissionTransactionView), transactionHandler.AdminDataTablesTransactions)
+		api.GET("admin/datatables/logs/activity", mAdminAuth, mPermission(rbac.PermissionLogsView), logsHandler.AdminDataTablesActivityLogs)
+		api.GET("admin/datatables/logs/audit", mAdminAuth, mPermission(rbac.PermissionLogsView), auditHandler.AdminDataTablesAuditLogs)
+		api.GET("admin/datatables/campaigns/exclusive", mAdminAuth, mPermission(rbac.PermissionCampaignView), campaignHandler.AdminDataTablesWinnersExclusiveCampaigns)
+		api.GET("admin/datatables/rewards", mAdminAuth, mPermission(rbac.PermissionCampaignView), rewardHandler.AdminDataTablesRewards)
+		api.GET("admin/datatables/withdrawal", mAdminAuth, mPermission(rbac.PermissionWithdrawalView), userHandler.AdminDatatablesWithdrawalRequest)
+		api.GET("admin/payouts/batches/:id", mAdminAuth, mPermission(rbac.PermissionWithdrawalView), payoutHandler.AdminGetPayoutBatch)
+
+		api.GET("admin/datatables/approvals", mAdminAuth, mPermission(rbac.PermissionApprovalView), approvalHandler.AdminDataTablesApprovals)
+		api.GET("admin/approvals/:id", mAdminAuth, mPermission(rbac.PermissionApprovalView), approvalHandler.GetApprovalByID)
+		api.PUT("admin/approvals/:id", mAdminAuth, mPermission(rbac.PermissionApprovalDecide), approvalHandler.DecideApproval)
+		api.GET("admin/datatables/company/cashflow", mAdminAuth, mPermission(rbac.PermissionCashFlowView), companyHandler.AdminDataTablesCompanyCashFlow)
 
 		// datatables for user
 		api.GET("datatables/campaigns", mAuth, campaignHandler.UserDataTablesCampaigns)
@@ -176,23 +486,73 @@ func main() {
 		// logs
 		api.POST("logs/activity/auth", mAuth, logsHandler.AddLogsActivityAuth)
 
+		// audit trail (for admin only)
+		api.GET("admin/audit/:entity_type/:entity_id", mAdminAuth, mPermission(rbac.PermissionLogsView), auditHandler.GetEntityHistory)
+
+		// email outbox (for admin only), use ?status=failed for the failed emails view
+		api.GET("admin/datatables/emails", mAdminAuth, mPermission(rbac.PermissionEmailView), mailerHandler.AdminDataTablesEmailOutbox)
+		api.GET("admin/emails/providers", mAdminAuth, mPermission(rbac.PermissionEmailView), mailerHandler.GetProviderStates)
+		api.POST("admin/emails/:id/resend", mAdminAuth, mPermission(rbac.PermissionEmailManage), mailerHandler.ResendEmail)
+		api.GET("admin/emails/deliverability/:id", mAdminAuth, mPermission(rbac.PermissionEmailView), mailerHandler.GetUserDeliverability)
+
+		// email suppressions (for admin only), addresses that hard-bounced or complained
+		api.GET("admin/datatables/emails/suppressions", mAdminAuth, mPermission(rbac.PermissionEmailView), mailerHandler.AdminDataTablesEmailSuppression)
+		api.DELETE("admin/emails/suppressions/:id", mAdminAuth, mPermission(rbac.PermissionEmailManage), mailerHandler.DeleteEmailSuppression)
+
+		// outbound webhooks (for admin only), ?status=failed lists the deliveries that gave up
+		api.GET("admin/webhooks", mAdminAuth, mPermission(rbac.PermissionWebhookView), webhookHandler.AdminGetAllWebhookSubscription)
+		api.GET("admin/datatables/webhooks/deliveries", mAdminAuth, mPermission(rbac.PermissionWebhookView), webhookHandler.AdminDataTablesWebhookDelivery)
+		api.GET("admin/webhooks/deliveries/:id", mAdminAuth, mPermission(rbac.PermissionWebhookView), webhookHandler.AdminGetWebhookDelivery)
+		api.POST("admin/webhooks/deliveries/:id/redeliver", mAdminAuth, mPermission(rbac.PermissionWebhookManage), webhookHandler.AdminRedeliverWebhook)
+
+		// domain events outbox (for admin only), ?status=failed lists the events a subscriber gave up on
+		api.GET("admin/datatables/events", mAdminAuth, mPermission(rbac.PermissionEventView), eventHandler.AdminDataTablesEventOutbox)
+		api.GET("admin/events/:id", mAdminAuth, mPermission(rbac.PermissionEventView), eventHandler.AdminGetEventOutbox)
+		api.POST("admin/events/:id/replay", mAdminAuth, mPermission(rbac.PermissionEventManage), eventHandler.AdminReplayEvent)
+
+		// scheduler (for admin only), trigger runs a job now even when it is paused
+		api.GET("admin/scheduler/jobs", mAdminAuth, mPermission(rbac.PermissionSchedulerView), schedulerHandler.AdminGetScheduledJobs)
+		api.GET("admin/datatables/scheduler/runs", mAdminAuth, mPermission(rbac.PermissionSchedulerView), schedulerHandler.AdminDataTablesJobRuns)
+		api.POST("admin/scheduler/jobs/:name/trigger", mAdminAuth, mPermission(rbac.PermissionSchedulerManage), schedulerHandler.AdminTriggerJob)
+		api.POST("admin/scheduler/jobs/:name/pause", mAdminAuth, mPermission(rbac.PermissionSchedulerManage), schedulerHandler.AdminPauseJob)
+		api.POST("admin/scheduler/jobs/:name/resume", mAdminAuth, mPermission(rbac.PermissionSchedulerManage), schedulerHandler.AdminResumeJob)
+
+		// job queue (for admin only), retry takes a dead job out of the dead letter
+		api.GET("admin/datatables/queue/jobs", mAdminAuth, mPermission(rbac.PermissionQueueView), jobQueueHandler.AdminDataTablesQueuedJob)
+		api.GET("admin/queue/stats", mAdminAuth, mPermission(rbac.PermissionQueueView), jobQueueHandler.AdminGetQueueStats)
+		api.GET("admin/queue/jobs/:id", mAdminAuth, mPermission(rbac.PermissionQueueView), jobQueueHandler.AdminGetQueuedJob)
+		api.POST("admin/queue/jobs/:id/retry", mAdminAuth, mPermission(rbac.PermissionQueueManage), jobQueueHandler.AdminRetryQueuedJob)
+
+		// email templates (for admin only), version 0 is the file shipped in html/<locale>/
+		api.GET("admin/email-templates", mAdminAuth, mPermission(rbac.PermissionEmailView), mailerHandler.GetAllEmailTemplate)
+		api.GET("admin/email-templates/:key/:locale", mAdminAuth, mPermission(rbac.PermissionEmailView), mailerHandler.GetEmailTemplateVersions)
+		api.POST("admin/email-templates/preview", mAdminAuth, mPermission(rbac.PermissionEmailView), mailerHandler.PreviewEmailTemplate)
+		api.POST("admin/email-templates", mAdminAuth, mPermission(rbac.PermissionEmailManage), mailerHandler.CreateEmailTemplate)
+		api.PUT("admin/email-templates/:id/activate", mAdminAuth, mPermission(rbac.PermissionEmailManage), mailerHandler.ActivateEmailTemplate)
+
 		// dashboard statistics
-		api.GET("admin/dashboard/statistics", mAdminAuth, webAndCMSHandler.GetStatisticsForAdminDashboard)
+		api.GET("admin/dashboard/statistics", mAdminAuth, mPermission(rbac.PermissionDashboardView), webAndCMSHandler.GetStatisticsForAdminDashboard)
 
 		// get chart
-		api.GET("admin/dashboard/chart", mAdminAuth, chartHandler.GetChart)
+		api.GET("admin/dashboard/chart", mAdminAuth, mPermission(rbac.PermissionDashboardView), chartHandler.GetChart)
 
 		// >>>>>>>>>>>>>>> end strict endpoint <<<<<<<<<<<<<<<
 
 		// >>>>>>>>>>>>>>> begin non-strict endpoint <<<<<<<<<<<<<<<
 
 		// authentication
-		api.POST("/users/register", userHandler.Register)
-		api.POST("/users/login", userHandler.Login)
+		api.POST("/users/register", mRateLimitRegister, userHandler.Register)
+		api.POST("/users/login", mRateLimitLogin, mRateLimitLoginAccount, userHandler.Login)
+		api.POST("/users/login/2fa", mRateLimitTwoFactor, mRateLimitLoginTwoFactorAccount, userHandler.LoginTwoFactor)
+		api.POST("/users/login/2fa/setup", mRateLimitTwoFactor, mRateLimitLoginTwoFactorAccount, userHandler.LoginTwoFactorSetup)
+
+		// email verification
+		api.GET("/users/verify-email/:token", mRateLimitVerifyEmail, userHandler.VerifyEmail)
 
 		// forgot password
-		api.GET("/users/forgot-password/:token", userHandler.ProcessForgotPasswordToken)
-		api.POST("/users/forgot-password", userHandler.CreateForgotPasswordToken)
+		api.GET("/users/forgot-password/:token", mRateLimitForgotPasswordToken, userHandler.ProcessForgotPasswordToken)
+		api.POST("/users/forgot-password", mRateLimitForgotPassword, mRateLimitForgotPasswordAccount, userHandler.CreateForgotPasswordToken)
+		api.POST("/users/forgot-password/confirm", mRateLimitResetPassword, userHandler.ResetPassword)
 
 		// users
 		api.GET("/users/name/:id", userHandler.GetNameByID)
@@ -212,6 +572,9 @@ func main() {
 		// campaigns exclusive by campaign id
 		api.GET("/campaigns/exclusive/campaign/:id", campaignHandler.GetCampaignExclusiveByCampaignID)
 
+		// draw of an exclusive campaign, the seed hash before the draw and everything needed to verify it after
+		api.GET("/campaigns/exclusive/campaign/:id/draw", drawHandler.GetDrawByCampaignID)
+
 		// transactions
 		api.GET("/transactions", transactionHandler.GetAllTransaction)
 		api.GET("/transactions/:id", transactionHandler.GetTransactionByID)
@@ -219,10 +582,17 @@ func main() {
 		api.GET("/transactions/campaigns/:id", transactionHandler.GetTransactionByCampaignID)
 		api.POST("/transactions/test/midtrans", transactionHandler.TestMidtrans)
 		api.POST("/transactions/webhooks", transactionHandler.TransactionWebhooks)
-		api.POST("/transactions/anonymous", transactionHandler.CreateAnonymousTransaction)
+		api.POST("/transactions/anonymous", mRateLimitAnonymousTransaction, transactionHandler.CreateAnonymousTransaction)
+
+		// email provider webhooks
+		api.POST("/emails/webhooks/mailgun", mailerHandler.MailgunWebhooks)
+		api.POST("/payouts/callback", payoutHandler.Callback)
+
+		// one-click unsubscribe, the signed token is the authentication
+		api.POST("/notifications/unsubscribe", mailerHandler.Unsubscribe)
 
 		// logs
-		api.POST("logs/activity", logsHandler.AddLogsActivity)
+		api.POST("logs/activity", mRateLimitActivityLog, logsHandler.AddLogsActivity)
 
 		// web
 		api.GET("web/home/statistics", webAndCMSHandler.GetStatisticsForHomePage)
@@ -42,8 +42,14 @@ func Auth(authService auth.Service, userService user.Service) gin.HandlerFunc {
 			return
 		}
 
-		userID := int(claim["the_cloud_donation_user_id"].(float64))
-		user, err := userService.GetUserByID(userID)
+		userID, ok := claim["the_cloud_donation_user_id"].(float64)
+
+		if !ok {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, helper.BasicAPIResponseError(http.StatusUnauthorized, "Unauthorized, invalid token!"))
+			return
+		}
+
+		user, err := userService.GetUserByID(int(userID))
 
 		if err != nil {
 			ctx.AbortWithStatusJSON(http.StatusUnauthorized, helper.BasicAPIResponseError(http.StatusUnauthorized, "Unauthorized, invalid token!"))
@@ -85,8 +91,14 @@ func AdminAuth(authService auth.Service, userService user.Service) gin.HandlerFu
 			return
 		}
 
-		userID := int(claim["the_cloud_donation_user_id"].(float64))
-		user, err := userService.GetUserByID(userID)
+		userID, ok := claim["the_cloud_donation_user_id"].(float64)
+
+		if !ok {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, helper.BasicAPIResponseError(http.StatusUnauthorized, "Unauthorized, invalid token!"))
+			return
+		}
+
+		user, err := userService.GetUserByID(int(userID))
 
 		if err != nil {
 			ctx.AbortWithStatusJSON(http.StatusUnauthorized, helper.BasicAPIResponseError(http.StatusUnauthorized, "Unauthorized, invalid token!"))
@@ -98,6 +110,11 @@ func AdminAuth(authService auth.Service, userService user.Service) gin.HandlerFu
 			return
 		}
 
+		if twoFactor, _ := claim["the_cloud_donation_two_factor"].(bool); !twoFactor {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, helper.BasicAPIResponseError(http.StatusForbidden, "Two-factor authentication required, please login again!"))
+			return
+		}
+
 		ctx.Set("userData", user)
 	}
 }
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+	"github.com/WeAreAmazingTeam/tcd-backend/rbac"
+	"github.com/WeAreAmazingTeam/tcd-backend/user"
+	"github.com/gin-gonic/gin"
+)
+
+// must be placed after Auth or AdminAuth, it reads the user set by them
+func RequirePermission(rbacService rbac.Service, permissions ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userData, ok := ctx.MustGet("userData").(user.User)
+
+		if !ok {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, helper.BasicAPIResponseError(http.StatusUnauthorized, "Unauthorized, invalid token!"))
+			return
+		}
+
+		for _, permission := range permissions {
+			allowed, err := rbacService.HasPermission(userData.Role, permission)
+
+			if err != nil {
+				ctx.AbortWithStatusJSON(http.StatusInternalServerError, helper.BasicAPIResponseError(http.StatusInternalServerError, "Failed to check permission!"))
+				return
+			}
+
+			if !allowed {
+				ctx.AbortWithStatusJSON(http.StatusForbidden, helper.BasicAPIResponseError(http.StatusForbidden, "You don't have permission to access this endpoint!"))
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/auth"
+	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+	"github.com/WeAreAmazingTeam/tcd-backend/ratelimit"
+	"github.com/WeAreAmazingTeam/tcd-backend/user"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// limits by client ip, for public endpoints
+func RateLimit(limiter *ratelimit.Limiter, policy ratelimit.Policy) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		allowOrAbort(ctx, limiter, policy, "ip:"+ctx.ClientIP())
+	}
+}
+
+// limits by account, must be placed after Auth or AdminAuth, it reads the user set by them
+func RateLimitByAccount(limiter *ratelimit.Limiter, policy ratelimit.Policy) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userData, ok := ctx.MustGet("userData").(user.User)
+
+		if !ok {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, helper.BasicAPIResponseError(http.StatusUnauthorized, "Unauthorized, invalid token!"))
+			return
+		}
+
+		allowOrAbort(ctx, limiter, policy, "user:"+strconv.Itoa(userData.ID))
+	}
+}
+
+// limits by the account a public request is for, account returns its key or "" when the request names none,
+// the handler then answers the request as invalid
+func RateLimitByRequestedAccount(limiter *ratelimit.Limiter, policy ratelimit.Policy, account func(*gin.Context) string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if key := account(ctx); key != "" {
+			allowOrAbort(ctx, limiter, policy, key)
+		}
+	}
+}
+
+// AccountByEmail keys a request on the email in its body
+func AccountByEmail(ctx *gin.Context) string {
+	email := strings.ToLower(strings.TrimSpace(bodyField(ctx, "email")))
+
+	if email == "" {
+		return ""
+	}
+
+	return "email:" + email
+}
+
+// AccountByChallengeToken keys a second login step on the user of its challenge token, the same key as
+// RateLimitByAccount so both share the count of the user
+func AccountByChallengeToken(authService auth.Service) func(*gin.Context) string {
+	return func(ctx *gin.Context) string {
+		userID, err := authService.ValidateChallengeToken(bodyField(ctx, "challenge_token"))
+
+		if err != nil {
+			return ""
+		}
+
+		return "user:" + strconv.Itoa(userID)
+	}
+}
+
+// bodyField reads a string field of a json or form body and leaves the body for the handler to bind
+func bodyField(ctx *gin.Context, field string) string {
+	if ctx.ContentType() != binding.MIMEJSON {
+		return ctx.PostForm(field)
+	}
+
+	body, err := io.ReadAll(ctx.Request.Body)
+	ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	if err != nil {
+		return ""
+	}
+
+	fields := map[string]any{}
+
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return ""
+	}
+
+	value, _ := fields[field].(string)
+
+	return value
+}
+
+func allowOrAbort(ctx *gin.Context, limiter *ratelimit.Limiter, policy ratelimit.Policy, key string) {
+	allowed, retryAfter, err := limiter.Allow(policy, key)
+
+	// never block requests because the limiter itself is broken
+	if err != nil {
+		log.Println("rate limit check failed, err: ", err.Error())
+		return
+	}
+
+	if !allowed {
+		AbortTooManyRequests(ctx, retryAfter, "Too many requests, please try again later!")
+		return
+	}
+}
+
+func AbortTooManyRequests(ctx *gin.Context, retryAfter time.Duration, message string) {
+	ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	ctx.AbortWithStatusJSON(http.StatusTooManyRequests, helper.BasicAPIResponseError(http.StatusTooManyRequests, message))
+}
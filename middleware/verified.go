@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+	"github.com/WeAreAmazingTeam/tcd-backend/user"
+	"github.com/gin-gonic/gin"
+)
+
+// must be placed after Auth, it reads the user set by it
+func EmailVerified() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userData, ok := ctx.MustGet("userData").(user.User)
+
+		if !ok {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, helper.BasicAPIResponseError(http.StatusUnauthorized, "Unauthorized, invalid token!"))
+			return
+		}
+
+		if !userData.IsEmailVerified() {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, helper.BasicAPIResponseError(http.StatusForbidden, "Please verify your email address first!"))
+			return
+		}
+	}
+}
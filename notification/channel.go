@@ -0,0 +1,153 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+)
+
+const (
+	ChannelEmail    = "email"
+	ChannelInApp    = "in_app"
+	ChannelSMS      = "sms"
+	ChannelWhatsApp = "whatsapp"
+	ChannelPush     = "push"
+)
+
+const (
+	ProviderGateway = "gateway"
+	ProviderFCM     = "fcm"
+	ProviderFake    = "fake"
+)
+
+type (
+	// Recipient carries every address a channel may need, a channel skips recipients without its address
+	Recipient struct {
+		UserID       int
+		Email        string
+		Locale       string
+		PhoneNumber  string
+		DeviceTokens []string
+	}
+
+	// Message is one event rendered for the short channels, Type and Data are kept for the email template
+	Message struct {
+		Type  string
+		Title string
+		Body  string
+		Link  string
+		Data  any
+	}
+
+	// Channel delivers a message to one recipient, an error means this channel did not accept it
+	Channel interface {
+		Name() string
+		Send(ctx context.Context, recipient Recipient, msg Message) error
+	}
+
+	emailChannel struct {
+		queue helper.MailQueue
+	}
+
+	inAppChannel struct {
+		svc *service
+	}
+
+	// FakeChannel keeps what it was asked to send, for local development and tests
+	FakeChannel struct {
+		name string
+		mu   sync.Mutex
+		sent []FakeDelivery
+	}
+
+	FakeDelivery struct {
+		Recipient Recipient
+		Message   Message
+	}
+)
+
+// Text is the plain version used by sms and whatsapp
+func (msg Message) Text() string {
+	lines := []string{}
+
+	for _, line := range []string{msg.Title, msg.Body, msg.Link} {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// NewChannel builds the sms, whatsapp or push channel for a provider name, the credentials come from constant
+func NewChannel(name, provider string) (Channel, error) {
+	if provider == ProviderFake {
+		return NewFakeChannel(name), nil
+	}
+
+	switch {
+	case name == ChannelSMS && provider == ProviderGateway:
+		return newSMSGatewayChannel(), nil
+	case name == ChannelWhatsApp && provider == ProviderGateway:
+		return newWhatsAppGatewayChannel(), nil
+	case name == ChannelPush && provider == ProviderFCM:
+		return newFCMChannel(), nil
+	}
+
+	return nil, fmt.Errorf("unknown %v provider %q", name, provider)
+}
+
+// NewEmailChannel sends through the email outbox, so the localized template, preferences and retries still apply
+func NewEmailChannel(queue helper.MailQueue) Channel {
+	return &emailChannel{queue: queue}
+}
+
+func (channel *emailChannel) Name() string {
+	return ChannelEmail
+}
+
+func (channel *emailChannel) Send(ctx context.Context, recipient Recipient, msg Message) error {
+	return channel.queue.QueueTemplateMail(recipient.Email, recipient.Locale, msg.Type, msg.Data)
+}
+
+func (channel *inAppChannel) Name() string {
+	return ChannelInApp
+}
+
+func (channel *inAppChannel) Send(ctx context.Context, recipient Recipient, msg Message) error {
+	return channel.svc.saveAndPublish(recipient.UserID, msg)
+}
+
+func NewFakeChannel(name string) *FakeChannel {
+	return &FakeChannel{name: name}
+}
+
+func (channel *FakeChannel) Name() string {
+	return channel.name
+}
+
+func (channel *FakeChannel) Send(ctx context.Context, recipient Recipient, msg Message) error {
+	channel.mu.Lock()
+	channel.sent = append(channel.sent, FakeDelivery{Recipient: recipient, Message: msg})
+	channel.mu.Unlock()
+
+	fmt.Printf("[NOTIFICATION] fake %v for user %v, type: %v, text: %q\n", channel.name, recipient.UserID, msg.Type, msg.Text())
+
+	return nil
+}
+
+func (channel *FakeChannel) Sent() []FakeDelivery {
+	channel.mu.Lock()
+	defer channel.mu.Unlock()
+
+	return append([]FakeDelivery{}, channel.sent...)
+}
+
+func (channel *FakeChannel) Reset() {
+	channel.mu.Lock()
+	channel.sent = nil
+	channel.mu.Unlock()
+}
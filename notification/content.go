@@ -0,0 +1,145 @@
+package notification
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"text/template"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/constant"
+	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+)
+
+// content is the short version of an email for the other channels, every field is a text/template
+// over the same data as the email. Channels lists where the type is sent, in that order.
+type content struct {
+	Channels []string
+	Titles   map[string]string
+	Bodies   map[string]string
+	Link     string
+}
+
+// the security emails (verification, password reset) have no entry and are email only
+var contents = map[string]content{
+	helper.EmailTemplateTransactionSuccess: {
+		Channels: []string{ChannelEmail, ChannelInApp, ChannelPush, ChannelWhatsApp},
+		Titles:   map[string]string{"en": "Thank you for your donation", "id": "Terima kasih atas donasi kamu"},
+		Bodies:   map[string]string{"en": "Your donation of {{.Amount}} has been received.", "id": "Donasi kamu sebesar {{.Amount}} telah kami terima."},
+		Link:     "{{.CampaignLink}}",
+	},
+	helper.EmailTemplateCampaignActive: {
+		Channels: []string{ChannelEmail, ChannelInApp, ChannelPush},
+		Titles:   map[string]string{"en": "Your campaign is now active", "id": "Kampanye kamu sudah aktif"},
+		Bodies:   map[string]string{"en": "{{.Campaign.Title}} is now accepting donations.", "id": "{{.Campaign.Title}} sekarang sudah bisa menerima donasi."},
+		Link:     "{{.CampaignLink}}",
+	},
+	helper.EmailTemplateCampaignFinished: {
+		Channels: []string{ChannelEmail, ChannelInApp, ChannelPush, ChannelWhatsApp},
+		Titles:   map[string]string{"en": "Your campaign has finished", "id": "Kampanye kamu telah selesai"},
+		Bodies:   map[string]string{"en": "{{.Campaign.Title}} collected {{.CollectedFunds}}, {{.FinalAmount}} has been added to your e-money.", "id": "{{.Campaign.Title}} mengumpulkan {{.CollectedFunds}}, {{.FinalAmount}} sudah masuk ke e-money kamu."},
+		Link:     "{{webURL}}/donate/{{.Campaign.ID}}",
+	},
+	helper.EmailTemplateEarnReward: {
+		Channels: []string{ChannelEmail, ChannelInApp, ChannelPush, ChannelWhatsApp},
+		Titles:   map[string]string{"en": "You won an exclusive campaign reward", "id": "Kamu memenangkan hadiah kampanye eksklusif"},
+		Bodies:   map[string]string{"en": "Congratulations, you won {{.Reward}}.", "id": "Selamat, kamu mendapatkan {{.Reward}}."},
+		Link:     "{{.CampaignLink}}",
+	},
+	helper.EmailTemplateRewardUpdate: {
+		Channels: []string{ChannelEmail, ChannelInApp, ChannelPush, ChannelWhatsApp},
+		Titles:   map[string]string{"en": "Your reward has been updated", "id": "Ada kabar terbaru untuk hadiah kamu"},
+		Bodies:   map[string]string{"en": "{{.Reward}} is now {{.Status}}.", "id": "Status {{.Reward}} sekarang {{.Status}}."},
+		Link:     "{{.CampaignLink}}",
+	},
+	helper.EmailTemplateWithdrawalRequest: {
+		Channels: []string{ChannelEmail, ChannelInApp, ChannelPush},
+		Titles:   map[string]string{"en": "Withdrawal requested", "id": "Penarikan dana diajukan"},
+		Bodies:   map[string]string{"en": "We received your withdrawal request of {{.Amount}}.", "id": "Kami menerima permintaan penarikan dana sebesar {{.Amount}}."},
+	},
+	helper.EmailTemplateWithdrawalApproved: {
+		Channels: []string{ChannelEmail, ChannelInApp, ChannelPush, ChannelSMS},
+		Titles:   map[string]string{"en": "Withdrawal approved", "id": "Penarikan dana disetujui"},
+		Bodies:   map[string]string{"en": "Your withdrawal of {{.Amount}} has been approved.", "id": "Penarikan dana sebesar {{.Amount}} telah disetujui."},
+	},
+	helper.EmailTemplateWithdrawalRejected: {
+		Channels: []string{ChannelEmail, ChannelInApp, ChannelPush, ChannelSMS},
+		Titles:   map[string]string{"en": "Withdrawal rejected", "id": "Penarikan dana ditolak"},
+		Bodies:   map[string]string{"en": "Your withdrawal of {{.Amount}} has been rejected.", "id": "Penarikan dana sebesar {{.Amount}} ditolak."},
+	},
+	helper.EmailTemplateAccountLocked: {
+		Channels: []string{ChannelEmail, ChannelInApp, ChannelSMS},
+		Titles:   map[string]string{"en": "Your account was temporarily locked", "id": "Akun kamu sempat dikunci"},
+		Bodies:   map[string]string{"en": "Too many failed logins from {{.IpAddress}}, logins were locked for {{.Duration}}.", "id": "Terlalu banyak login gagal dari {{.IpAddress}}, login dikunci selama {{.Duration}}."},
+		Link:     "{{.URL}}",
+	},
+}
+
+var funcs = template.FuncMap{
+	"webURL": func() string { return os.Getenv("WEB_URL") },
+}
+
+var errNoContent = errors.New("no notification content for this type")
+
+func HasContent(notificationType string) bool {
+	_, ok := contents[notificationType]
+	return ok
+}
+
+// ChannelsOf returns the channels a notification type is declared for
+func ChannelsOf(notificationType string) []string {
+	content, ok := contents[notificationType]
+
+	if !ok {
+		return []string{ChannelEmail}
+	}
+
+	return content.Channels
+}
+
+// renderContent fills title, body and link of a notification in the user locale
+func renderContent(notificationType, locale string, data any) (msg Message, err error) {
+	content, ok := contents[notificationType]
+
+	if !ok {
+		return msg, errNoContent
+	}
+
+	if _, ok := content.Titles[locale]; !ok {
+		locale = constant.MAIL_DEFAULT_LOCALE
+	}
+
+	msg.Type = notificationType
+	msg.Data = data
+
+	if msg.Title, err = execute(content.Titles[locale], data); err != nil {
+		return msg, err
+	}
+
+	if msg.Body, err = execute(content.Bodies[locale], data); err != nil {
+		return msg, err
+	}
+
+	if content.Link != "" {
+		if msg.Link, err = execute(content.Link, data); err != nil {
+			return msg, err
+		}
+	}
+
+	return msg, nil
+}
+
+func execute(source string, data any) (string, error) {
+	tpl, err := template.New("notification").Funcs(funcs).Parse(source)
+
+	if err != nil {
+		return "", err
+	}
+
+	buf := new(bytes.Buffer)
+
+	if err := tpl.Execute(buf, data); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
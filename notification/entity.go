@@ -0,0 +1,25 @@
+package notification
+
+import (
+	"database/sql"
+	"time"
+)
+
+const (
+	EventNotification = "notification"
+	EventUnreadCount  = "unread_count"
+)
+
+type (
+	// Notification is the in-app copy of an event, type is the same key as the email template
+	Notification struct {
+		ID        int          `json:"id"`
+		UserID    int          `json:"user_id"`
+		Type      string       `json:"type"`
+		Title     string       `json:"title"`
+		Body      string       `json:"body"`
+		Link      string       `json:"link"`
+		ReadAt    sql.NullTime `json:"read_at"`
+		CreatedAt time.Time    `json:"created_at"`
+	}
+)
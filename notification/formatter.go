@@ -0,0 +1,58 @@
+package notification
+
+import "time"
+
+type (
+	NotificationFormatter struct {
+		ID        int        `json:"id"`
+		Type      string     `json:"type"`
+		Title     string     `json:"title"`
+		Body      string     `json:"body"`
+		Link      string     `json:"link"`
+		IsRead    bool       `json:"is_read"`
+		ReadAt    *time.Time `json:"read_at"`
+		CreatedAt time.Time  `json:"created_at"`
+	}
+
+	NotificationListFormatter struct {
+		Notifications []NotificationFormatter `json:"notifications"`
+		UnreadCount   int64                   `json:"unread_count"`
+		Total         int64                   `json:"total"`
+		Page          int                     `json:"page"`
+		Limit         int                     `json:"limit"`
+	}
+
+	UnreadCountFormatter struct {
+		UnreadCount int64 `json:"unread_count"`
+	}
+)
+
+func FormatNotificationData(notification Notification) NotificationFormatter {
+	formatData := NotificationFormatter{
+		ID:        notification.ID,
+		Type:      notification.Type,
+		Title:     notification.Title,
+		Body:      notification.Body,
+		Link:      notification.Link,
+		IsRead:    notification.ReadAt.Valid,
+		CreatedAt: notification.CreatedAt,
+	}
+
+	if notification.ReadAt.Valid {
+		formatData.ReadAt = &notification.ReadAt.Time
+	}
+
+	return formatData
+}
+
+func FormatListNotificationData(notifications []Notification) (response []NotificationFormatter) {
+	for _, val := range notifications {
+		response = append(response, FormatNotificationData(val))
+	}
+
+	if len(response) == 0 {
+		return []NotificationFormatter{}
+	}
+
+	return response
+}
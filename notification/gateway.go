@@ -0,0 +1,85 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/constant"
+)
+
+// gatewayChannel posts {to, message, sender} as json with a bearer token, the shape most local sms
+// and whatsapp gateways accept. Any 2xx means the gateway took the message.
+type gatewayChannel struct {
+	name   string
+	url    string
+	token  string
+	sender string
+	client *http.Client
+}
+
+func newSMSGatewayChannel() *gatewayChannel {
+	return &gatewayChannel{
+		name:   ChannelSMS,
+		url:    constant.SMS_GATEWAY_URL,
+		token:  constant.SMS_GATEWAY_TOKEN,
+		sender: constant.SMS_SENDER_ID,
+		client: &http.Client{},
+	}
+}
+
+func newWhatsAppGatewayChannel() *gatewayChannel {
+	return &gatewayChannel{
+		name:   ChannelWhatsApp,
+		url:    constant.WHATSAPP_GATEWAY_URL,
+		token:  constant.WHATSAPP_GATEWAY_TOKEN,
+		client: &http.Client{},
+	}
+}
+
+func (channel *gatewayChannel) Name() string {
+	return channel.name
+}
+
+func (channel *gatewayChannel) Send(ctx context.Context, recipient Recipient, msg Message) error {
+	if channel.url == "" {
+		return fmt.Errorf("%v gateway url is not configured", channel.name)
+	}
+
+	payload, err := json.Marshal(map[string]string{
+		"to":      recipient.PhoneNumber,
+		"message": msg.Text(),
+		"sender":  channel.sender,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, channel.url, bytes.NewReader(payload))
+
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+channel.token)
+
+	res, err := channel.client.Do(req)
+
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		return fmt.Errorf("%v gateway responded %v: %s", channel.name, res.StatusCode, bytes.TrimSpace(body))
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package notification
+
+import "sync"
+
+type (
+	// Event is one server-sent event, Name becomes the "event:" line
+	Event struct {
+		Name string
+		Data any
+	}
+
+	// Hub fans events out to the open streams of a user. It lives in this process only,
+	// a user connected to another instance gets the notification on the next list call.
+	Hub struct {
+		mu          sync.RWMutex
+		subscribers map[int]map[chan Event]struct{}
+	}
+)
+
+// a slow client misses events instead of blocking the publisher
+const subscriberBuffer = 16
+
+func NewHub() *Hub {
+	return &Hub{subscribers: map[int]map[chan Event]struct{}{}}
+}
+
+func (hub *Hub) Subscribe(userID int) (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+
+	hub.mu.Lock()
+
+	if hub.subscribers[userID] == nil {
+		hub.subscribers[userID] = map[chan Event]struct{}{}
+	}
+
+	hub.subscribers[userID][ch] = struct{}{}
+	hub.mu.Unlock()
+
+	unsubscribe := func() {
+		hub.mu.Lock()
+		defer hub.mu.Unlock()
+
+		if _, ok := hub.subscribers[userID][ch]; !ok {
+			return
+		}
+
+		delete(hub.subscribers[userID], ch)
+
+		if len(hub.subscribers[userID]) == 0 {
+			delete(hub.subscribers, userID)
+		}
+
+		close(ch)
+	}
+
+	return ch, unsubscribe
+}
+
+func (hub *Hub) Publish(userID int, event Event) {
+	hub.mu.RLock()
+	defer hub.mu.RUnlock()
+
+	for ch := range hub.subscribers[userID] {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package notification
+
+import (
+	"context"
+	"errors"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/constant"
+	"github.com/WeAreAmazingTeam/tcd-backend/jobqueue"
+)
+
+const JobTypeDeliverNotification = "notification.deliver"
+
+// DeliverNotification sends one rendered message on one of the channels behind an external gateway,
+// the email template data is not kept since the email channel is never queued
+type DeliverNotification struct {
+	Channel   string    `json:"channel"`
+	Recipient Recipient `json:"recipient"`
+	Message   Message   `json:"message"`
+}
+
+func (DeliverNotification) JobType() string { return JobTypeDeliverNotification }
+
+// queued channels call a gateway, the others only write to the database and stay in the request
+func isQueuedChannel(name string) bool {
+	return name == ChannelSMS || name == ChannelWhatsApp || name == ChannelPush
+}
+
+func (svc *service) RegisterJobHandlers(registry *jobqueue.Registry) {
+	jobqueue.Handle(registry, svc.deliverQueued)
+}
+
+func (svc *service) deliverQueued(job DeliverNotification) error {
+	if _, ok := svc.channels[job.Channel]; !ok {
+		return errors.New("notification channel " + job.Channel + " is not registered")
+	}
+
+	return svc.deliver(job.Channel, job.Recipient, job.Message)
+}
+
+// deliver sends on one channel, device tokens the push gateway rejected are removed instead of failing
+func (svc *service) deliver(name string, to Recipient, msg Message) error {
+	ctx, cancel := context.WithTimeout(context.Background(), constant.NOTIFICATION_CHANNEL_TIMEOUT)
+	defer cancel()
+
+	err := svc.channels[name].Send(ctx, to, msg)
+
+	var invalidTokens *InvalidTokensError
+
+	if errors.As(err, &invalidTokens) {
+		_, err = svc.userRepo.DeleteDeviceTokensByToken(invalidTokens.Tokens)
+	}
+
+	return err
+}
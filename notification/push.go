@@ -0,0 +1,115 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/constant"
+)
+
+type (
+	// fcmChannel sends one multicast request per notification to every device of the user
+	fcmChannel struct {
+		url    string
+		key    string
+		client *http.Client
+	}
+
+	fcmResponse struct {
+		Results []struct {
+			Error string `json:"error"`
+		} `json:"results"`
+	}
+
+	// InvalidTokensError lists device tokens the push provider no longer knows, they should be forgotten
+	InvalidTokensError struct {
+		Tokens []string
+	}
+)
+
+func (err *InvalidTokensError) Error() string {
+	return fmt.Sprintf("%d device tokens are no longer registered", len(err.Tokens))
+}
+
+func newFCMChannel() *fcmChannel {
+	return &fcmChannel{
+		url:    constant.FCM_URL,
+		key:    constant.FCM_SERVER_KEY,
+		client: &http.Client{},
+	}
+}
+
+func (channel *fcmChannel) Name() string {
+	return ChannelPush
+}
+
+func (channel *fcmChannel) Send(ctx context.Context, recipient Recipient, msg Message) error {
+	if channel.key == "" {
+		return errors.New("fcm server key is not configured")
+	}
+
+	payload, err := json.Marshal(map[string]any{
+		"registration_ids": recipient.DeviceTokens,
+		"notification": map[string]string{
+			"title": msg.Title,
+			"body":  msg.Body,
+		},
+		"data": map[string]string{
+			"type": msg.Type,
+			"link": msg.Link,
+		},
+	})
+
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, channel.url, bytes.NewReader(payload))
+
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "key="+channel.key)
+
+	res, err := channel.client.Do(req)
+
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		return fmt.Errorf("fcm responded %v: %s", res.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var result fcmResponse
+
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return err
+	}
+
+	// results come back in the same order as registration_ids
+	invalid := []string{}
+
+	for i, val := range result.Results {
+		if i < len(recipient.DeviceTokens) && (val.Error == "NotRegistered" || val.Error == "InvalidRegistration") {
+			invalid = append(invalid, recipient.DeviceTokens[i])
+		}
+	}
+
+	if len(invalid) > 0 {
+		return &InvalidTokensError{Tokens: invalid}
+	}
+
+	return nil
+}
@@ -0,0 +1,19 @@
+package notification
+
+import "gorm.io/gorm"
+
+type Repository interface {
+	SaveNotification(Notification) (Notification, error)
+	GetNotificationsByUserID(userID int, unreadOnly bool, limit, offset int) ([]Notification, int64, error)
+	CountUnreadNotification(userID int) (int64, error)
+	MarkNotificationRead(userID, id int) (Notification, error)
+	MarkAllNotificationRead(userID int) (int64, error)
+}
+
+type repository struct {
+	DB *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *repository {
+	return &repository{DB: db}
+}
@@ -0,0 +1,72 @@
+package notification
+
+import (
+	"errors"
+	"time"
+)
+
+func (repo *repository) SaveNotification(notification Notification) (Notification, error) {
+	if err := repo.DB.Create(&notification).Error; err != nil {
+		return notification, err
+	}
+	return notification, nil
+}
+
+func (repo *repository) GetNotificationsByUserID(userID int, unreadOnly bool, limit, offset int) (notifications []Notification, total int64, err error) {
+	query := repo.DB.Model(&Notification{}).Where("user_id = ?", userID)
+
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return notifications, total, err
+	}
+
+	if err := query.Order("id DESC").Limit(limit).Offset(offset).Find(&notifications).Error; err != nil {
+		return notifications, total, err
+	}
+
+	return notifications, total, nil
+}
+
+func (repo *repository) CountUnreadNotification(userID int) (total int64, err error) {
+	if err := repo.DB.Model(&Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Count(&total).Error; err != nil {
+		return total, err
+	}
+	return total, nil
+}
+
+// MarkNotificationRead is scoped to the owner so one user cannot read another user notification
+func (repo *repository) MarkNotificationRead(userID, id int) (notification Notification, err error) {
+	if err := repo.DB.Where("id = ? AND user_id = ?", id, userID).Find(&notification).Error; err != nil {
+		return notification, err
+	}
+
+	if notification.ID == 0 {
+		return notification, errors.New("sql: no rows in result set")
+	}
+
+	if notification.ReadAt.Valid {
+		return notification, nil
+	}
+
+	notification.ReadAt.Time = time.Now()
+	notification.ReadAt.Valid = true
+
+	if err := repo.DB.Model(&notification).Update("read_at", notification.ReadAt).Error; err != nil {
+		return notification, err
+	}
+
+	return notification, nil
+}
+
+func (repo *repository) MarkAllNotificationRead(userID int) (int64, error) {
+	result := repo.DB.Model(&Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Update("read_at", time.Now())
+
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
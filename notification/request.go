@@ -0,0 +1,20 @@
+package notification
+
+import "github.com/WeAreAmazingTeam/tcd-backend/user"
+
+type (
+	RequestGetNotifications struct {
+		Page       int  `form:"page"`
+		Limit      int  `form:"limit"`
+		UnreadOnly bool `form:"unread_only"`
+		User       user.User
+	}
+
+	RequestGetNotificationByID struct {
+		ID int `uri:"id" binding:"required"`
+	}
+
+	RequestMarkNotificationRead struct {
+		User user.User
+	}
+)
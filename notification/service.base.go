@@ -0,0 +1,47 @@
+package notification
+
+import (
+	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+	"github.com/WeAreAmazingTeam/tcd-backend/jobqueue"
+	"github.com/WeAreAmazingTeam/tcd-backend/user"
+)
+
+type Service interface {
+	Notify(recipient helper.NotificationRecipient, notificationType string, data any) error
+
+	GetNotifications(RequestGetNotifications) (NotificationListFormatter, error)
+	CountUnread(userID int) (int64, error)
+	MarkRead(RequestGetNotificationByID, RequestMarkNotificationRead) (Notification, error)
+	MarkAllRead(RequestMarkNotificationRead) (int64, error)
+	Subscribe(userID int) (<-chan Event, func())
+
+	RegisterJobHandlers(*jobqueue.Registry)
+}
+
+type service struct {
+	repo     Repository
+	userRepo user.Repository
+	hub      *Hub
+	channels map[string]Channel
+	queue    jobqueue.Service
+}
+
+// NewService registers the given channels next to the in-app channel, a type declaring
+// a channel that is not registered here simply skips it
+func NewService(repository Repository, userRepository user.Repository, hub *Hub, channels []Channel, queue jobqueue.Service) *service {
+	svc := &service{
+		repo:     repository,
+		userRepo: userRepository,
+		hub:      hub,
+		channels: map[string]Channel{},
+		queue:    queue,
+	}
+
+	svc.channels[ChannelInApp] = &inAppChannel{svc: svc}
+
+	for _, channel := range channels {
+		svc.channels[channel.Name()] = channel
+	}
+
+	return svc
+}
@@ -0,0 +1,216 @@
+package notification
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+	"github.com/WeAreAmazingTeam/tcd-backend/jobqueue"
+)
+
+const (
+	defaultLimit = 20
+	maxLimit     = 100
+)
+
+// Notify renders the type once and hands it to every channel the type declares,
+// one failing channel does not stop the others
+func (svc *service) Notify(recipient helper.NotificationRecipient, notificationType string, data any) error {
+	msg := Message{Type: notificationType, Data: data}
+
+	if HasContent(notificationType) {
+		rendered, err := renderContent(notificationType, recipient.Locale, data)
+
+		if err != nil {
+			return err
+		}
+
+		msg = rendered
+	}
+
+	channels := ChannelsOf(notificationType)
+	to := Recipient{UserID: recipient.UserID, Email: recipient.Email, Locale: recipient.Locale}
+
+	if recipient.UserID != 0 && needsChannel(channels, ChannelSMS, ChannelWhatsApp, ChannelPush) {
+		if err := svc.loadAddresses(&to); err != nil {
+			return err
+		}
+	}
+
+	failures := []string{}
+	jobs := []jobqueue.Job{}
+
+	for _, name := range channels {
+		if _, ok := svc.channels[name]; !ok || !hasAddress(name, to) {
+			continue
+		}
+
+		// the gateway channels are sent by the job queue, the request does not wait for them
+		if isQueuedChannel(name) {
+			queuedMsg := msg
+			queuedMsg.Data = nil
+			jobs = append(jobs, DeliverNotification{Channel: name, Recipient: to, Message: queuedMsg})
+			continue
+		}
+
+		if err := svc.deliver(name, to, msg); err != nil {
+			failures = append(failures, fmt.Sprintf("%v: %v", name, err.Error()))
+		}
+	}
+
+	if err := svc.queue.Enqueue(jobs...); err != nil {
+		failures = append(failures, fmt.Sprintf("queue: %v", err.Error()))
+	}
+
+	if len(failures) > 0 {
+		return errors.New(strings.Join(failures, "; "))
+	}
+
+	return nil
+}
+
+// loadAddresses fills the phone number and device tokens, the callers only know the email
+func (svc *service) loadAddresses(to *Recipient) error {
+	userData, err := svc.userRepo.GetUserByID(to.UserID)
+
+	if err != nil {
+		return err
+	}
+
+	deviceTokens, err := svc.userRepo.GetDeviceTokensByUserID(to.UserID)
+
+	if err != nil {
+		return err
+	}
+
+	to.PhoneNumber = userData.PhoneNumber
+
+	for _, val := range deviceTokens {
+		to.DeviceTokens = append(to.DeviceTokens, val.Token)
+	}
+
+	return nil
+}
+
+func needsChannel(channels []string, names ...string) bool {
+	for _, channel := range channels {
+		for _, name := range names {
+			if channel == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func hasAddress(channel string, to Recipient) bool {
+	switch channel {
+	case ChannelEmail:
+		return to.Email != ""
+	case ChannelInApp:
+		return to.UserID != 0
+	case ChannelSMS, ChannelWhatsApp:
+		return to.PhoneNumber != ""
+	case ChannelPush:
+		return len(to.DeviceTokens) > 0
+	}
+	return true
+}
+
+// saveAndPublish stores the in-app copy and pushes it to the open streams of the user
+func (svc *service) saveAndPublish(userID int, msg Message) error {
+	notification, err := svc.repo.SaveNotification(Notification{
+		UserID: userID,
+		Type:   msg.Type,
+		Title:  msg.Title,
+		Body:   msg.Body,
+		Link:   msg.Link,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	svc.hub.Publish(userID, Event{Name: EventNotification, Data: FormatNotificationData(notification)})
+	svc.publishUnreadCount(userID)
+
+	return nil
+}
+
+func (svc *service) GetNotifications(req RequestGetNotifications) (formatData NotificationListFormatter, err error) {
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+
+	if req.Limit <= 0 {
+		req.Limit = defaultLimit
+	}
+
+	if req.Limit > maxLimit {
+		req.Limit = maxLimit
+	}
+
+	notifications, total, err := svc.repo.GetNotificationsByUserID(req.User.ID, req.UnreadOnly, req.Limit, (req.Page-1)*req.Limit)
+
+	if err != nil {
+		return formatData, err
+	}
+
+	unreadCount, err := svc.repo.CountUnreadNotification(req.User.ID)
+
+	if err != nil {
+		return formatData, err
+	}
+
+	return NotificationListFormatter{
+		Notifications: FormatListNotificationData(notifications),
+		UnreadCount:   unreadCount,
+		Total:         total,
+		Page:          req.Page,
+		Limit:         req.Limit,
+	}, nil
+}
+
+func (svc *service) CountUnread(userID int) (int64, error) {
+	return svc.repo.CountUnreadNotification(userID)
+}
+
+func (svc *service) MarkRead(reqDetail RequestGetNotificationByID, req RequestMarkNotificationRead) (Notification, error) {
+	notification, err := svc.repo.MarkNotificationRead(req.User.ID, reqDetail.ID)
+
+	if err != nil {
+		return notification, err
+	}
+
+	svc.publishUnreadCount(req.User.ID)
+
+	return notification, nil
+}
+
+func (svc *service) MarkAllRead(req RequestMarkNotificationRead) (int64, error) {
+	updated, err := svc.repo.MarkAllNotificationRead(req.User.ID)
+
+	if err != nil {
+		return updated, err
+	}
+
+	svc.publishUnreadCount(req.User.ID)
+
+	return updated, nil
+}
+
+func (svc *service) Subscribe(userID int) (<-chan Event, func()) {
+	return svc.hub.Subscribe(userID)
+}
+
+// publishUnreadCount keeps the badge of every open tab in sync
+func (svc *service) publishUnreadCount(userID int) {
+	unreadCount, err := svc.repo.CountUnreadNotification(userID)
+
+	if err != nil {
+		return
+	}
+
+	svc.hub.Publish(userID, Event{Name: EventUnreadCount, Data: UnreadCountFormatter{UnreadCount: unreadCount}})
+}
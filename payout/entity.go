@@ -0,0 +1,76 @@
+package payout
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	// ProviderNone turns payouts off, bank accounts can not be verified and withdrawals are paid by hand
+	ProviderNone   = ""
+	ProviderFake   = "fake"
+	ProviderXendit = "xendit"
+)
+
+// the states a callback or a lookup reports for one disbursement
+const (
+	DisbursementPending   = "pending"
+	DisbursementCompleted = "completed"
+	DisbursementFailed    = "failed"
+)
+
+// bank codes accepted for payouts, the providers map them to their own codes
+var Banks = map[string]string{
+	"bca":     "BCA",
+	"bni":     "BNI",
+	"bri":     "BRI",
+	"mandiri": "Mandiri",
+	"permata": "Permata",
+	"cimb":    "CIMB Niaga",
+	"danamon": "Danamon",
+	"btn":     "BTN",
+	"bsi":     "Bank Syariah Indonesia",
+}
+
+var (
+	ErrAccountNotFound = errors.New("the bank account was not found")
+	ErrDisabled        = errors.New("payouts are not configured")
+	ErrInvalidCallback = errors.New("invalid payout callback token")
+	// ErrRejected is a definite answer, the provider refused the request and did not take any of it. Every other
+	// error of a call may have been taken by the provider with the answer lost on the way.
+	ErrRejected             = errors.New("the payout provider rejected the request")
+	ErrDisbursementNotFound = errors.New("the payout provider has no disbursement with this reference")
+)
+
+type (
+	// Account is what the user typed, HolderName is only a hint for the fake, real providers look the number up
+	Account struct {
+		BankCode      string
+		AccountNumber string
+		HolderName    string
+	}
+
+	// Disbursement is one transfer of a batch, ExternalID comes back in the status callback
+	Disbursement struct {
+		ExternalID string
+		Account
+		Amount      int64
+		Description string
+	}
+
+	DisbursementUpdate struct {
+		ExternalID    string
+		Status        string
+		FailureReason string
+	}
+)
+
+func IsSupportedBank(code string) bool {
+	_, ok := Banks[code]
+	return ok
+}
+
+// SameHolderName compares names the way banks print them, case and spacing do not matter
+func SameHolderName(a, b string) bool {
+	return strings.Join(strings.Fields(strings.ToUpper(a)), " ") == strings.Join(strings.Fields(strings.ToUpper(b)), " ")
+}
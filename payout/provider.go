@@ -0,0 +1,142 @@
+package payout
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+type (
+	// Provider talks to the payout partner, every call is made with a deadline from the caller
+	Provider interface {
+		Name() string
+		// InquireAccount returns the holder name the bank has for the account, ErrAccountNotFound when there is none
+		InquireAccount(ctx context.Context, account Account) (holderName string, err error)
+		// Disburse submits one batch and returns the provider id of it. The reference is the idempotency key,
+		// a batch sent again with the same reference is not paid twice.
+		Disburse(ctx context.Context, reference string, disbursements []Disbursement) (providerBatchID string, err error)
+		// GetDisbursement looks one disbursement up by its external id, ErrDisbursementNotFound when the provider never took it
+		GetDisbursement(ctx context.Context, externalID string) (DisbursementUpdate, error)
+		// ParseCallback checks a status callback came from the provider and returns what it reports
+		ParseCallback(header http.Header, body []byte) ([]DisbursementUpdate, error)
+	}
+
+	disabledProvider struct{}
+
+	// FakeProvider answers locally, for development and tests. Registered accounts answer with their name,
+	// numbers starting with "000" do not exist and every other account answers with the name it was asked about.
+	// Disbursements are only kept, post a callback to settle them.
+	FakeProvider struct {
+		mu       sync.Mutex
+		accounts map[string]string
+		batches  map[string][]Disbursement
+	}
+)
+
+// NewProvider builds the payout provider for a name, the credentials come from constant
+func NewProvider(name string) (Provider, error) {
+	switch name {
+	case ProviderNone:
+		return disabledProvider{}, nil
+	case ProviderFake:
+		return NewFakeProvider(), nil
+	case ProviderXendit:
+		return newXenditProvider(), nil
+	}
+
+	return nil, fmt.Errorf("unknown payout provider %q", name)
+}
+
+func (disabledProvider) Name() string {
+	return ProviderNone
+}
+
+func (disabledProvider) InquireAccount(ctx context.Context, account Account) (string, error) {
+	return "", ErrDisabled
+}
+
+func (disabledProvider) Disburse(ctx context.Context, reference string, disbursements []Disbursement) (string, error) {
+	return "", ErrDisabled
+}
+
+func (disabledProvider) GetDisbursement(ctx context.Context, externalID string) (DisbursementUpdate, error) {
+	return DisbursementUpdate{}, ErrDisabled
+}
+
+func (disabledProvider) ParseCallback(header http.Header, body []byte) ([]DisbursementUpdate, error) {
+	return nil, ErrDisabled
+}
+
+func NewFakeProvider() *FakeProvider {
+	return &FakeProvider{accounts: map[string]string{}, batches: map[string][]Disbursement{}}
+}
+
+func (provider *FakeProvider) Name() string {
+	return ProviderFake
+}
+
+// Register makes the fake answer with holderName for this account
+func (provider *FakeProvider) Register(bankCode, accountNumber, holderName string) {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	provider.accounts[bankCode+":"+accountNumber] = holderName
+}
+
+func (provider *FakeProvider) InquireAccount(ctx context.Context, account Account) (string, error) {
+	provider.mu.Lock()
+	holderName, ok := provider.accounts[account.BankCode+":"+account.AccountNumber]
+	provider.mu.Unlock()
+
+	fmt.Printf("[PAYOUT] fake inquiry for %v account %v\n", account.BankCode, account.AccountNumber)
+
+	if ok {
+		return holderName, nil
+	}
+
+	if strings.HasPrefix(account.AccountNumber, "000") {
+		return "", ErrAccountNotFound
+	}
+
+	return account.HolderName, nil
+}
+
+func (provider *FakeProvider) Disburse(ctx context.Context, reference string, disbursements []Disbursement) (string, error) {
+	provider.mu.Lock()
+	provider.batches[reference] = append([]Disbursement{}, disbursements...)
+	provider.mu.Unlock()
+
+	fmt.Printf("[PAYOUT] fake batch %v with %v disbursements\n", reference, len(disbursements))
+
+	return "fake-" + reference, nil
+}
+
+// Batch returns what was submitted with the reference
+func (provider *FakeProvider) Batch(reference string) []Disbursement {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	return append([]Disbursement{}, provider.batches[reference]...)
+}
+
+// GetDisbursement answers pending for everything submitted, the fake does not know what the callbacks settled
+func (provider *FakeProvider) GetDisbursement(ctx context.Context, externalID string) (DisbursementUpdate, error) {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	for _, disbursements := range provider.batches {
+		for _, val := range disbursements {
+			if val.ExternalID == externalID {
+				return DisbursementUpdate{ExternalID: externalID, Status: DisbursementPending}, nil
+			}
+		}
+	}
+
+	return DisbursementUpdate{}, ErrDisbursementNotFound
+}
+
+func (provider *FakeProvider) ParseCallback(header http.Header, body []byte) ([]DisbursementUpdate, error) {
+	return parseCallback(header, body)
+}
@@ -0,0 +1,247 @@
+package payout
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/constant"
+)
+
+// a 404 is a rejection for a submit and a missing disbursement for a lookup
+var errNotFound = fmt.Errorf("%w, not found", ErrRejected)
+
+// xenditProvider follows the xendit batch disbursement api: basic auth with the secret key, upper case bank
+// codes, and callbacks carrying the token from the dashboard in the X-Callback-Token header.
+type xenditProvider struct {
+	url       string
+	secretKey string
+	client    *http.Client
+}
+
+type (
+	xenditDisbursement struct {
+		ExternalID        string `json:"external_id"`
+		Amount            int64  `json:"amount"`
+		BankCode          string `json:"bank_code"`
+		BankAccountName   string `json:"bank_account_name"`
+		BankAccountNumber string `json:"bank_account_number"`
+		Description       string `json:"description"`
+	}
+
+	xenditCallbackDisbursement struct {
+		ExternalID  string `json:"external_id"`
+		Status      string `json:"status"`
+		FailureCode string `json:"failure_code"`
+	}
+
+	// the callback of a whole batch lists its disbursements, the callback of a single one is the disbursement itself
+	xenditCallback struct {
+		xenditCallbackDisbursement
+		Disbursements []xenditCallbackDisbursement `json:"disbursements"`
+	}
+)
+
+func newXenditProvider() *xenditProvider {
+	return &xenditProvider{
+		url:       strings.TrimRight(constant.PAYOUT_BASE_URL, "/"),
+		secretKey: constant.PAYOUT_SECRET_KEY,
+		client:    &http.Client{},
+	}
+}
+
+func (provider *xenditProvider) Name() string {
+	return ProviderXendit
+}
+
+func (provider *xenditProvider) InquireAccount(ctx context.Context, account Account) (string, error) {
+	var res struct {
+		Status                string `json:"status"`
+		BankAccountHolderName string `json:"bank_account_holder_name"`
+	}
+
+	err := provider.post(ctx, "/bank_account_data_requests", "", map[string]string{
+		"bank_code":           strings.ToUpper(account.BankCode),
+		"bank_account_number": account.AccountNumber,
+	}, &res)
+
+	if err != nil {
+		return "", err
+	}
+
+	if res.Status != "SUCCESS" || res.BankAccountHolderName == "" {
+		return "", ErrAccountNotFound
+	}
+
+	return res.BankAccountHolderName, nil
+}
+
+func (provider *xenditProvider) Disburse(ctx context.Context, reference string, disbursements []Disbursement) (string, error) {
+	items := []xenditDisbursement{}
+
+	for _, val := range disbursements {
+		items = append(items, xenditDisbursement{
+			ExternalID:        val.ExternalID,
+			Amount:            val.Amount,
+			BankCode:          strings.ToUpper(val.BankCode),
+			BankAccountName:   val.HolderName,
+			BankAccountNumber: val.AccountNumber,
+			Description:       val.Description,
+		})
+	}
+
+	var res struct {
+		ID string `json:"id"`
+	}
+
+	err := provider.post(ctx, "/batch_disbursements", reference, map[string]any{
+		"reference":     reference,
+		"disbursements": items,
+	}, &res)
+
+	if err != nil {
+		return "", err
+	}
+
+	return res.ID, nil
+}
+
+// GetDisbursement looks the disbursement up by the external id the batch gave it
+func (provider *xenditProvider) GetDisbursement(ctx context.Context, externalID string) (DisbursementUpdate, error) {
+	var res []xenditCallbackDisbursement
+
+	err := provider.do(ctx, http.MethodGet, "/disbursements?external_id="+url.QueryEscape(externalID), "", nil, &res)
+
+	if err != nil {
+		if errors.Is(err, errNotFound) {
+			return DisbursementUpdate{}, ErrDisbursementNotFound
+		}
+
+		return DisbursementUpdate{}, err
+	}
+
+	if len(res) == 0 {
+		return DisbursementUpdate{}, ErrDisbursementNotFound
+	}
+
+	update := DisbursementUpdate{ExternalID: externalID, Status: DisbursementPending}
+
+	switch strings.ToUpper(res[0].Status) {
+	case "COMPLETED":
+		update.Status = DisbursementCompleted
+	case "FAILED":
+		update.Status = DisbursementFailed
+		update.FailureReason = res[0].FailureCode
+	}
+
+	return update, nil
+}
+
+func (provider *xenditProvider) ParseCallback(header http.Header, body []byte) ([]DisbursementUpdate, error) {
+	return parseCallback(header, body)
+}
+
+func (provider *xenditProvider) post(ctx context.Context, path, idempotencyKey string, payload, out any) error {
+	return provider.do(ctx, http.MethodPost, path, idempotencyKey, payload, out)
+}
+
+// do wraps ErrRejected only around the answers that say the request was refused. A timeout, a reset
+// connection, a 5xx, 408, 409 or 429 leave open whether the provider took it.
+func (provider *xenditProvider) do(ctx context.Context, method, path, idempotencyKey string, payload, out any) error {
+	var body io.Reader
+
+	if payload != nil {
+		encoded, err := json.Marshal(payload)
+
+		if err != nil {
+			return err
+		}
+
+		body = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, provider.url+path, body)
+
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.SetBasicAuth(provider.secretKey, "")
+
+	if idempotencyKey != "" {
+		req.Header.Set("X-IDEMPOTENCY-KEY", idempotencyKey)
+	}
+
+	res, err := provider.client.Do(req)
+
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		resBody, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+
+		switch {
+		case res.StatusCode == http.StatusNotFound:
+			return fmt.Errorf("%w, xendit responded %v: %s", errNotFound, res.StatusCode, string(resBody))
+		case res.StatusCode >= 400 && res.StatusCode < 500 && res.StatusCode != http.StatusRequestTimeout &&
+			res.StatusCode != http.StatusConflict && res.StatusCode != http.StatusTooManyRequests:
+			return fmt.Errorf("%w, xendit responded %v: %s", ErrRejected, res.StatusCode, string(resBody))
+		}
+
+		return fmt.Errorf("xendit responded %v: %s", res.StatusCode, string(resBody))
+	}
+
+	return json.NewDecoder(io.LimitReader(res.Body, 1<<20)).Decode(out)
+}
+
+// parseCallback reads the xendit callback shape, the fake takes the same one so it can be settled by hand
+func parseCallback(header http.Header, body []byte) ([]DisbursementUpdate, error) {
+	token := header.Get("X-Callback-Token")
+
+	// no token configured means no callback is trusted
+	if constant.PAYOUT_CALLBACK_TOKEN == "" || subtle.ConstantTimeCompare([]byte(token), []byte(constant.PAYOUT_CALLBACK_TOKEN)) != 1 {
+		return nil, ErrInvalidCallback
+	}
+
+	var callback xenditCallback
+
+	if err := json.Unmarshal(body, &callback); err != nil {
+		return nil, err
+	}
+
+	updates := []DisbursementUpdate{}
+
+	if len(callback.Disbursements) == 0 {
+		callback.Disbursements = append(callback.Disbursements, callback.xenditCallbackDisbursement)
+	}
+
+	for _, val := range callback.Disbursements {
+		update := DisbursementUpdate{ExternalID: val.ExternalID}
+
+		switch strings.ToUpper(val.Status) {
+		case "COMPLETED":
+			update.Status = DisbursementCompleted
+		case "FAILED":
+			update.Status = DisbursementFailed
+			update.FailureReason = val.FailureCode
+		default:
+			// still on its way
+			continue
+		}
+
+		updates = append(updates, update)
+	}
+
+	return updates, nil
+}
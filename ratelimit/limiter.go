@@ -0,0 +1,106 @@
+package ratelimit
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+type LockoutConfig struct {
+	// failed logins allowed inside Window before the account is locked
+	Threshold int64
+	Window    time.Duration
+	// first lock lasts BaseDuration, every next lock doubles it up to MaxDuration
+	BaseDuration time.Duration
+	MaxDuration  time.Duration
+}
+
+type Limiter struct {
+	store   Store
+	lockout LockoutConfig
+}
+
+func NewLimiter(store Store, lockout LockoutConfig) *Limiter {
+	return &Limiter{store: store, lockout: lockout}
+}
+
+// Allow counts one hit for key under policy, retryAfter is set when the hit is over the limit
+func (limiter *Limiter) Allow(policy Policy, key string) (allowed bool, retryAfter time.Duration, err error) {
+	count, ttl, err := limiter.store.Incr(fmt.Sprintf("%s:%s", policy.Name, key), policy.Window)
+
+	if err != nil {
+		return true, 0, err
+	}
+
+	if count > policy.Limit {
+		return false, ttl, nil
+	}
+
+	return true, 0, nil
+}
+
+// LoginLockedFor returns how long the account is still locked, zero when it is not locked
+func (limiter *Limiter) LoginLockedFor(account string) (time.Duration, error) {
+	return limiter.store.TTL(limiter.lockKey(account))
+}
+
+// RecordLoginFailure returns the lock duration when this failure locks the account, zero otherwise
+func (limiter *Limiter) RecordLoginFailure(account string) (time.Duration, error) {
+	failures, _, err := limiter.store.Incr(limiter.failureKey(account), limiter.lockout.Window)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if failures < limiter.lockout.Threshold {
+		return 0, nil
+	}
+
+	if err := limiter.store.Delete(limiter.failureKey(account)); err != nil {
+		return 0, err
+	}
+
+	// the lock counter remembers previous locks for a day, so repeated attacks get longer locks
+	locks, _, err := limiter.store.Incr(limiter.lockCountKey(account), 24*time.Hour)
+
+	if err != nil {
+		return 0, err
+	}
+
+	lockedFor := limiter.lockout.BaseDuration
+
+	for i := int64(1); i < locks && lockedFor < limiter.lockout.MaxDuration; i++ {
+		lockedFor *= 2
+	}
+
+	if lockedFor > limiter.lockout.MaxDuration {
+		lockedFor = limiter.lockout.MaxDuration
+	}
+
+	// start the lock window fresh, an old lock must not shorten the new one
+	if err := limiter.store.Delete(limiter.lockKey(account)); err != nil {
+		return 0, err
+	}
+
+	if _, _, err := limiter.store.Incr(limiter.lockKey(account), lockedFor); err != nil {
+		return 0, err
+	}
+
+	return lockedFor, nil
+}
+
+func (limiter *Limiter) ResetLoginFailures(account string) error {
+	return limiter.store.Delete(limiter.failureKey(account))
+}
+
+func (limiter *Limiter) failureKey(account string) string {
+	return "login_failure:" + strings.ToLower(account)
+}
+
+func (limiter *Limiter) lockCountKey(account string) string {
+	return "login_lock_count:" + strings.ToLower(account)
+}
+
+func (limiter *Limiter) lockKey(account string) string {
+	return "login_lock:" + strings.ToLower(account)
+}
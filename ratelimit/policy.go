@@ -0,0 +1,63 @@
+package ratelimit
+
+import (
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	PolicyLogin                = "login"
+	PolicyTwoFactor            = "two_factor"
+	PolicyRegister             = "register"
+	PolicyVerifyEmail          = "verify_email"
+	PolicyForgotPassword       = "forgot_password"
+	PolicyForgotPasswordToken  = "forgot_password_token"
+	PolicyResetPassword        = "reset_password"
+	PolicyAnonymousTransaction = "anonymous_transaction"
+	PolicyActivityLog          = "activity_log"
+
+	// counted per account, so spreading the attempts over many addresses does not help
+	PolicyLoginAccount          = "login_account"
+	PolicyTwoFactorAccount      = "two_factor_account"
+	PolicyForgotPasswordAccount = "forgot_password_account"
+)
+
+type Policy struct {
+	Name   string
+	Limit  int64
+	Window time.Duration
+}
+
+// ParsePolicy reads a spec like "10/1m" (10 hits every minute), def is used when spec is empty or invalid
+func ParsePolicy(name, spec string, def Policy) Policy {
+	def.Name = name
+
+	if spec == "" {
+		return def
+	}
+
+	parts := strings.SplitN(spec, "/", 2)
+
+	if len(parts) != 2 {
+		log.Printf("invalid rate limit policy %s: %q, using default", name, spec)
+		return def
+	}
+
+	limit, err := strconv.ParseInt(strings.TrimSpace(parts[0]), 10, 64)
+
+	if err != nil || limit <= 0 {
+		log.Printf("invalid rate limit policy %s: %q, using default", name, spec)
+		return def
+	}
+
+	window, err := time.ParseDuration(strings.TrimSpace(parts[1]))
+
+	if err != nil || window <= 0 {
+		log.Printf("invalid rate limit policy %s: %q, using default", name, spec)
+		return def
+	}
+
+	return Policy{Name: name, Limit: limit, Window: window}
+}
@@ -0,0 +1,201 @@
+package ratelimit
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+// Store keeps fixed window counters, every key expires by itself after its window
+type Store interface {
+	// Incr adds one hit to key and returns the total hits and the time left of the window
+	Incr(key string, window time.Duration) (count int64, ttl time.Duration, err error)
+	// TTL returns the time left of key, zero when the key does not exist
+	TTL(key string) (time.Duration, error)
+	Delete(key string) error
+}
+
+type redisStore struct {
+	client *redis.Client
+	prefix string
+}
+
+func NewRedisStore(client *redis.Client) *redisStore {
+	return &redisStore{client: client, prefix: "tcd:ratelimit:"}
+}
+
+func (store *redisStore) Incr(key string, window time.Duration) (int64, time.Duration, error) {
+	key = store.prefix + key
+
+	pipe := store.client.TxPipeline()
+	incr := pipe.Incr(key)
+	pttl := pipe.PTTL(key)
+
+	if _, err := pipe.Exec(); err != nil {
+		return 0, 0, err
+	}
+
+	ttl := pttl.Val()
+
+	// first hit of the window, or a key left without expiry
+	if ttl < 0 {
+		if err := store.client.PExpire(key, window).Err(); err != nil {
+			return 0, 0, err
+		}
+
+		ttl = window
+	}
+
+	return incr.Val(), ttl, nil
+}
+
+func (store *redisStore) TTL(key string) (time.Duration, error) {
+	ttl, err := store.client.PTTL(store.prefix + key).Result()
+
+	if err != nil {
+		return 0, err
+	}
+
+	if ttl < 0 {
+		return 0, nil
+	}
+
+	return ttl, nil
+}
+
+func (store *redisStore) Delete(key string) error {
+	return store.client.Del(store.prefix + key).Err()
+}
+
+type memoryEntry struct {
+	count     int64
+	expiredAt time.Time
+}
+
+type memoryStore struct {
+	mutex   sync.Mutex
+	entries map[string]memoryEntry
+}
+
+// NewMemoryStore only counts hits of this process, use it when Redis is not available
+func NewMemoryStore() *memoryStore {
+	store := &memoryStore{entries: map[string]memoryEntry{}}
+
+	go store.cleanup(time.Minute)
+
+	return store
+}
+
+func (store *memoryStore) Incr(key string, window time.Duration) (int64, time.Duration, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	now := time.Now()
+	entry, ok := store.entries[key]
+
+	if !ok || !now.Before(entry.expiredAt) {
+		entry = memoryEntry{expiredAt: now.Add(window)}
+	}
+
+	entry.count++
+	store.entries[key] = entry
+
+	return entry.count, entry.expiredAt.Sub(now), nil
+}
+
+func (store *memoryStore) TTL(key string) (time.Duration, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	entry, ok := store.entries[key]
+
+	if !ok {
+		return 0, nil
+	}
+
+	ttl := time.Until(entry.expiredAt)
+
+	if ttl <= 0 {
+		delete(store.entries, key)
+		return 0, nil
+	}
+
+	return ttl, nil
+}
+
+func (store *memoryStore) Delete(key string) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	delete(store.entries, key)
+
+	return nil
+}
+
+func (store *memoryStore) cleanup(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+
+	for range ticker.C {
+		store.mutex.Lock()
+
+		now := time.Now()
+
+		for key, entry := range store.entries {
+			if !now.Before(entry.expiredAt) {
+				delete(store.entries, key)
+			}
+		}
+
+		store.mutex.Unlock()
+	}
+}
+
+type fallbackStore struct {
+	primary  Store
+	fallback Store
+}
+
+// NewFallbackStore uses primary and switches to fallback for every call primary fails,
+// so an unreachable Redis degrades to per-process limits instead of no limits at all
+func NewFallbackStore(primary, fallback Store) *fallbackStore {
+	return &fallbackStore{primary: primary, fallback: fallback}
+}
+
+func (store *fallbackStore) Incr(key string, window time.Duration) (int64, time.Duration, error) {
+	count, ttl, err := store.primary.Incr(key, window)
+
+	if err != nil {
+		log.Println("rate limit store unavailable, using fallback, err: ", err.Error())
+		return store.fallback.Incr(key, window)
+	}
+
+	return count, ttl, nil
+}
+
+func (store *fallbackStore) TTL(key string) (time.Duration, error) {
+	ttl, err := store.primary.TTL(key)
+
+	if err != nil {
+		log.Println("rate limit store unavailable, using fallback, err: ", err.Error())
+		return store.fallback.TTL(key)
+	}
+
+	// a lock set while primary was down only lives in fallback
+	if ttl == 0 {
+		return store.fallback.TTL(key)
+	}
+
+	return ttl, nil
+}
+
+func (store *fallbackStore) Delete(key string) error {
+	err := store.primary.Delete(key)
+
+	if fallbackErr := store.fallback.Delete(key); err == nil {
+		err = fallbackErr
+	}
+
+	return err
+}
@@ -0,0 +1,19 @@
+package rbac
+
+import "github.com/WeAreAmazingTeam/tcd-backend/constant"
+
+type (
+	Role struct {
+		ID          int    `json:"id"`
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		constant.CreatedUpdatedDeleted
+	}
+
+	RolePermission struct {
+		ID         int    `json:"id"`
+		RoleID     int    `json:"role_id"`
+		Permission string `json:"permission"`
+		constant.CreatedDeleted
+	}
+)
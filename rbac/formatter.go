@@ -0,0 +1,25 @@
+package rbac
+
+type (
+	RoleFormatter struct {
+		ID          int      `json:"id"`
+		Name        string   `json:"name"`
+		Description string   `json:"description"`
+		Permissions []string `json:"permissions"`
+	}
+)
+
+func FormatRoleData(role Role, permissions []string) RoleFormatter {
+	if permissions == nil {
+		permissions = []string{}
+	}
+
+	formatData := RoleFormatter{
+		ID:          role.ID,
+		Name:        role.Name,
+		Description: role.Description,
+		Permissions: permissions,
+	}
+
+	return formatData
+}
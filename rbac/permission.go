@@ -0,0 +1,144 @@
+package rbac
+
+const (
+	RoleSuperAdmin = "superadmin"
+	RoleFinance    = "finance"
+	RoleModerator  = "moderator"
+	RoleSupport    = "support"
+	RoleUser       = "user"
+
+	// legacy role, every account created before roles existed is an admin
+	RoleLegacyAdmin = "admin"
+)
+
+const (
+	PermissionUserView   = "user.view"
+	PermissionUserManage = "user.manage"
+	PermissionUserDelete = "user.delete"
+
+	PermissionRoleManage = "role.manage"
+
+	PermissionWithdrawalView    = "withdrawal.view"
+	PermissionWithdrawalApprove = "withdrawal.approve"
+	PermissionWithdrawalDelete  = "withdrawal.delete"
+
+	PermissionCampaignView     = "campaign.view"
+	PermissionCampaignModerate = "campaign.moderate"
+
+	PermissionTransactionView = "transaction.view"
+
+	PermissionCashFlowView   = "cashflow.view"
+	PermissionCashFlowManage = "cashflow.manage"
+
+	PermissionLogsView = "logs.view"
+
+	PermissionEmailView   = "email.view"
+	PermissionEmailManage = "email.manage"
+
+	PermissionWebhookView   = "webhook.view"
+	PermissionWebhookManage = "webhook.manage"
+
+	PermissionEventView   = "event.view"
+	PermissionEventManage = "event.manage"
+
+	PermissionSchedulerView   = "scheduler.view"
+	PermissionSchedulerManage = "scheduler.manage"
+
+	PermissionQueueView   = "queue.view"
+	PermissionQueueManage = "queue.manage"
+
+	PermissionApprovalView   = "approval.view"
+	PermissionApprovalDecide = "approval.decide"
+
+	PermissionDashboardView = "dashboard.view"
+)
+
+var AllPermissions = []string{
+	PermissionUserView,
+	PermissionUserManage,
+	PermissionUserDelete,
+	PermissionRoleManage,
+	PermissionWithdrawalView,
+	PermissionWithdrawalApprove,
+	PermissionWithdrawalDelete,
+	PermissionCampaignView,
+	PermissionCampaignModerate,
+	PermissionTransactionView,
+	PermissionCashFlowView,
+	PermissionCashFlowManage,
+	PermissionLogsView,
+	PermissionEmailView,
+	PermissionEmailManage,
+	PermissionWebhookView,
+	PermissionWebhookManage,
+	PermissionEventView,
+	PermissionEventManage,
+	PermissionSchedulerView,
+	PermissionSchedulerManage,
+	PermissionQueueView,
+	PermissionQueueManage,
+	PermissionApprovalView,
+	PermissionApprovalDecide,
+	PermissionDashboardView,
+}
+
+var DefaultRoles = []string{
+	RoleSuperAdmin,
+	RoleLegacyAdmin,
+	RoleFinance,
+	RoleModerator,
+	RoleSupport,
+	RoleUser,
+}
+
+// used when the role has no row in the roles table yet
+var DefaultRolePermissions = map[string][]string{
+	RoleSuperAdmin:  AllPermissions,
+	RoleLegacyAdmin: AllPermissions,
+	RoleFinance: {
+		PermissionUserView,
+		PermissionWithdrawalView,
+		PermissionWithdrawalApprove,
+		PermissionTransactionView,
+		PermissionCashFlowView,
+		PermissionCashFlowManage,
+		PermissionEventView,
+		PermissionEventManage,
+		PermissionApprovalView,
+		PermissionApprovalDecide,
+		PermissionDashboardView,
+	},
+	RoleModerator: {
+		PermissionUserView,
+		PermissionCampaignView,
+		PermissionCampaignModerate,
+		PermissionTransactionView,
+		PermissionDashboardView,
+	},
+	RoleSupport: {
+		PermissionUserView,
+		PermissionWithdrawalView,
+		PermissionCampaignView,
+		PermissionTransactionView,
+		PermissionLogsView,
+		PermissionEmailView,
+		PermissionEmailManage,
+		PermissionWebhookView,
+		PermissionWebhookManage,
+		PermissionEventView,
+		PermissionSchedulerView,
+		PermissionQueueView,
+		PermissionApprovalView,
+		PermissionDashboardView,
+	},
+	RoleUser: {},
+}
+
+func IsValidPermission(permission string) bool {
+	for _, val := range AllPermissions {
+		if val == permission {
+			return true
+		}
+	}
+	return false
+}
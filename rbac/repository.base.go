@@ -0,0 +1,23 @@
+package rbac
+
+import "gorm.io/gorm"
+
+type Repository interface {
+	GetAllRole() ([]Role, error)
+	GetRoleByID(id int) (Role, error)
+	GetRoleByName(name string) (Role, error)
+	SaveRole(Role) (Role, error)
+	UpdateRole(Role) (Role, error)
+	DeleteRole(Role) (bool, error)
+
+	GetPermissionsByRoleID(roleID int) ([]string, error)
+	ReplaceRolePermissions(roleID int, permissions []string) error
+}
+
+type repository struct {
+	DB *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *repository {
+	return &repository{DB: db}
+}
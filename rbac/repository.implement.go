@@ -0,0 +1,90 @@
+package rbac
+
+import (
+	"errors"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/constant"
+	"gorm.io/gorm"
+)
+
+func (repo *repository) GetAllRole() (roles []Role, err error) {
+	if err := repo.DB.Order("id ASC").Find(&roles).Error; err != nil {
+		return roles, err
+	}
+	return roles, nil
+}
+
+func (repo *repository) GetRoleByID(id int) (role Role, err error) {
+	if err := repo.DB.Where("id = ?", id).Find(&role).Error; err != nil {
+		return role, err
+	}
+
+	if role.ID == 0 {
+		return role, errors.New("sql: no rows in result set")
+	}
+
+	return role, nil
+}
+
+func (repo *repository) GetRoleByName(name string) (role Role, err error) {
+	if err := repo.DB.Where("name = ?", name).Find(&role).Error; err != nil {
+		return role, err
+	}
+
+	if role.ID == 0 {
+		return role, errors.New("sql: no rows in result set")
+	}
+
+	return role, nil
+}
+
+func (repo *repository) SaveRole(role Role) (Role, error) {
+	if err := repo.DB.Create(&role).Error; err != nil {
+		return role, err
+	}
+	return role, nil
+}
+
+func (repo *repository) UpdateRole(role Role) (Role, error) {
+	if err := repo.DB.Save(&role).Error; err != nil {
+		return role, err
+	}
+	return role, nil
+}
+
+func (repo *repository) DeleteRole(role Role) (bool, error) {
+	if constant.DELETED_BY {
+		if err := repo.DB.Save(&role).Error; err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	if err := repo.DB.Delete(&role).Error; err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (repo *repository) GetPermissionsByRoleID(roleID int) (permissions []string, err error) {
+	if err := repo.DB.Model(&RolePermission{}).Where("role_id = ?", roleID).Pluck("permission", &permissions).Error; err != nil {
+		return permissions, err
+	}
+	return permissions, nil
+}
+
+func (repo *repository) ReplaceRolePermissions(roleID int, permissions []string) error {
+	return repo.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("role_id = ?", roleID).Delete(&RolePermission{}).Error; err != nil {
+			return err
+		}
+
+		for _, permission := range permissions {
+			if err := tx.Create(&RolePermission{RoleID: roleID, Permission: permission}).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,24 @@
+package rbac
+
+import "github.com/WeAreAmazingTeam/tcd-backend/user"
+
+type (
+	RequestGetRoleByID struct {
+		ID int `uri:"id" binding:"required"`
+	}
+
+	RequestCreateRole struct {
+		Name        string   `json:"name" binding:"required"`
+		Description string   `json:"description"`
+		Permissions []string `json:"permissions"`
+		User        user.User
+	}
+
+	RequestUpdateRole struct {
+		RequestCreateRole
+	}
+
+	RequestDeleteRole struct {
+		User user.User
+	}
+)
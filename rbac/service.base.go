@@ -0,0 +1,30 @@
+package rbac
+
+import "github.com/WeAreAmazingTeam/tcd-backend/audit"
+
+type Service interface {
+	GetAllRole() ([]Role, error)
+	GetRoleByID(RequestGetRoleByID) (Role, error)
+	CreateRole(RequestCreateRole) (Role, error)
+	UpdateRole(RequestGetRoleByID, RequestUpdateRole) (Role, error)
+	DeleteRole(RequestGetRoleByID, RequestDeleteRole) (bool, error)
+
+	IsRoleExist(name string) (bool, error)
+	GetPermissionsByRole(name string) ([]string, error)
+	HasPermission(roleName string, permission string) (bool, error)
+}
+
+type service struct {
+	repo     Repository
+	auditSvc audit.Service
+}
+
+func NewService(
+	repository Repository,
+	auditService audit.Service,
+) *service {
+	return &service{
+		repo:     repository,
+		auditSvc: auditService,
+	}
+}
@@ -0,0 +1,226 @@
+package rbac
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/audit"
+	"github.com/WeAreAmazingTeam/tcd-backend/constant"
+	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+)
+
+func (svc *service) GetAllRole() ([]Role, error) {
+	roles, err := svc.repo.GetAllRole()
+
+	if err != nil {
+		return roles, err
+	}
+
+	// default roles that are not stored yet are still assignable, so list them too
+	for _, name := range DefaultRoles {
+		stored := false
+
+		for _, val := range roles {
+			if val.Name == name {
+				stored = true
+				break
+			}
+		}
+
+		if !stored {
+			roles = append(roles, Role{Name: name, Description: "Default role."})
+		}
+	}
+
+	return roles, nil
+}
+
+func (svc *service) GetRoleByID(req RequestGetRoleByID) (Role, error) {
+	role, err := svc.repo.GetRoleByID(req.ID)
+
+	if err != nil {
+		return role, err
+	}
+
+	return role, nil
+}
+
+func (svc *service) CreateRole(req RequestCreateRole) (Role, error) {
+	name := strings.ToLower(strings.TrimSpace(req.Name))
+
+	if err := validatePermissions(req.Permissions); err != nil {
+		return Role{}, err
+	}
+
+	if _, err := svc.repo.GetRoleByName(name); err == nil {
+		return Role{}, fmt.Errorf("role %v already exist", name)
+	} else if !helper.IsErrNoRows(err.Error()) {
+		return Role{}, err
+	}
+
+	role := Role{}
+	role.Name = name
+	role.Description = req.Description
+	role.CreatedBy = helper.SetNS(strconv.Itoa(req.User.ID))
+
+	newRoleData, err := svc.repo.SaveRole(role)
+
+	if err != nil {
+		return newRoleData, err
+	}
+
+	if err := svc.repo.ReplaceRolePermissions(newRoleData.ID, req.Permissions); err != nil {
+		return newRoleData, err
+	}
+
+	audit.Record(svc.auditSvc, req.User, audit.ActionCreate, audit.EntityRole, newRoleData.ID, nil, FormatRoleData(newRoleData, req.Permissions))
+
+	return newRoleData, nil
+}
+
+func (svc *service) UpdateRole(reqDetail RequestGetRoleByID, reqUpdate RequestUpdateRole) (role Role, err error) {
+	role, err = svc.repo.GetRoleByID(reqDetail.ID)
+
+	if err != nil {
+		return role, err
+	}
+
+	if err := validatePermissions(reqUpdate.Permissions); err != nil {
+		return role, err
+	}
+
+	name := strings.ToLower(strings.TrimSpace(reqUpdate.Name))
+
+	if name != role.Name {
+		return role, fmt.Errorf("role name cannot be changed, it may be assigned to users")
+	}
+
+	beforePermissions, err := svc.repo.GetPermissionsByRoleID(role.ID)
+
+	if err != nil {
+		return role, err
+	}
+
+	before := FormatRoleData(role, beforePermissions)
+
+	role.Description = reqUpdate.Description
+	role.UpdatedBy = helper.SetNS(strconv.Itoa(reqUpdate.User.ID))
+
+	updatedRole, err := svc.repo.UpdateRole(role)
+
+	if err != nil {
+		return updatedRole, err
+	}
+
+	if err := svc.repo.ReplaceRolePermissions(updatedRole.ID, reqUpdate.Permissions); err != nil {
+		return updatedRole, err
+	}
+
+	audit.Record(svc.auditSvc, reqUpdate.User, audit.ActionUpdate, audit.EntityRole, updatedRole.ID, before, FormatRoleData(updatedRole, reqUpdate.Permissions))
+
+	return updatedRole, nil
+}
+
+func (svc *service) DeleteRole(reqDetail RequestGetRoleByID, reqDelete RequestDeleteRole) (bool, error) {
+	role, err := svc.repo.GetRoleByID(reqDetail.ID)
+
+	if err != nil {
+		return false, err
+	}
+
+	if role.Name == RoleSuperAdmin || role.Name == RoleUser {
+		return false, fmt.Errorf("role %v cannot be deleted", role.Name)
+	}
+
+	beforePermissions, err := svc.repo.GetPermissionsByRoleID(role.ID)
+
+	if err != nil {
+		return false, err
+	}
+
+	before := FormatRoleData(role, beforePermissions)
+
+	if constant.DELETED_BY {
+		role.UpdatedBy = helper.SetNS(strconv.Itoa(reqDelete.User.ID))
+		role.DeletedAt = *helper.SetNowNT()
+		role.DeletedBy = helper.SetNS(strconv.Itoa(reqDelete.User.ID))
+	}
+
+	status, err := svc.repo.DeleteRole(role)
+
+	if err != nil {
+		return status, err
+	}
+
+	if err := svc.repo.ReplaceRolePermissions(role.ID, nil); err != nil {
+		return status, err
+	}
+
+	audit.Record(svc.auditSvc, reqDelete.User, audit.ActionDelete, audit.EntityRole, role.ID, before, nil)
+
+	return status, nil
+}
+
+func (svc *service) IsRoleExist(name string) (bool, error) {
+	if _, ok := DefaultRolePermissions[name]; ok {
+		return true, nil
+	}
+
+	if _, err := svc.repo.GetRoleByName(name); err != nil {
+		if helper.IsErrNoRows(err.Error()) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (svc *service) GetPermissionsByRole(name string) ([]string, error) {
+	role, err := svc.repo.GetRoleByName(name)
+
+	if err != nil {
+		if helper.IsErrNoRows(err.Error()) {
+			return DefaultRolePermissions[name], nil
+		}
+		return nil, err
+	}
+
+	permissions, err := svc.repo.GetPermissionsByRoleID(role.ID)
+
+	if err != nil {
+		return permissions, err
+	}
+
+	return permissions, nil
+}
+
+func (svc *service) HasPermission(roleName string, permission string) (bool, error) {
+	if roleName == RoleSuperAdmin {
+		return true, nil
+	}
+
+	permissions, err := svc.GetPermissionsByRole(roleName)
+
+	if err != nil {
+		return false, err
+	}
+
+	for _, val := range permissions {
+		if val == permission {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func validatePermissions(permissions []string) error {
+	for _, val := range permissions {
+		if !IsValidPermission(val) {
+			return fmt.Errorf("permission %v is not valid", val)
+		}
+	}
+	return nil
+}
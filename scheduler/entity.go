@@ -0,0 +1,57 @@
+package scheduler
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/joblock"
+)
+
+const (
+	RunStatusRunning   = "running"
+	RunStatusSucceeded = "succeeded"
+	RunStatusFailed    = "failed"
+)
+
+const (
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+)
+
+// Job is declared in code, the scheduled_jobs row only keeps what an admin can change at runtime
+type Job struct {
+	Name        string
+	Schedule    string
+	Description string
+	// Handler returns how many records the run affected, it should stop when the lease is lost
+	Handler func(lease *joblock.Lease) (int64, error)
+}
+
+type (
+	ScheduledJob struct {
+		ID          int          `json:"id"`
+		Name        string       `json:"name"`
+		Schedule    string       `json:"schedule"`
+		Description string       `json:"description"`
+		IsPaused    int          `json:"is_paused"`
+		PausedBy    int          `json:"paused_by"`
+		PausedAt    sql.NullTime `json:"paused_at"`
+		CreatedAt   time.Time    `json:"created_at"`
+		UpdatedAt   time.Time    `json:"updated_at"`
+	}
+
+	// JobRun is one run of a job on the instance that held its lease, TriggeredBy is 0 for the schedule
+	JobRun struct {
+		ID          int            `json:"id"`
+		JobName     string         `json:"job_name"`
+		TriggerType string         `json:"trigger_type"`
+		TriggeredBy int            `json:"triggered_by"`
+		Status      string         `json:"status"`
+		Affected    int64          `json:"affected"`
+		Error       sql.NullString `json:"error"`
+		StartedAt   time.Time      `json:"started_at"`
+		FinishedAt  sql.NullTime   `json:"finished_at"`
+		CreatedAt   time.Time      `json:"created_at"`
+		UpdatedAt   time.Time      `json:"updated_at"`
+	}
+)
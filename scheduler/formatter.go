@@ -0,0 +1,73 @@
+package scheduler
+
+import "time"
+
+type (
+	JobRunFormatter struct {
+		ID          int        `json:"id"`
+		JobName     string     `json:"job_name"`
+		TriggerType string     `json:"trigger_type"`
+		TriggeredBy int        `json:"triggered_by"`
+		Status      string     `json:"status"`
+		Affected    int64      `json:"affected"`
+		Error       string     `json:"error"`
+		StartedAt   time.Time  `json:"started_at"`
+		FinishedAt  *time.Time `json:"finished_at"`
+	}
+
+	ScheduledJobFormatter struct {
+		Name        string           `json:"name"`
+		Schedule    string           `json:"schedule"`
+		Description string           `json:"description"`
+		IsPaused    bool             `json:"is_paused"`
+		PausedBy    int              `json:"paused_by"`
+		PausedAt    *time.Time       `json:"paused_at"`
+		NextRunAt   *time.Time       `json:"next_run_at"`
+		LastRun     *JobRunFormatter `json:"last_run"`
+	}
+)
+
+func FormatJobRunData(run JobRun) JobRunFormatter {
+	formatData := JobRunFormatter{
+		ID:          run.ID,
+		JobName:     run.JobName,
+		TriggerType: run.TriggerType,
+		TriggeredBy: run.TriggeredBy,
+		Status:      run.Status,
+		Affected:    run.Affected,
+		Error:       run.Error.String,
+		StartedAt:   run.StartedAt,
+	}
+
+	if run.FinishedAt.Valid {
+		formatData.FinishedAt = &run.FinishedAt.Time
+	}
+
+	return formatData
+}
+
+// nextRunAt is the next time this instance fires the job, whichever instance gets the lease runs it
+func FormatScheduledJobData(job ScheduledJob, nextRunAt time.Time, lastRun *JobRun) ScheduledJobFormatter {
+	formatData := ScheduledJobFormatter{
+		Name:        job.Name,
+		Schedule:    job.Schedule,
+		Description: job.Description,
+		IsPaused:    job.IsPaused == 1,
+		PausedBy:    job.PausedBy,
+	}
+
+	if job.PausedAt.Valid {
+		formatData.PausedAt = &job.PausedAt.Time
+	}
+
+	if !nextRunAt.IsZero() && !formatData.IsPaused {
+		formatData.NextRunAt = &nextRunAt
+	}
+
+	if lastRun != nil {
+		lastRunData := FormatJobRunData(*lastRun)
+		formatData.LastRun = &lastRunData
+	}
+
+	return formatData
+}
@@ -0,0 +1,29 @@
+package scheduler
+
+const (
+	QueryAdminDataTablesJobRun = `
+		SELECT
+			id,
+			job_name,
+			trigger_type,
+			triggered_by,
+			status,
+			affected,
+			error,
+			started_at,
+			finished_at
+		FROM
+			job_runs
+		WHERE
+			1 = 1
+	`
+
+	QueryCountAllAdminDataTablesJobRun = `
+		SELECT
+			COUNT(id) AS count_id
+		FROM
+			job_runs
+		WHERE
+			1 = 1
+	`
+)
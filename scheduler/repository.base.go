@@ -0,0 +1,28 @@
+package scheduler
+
+import (
+	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	GetScheduledJobByName(name string) (ScheduledJob, error)
+	SyncScheduledJob(ScheduledJob) (ScheduledJob, error)
+	UpdateScheduledJob(ScheduledJob) (ScheduledJob, error)
+
+	GetLastJobRun(jobName string) (JobRun, error)
+	SaveJobRun(JobRun) (JobRun, error)
+	UpdateJobRun(JobRun) (JobRun, error)
+	FailInterruptedJobRuns(jobName string) (int64, error)
+
+	AdminDataTablesJobRun(ctx *gin.Context) (helper.DataTables, error)
+}
+
+type repository struct {
+	DB *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *repository {
+	return &repository{DB: db}
+}
@@ -0,0 +1,230 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+	"github.com/gin-gonic/gin"
+)
+
+func (repo *repository) GetScheduledJobByName(name string) (job ScheduledJob, err error) {
+	if err := repo.DB.Where("name = ?", name).Find(&job).Error; err != nil {
+		return job, err
+	}
+
+	if job.ID == 0 {
+		return job, errors.New("sql: no rows in result set")
+	}
+
+	return job, nil
+}
+
+// SyncScheduledJob saves the schedule and description declared in code, the pause set by an admin is kept
+func (repo *repository) SyncScheduledJob(job ScheduledJob) (ScheduledJob, error) {
+	existing, err := repo.GetScheduledJobByName(job.Name)
+
+	if err != nil {
+		if !helper.IsErrNoRows(err.Error()) {
+			return existing, err
+		}
+
+		if err := repo.DB.Create(&job).Error; err != nil {
+			return job, err
+		}
+
+		return job, nil
+	}
+
+	existing.Schedule = job.Schedule
+	existing.Description = job.Description
+
+	if err := repo.DB.Save(&existing).Error; err != nil {
+		return existing, err
+	}
+
+	return existing, nil
+}
+
+func (repo *repository) UpdateScheduledJob(job ScheduledJob) (ScheduledJob, error) {
+	if err := repo.DB.Save(&job).Error; err != nil {
+		return job, err
+	}
+	return job, nil
+}
+
+func (repo *repository) GetLastJobRun(jobName string) (run JobRun, err error) {
+	if err := repo.DB.Where("job_name = ?", jobName).Order("id DESC").Limit(1).Find(&run).Error; err != nil {
+		return run, err
+	}
+
+	if run.ID == 0 {
+		return run, errors.New("sql: no rows in result set")
+	}
+
+	return run, nil
+}
+
+func (repo *repository) SaveJobRun(run JobRun) (JobRun, error) {
+	if err := repo.DB.Create(&run).Error; err != nil {
+		return run, err
+	}
+	return run, nil
+}
+
+func (repo *repository) UpdateJobRun(run JobRun) (JobRun, error) {
+	if err := repo.DB.Save(&run).Error; err != nil {
+		return run, err
+	}
+	return run, nil
+}
+
+// FailInterruptedJobRuns closes the runs of a process that died mid run, only call it while holding the job lease
+func (repo *repository) FailInterruptedJobRuns(jobName string) (int64, error) {
+	result := repo.DB.Model(&JobRun{}).
+		Where("job_name = ? AND status = ?", jobName, RunStatusRunning).
+		Updates(map[string]any{"status": RunStatusFailed, "error": "interrupted, the instance running it stopped", "finished_at": time.Now(), "updated_at": time.Now()})
+
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
+
+// besides the datatables params it filters by the job_name, status and trigger_type query params
+func (repo *repository) AdminDataTablesJobRun(ctx *gin.Context) (result helper.DataTables, err error) {
+	var (
+		query string = QueryAdminDataTablesJobRun
+		where string = ""
+		order string = ""
+		limit string = ""
+	)
+
+	var (
+		no       int = 1
+		total    int = 0
+		filtered int = 0
+	)
+
+	var (
+		data []map[string]any
+		args []any
+	)
+
+	listOrder := []string{"", "job_name", "trigger_type", "status", "affected", "started_at", "finished_at", ""}
+
+	if jobName := ctx.Query("job_name"); jobName != "" {
+		where = fmt.Sprintf("%s AND job_name = ?", where)
+		args = append(args, jobName)
+	}
+
+	if status := ctx.Query("status"); status != "" {
+		where = fmt.Sprintf("%s AND status = ?", where)
+		args = append(args, status)
+	}
+
+	if triggerType := ctx.Query("trigger_type"); triggerType != "" {
+		where = fmt.Sprintf("%s AND trigger_type = ?", where)
+		args = append(args, triggerType)
+	}
+
+	if searchValue := ctx.Query("search[value]"); searchValue != "" {
+		where = fmt.Sprintf("%s AND (job_name LIKE ? OR error LIKE ?)", where)
+		for i := 0; i < 2; i++ {
+			args = append(args, "%"+searchValue+"%")
+		}
+	}
+
+	orderColumn := ctx.Query("order[0][column]")
+	starting, _ := strconv.Atoi(ctx.Query("start"))
+
+	if orderColumn != "" {
+		orderType := "ASC"
+		orderColumn, _ := strconv.Atoi(orderColumn)
+
+		if strings.ToUpper(ctx.Query("order[0][dir]")) == "DESC" {
+			orderType = "DESC"
+		}
+
+		if orderColumn > 0 && orderColumn < len(listOrder) && listOrder[orderColumn] != "" {
+			order = fmt.Sprintf("ORDER BY %s %s", listOrder[orderColumn], orderType)
+		} else {
+			order = "ORDER BY id DESC"
+		}
+	} else {
+		order = "ORDER BY id DESC"
+	}
+
+	if starting != -1 {
+		length, _ := strconv.Atoi(ctx.Query("length"))
+		limit = fmt.Sprintf("LIMIT %v OFFSET %v", length, starting)
+		no = starting + 1
+	}
+
+	if err := repo.DB.Raw(helper.ConvertToInLineQuery(QueryCountAllAdminDataTablesJobRun)).Scan(&total).Error; err != nil {
+		return result, err
+	}
+
+	if where != "" {
+		query = query + where
+
+		if err := repo.DB.Raw(helper.ConvertToInLineQuery(QueryCountAllAdminDataTablesJobRun)+where, args...).Scan(&filtered).Error; err != nil {
+			return result, err
+		}
+	} else {
+		filtered = total
+	}
+
+	query = fmt.Sprintf("%s %s %s", query, order, limit)
+
+	rows, err := repo.DB.Raw(helper.ConvertToInLineQuery(query), args...).Rows()
+
+	if err != nil {
+		return result, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		tmp := JobRun{}
+
+		err := rows.Scan(
+			&tmp.ID,
+			&tmp.JobName,
+			&tmp.TriggerType,
+			&tmp.TriggeredBy,
+			&tmp.Status,
+			&tmp.Affected,
+			&tmp.Error,
+			&tmp.StartedAt,
+			&tmp.FinishedAt,
+		)
+
+		if err != nil {
+			return result, err
+		}
+
+		formatData := FormatJobRunData(tmp)
+
+		data = append(data, map[string]any{
+			"no":           no,
+			"id":           formatData.ID,
+			"job_name":     formatData.JobName,
+			"trigger_type": formatData.TriggerType,
+			"triggered_by": formatData.TriggeredBy,
+			"status":       formatData.Status,
+			"affected":     formatData.Affected,
+			"error":        formatData.Error,
+			"started_at":   formatData.StartedAt,
+			"finished_at":  formatData.FinishedAt,
+		})
+
+		no++
+	}
+
+	return helper.BuildDatatTables(data, filtered, total), nil
+}
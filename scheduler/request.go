@@ -0,0 +1,13 @@
+package scheduler
+
+import "github.com/WeAreAmazingTeam/tcd-backend/user"
+
+type (
+	RequestGetScheduledJobByName struct {
+		Name string `uri:"name" binding:"required"`
+	}
+
+	RequestUpdateScheduledJob struct {
+		User user.User
+	}
+)
@@ -0,0 +1,43 @@
+package scheduler
+
+import (
+	"sync"
+	"time"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/audit"
+	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+	"github.com/WeAreAmazingTeam/tcd-backend/joblock"
+	"github.com/gin-gonic/gin"
+	"github.com/robfig/cron/v3"
+)
+
+type Service interface {
+	Register(Job)
+	Start(location *time.Location)
+
+	GetScheduledJobs() ([]ScheduledJobFormatter, error)
+	TriggerJob(RequestGetScheduledJobByName, RequestUpdateScheduledJob) (JobRun, error)
+	PauseJob(RequestGetScheduledJobByName, RequestUpdateScheduledJob) (ScheduledJob, error)
+	ResumeJob(RequestGetScheduledJobByName, RequestUpdateScheduledJob) (ScheduledJob, error)
+	AdminDataTablesJobRun(*gin.Context) (helper.DataTables, error)
+}
+
+type service struct {
+	repo      Repository
+	jobRunner *joblock.Runner
+	auditSvc  audit.Service
+
+	mu      sync.RWMutex
+	jobs    []Job
+	cron    *cron.Cron
+	entries map[string]cron.EntryID
+}
+
+func NewService(repository Repository, jobRunner *joblock.Runner, auditService audit.Service) *service {
+	return &service{
+		repo:      repository,
+		jobRunner: jobRunner,
+		auditSvc:  auditService,
+		entries:   map[string]cron.EntryID{},
+	}
+}
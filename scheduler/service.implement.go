@@ -0,0 +1,291 @@
+package scheduler
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/audit"
+	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+	"github.com/WeAreAmazingTeam/tcd-backend/joblock"
+	"github.com/WeAreAmazingTeam/tcd-backend/user"
+	"github.com/gin-gonic/gin"
+	"github.com/robfig/cron/v3"
+)
+
+// Register panics on a duplicate name, the name is the job lease and the key of its history
+func (svc *service) Register(job Job) {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+
+	for _, val := range svc.jobs {
+		if val.Name == job.Name {
+			panic(fmt.Sprintf("scheduled job %s already registered", job.Name))
+		}
+	}
+
+	svc.jobs = append(svc.jobs, job)
+}
+
+// Start saves the registered jobs and schedules them, every instance schedules every job
+// and the job lease decides which one runs it
+func (svc *service) Start(location *time.Location) {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+
+	svc.cron = cron.New(cron.WithLocation(location))
+
+	for _, job := range svc.jobs {
+		var scheduledJob ScheduledJob
+		scheduledJob.Name = job.Name
+		scheduledJob.Schedule = job.Schedule
+		scheduledJob.Description = job.Description
+
+		if _, err := svc.repo.SyncScheduledJob(scheduledJob); err != nil {
+			log.Fatal("error while save scheduled job ", job.Name, ", err: ", err.Error())
+		}
+
+		job := job
+		entryID, err := svc.cron.AddFunc(job.Schedule, func() {
+			svc.runScheduled(job)
+		})
+
+		if err != nil {
+			log.Fatal("error while schedule job ", job.Name, ", err: ", err.Error())
+		}
+
+		svc.entries[job.Name] = entryID
+	}
+
+	go svc.cron.Start()
+}
+
+func (svc *service) GetScheduledJobs() ([]ScheduledJobFormatter, error) {
+	svc.mu.RLock()
+	defer svc.mu.RUnlock()
+
+	scheduledJobs := []ScheduledJobFormatter{}
+
+	for _, job := range svc.jobs {
+		scheduledJob, err := svc.repo.GetScheduledJobByName(job.Name)
+
+		if err != nil {
+			return scheduledJobs, err
+		}
+
+		var lastRun *JobRun
+
+		if run, err := svc.repo.GetLastJobRun(job.Name); err == nil {
+			lastRun = &run
+		} else if !helper.IsErrNoRows(err.Error()) {
+			return scheduledJobs, err
+		}
+
+		var nextRunAt time.Time
+
+		if entryID, ok := svc.entries[job.Name]; ok {
+			nextRunAt = svc.cron.Entry(entryID).Next
+		}
+
+		scheduledJobs = append(scheduledJobs, FormatScheduledJobData(scheduledJob, nextRunAt, lastRun))
+	}
+
+	return scheduledJobs, nil
+}
+
+// TriggerJob runs a job now even when it is paused, it returns the run as soon as it started
+// and fails when another instance holds the job lease
+func (svc *service) TriggerJob(reqDetail RequestGetScheduledJobByName, reqTrigger RequestUpdateScheduledJob) (JobRun, error) {
+	job, ok := svc.job(reqDetail.Name)
+
+	if !ok {
+		return JobRun{}, errors.New("sql: no rows in result set")
+	}
+
+	started := make(chan JobRun, 1)
+	done := make(chan error, 1)
+
+	go func() {
+		ran, err := svc.execute(job, TriggerManual, reqTrigger.User, started)
+
+		if !ran && err == nil {
+			err = errors.New("job is already running")
+		}
+
+		done <- err
+	}()
+
+	select {
+	case run := <-started:
+		return run, nil
+	case err := <-done:
+		// a quick run may be done before its start is read
+		select {
+		case run := <-started:
+			return run, nil
+		default:
+			return JobRun{}, err
+		}
+	}
+}
+
+func (svc *service) PauseJob(reqDetail RequestGetScheduledJobByName, reqPause RequestUpdateScheduledJob) (ScheduledJob, error) {
+	scheduledJob, err := svc.scheduledJob(reqDetail.Name)
+
+	if err != nil {
+		return scheduledJob, err
+	}
+
+	if scheduledJob.IsPaused == 1 {
+		return scheduledJob, errors.New("job is already paused")
+	}
+
+	before := scheduledJob
+	scheduledJob.IsPaused = 1
+	scheduledJob.PausedBy = reqPause.User.ID
+	scheduledJob.PausedAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+	scheduledJob, err = svc.repo.UpdateScheduledJob(scheduledJob)
+
+	if err != nil {
+		return scheduledJob, err
+	}
+
+	audit.Record(svc.auditSvc, reqPause.User, audit.ActionUpdate, audit.EntityScheduledJob, scheduledJob.ID, before, scheduledJob)
+
+	return scheduledJob, nil
+}
+
+func (svc *service) ResumeJob(reqDetail RequestGetScheduledJobByName, reqResume RequestUpdateScheduledJob) (ScheduledJob, error) {
+	scheduledJob, err := svc.scheduledJob(reqDetail.Name)
+
+	if err != nil {
+		return scheduledJob, err
+	}
+
+	if scheduledJob.IsPaused != 1 {
+		return scheduledJob, errors.New("job is not paused")
+	}
+
+	before := scheduledJob
+	scheduledJob.IsPaused = 0
+	scheduledJob.PausedBy = 0
+	scheduledJob.PausedAt = sql.NullTime{}
+
+	scheduledJob, err = svc.repo.UpdateScheduledJob(scheduledJob)
+
+	if err != nil {
+		return scheduledJob, err
+	}
+
+	audit.Record(svc.auditSvc, reqResume.User, audit.ActionUpdate, audit.EntityScheduledJob, scheduledJob.ID, before, scheduledJob)
+
+	return scheduledJob, nil
+}
+
+func (svc *service) AdminDataTablesJobRun(ctx *gin.Context) (helper.DataTables, error) {
+	dataTablesJobRun, err := svc.repo.AdminDataTablesJobRun(ctx)
+
+	if err != nil {
+		return dataTablesJobRun, err
+	}
+
+	return dataTablesJobRun, nil
+}
+
+func (svc *service) job(name string) (Job, bool) {
+	svc.mu.RLock()
+	defer svc.mu.RUnlock()
+
+	for _, job := range svc.jobs {
+		if job.Name == name {
+			return job, true
+		}
+	}
+
+	return Job{}, false
+}
+
+// only the registered jobs can be paused, a row left by a removed job is not
+func (svc *service) scheduledJob(name string) (ScheduledJob, error) {
+	if _, ok := svc.job(name); !ok {
+		return ScheduledJob{}, errors.New("sql: no rows in result set")
+	}
+
+	return svc.repo.GetScheduledJobByName(name)
+}
+
+// the pause is read on every tick, so pausing on one instance pauses the job on all of them
+func (svc *service) runScheduled(job Job) {
+	scheduledJob, err := svc.repo.GetScheduledJobByName(job.Name)
+
+	if err != nil {
+		log.Printf("[SCHEDULER] %s not run, err: %s", job.Name, err.Error())
+		return
+	}
+
+	if scheduledJob.IsPaused == 1 {
+		return
+	}
+
+	if _, err := svc.execute(job, TriggerSchedule, user.User{}, nil); err != nil {
+		log.Printf("[SCHEDULER] %s failed, err: %s", job.Name, err.Error())
+	}
+}
+
+// execute records the run of a job under its lease, ran is false when another instance holds the lease
+func (svc *service) execute(job Job, triggerType string, actor user.User, started chan<- JobRun) (bool, error) {
+	return svc.jobRunner.Run(job.Name, func(lease *joblock.Lease) error {
+		// the lease is exclusive, a run still marked running was left by a process that died
+		if _, err := svc.repo.FailInterruptedJobRuns(job.Name); err != nil {
+			return err
+		}
+
+		var run JobRun
+		run.JobName = job.Name
+		run.TriggerType = triggerType
+		run.TriggeredBy = actor.ID
+		run.Status = RunStatusRunning
+		run.StartedAt = time.Now()
+
+		run, err := svc.repo.SaveJobRun(run)
+
+		if err != nil {
+			return err
+		}
+
+		if started != nil {
+			started <- run
+		}
+
+		affected, jobErr := svc.call(job, lease)
+
+		run.Affected = affected
+		run.Status = RunStatusSucceeded
+		run.FinishedAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+		if jobErr != nil {
+			run.Status = RunStatusFailed
+			run.Error = helper.SetNS(jobErr.Error())
+		}
+
+		if _, err := svc.repo.UpdateJobRun(run); err != nil {
+			log.Printf("[SCHEDULER] run %d of %s not saved, err: %s", run.ID, job.Name, err.Error())
+		}
+
+		return jobErr
+	})
+}
+
+// call turns a panic of the handler into a failed run
+func (svc *service) call(job Job, lease *joblock.Lease) (affected int64, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return job.Handler(lease)
+}
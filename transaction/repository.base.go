@@ -1,6 +1,7 @@
 package transaction
 
 import (
+	"github.com/WeAreAmazingTeam/tcd-backend/event"
 	"github.com/WeAreAmazingTeam/tcd-backend/helper"
 	"github.com/WeAreAmazingTeam/tcd-backend/user"
 	"github.com/gin-gonic/gin"
@@ -15,6 +16,8 @@ type Repository interface {
 	GetTransactionByCode(code string) (Transaction, error)
 	SaveTransaction(transaction Transaction) (Transaction, error)
 	UpdateTransaction(Transaction) (Transaction, error)
+	PayTransaction(transaction Transaction, events ...event.Event) (bool, error)
+	SaveEMoneyTransaction(transaction Transaction, events func(Transaction) []event.Event) (Transaction, error)
 	DeleteTransaction(Transaction) (bool, error)
 
 	AdminDataTablesTransactions(*gin.Context) (helper.DataTables, error)
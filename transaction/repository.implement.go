@@ -1,16 +1,22 @@
 package transaction
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
 
+	"github.com/WeAreAmazingTeam/tcd-backend/campaign"
 	"github.com/WeAreAmazingTeam/tcd-backend/constant"
+	"github.com/WeAreAmazingTeam/tcd-backend/event"
 	"github.com/WeAreAmazingTeam/tcd-backend/helper"
 	"github.com/WeAreAmazingTeam/tcd-backend/user"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
+var ErrEMoneyNotEnough = errors.New("e-money balance is not enough")
+
 func (repo *repository) GetAllTransaction(ctx *gin.Context) (transactions []Transaction, err error) {
 	additionalQuery := ""
 
@@ -260,6 +266,98 @@ func (repo *repository) UpdateTransaction(transaction Transaction) (Transaction,
 	return transaction, nil
 }
 
+// PayTransaction marks the transaction paid, adds it to the campaign and stores the events in one database
+// transaction. False means it was already paid, midtrans sends the same notification more than once.
+func (repo *repository) PayTransaction(transaction Transaction, events ...event.Event) (bool, error) {
+	paid := false
+
+	err := repo.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&Transaction{}).
+			Where("id = ? AND status <> ?", transaction.ID, "paid").
+			Updates(map[string]any{"status": "paid", "updated_by": transaction.UpdatedBy})
+
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		if err := addToCampaign(tx, transaction); err != nil {
+			return err
+		}
+
+		paid = true
+
+		return event.Store(tx, events...)
+	})
+
+	if err != nil {
+		return false, err
+	}
+
+	if paid {
+		event.Wake()
+	}
+
+	return paid, nil
+}
+
+// SaveEMoneyTransaction takes the amount from the donor balance not held by withdrawals, saves the paid transaction,
+// adds it to the campaign and stores the events in one database transaction. The events are built once the
+// transaction has an id.
+func (repo *repository) SaveEMoneyTransaction(transaction Transaction, events func(Transaction) []event.Event) (Transaction, error) {
+	err := repo.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&user.User{}).
+			Where("id = ? AND e_money - e_money_held >= ?", transaction.UserID, transaction.Amount).
+			Update("e_money", gorm.Expr("e_money - ?", transaction.Amount))
+
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return ErrEMoneyNotEnough
+		}
+
+		if err := tx.Create(&transaction).Error; err != nil {
+			return err
+		}
+
+		eMoneyFlow := user.UserEMoneyFlow{
+			UserID: transaction.UserID,
+			Status: "out",
+			Amount: transaction.Amount,
+			Note:   fmt.Sprintf("Donate with e-money to campaign id %v.", transaction.CampaignID),
+		}
+
+		if err := tx.Create(&eMoneyFlow).Error; err != nil {
+			return err
+		}
+
+		if err := addToCampaign(tx, transaction); err != nil {
+			return err
+		}
+
+		return event.Store(tx, events(transaction)...)
+	})
+
+	if err != nil {
+		return transaction, err
+	}
+
+	event.Wake()
+
+	return transaction, nil
+}
+
+func addToCampaign(tx *gorm.DB, transaction Transaction) error {
+	return tx.Model(&campaign.Campaign{}).
+		Where("id = ?", transaction.CampaignID).
+		Updates(map[string]any{"current_amount": gorm.Expr("current_amount + ?", transaction.Amount), "donor_count": gorm.Expr("donor_count + ?", 1)}).Error
+}
+
 func (repo *repository) DeleteTransaction(transaction Transaction) (bool, error) {
 	if constant.DELETED_BY {
 		if err := repo.DB.Save(&transaction).Error; err != nil {
@@ -1,8 +1,10 @@
 package transaction
 
 import (
+	"github.com/WeAreAmazingTeam/tcd-backend/audit"
 	"github.com/WeAreAmazingTeam/tcd-backend/campaign"
 	"github.com/WeAreAmazingTeam/tcd-backend/company"
+	"github.com/WeAreAmazingTeam/tcd-backend/event"
 	"github.com/WeAreAmazingTeam/tcd-backend/helper"
 	"github.com/WeAreAmazingTeam/tcd-backend/payment"
 	"github.com/WeAreAmazingTeam/tcd-backend/user"
@@ -25,6 +27,8 @@ type Service interface {
 	ProcessRequestFromMidtrans(req MidtransRequest) error
 
 	GetTotalTransaction(condition string) (int, error)
+
+	RegisterSubscribers(*event.Bus)
 }
 
 type service struct {
@@ -32,8 +36,8 @@ type service struct {
 	campaignRepo campaign.Repository
 	userRepo     user.Repository
 	companyRepo  company.Repository
-	campaignSvc  campaign.Service
 	paymentSvc   payment.Service
+	auditSvc     audit.Service
 }
 
 func NewService(
@@ -41,15 +45,15 @@ func NewService(
 	campaignRepository campaign.Repository,
 	userRepository user.Repository,
 	companyRepository company.Repository,
-	campaignService campaign.Service,
 	paymentService payment.Service,
+	auditService audit.Service,
 ) *service {
 	return &service{
 		repo:         repository,
 		campaignRepo: campaignRepository,
 		userRepo:     userRepository,
 		companyRepo:  companyRepository,
-		campaignSvc:  campaignService,
 		paymentSvc:   paymentService,
+		auditSvc:     auditService,
 	}
 }
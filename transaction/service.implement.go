@@ -3,13 +3,12 @@ package transaction
 import (
 	"fmt"
 	"log"
-	"math"
-	"os"
 	"strconv"
+	"time"
 
-	"github.com/WeAreAmazingTeam/tcd-backend/campaign"
-	"github.com/WeAreAmazingTeam/tcd-backend/company"
+	"github.com/WeAreAmazingTeam/tcd-backend/audit"
 	"github.com/WeAreAmazingTeam/tcd-backend/constant"
+	"github.com/WeAreAmazingTeam/tcd-backend/event"
 	"github.com/WeAreAmazingTeam/tcd-backend/helper"
 	"github.com/WeAreAmazingTeam/tcd-backend/payment"
 	"github.com/WeAreAmazingTeam/tcd-backend/user"
@@ -95,19 +94,7 @@ func (svc *service) CreateTransaction(req RequestCreateTransaction, campaignName
 		return newTransactionData, err
 	}
 
-	if req.UserID != 0 {
-		svc.companyRepo.CreateCompanyCashFlow(company.CompanyCashFlow{
-			Status: "in",
-			Amount: int64(req.Amount),
-			Note:   fmt.Sprintf("Donate to campaign id %v by %v.", req.CampaignID, req.UserID),
-		})
-	} else {
-		svc.companyRepo.CreateCompanyCashFlow(company.CompanyCashFlow{
-			Status: "in",
-			Amount: int64(req.Amount),
-			Note:   fmt.Sprintf("Donate to campaign id %v by anonymous.", req.CampaignID),
-		})
-	}
+	audit.Record(svc.auditSvc, req.User, audit.ActionCreate, audit.EntityTransaction, newTransactionData.ID, nil, newTransactionData)
 
 	return newTransactionData, nil
 }
@@ -130,6 +117,7 @@ func (svc *service) DeleteTransaction(reqDetail RequestGetTransactionByID, reqDe
 			return false, err
 		}
 
+		before := transaction
 		transaction.UpdatedBy = helper.SetNS(strconv.Itoa(reqDelete.User.ID))
 		transaction.DeletedAt = *helper.SetNowNT()
 		transaction.DeletedBy = helper.SetNS(strconv.Itoa(reqDelete.User.ID))
@@ -140,10 +128,14 @@ func (svc *service) DeleteTransaction(reqDetail RequestGetTransactionByID, reqDe
 			return status, err
 		}
 
+		audit.Record(svc.auditSvc, reqDelete.User, audit.ActionDelete, audit.EntityTransaction, transaction.ID, before, nil)
+
 		return status, nil
 	}
 
-	if _, err := svc.repo.GetTransactionByID(reqDetail.ID); err != nil {
+	before, err := svc.repo.GetTransactionByID(reqDetail.ID)
+
+	if err != nil {
 		return false, err
 	}
 
@@ -156,6 +148,8 @@ func (svc *service) DeleteTransaction(reqDetail RequestGetTransactionByID, reqDe
 		return status, err
 	}
 
+	audit.Record(svc.auditSvc, reqDelete.User, audit.ActionDelete, audit.EntityTransaction, reqDetail.ID, before, nil)
+
 	return status, nil
 }
 
@@ -169,6 +163,8 @@ func (svc *service) UserDataTablesTransactions(ctx *gin.Context, user user.User)
 	return dataTablesTransactions, nil
 }
 
+// ProcessRequestFromMidtrans only settles the payment, everything that follows a paid donation
+// (cash flow, emails, webhooks, finishing the campaign) is a subscriber of event.DonationPaid
 func (svc *service) ProcessRequestFromMidtrans(req MidtransRequest) error {
 	transaction, err := svc.repo.GetTransactionByCode(req.OrderID)
 
@@ -177,6 +173,8 @@ func (svc *service) ProcessRequestFromMidtrans(req MidtransRequest) error {
 		return err
 	}
 
+	before := transaction
+
 	if req.PaymentType == "credit_card" && req.TransactionStatus == "capture" && req.FraudStatus == "accept" {
 		transaction.Status = "paid"
 	} else if req.TransactionStatus == "settlement" {
@@ -185,119 +183,51 @@ func (svc *service) ProcessRequestFromMidtrans(req MidtransRequest) error {
 		transaction.Status = "cancelled"
 	}
 
-	updatedTransaction, err := svc.repo.UpdateTransaction(transaction)
-
-	if err != nil {
-		log.Println("[transaction webhooks] error while update transaction, err: ", err.Error())
-		return err
-	}
-
-	if updatedTransaction.Status == "paid" {
-		campaignData, err := svc.campaignRepo.GetCampaignByID(updatedTransaction.CampaignID)
+	if transaction.Status != "paid" {
+		updatedTransaction, err := svc.repo.UpdateTransaction(transaction)
 
 		if err != nil {
-			log.Println("[transaction webhooks] error while get campaign by id, err: ", err.Error())
+			log.Println("[transaction webhooks] error while update transaction, err: ", err.Error())
 			return err
 		}
 
-		if campaignData.CurrentAmount+updatedTransaction.Amount >= campaignData.GoalAmount {
-			campaignData.Status = "finished"
-			campaignData.UpdatedBy = helper.SetNS("MIDTRANS")
-
-			updatedCampaign, err := svc.campaignRepo.UpdateCampaign(campaignData)
-
-			if err != nil {
-				log.Println("[transaction webhooks] error while update campaign, err: ", err.Error())
-				return err
-			}
-
-			sumAmount := float64(campaignData.CurrentAmount + updatedTransaction.Amount)
-			forDeducted := sumAmount - math.Round(float64(sumAmount-(sumAmount*(float64(6)/float64(100)))))
-			deductedAmount := sumAmount - forDeducted
-
-			if err := svc.userRepo.GiveEMoneyToUser(updatedCampaign.UserID, int(deductedAmount)); err != nil {
-				log.Println("[transaction webhooks] error while update campaign, err: ", err.Error())
-				return err
-			}
-
-			svc.userRepo.CreateEMoneyFlow(user.UserEMoneyFlow{
-				UserID: updatedCampaign.UserID,
-				Status: "in",
-				Amount: int64(sumAmount),
-				Note:   fmt.Sprintf("Funds from the donation campaign: %v.", updatedCampaign.Title),
-			})
-
-			svc.userRepo.CreateEMoneyFlow(user.UserEMoneyFlow{
-				UserID: updatedCampaign.UserID,
-				Status: "out",
-				Amount: int64(deductedAmount),
-				Note:   fmt.Sprintf("Admin fee for the donation campaign: %v.", updatedCampaign.Title),
-			})
-
-			svc.companyRepo.CreateCompanyCashFlow(company.CompanyCashFlow{
-				Status: "out",
-				Amount: int64(sumAmount),
-				Note:   fmt.Sprintf("Disburse funds for donation campaign: %v.", updatedCampaign.Title),
-			})
-
-			svc.companyRepo.CreateCompanyCashFlow(company.CompanyCashFlow{
-				Status: "in",
-				Amount: int64(deductedAmount),
-				Note:   fmt.Sprintf("Admin fee from donation campaign: %v.", updatedCampaign.Title),
-			})
-
-			userOwnerCampaign, err := svc.userRepo.GetUserByID(updatedCampaign.UserID)
-
-			if err != nil {
-				return err
-			}
-
-			{
-				templateData := helper.EmailCampaignFinished{
-					Campaign:       updatedCampaign,
-					Name:           userOwnerCampaign.Name,
-					GoalAmount:     helper.FormatRupiah(float64(updatedCampaign.GoalAmount)),
-					CollectedFunds: helper.FormatRupiah(sumAmount),
-					AdminFee:       helper.FormatRupiah(forDeducted),
-					FinalAmount:    helper.FormatRupiah(deductedAmount),
-				}
-				go helper.SendMail(userOwnerCampaign.Email, fmt.Sprintf("Your Campaign (%v) Has Finished", updatedCampaign.Title), templateData, "html/campaign_finished.html")
-			}
-
-			if campaignData.IsExclusive == 1 {
-				var reqCheckAndSetWinnerCampaignExclusive campaign.RequestGetCampaignExclusiveByCampaignID
-				reqCheckAndSetWinnerCampaignExclusive.ID = campaignData.ID
-
-				if _, err := svc.campaignSvc.CheckAndSetWinnerCampaignExclusive(reqCheckAndSetWinnerCampaignExclusive); err != nil {
-					log.Println("[transaction webhooks] error while check and set winner campaign exclusive, err: ", err.Error())
-					return err
-				}
-			}
-		}
+		audit.Record(svc.auditSvc, user.User{}, audit.ActionUpdate, audit.EntityTransaction, updatedTransaction.ID, before, updatedTransaction)
 
-		if err := svc.campaignRepo.UpdateCampaignFromPayment(campaignData.ID, updatedTransaction.Amount); err != nil {
-			log.Println("[transaction webhooks] error while update campaign from payment, err: ", err.Error())
-			return err
-		}
+		return nil
+	}
 
-		if updatedTransaction.UserID != 0 {
-			userTransaction, err := svc.userRepo.GetUserByID(updatedTransaction.UserID)
-
-			if err != nil {
-				return err
-			}
-
-			{
-				templateData := helper.EmailTransactionSuccess{
-					CampaignLink: os.Getenv("WEB_URL") + "/donate/" + strconv.Itoa(campaignData.ID),
-					Name:         userTransaction.Name,
-					Amount:       helper.FormatRupiah(float64(transaction.Amount)),
-				}
-				go helper.SendMail(userTransaction.Email, "Thank You For Your Donation!", templateData, "html/transaction_success.html")
-			}
-		}
+	campaignData, err := svc.campaignRepo.GetCampaignByID(transaction.CampaignID)
+
+	if err != nil {
+		log.Println("[transaction webhooks] error while get campaign by id, err: ", err.Error())
+		return err
+	}
+
+	transaction.UpdatedBy = helper.SetNS("MIDTRANS")
+
+	paid, err := svc.repo.PayTransaction(transaction, event.DonationPaid{
+		TransactionID:   transaction.ID,
+		Code:            transaction.Code,
+		CampaignID:      transaction.CampaignID,
+		CampaignOwnerID: campaignData.UserID,
+		UserID:          transaction.UserID,
+		Amount:          transaction.Amount,
+		PaidWith:        event.PaidWithMidtrans,
+		PaidAt:          time.Now(),
+	})
+
+	if err != nil {
+		log.Println("[transaction webhooks] error while pay transaction, err: ", err.Error())
+		return err
+	}
+
+	// a repeated notification for a transaction that is already paid
+	if !paid {
+		return nil
 	}
 
+	audit.Record(svc.auditSvc, user.User{}, audit.ActionUpdate, audit.EntityTransaction, transaction.ID, before, transaction)
+
 	return nil
 }
 
@@ -349,14 +279,31 @@ func (svc *service) CreateAnonymousTransaction(req RequestCreateAnonymousTransac
 		return newTransactionData, err
 	}
 
+	audit.Record(svc.auditSvc, req.User, audit.ActionCreate, audit.EntityTransaction, newTransactionData.ID, nil, newTransactionData)
+
 	return newTransactionData, nil
 }
 
+// CreateTransactionWithEMoney settles right away, the side effects are the same subscribers as a midtrans payment
 func (svc *service) CreateTransactionWithEMoney(req RequestCreateTransactionWithEMoney, campaignName string) (Transaction, error) {
+	campaignData, err := svc.campaignRepo.GetCampaignByID(req.CampaignID)
+
+	if err != nil {
+		log.Println("[transaction with e-money] error while get campaign by id, err: ", err.Error())
+		return Transaction{}, err
+	}
+
+	userBefore, err := svc.userRepo.GetUserByID(req.User.ID)
+
+	if err != nil {
+		return Transaction{}, err
+	}
+
 	transaction := Transaction{}
 
 	transaction.CampaignID = req.CampaignID
-	transaction.UserID = req.UserID
+	// the balance taken is always the signed in user one, whatever user_id the body carries
+	transaction.UserID = req.User.ID
 	transaction.Amount = req.Amount
 	transaction.Comment = req.Comment
 	transaction.Status = "paid"
@@ -365,124 +312,29 @@ func (svc *service) CreateTransactionWithEMoney(req RequestCreateTransactionWith
 	transaction.PaymentToken = "-"
 	transaction.CreatedBy = helper.SetNS(strconv.Itoa(req.User.ID))
 
-	newTransactionData, err := svc.repo.SaveTransaction(transaction)
-
-	if err != nil {
-		return newTransactionData, err
-	}
-
-	campaignData, err := svc.campaignRepo.GetCampaignByID(req.CampaignID)
-
-	if err != nil {
-		log.Println("[transaction with e-money] error while get campaign by id, err: ", err.Error())
-		return newTransactionData, err
-	}
-
-	if campaignData.CurrentAmount+req.Amount >= campaignData.GoalAmount {
-		campaignData.Status = "finished"
-		campaignData.UpdatedBy = helper.SetNS(fmt.Sprintf("Transaction with e-money by user id %v.", req.User.ID))
-
-		updatedCampaign, err := svc.campaignRepo.UpdateCampaign(campaignData)
-
-		if err != nil {
-			log.Println("[transaction with e-money] error while update campaign, err: ", err.Error())
-			return newTransactionData, err
-		}
-
-		sumAmount := float64(campaignData.CurrentAmount + req.Amount)
-		forDeducted := sumAmount - math.Round(float64(sumAmount-(sumAmount*(float64(6)/float64(100)))))
-		deductedAmount := sumAmount - forDeducted
-
-		if err := svc.userRepo.GiveEMoneyToUser(updatedCampaign.UserID, int(deductedAmount)); err != nil {
-			log.Println("[transaction with e-money] error while update campaign, err: ", err.Error())
-			return newTransactionData, err
-		}
-
-		svc.userRepo.CreateEMoneyFlow(user.UserEMoneyFlow{
-			UserID: updatedCampaign.UserID,
-			Status: "in",
-			Amount: int64(sumAmount),
-			Note:   fmt.Sprintf("Funds from the donation campaign: %v.", updatedCampaign.Title),
-		})
-
-		svc.userRepo.CreateEMoneyFlow(user.UserEMoneyFlow{
-			UserID: updatedCampaign.UserID,
-			Status: "out",
-			Amount: int64(deductedAmount),
-			Note:   fmt.Sprintf("Admin fee for the donation campaign: %v.", updatedCampaign.Title),
-		})
-
-		svc.companyRepo.CreateCompanyCashFlow(company.CompanyCashFlow{
-			Status: "out",
-			Amount: int64(sumAmount),
-			Note:   fmt.Sprintf("Disburse funds for exclusive campaign: %v.", updatedCampaign.Title),
-		})
-
-		svc.companyRepo.CreateCompanyCashFlow(company.CompanyCashFlow{
-			Status: "in",
-			Amount: int64(deductedAmount),
-			Note:   fmt.Sprintf("Admin fee from donation campaign: %v.", updatedCampaign.Title),
-		})
-
-		userOwnerCampaign, err := svc.userRepo.GetUserByID(updatedCampaign.UserID)
-
-		if err != nil {
-			return newTransactionData, err
-		}
-
-		{
-			templateData := helper.EmailCampaignFinished{
-				Campaign:       updatedCampaign,
-				Name:           userOwnerCampaign.Name,
-				GoalAmount:     helper.FormatRupiah(float64(updatedCampaign.GoalAmount)),
-				CollectedFunds: helper.FormatRupiah(sumAmount),
-				AdminFee:       helper.FormatRupiah(forDeducted),
-				FinalAmount:    helper.FormatRupiah(deductedAmount),
-			}
-			go helper.SendMail(userOwnerCampaign.Email, fmt.Sprintf("Your Campaign (%v) Has Finished", updatedCampaign.Title), templateData, "html/campaign_finished.html")
-		}
-
-		if campaignData.IsExclusive == 1 {
-			var reqCheckAndSetWinnerCampaignExclusive campaign.RequestGetCampaignExclusiveByCampaignID
-			reqCheckAndSetWinnerCampaignExclusive.ID = campaignData.ID
-
-			if _, err := svc.campaignSvc.CheckAndSetWinnerCampaignExclusive(reqCheckAndSetWinnerCampaignExclusive); err != nil {
-				log.Println("[transaction with e-money] error while check and set winner campaign exclusive, err: ", err.Error())
-				return newTransactionData, err
-			}
-		}
-	}
-
-	if err := svc.campaignRepo.UpdateCampaignFromPayment(campaignData.ID, req.Amount); err != nil {
-		log.Println("[transaction with e-money] error while update campaign from payment, err: ", err.Error())
-		return newTransactionData, err
-	}
-
-	userData, err := svc.userRepo.GetUserByID(req.User.ID)
+	newTransactionData, err := svc.repo.SaveEMoneyTransaction(transaction, func(paidTransaction Transaction) []event.Event {
+		return []event.Event{event.DonationPaid{
+			TransactionID:   paidTransaction.ID,
+			Code:            paidTransaction.Code,
+			CampaignID:      paidTransaction.CampaignID,
+			CampaignOwnerID: campaignData.UserID,
+			UserID:          paidTransaction.UserID,
+			Amount:          paidTransaction.Amount,
+			PaidWith:        event.PaidWithEMoney,
+			PaidAt:          time.Now(),
+		}}
+	})
 
 	if err != nil {
+		log.Println("[transaction with e-money] error while save transaction, err: ", err.Error())
 		return newTransactionData, err
 	}
 
-	userData.EMoney = userData.EMoney - float64(req.Amount)
-	userData, err = svc.userRepo.UpdateUser(userData)
+	audit.Record(svc.auditSvc, req.User, audit.ActionCreate, audit.EntityTransaction, newTransactionData.ID, nil, newTransactionData)
 
-	if err != nil {
-		return newTransactionData, err
+	if userAfter, err := svc.userRepo.GetUserByID(req.User.ID); err == nil {
+		audit.Record(svc.auditSvc, req.User, audit.ActionUpdate, audit.EntityUser, userAfter.ID, userBefore, userAfter)
 	}
 
-	svc.userRepo.CreateEMoneyFlow(user.UserEMoneyFlow{
-		UserID: req.UserID,
-		Status: "out",
-		Amount: int64(req.Amount),
-		Note:   fmt.Sprintf("Donate with e-money to campaign id %v.", req.CampaignID),
-	})
-
-	svc.companyRepo.CreateCompanyCashFlow(company.CompanyCashFlow{
-		Status: "in",
-		Amount: int64(req.Amount),
-		Note:   fmt.Sprintf("Donate with e-money to campaign id %v by %v.", req.CampaignID, req.UserID),
-	})
-
 	return newTransactionData, nil
 }
@@ -0,0 +1,70 @@
+package transaction
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/company"
+	"github.com/WeAreAmazingTeam/tcd-backend/event"
+	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+)
+
+// RegisterSubscribers hangs the donation side of a settlement on the bus, the same subscribers
+// run for midtrans and e-money payments
+func (svc *service) RegisterSubscribers(bus *event.Bus) {
+	event.On(bus, "transaction.cash_flow", svc.recordDonationCashFlow)
+	event.On(bus, "transaction.donor_receipt", svc.sendDonorReceipt)
+	event.On(bus, "transaction.webhook_donation_paid", svc.publishDonationPaidWebhook)
+}
+
+func (svc *service) recordDonationCashFlow(paid event.DonationPaid) error {
+	note := fmt.Sprintf("Donate to campaign id %v by %v.", paid.CampaignID, paid.UserID)
+
+	if paid.PaidWith == event.PaidWithEMoney {
+		note = fmt.Sprintf("Donate with e-money to campaign id %v by %v.", paid.CampaignID, paid.UserID)
+	} else if paid.IsAnonymous() {
+		note = fmt.Sprintf("Donate to campaign id %v by anonymous.", paid.CampaignID)
+	}
+
+	if _, err := svc.companyRepo.CreateCompanyCashFlow(company.CompanyCashFlow{
+		Status: "in",
+		Amount: paid.Amount,
+		Note:   note,
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (svc *service) sendDonorReceipt(paid event.DonationPaid) error {
+	if paid.IsAnonymous() {
+		return nil
+	}
+
+	donor, err := svc.userRepo.GetUserByID(paid.UserID)
+
+	if err != nil {
+		return err
+	}
+
+	templateData := helper.EmailTransactionSuccess{
+		CampaignLink: os.Getenv("WEB_URL") + "/donate/" + strconv.Itoa(paid.CampaignID),
+		Name:         donor.Name,
+		Amount:       helper.FormatRupiah(float64(paid.Amount)),
+	}
+	return helper.SendNotification(helper.NotificationRecipient{UserID: donor.ID, Email: donor.Email, Locale: donor.Locale}, helper.EmailTemplateTransactionSuccess, templateData)
+}
+
+func (svc *service) publishDonationPaidWebhook(paid event.DonationPaid) error {
+	return helper.PublishWebhookEvent(helper.WebhookEventDonationPaid, paid.CampaignOwnerID, helper.WebhookDonationPaid{
+		TransactionID: paid.TransactionID,
+		Code:          paid.Code,
+		CampaignID:    paid.CampaignID,
+		Amount:        paid.Amount,
+		Anonymous:     paid.IsAnonymous(),
+		PaidWith:      paid.PaidWith,
+		PaidAt:        paid.PaidAt,
+	})
+}
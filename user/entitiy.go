@@ -1,11 +1,65 @@
 package user
 
 import (
+	"database/sql"
+	"errors"
 	"time"
 
 	"github.com/WeAreAmazingTeam/tcd-backend/constant"
 )
 
+// withdrawal request states, requested -> approved -> processing -> paid, or rejected / cancelled / failed with
+// the hold released
+const (
+	WithdrawalStatusRequested  = "requested"
+	WithdrawalStatusApproved   = "approved"
+	WithdrawalStatusProcessing = "processing"
+	WithdrawalStatusPaid       = "paid"
+	WithdrawalStatusRejected   = "rejected"
+	WithdrawalStatusCancelled  = "cancelled"
+	// the payout provider, or the admin paying by hand when payouts are off, could not pay it
+	WithdrawalStatusFailed = "failed"
+	// requests made before holds existed, they are approved like requested ones
+	WithdrawalStatusPending = "pending"
+)
+
+// a processing request may already be on its way to the bank, so it is only closed by its result and never rejected
+var withdrawalTransitions = map[string][]string{
+	WithdrawalStatusRequested:  {WithdrawalStatusApproved, WithdrawalStatusRejected, WithdrawalStatusCancelled},
+	WithdrawalStatusPending:    {WithdrawalStatusApproved, WithdrawalStatusRejected, WithdrawalStatusCancelled},
+	WithdrawalStatusApproved:   {WithdrawalStatusProcessing, WithdrawalStatusRejected},
+	WithdrawalStatusProcessing: {WithdrawalStatusPaid, WithdrawalStatusFailed},
+}
+
+var (
+	ErrEMoneyNotEnough      = errors.New("e-money balance is not enough")
+	ErrWithdrawalDailyLimit = errors.New("daily withdrawal limit reached")
+)
+
+// email categories, account and security emails are always sent
+const (
+	NotificationCategoryAccount  = "account"
+	NotificationCategorySecurity = "security"
+	NotificationCategoryDonation = "donation"
+	NotificationCategoryCampaign = "campaign"
+	NotificationCategoryReward   = "reward"
+)
+
+var NotificationCategories = []string{
+	NotificationCategoryAccount,
+	NotificationCategorySecurity,
+	NotificationCategoryDonation,
+	NotificationCategoryCampaign,
+	NotificationCategoryReward,
+}
+
+// the categories a user can turn off
+var OptionalNotificationCategories = []string{
+	NotificationCategoryDonation,
+	NotificationCategoryCampaign,
+	NotificationCategoryReward,
+}
+
 type (
 	User struct {
 		ID       int
@@ -14,6 +68,14 @@ type (
 		Email    string
 		Password string
 		EMoney   float64
+		// part of EMoney reserved by open withdrawal requests, it can not be spent or withdrawn again
+		EMoneyHeld float64
+		// "en" or "id", picks the language of the emails sent to this user
+		Locale string
+		// E.164 number for sms and whatsapp notifications, empty when not registered
+		PhoneNumber string
+		// null until the owner clicks the link from the verification email
+		EmailVerifiedAt sql.NullTime
 		constant.CreatedUpdatedDeleted
 	}
 
@@ -26,23 +88,175 @@ type (
 		constant.CreatedUpdatedDeleted
 	}
 
+	// withdrawalCashFlow is the company cash flow row of a paid withdrawal, written in the same database
+	// transaction as the payment. The company package owns the table but imports this one.
+	withdrawalCashFlow struct {
+		ID     int
+		Status string
+		Amount int64
+		Note   string
+		constant.CreatedUpdatedDeleted
+	}
+
 	UserWithdrawalRequest struct {
 		ID     int
 		UserID int
 		Status string
+		// the account is copied, deleting it later does not change where the request is paid
+		BankAccountID     int
+		BankCode          string
+		AccountNumber     string
+		AccountHolderName string
+		// Amount leaves the wallet, the user receives NetAmount after the Fee
+		Amount    int64
+		Fee       int64
+		NetAmount int64
+		// the part of Amount still reserved on the wallet, zero once the request is paid or closed
+		HeldAmount   int64
+		Note         string
+		RejectReason sql.NullString
+		// set while a payout batch pays the request, the reference is the id the provider reports back
+		PayoutBatchID   int
+		PayoutReference sql.NullString
+		FailureReason   sql.NullString
+		ApprovedAt      sql.NullTime
+		ProcessingAt    sql.NullTime
+		PaidAt          sql.NullTime
+		RejectedAt      sql.NullTime
+		CancelledAt     sql.NullTime
+		FailedAt        sql.NullTime
+		constant.CreatedUpdatedDeleted
+	}
+
+	// where withdrawals are paid, one account of a user is the default
+	UserBankAccount struct {
+		ID            int
+		UserID        int
+		BankCode      string
+		AccountNumber string
+		HolderName    string
+		IsDefault     int
+		// the holder name the payout provider returned, null until the account is verified
+		VerifiedName sql.NullString
+		VerifiedAt   sql.NullTime
+		constant.CreatedUpdatedDeleted
+	}
+
+	WithdrawalDailyLimit struct {
+		Since time.Time
+		// zero means no limit
 		Amount int64
-		Note   string
+		Count  int
+	}
+
+	UserTwoFactor struct {
+		ID           int          `json:"id"`
+		UserID       int          `json:"user_id"`
+		Secret       string       `json:"-"`
+		IsEnabled    int          `json:"is_enabled"`
+		LastUsedStep int64        `json:"-"`
+		EnabledAt    sql.NullTime `json:"enabled_at"`
 		constant.CreatedUpdatedDeleted
 	}
 
+	UserRecoveryCode struct {
+		ID       int          `json:"id"`
+		UserID   int          `json:"user_id"`
+		CodeHash string       `json:"-"`
+		UsedAt   sql.NullTime `json:"used_at"`
+		constant.CreatedDeleted
+	}
+
+	// only the sha256 of the token is stored, the raw token exists in the email only
+	UserEmailVerificationToken struct {
+		ID        int       `json:"id"`
+		UserID    int       `json:"user_id"`
+		TokenHash string    `json:"-"`
+		CreatedAt time.Time `json:"created_at"`
+	}
+
+	// a missing row means the category is enabled
+	UserNotificationPreference struct {
+		ID           int       `json:"id"`
+		UserID       int       `json:"user_id"`
+		Category     string    `json:"category"`
+		EmailEnabled bool      `json:"email_enabled"`
+		CreatedAt    time.Time `json:"created_at"`
+		UpdatedAt    time.Time `json:"updated_at"`
+	}
+
+	// push token of one app install, the same token moves to whoever registers it last
+	UserDeviceToken struct {
+		ID        int       `json:"id"`
+		UserID    int       `json:"user_id"`
+		Token     string    `json:"-"`
+		Platform  string    `json:"platform"`
+		CreatedAt time.Time `json:"created_at"`
+		UpdatedAt time.Time `json:"updated_at"`
+	}
+
+	// only the sha256 of the token is stored, the raw token exists in the email only
 	UserForgotPasswordToken struct {
 		ID        int       `json:"id"`
 		UserID    int       `json:"user_id"`
-		Token     string    `json:"token"`
+		TokenHash string    `json:"-"`
+		ExpiredAt time.Time `json:"expired_at"`
 		CreatedAt time.Time `json:"created_at"`
 	}
 )
 
+// AuditActor is how the user appears in the audit log, the empty user is recorded as the system
+func (user User) AuditActor() (int, string) {
+	return user.ID, user.Role
+}
+
+func (user User) IsEmailVerified() bool {
+	return user.EmailVerifiedAt.Valid
+}
+
+// AvailableEMoney is what the user can still spend or withdraw
+func (user User) AvailableEMoney() float64 {
+	return user.EMoney - user.EMoneyHeld
+}
+
+func (withdrawal UserWithdrawalRequest) CanMoveTo(status string) bool {
+	for _, val := range withdrawalTransitions[withdrawal.Status] {
+		if val == status {
+			return true
+		}
+	}
+	return false
+}
+
+// deductedOnApproval is true for a request approved before holds existed, its amount already left the wallet
+func (withdrawal UserWithdrawalRequest) deductedOnApproval() bool {
+	return withdrawal.HeldAmount == 0 &&
+		(withdrawal.Status == WithdrawalStatusApproved || withdrawal.Status == WithdrawalStatusProcessing)
+}
+
+// isClosedUnpaid is true for the states that give the amount back to the user
+func isClosedUnpaid(status string) bool {
+	return status == WithdrawalStatusRejected || status == WithdrawalStatusFailed
+}
+
+// isPayoutStatus is true for the states the payout of a request moves it through
+func isPayoutStatus(status string) bool {
+	return status == WithdrawalStatusProcessing || status == WithdrawalStatusPaid || status == WithdrawalStatusFailed
+}
+
+func IsOptionalNotificationCategory(category string) bool {
+	for _, val := range OptionalNotificationCategories {
+		if val == category {
+			return true
+		}
+	}
+	return false
+}
+
 func (UserEMoneyFlow) TableName() string {
 	return "user_emoney_flow"
 }
+
+func (withdrawalCashFlow) TableName() string {
+	return "company_cash_flow"
+}
@@ -1,38 +1,108 @@
 package user
 
+import (
+	"database/sql"
+	"time"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/payout"
+)
+
 type (
 	UserFormatter struct {
-		ID    int    `json:"id"`
-		Role  string `json:"role"`
-		Name  string `json:"name"`
-		Email string `json:"email"`
-		Token string `json:"token"`
+		ID              int    `json:"id"`
+		Role            string `json:"role"`
+		Name            string `json:"name"`
+		Email           string `json:"email"`
+		Locale          string `json:"locale"`
+		PhoneNumber     string `json:"phone_number"`
+		IsEmailVerified bool   `json:"is_email_verified"`
+		Token           string `json:"token"`
 	}
 
 	UserListFormatter struct {
-		ID     int     `json:"id"`
-		Role   string  `json:"role"`
-		Name   string  `json:"name"`
-		Email  string  `json:"email"`
-		EMoney float64 `json:"e_money"`
+		ID              int     `json:"id"`
+		Role            string  `json:"role"`
+		Name            string  `json:"name"`
+		Email           string  `json:"email"`
+		EMoney          float64 `json:"e_money"`
+		EMoneyHeld      float64 `json:"e_money_held"`
+		IsEmailVerified bool    `json:"is_email_verified"`
+	}
+
+	TwoFactorChallengeFormatter struct {
+		TwoFactorRequired  bool   `json:"two_factor_required"`
+		EnrolmentRequired  bool   `json:"enrolment_required"`
+		ChallengeToken     string `json:"challenge_token"`
+		ChallengeExpiresIn int    `json:"challenge_expires_in"`
+	}
+
+	TwoFactorSetupFormatter struct {
+		Secret          string `json:"secret"`
+		ProvisioningURI string `json:"provisioning_uri"`
+	}
+
+	TwoFactorLoginFormatter struct {
+		UserFormatter
+		RecoveryCodes []string `json:"recovery_codes,omitempty"`
+	}
+
+	DeviceTokenFormatter struct {
+		ID        int       `json:"id"`
+		Platform  string    `json:"platform"`
+		CreatedAt time.Time `json:"created_at"`
+		UpdatedAt time.Time `json:"updated_at"`
+	}
+
+	BankAccountFormatter struct {
+		ID            int        `json:"id"`
+		BankCode      string     `json:"bank_code"`
+		BankName      string     `json:"bank_name"`
+		AccountNumber string     `json:"account_number"`
+		HolderName    string     `json:"holder_name"`
+		IsDefault     bool       `json:"is_default"`
+		IsVerified    bool       `json:"is_verified"`
+		VerifiedAt    *time.Time `json:"verified_at"`
+	}
+
+	NotificationPreferenceFormatter struct {
+		Category     string `json:"category"`
+		EmailEnabled bool   `json:"email_enabled"`
+		Optional     bool   `json:"optional"`
 	}
 
 	WithdrawalRequestFormatter struct {
-		ID     int    `json:"id"`
-		UserID int    `json:"user_id"`
-		Status string `json:"status"`
-		Amount int64  `json:"amount"`
-		Note   string `json:"note"`
+		ID                int        `json:"id"`
+		UserID            int        `json:"user_id"`
+		Status            string     `json:"status"`
+		BankCode          string     `json:"bank_code"`
+		AccountNumber     string     `json:"account_number"`
+		AccountHolderName string     `json:"account_holder_name"`
+		Amount            int64      `json:"amount"`
+		Fee               int64      `json:"fee"`
+		NetAmount         int64      `json:"net_amount"`
+		HeldAmount        int64      `json:"held_amount"`
+		Note              string     `json:"note"`
+		RejectReason      *string    `json:"reject_reason"`
+		FailureReason     *string    `json:"failure_reason"`
+		ApprovedAt        *time.Time `json:"approved_at"`
+		ProcessingAt      *time.Time `json:"processing_at"`
+		PaidAt            *time.Time `json:"paid_at"`
+		RejectedAt        *time.Time `json:"rejected_at"`
+		CancelledAt       *time.Time `json:"cancelled_at"`
+		FailedAt          *time.Time `json:"failed_at"`
 	}
 )
 
 func FormatUserData(user User, token string) UserFormatter {
 	formatData := UserFormatter{
-		ID:    user.ID,
-		Role:  user.Role,
-		Name:  user.Name,
-		Email: user.Email,
-		Token: token,
+		ID:              user.ID,
+		Role:            user.Role,
+		Name:            user.Name,
+		Email:           user.Email,
+		Locale:          user.Locale,
+		PhoneNumber:     user.PhoneNumber,
+		IsEmailVerified: user.IsEmailVerified(),
+		Token:           token,
 	}
 
 	return formatData
@@ -40,11 +110,13 @@ func FormatUserData(user User, token string) UserFormatter {
 
 func FormatUserFullData(user User) UserListFormatter {
 	formatData := UserListFormatter{
-		ID:     user.ID,
-		Role:   user.Role,
-		Name:   user.Name,
-		Email:  user.Email,
-		EMoney: user.EMoney,
+		ID:              user.ID,
+		Role:            user.Role,
+		Name:            user.Name,
+		Email:           user.Email,
+		EMoney:          user.EMoney,
+		EMoneyHeld:      user.EMoneyHeld,
+		IsEmailVerified: user.IsEmailVerified(),
 	}
 
 	return formatData
@@ -58,6 +130,8 @@ func FormatListUserData(users []User) (response []UserListFormatter) {
 		tmp.Name = val.Name
 		tmp.Email = val.Email
 		tmp.EMoney = val.EMoney
+		tmp.EMoneyHeld = val.EMoneyHeld
+		tmp.IsEmailVerified = val.IsEmailVerified()
 
 		response = append(response, tmp)
 	}
@@ -71,12 +145,98 @@ func FormatListUserData(users []User) (response []UserListFormatter) {
 
 func FormatWithdrawalRequestData(request UserWithdrawalRequest) WithdrawalRequestFormatter {
 	formatData := WithdrawalRequestFormatter{
-		ID:     request.ID,
-		UserID: request.UserID,
-		Status: request.Status,
-		Amount: request.Amount,
-		Note:   request.Note,
+		ID:                request.ID,
+		UserID:            request.UserID,
+		Status:            request.Status,
+		BankCode:          request.BankCode,
+		AccountNumber:     request.AccountNumber,
+		AccountHolderName: request.AccountHolderName,
+		Amount:            request.Amount,
+		Fee:               request.Fee,
+		NetAmount:         request.NetAmount,
+		HeldAmount:        request.HeldAmount,
+		Note:              request.Note,
+		ApprovedAt:        nullTime(request.ApprovedAt),
+		ProcessingAt:      nullTime(request.ProcessingAt),
+		PaidAt:            nullTime(request.PaidAt),
+		RejectedAt:        nullTime(request.RejectedAt),
+		CancelledAt:       nullTime(request.CancelledAt),
+		FailedAt:          nullTime(request.FailedAt),
+	}
+
+	if request.RejectReason.Valid {
+		formatData.RejectReason = &request.RejectReason.String
+	}
+
+	if request.FailureReason.Valid {
+		formatData.FailureReason = &request.FailureReason.String
 	}
 
 	return formatData
 }
+
+func FormatNotificationPreferences(preferences []UserNotificationPreference) (response []NotificationPreferenceFormatter) {
+	for _, val := range preferences {
+		response = append(response, NotificationPreferenceFormatter{
+			Category:     val.Category,
+			EmailEnabled: val.EmailEnabled,
+			Optional:     IsOptionalNotificationCategory(val.Category),
+		})
+	}
+
+	if len(response) == 0 {
+		return []NotificationPreferenceFormatter{}
+	}
+
+	return response
+}
+
+func FormatListDeviceTokenData(deviceTokens []UserDeviceToken) (response []DeviceTokenFormatter) {
+	for _, val := range deviceTokens {
+		response = append(response, DeviceTokenFormatter{
+			ID:        val.ID,
+			Platform:  val.Platform,
+			CreatedAt: val.CreatedAt,
+			UpdatedAt: val.UpdatedAt,
+		})
+	}
+
+	if len(response) == 0 {
+		return []DeviceTokenFormatter{}
+	}
+
+	return response
+}
+
+func FormatBankAccountData(bankAccount UserBankAccount) BankAccountFormatter {
+	return BankAccountFormatter{
+		ID:            bankAccount.ID,
+		BankCode:      bankAccount.BankCode,
+		BankName:      payout.Banks[bankAccount.BankCode],
+		AccountNumber: bankAccount.AccountNumber,
+		HolderName:    bankAccount.HolderName,
+		IsDefault:     bankAccount.IsDefault == 1,
+		IsVerified:    bankAccount.VerifiedAt.Valid,
+		VerifiedAt:    nullTime(bankAccount.VerifiedAt),
+	}
+}
+
+func FormatListBankAccountData(bankAccounts []UserBankAccount) (response []BankAccountFormatter) {
+	for _, val := range bankAccounts {
+		response = append(response, FormatBankAccountData(val))
+	}
+
+	if len(response) == 0 {
+		return []BankAccountFormatter{}
+	}
+
+	return response
+}
+
+func nullTime(value sql.NullTime) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+
+	return &value.Time
+}
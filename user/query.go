@@ -9,6 +9,7 @@ const (
 			email,
 			password,
 			e_money,
+			e_money_held,
 			created_at,
 			created_by,
 			updated_at,
@@ -29,6 +30,7 @@ const (
 			email,
 			password,
 			e_money,
+			e_money_held,
 			created_at,
 			created_by,
 			updated_at,
@@ -96,8 +98,15 @@ const (
 		SELECT
 			id,
 			status,
+			bank_code,
+			account_number,
+			account_holder_name,
 			amount,
+			fee,
+			net_amount,
+			held_amount,
 			note,
+			reject_reason,
 			created_at,
 			created_by,
 			updated_at,
@@ -129,8 +138,15 @@ const (
 			user_id,
 			COALESCE((SELECT name FROM users WHERE id = user_id), '') AS user_name,
 			status,
+			bank_code,
+			account_number,
+			account_holder_name,
 			amount,
+			fee,
+			net_amount,
+			held_amount,
 			note,
+			reject_reason,
 			created_at,
 			created_by,
 			updated_at,
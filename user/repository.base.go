@@ -1,6 +1,8 @@
 package user
 
 import (
+	"time"
+
 	"github.com/WeAreAmazingTeam/tcd-backend/helper"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -16,16 +18,53 @@ type Repository interface {
 	DeleteUser(User) (bool, error)
 
 	GiveEMoneyToUser(userID, eMoney int) error
+	AdjustEMoney(userID int, delta float64) error
 
 	CreateEMoneyFlow(UserEMoneyFlow) (UserEMoneyFlow, error)
 
-	GetDataForgotPasswordByToken(token string) (UserForgotPasswordToken, error)
+	GetDataForgotPasswordByToken(tokenHash string) (UserForgotPasswordToken, error)
 	CreateForgotPasswordToken(UserForgotPasswordToken) (UserForgotPasswordToken, error)
 	DeleteForgotPasswordToken(UserForgotPasswordToken) (bool, error)
+	DeleteForgotPasswordTokenByUserID(userID int) (bool, error)
+	DeleteExpiredForgotPasswordToken(now time.Time) (int64, error)
+
+	GetEmailVerificationByToken(tokenHash string) (UserEmailVerificationToken, error)
+	GetLatestEmailVerificationByUserID(userID int) (UserEmailVerificationToken, error)
+	CreateEmailVerificationToken(UserEmailVerificationToken) (UserEmailVerificationToken, error)
+	DeleteEmailVerificationTokenByUserID(userID int) (bool, error)
+	VerifyEmailOfUsersCreatedBefore(before time.Time) (int64, error)
+
+	GetTwoFactorByUserID(userID int) (UserTwoFactor, error)
+	SaveTwoFactor(UserTwoFactor) (UserTwoFactor, error)
+	DeleteTwoFactor(UserTwoFactor) (bool, error)
+	ReplaceRecoveryCodes(userID int, codeHashes []string) error
+	UseRecoveryCode(userID int, codeHash string) (bool, error)
+	UseTwoFactorStep(id int, step int64) (bool, error)
+
+	GetNotificationPreferencesByUserID(userID int) ([]UserNotificationPreference, error)
+	GetNotificationPreference(userID int, category string) (UserNotificationPreference, error)
+	SaveNotificationPreference(UserNotificationPreference) (UserNotificationPreference, error)
+
+	GetDeviceTokensByUserID(userID int) ([]UserDeviceToken, error)
+	GetDeviceTokenByToken(token string) (UserDeviceToken, error)
+	SaveDeviceToken(UserDeviceToken) (UserDeviceToken, error)
+	DeleteDeviceToken(userID, id int) (bool, error)
+	DeleteDeviceTokensByToken(tokens []string) (int64, error)
+
+	GetBankAccountsByUserID(userID int) ([]UserBankAccount, error)
+	GetBankAccountByID(userID, id int) (UserBankAccount, error)
+	GetDefaultBankAccount(userID int) (UserBankAccount, error)
+	CreateBankAccount(UserBankAccount) (UserBankAccount, error)
+	UpdateBankAccount(UserBankAccount) (UserBankAccount, error)
+	SetDefaultBankAccount(userID, id int) (bool, error)
+	DeleteBankAccount(UserBankAccount) (bool, error)
 
 	GetWithdrawalRequestByID(id int) (UserWithdrawalRequest, error)
-	CreateWithdrawalRequest(UserWithdrawalRequest) (UserWithdrawalRequest, error)
-	UpdateUserWithdrawalRequest(UserWithdrawalRequest) (UserWithdrawalRequest, error)
+	GetApprovedWithdrawalRequests(limit int) ([]UserWithdrawalRequest, error)
+	GetWithdrawalRequestsByPayoutBatchID(batchID int) ([]UserWithdrawalRequest, error)
+	GetWithdrawalRequestByPayoutReference(reference string) (UserWithdrawalRequest, error)
+	CreateWithdrawalRequest(UserWithdrawalRequest, WithdrawalDailyLimit) (UserWithdrawalRequest, error)
+	UpdateUserWithdrawalRequest(userWithdrawalRequest, before UserWithdrawalRequest) (bool, error)
 	DeleteUserWithdrawalRequest(UserWithdrawalRequest) (bool, error)
 
 	AdminDataTablesUsers(ctx *gin.Context) (helper.DataTables, error)
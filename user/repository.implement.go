@@ -6,6 +6,7 @@ import (
 	"os/user"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/WeAreAmazingTeam/tcd-backend/constant"
 	"github.com/WeAreAmazingTeam/tcd-backend/helper"
@@ -62,6 +63,7 @@ func (repo *repository) GetAllUser() (users []User, err error) {
 			&tmp.Email,
 			&tmp.Password,
 			&tmp.EMoney,
+			&tmp.EMoneyHeld,
 			&tmp.CreatedAt,
 			&tmp.CreatedBy,
 			&tmp.UpdatedAt,
@@ -81,7 +83,8 @@ func (repo *repository) GetAllUser() (users []User, err error) {
 }
 
 func (repo *repository) UpdateUser(user User) (User, error) {
-	if err := repo.DB.Save(&user).Error; err != nil {
+	// the balance and the hold only move through atomic updates, the copy loaded with the request may be stale
+	if err := repo.DB.Omit("e_money", "e_money_held").Save(&user).Error; err != nil {
 		return user, err
 	}
 	return user, nil
@@ -89,7 +92,7 @@ func (repo *repository) UpdateUser(user User) (User, error) {
 
 func (repo *repository) DeleteUser(user User) (bool, error) {
 	if constant.DELETED_BY {
-		if err := repo.DB.Save(&user).Error; err != nil {
+		if err := repo.DB.Omit("e_money", "e_money_held").Save(&user).Error; err != nil {
 			return false, err
 		}
 		return true, nil
@@ -184,6 +187,7 @@ func (repo *repository) AdminDataTablesUsers(ctx *gin.Context) (result helper.Da
 			&tmp.Email,
 			&tmp.Password,
 			&tmp.EMoney,
+			&tmp.EMoneyHeld,
 			&tmp.CreatedAt,
 			&tmp.CreatedBy,
 			&tmp.UpdatedAt,
@@ -197,19 +201,20 @@ func (repo *repository) AdminDataTablesUsers(ctx *gin.Context) (result helper.Da
 		}
 
 		data = append(data, map[string]any{
-			"no":         no,
-			"id":         tmp.ID,
-			"role":       tmp.Role,
-			"name":       tmp.Name,
-			"email":      tmp.Email,
-			"password":   tmp.Password,
-			"e_money":    tmp.EMoney,
-			"created_at": helper.HNTime(tmp.CreatedAt),
-			"created_by": helper.HNString(tmp.CreatedBy),
-			"updated_at": helper.HNTime(tmp.UpdatedAt),
-			"updated_by": helper.HNString(tmp.UpdatedBy),
-			"deleted_at": helper.HNTimeGDeletedAt(tmp.DeletedAt),
-			"deleted_by": helper.HNString(tmp.DeletedBy),
+			"no":           no,
+			"id":           tmp.ID,
+			"role":         tmp.Role,
+			"name":         tmp.Name,
+			"email":        tmp.Email,
+			"password":     tmp.Password,
+			"e_money":      tmp.EMoney,
+			"e_money_held": tmp.EMoneyHeld,
+			"created_at":   helper.HNTime(tmp.CreatedAt),
+			"created_by":   helper.HNString(tmp.CreatedBy),
+			"updated_at":   helper.HNTime(tmp.UpdatedAt),
+			"updated_by":   helper.HNString(tmp.UpdatedBy),
+			"deleted_at":   helper.HNTimeGDeletedAt(tmp.DeletedAt),
+			"deleted_by":   helper.HNString(tmp.DeletedBy),
 		})
 
 		no++
@@ -242,6 +247,23 @@ func (repo *repository) GiveEMoneyToUser(userID, eMoney int) error {
 	return nil
 }
 
+// AdjustEMoney moves the balance by delta, never below what open withdrawal requests hold
+func (repo *repository) AdjustEMoney(userID int, delta float64) error {
+	result := repo.DB.Model(&User{}).
+		Where("id = ? AND e_money + ? >= e_money_held", userID, delta).
+		Update("e_money", gorm.Expr("e_money + ?", delta))
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrEMoneyNotEnough
+	}
+
+	return nil
+}
+
 func (repo *repository) CreateEMoneyFlow(userEMoneyFlow UserEMoneyFlow) (UserEMoneyFlow, error) {
 	if err := repo.DB.Create(&userEMoneyFlow).Error; err != nil {
 		return userEMoneyFlow, err
@@ -360,11 +382,39 @@ func (repo *repository) UserDataTablesEMoneyFlow(ctx *gin.Context, user User) (r
 	return helper.BuildDatatTables(data, filtered, total), nil
 }
 
-func (repo *repository) CreateWithdrawalRequest(userWithdrawalRequest UserWithdrawalRequest) (UserWithdrawalRequest, error) {
-	if err := repo.DB.Create(&userWithdrawalRequest).Error; err != nil {
-		return userWithdrawalRequest, err
-	}
-	return userWithdrawalRequest, nil
+// CreateWithdrawalRequest holds the amount on the wallet and saves the request in one database transaction.
+// The hold locks the user row, so the daily totals counted after it can not race another request of the same user.
+// Requests that gave the amount back, rejected, cancelled or failed ones, do not count toward the limit.
+func (repo *repository) CreateWithdrawalRequest(userWithdrawalRequest UserWithdrawalRequest, limit WithdrawalDailyLimit) (UserWithdrawalRequest, error) {
+	err := repo.DB.Transaction(func(tx *gorm.DB) error {
+		if err := holdEMoney(tx, userWithdrawalRequest.UserID, userWithdrawalRequest.HeldAmount); err != nil {
+			return err
+		}
+
+		var today struct {
+			Amount int64
+			Count  int
+		}
+
+		if err := tx.Model(&UserWithdrawalRequest{}).
+			Select("COALESCE(SUM(amount), 0) AS amount, COUNT(id) AS count").
+			Where("user_id = ? AND created_at >= ? AND status NOT IN ?", userWithdrawalRequest.UserID, limit.Since, []string{WithdrawalStatusRejected, WithdrawalStatusCancelled, WithdrawalStatusFailed}).
+			Scan(&today).Error; err != nil {
+			return err
+		}
+
+		if limit.Amount > 0 && today.Amount+userWithdrawalRequest.Amount > limit.Amount {
+			return ErrWithdrawalDailyLimit
+		}
+
+		if limit.Count > 0 && today.Count+1 > limit.Count {
+			return ErrWithdrawalDailyLimit
+		}
+
+		return tx.Create(&userWithdrawalRequest).Error
+	})
+
+	return userWithdrawalRequest, err
 }
 
 func (repo *repository) UserDataTablesWithdrawalRequest(ctx *gin.Context, user User) (result helper.DataTables, err error) {
@@ -444,8 +494,15 @@ func (repo *repository) UserDataTablesWithdrawalRequest(ctx *gin.Context, user U
 		err := rows.Scan(
 			&tmp.ID,
 			&tmp.Status,
+			&tmp.BankCode,
+			&tmp.AccountNumber,
+			&tmp.AccountHolderName,
 			&tmp.Amount,
+			&tmp.Fee,
+			&tmp.NetAmount,
+			&tmp.HeldAmount,
 			&tmp.Note,
+			&tmp.RejectReason,
 			&tmp.CreatedAt,
 			&tmp.CreatedBy,
 			&tmp.UpdatedAt,
@@ -459,17 +516,24 @@ func (repo *repository) UserDataTablesWithdrawalRequest(ctx *gin.Context, user U
 		}
 
 		data = append(data, map[string]any{
-			"no":         no,
-			"id":         tmp.ID,
-			"status":     tmp.Status,
-			"amount":     tmp.Amount,
-			"note":       tmp.Note,
-			"created_at": helper.HNTime(tmp.CreatedAt),
-			"created_by": helper.HNString(tmp.CreatedBy),
-			"updated_at": helper.HNTime(tmp.UpdatedAt),
-			"updated_by": helper.HNString(tmp.UpdatedBy),
-			"deleted_at": helper.HNTimeGDeletedAt(tmp.DeletedAt),
-			"deleted_by": helper.HNString(tmp.DeletedBy),
+			"no":                  no,
+			"id":                  tmp.ID,
+			"status":              tmp.Status,
+			"bank_code":           tmp.BankCode,
+			"account_number":      tmp.AccountNumber,
+			"account_holder_name": tmp.AccountHolderName,
+			"amount":              tmp.Amount,
+			"fee":                 tmp.Fee,
+			"net_amount":          tmp.NetAmount,
+			"held_amount":         tmp.HeldAmount,
+			"note":                tmp.Note,
+			"reject_reason":       helper.HNString(tmp.RejectReason),
+			"created_at":          helper.HNTime(tmp.CreatedAt),
+			"created_by":          helper.HNString(tmp.CreatedBy),
+			"updated_at":          helper.HNTime(tmp.UpdatedAt),
+			"updated_by":          helper.HNString(tmp.UpdatedBy),
+			"deleted_at":          helper.HNTimeGDeletedAt(tmp.DeletedAt),
+			"deleted_by":          helper.HNString(tmp.DeletedBy),
 		})
 
 		no++
@@ -558,8 +622,15 @@ func (repo *repository) AdminDataTablesWithdrawalRequest(ctx *gin.Context) (resu
 			&tmp.UserID,
 			&userName,
 			&tmp.Status,
+			&tmp.BankCode,
+			&tmp.AccountNumber,
+			&tmp.AccountHolderName,
 			&tmp.Amount,
+			&tmp.Fee,
+			&tmp.NetAmount,
+			&tmp.HeldAmount,
 			&tmp.Note,
+			&tmp.RejectReason,
 			&tmp.CreatedAt,
 			&tmp.CreatedBy,
 			&tmp.UpdatedAt,
@@ -573,19 +644,26 @@ func (repo *repository) AdminDataTablesWithdrawalRequest(ctx *gin.Context) (resu
 		}
 
 		data = append(data, map[string]any{
-			"no":         no,
-			"id":         tmp.ID,
-			"user_id":    tmp.UserID,
-			"user_name":  userName,
-			"status":     tmp.Status,
-			"amount":     tmp.Amount,
-			"note":       tmp.Note,
-			"created_at": helper.HNTime(tmp.CreatedAt),
-			"created_by": helper.HNString(tmp.CreatedBy),
-			"updated_at": helper.HNTime(tmp.UpdatedAt),
-			"updated_by": helper.HNString(tmp.UpdatedBy),
-			"deleted_at": helper.HNTimeGDeletedAt(tmp.DeletedAt),
-			"deleted_by": helper.HNString(tmp.DeletedBy),
+			"no":                  no,
+			"id":                  tmp.ID,
+			"user_id":             tmp.UserID,
+			"user_name":           userName,
+			"status":              tmp.Status,
+			"bank_code":           tmp.BankCode,
+			"account_number":      tmp.AccountNumber,
+			"account_holder_name": tmp.AccountHolderName,
+			"amount":              tmp.Amount,
+			"fee":                 tmp.Fee,
+			"net_amount":          tmp.NetAmount,
+			"held_amount":         tmp.HeldAmount,
+			"note":                tmp.Note,
+			"reject_reason":       helper.HNString(tmp.RejectReason),
+			"created_at":          helper.HNTime(tmp.CreatedAt),
+			"created_by":          helper.HNString(tmp.CreatedBy),
+			"updated_at":          helper.HNTime(tmp.UpdatedAt),
+			"updated_by":          helper.HNString(tmp.UpdatedBy),
+			"deleted_at":          helper.HNTimeGDeletedAt(tmp.DeletedAt),
+			"deleted_by":          helper.HNString(tmp.DeletedBy),
 		})
 
 		no++
@@ -606,11 +684,160 @@ func (repo *repository) GetWithdrawalRequestByID(id int) (userWithdrawalRequest
 	return userWithdrawalRequest, nil
 }
 
-func (repo *repository) UpdateUserWithdrawalRequest(userWithdrawalRequest UserWithdrawalRequest) (UserWithdrawalRequest, error) {
-	if err := repo.DB.Save(&userWithdrawalRequest).Error; err != nil {
-		return userWithdrawalRequest, err
+// GetApprovedWithdrawalRequests leaves out requests approved before holds existed, they were paid by hand
+func (repo *repository) GetApprovedWithdrawalRequests(limit int) (withdrawals []UserWithdrawalRequest, err error) {
+	if err := repo.DB.Where("status = ? AND held_amount > 0", WithdrawalStatusApproved).Order("approved_at ASC, id ASC").Limit(limit).Find(&withdrawals).Error; err != nil {
+		return withdrawals, err
 	}
-	return userWithdrawalRequest, nil
+	return withdrawals, nil
+}
+
+func (repo *repository) GetWithdrawalRequestsByPayoutBatchID(batchID int) (withdrawals []UserWithdrawalRequest, err error) {
+	if err := repo.DB.Where("payout_batch_id = ?", batchID).Order("id ASC").Find(&withdrawals).Error; err != nil {
+		return withdrawals, err
+	}
+	return withdrawals, nil
+}
+
+func (repo *repository) GetWithdrawalRequestByPayoutReference(reference string) (withdrawal UserWithdrawalRequest, err error) {
+	if err := repo.DB.Where("payout_reference = ?", reference).Find(&withdrawal).Error; err != nil {
+		return withdrawal, err
+	}
+
+	if withdrawal.ID == 0 {
+		return withdrawal, errors.New("sql: no rows in result set")
+	}
+
+	return withdrawal, nil
+}
+
+// UpdateUserWithdrawalRequest moves the request from the state in before and settles the wallet in the same database
+// transaction: a larger HeldAmount places a hold, a smaller one releases it, and paid spends what was held.
+// It returns false when the request changed since before was read.
+func (repo *repository) UpdateUserWithdrawalRequest(userWithdrawalRequest, before UserWithdrawalRequest) (bool, error) {
+	updated := false
+
+	err := repo.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&UserWithdrawalRequest{}).
+			Where("id = ? AND status = ? AND held_amount = ?", before.ID, before.Status, before.HeldAmount).
+			Select("*").
+			Omit("id", "created_at", "created_by").
+			Updates(&userWithdrawalRequest)
+
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		if userWithdrawalRequest.Status == WithdrawalStatusPaid {
+			if err := spendHeldEMoney(tx, userWithdrawalRequest, before.HeldAmount); err != nil {
+				return err
+			}
+		} else if before.deductedOnApproval() && isClosedUnpaid(userWithdrawalRequest.Status) {
+			if err := refundEMoney(tx, userWithdrawalRequest); err != nil {
+				return err
+			}
+		} else if delta := userWithdrawalRequest.HeldAmount - before.HeldAmount; delta > 0 {
+			if err := holdEMoney(tx, userWithdrawalRequest.UserID, delta); err != nil {
+				return err
+			}
+		} else if delta < 0 {
+			if err := tx.Model(&User{}).Where("id = ?", userWithdrawalRequest.UserID).
+				Update("e_money_held", gorm.Expr("e_money_held - ?", -delta)).Error; err != nil {
+				return err
+			}
+		}
+
+		updated = true
+		return nil
+	})
+
+	return updated, err
+}
+
+func holdEMoney(tx *gorm.DB, userID int, amount int64) error {
+	result := tx.Model(&User{}).
+		Where("id = ? AND e_money - e_money_held >= ?", userID, amount).
+		Update("e_money_held", gorm.Expr("e_money_held + ?", amount))
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrEMoneyNotEnough
+	}
+
+	return nil
+}
+
+// spendHeldEMoney takes the held amount out of the wallet. Requests approved before holds existed were deducted
+// on approval and hold nothing, so they are paid without touching the wallet again; their company cash flow
+// was written on approval too.
+func spendHeldEMoney(tx *gorm.DB, withdrawal UserWithdrawalRequest, held int64) error {
+	if held == 0 {
+		return nil
+	}
+
+	result := tx.Model(&User{}).
+		Where("id = ? AND e_money >= ? AND e_money_held >= ?", withdrawal.UserID, held, held).
+		Updates(map[string]any{
+			"e_money":      gorm.Expr("e_money - ?", held),
+			"e_money_held": gorm.Expr("e_money_held - ?", held),
+		})
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrEMoneyNotEnough
+	}
+
+	if err := tx.Create(&UserEMoneyFlow{
+		UserID: withdrawal.UserID,
+		Status: "out",
+		Amount: held,
+		Note:   fmt.Sprintf("Withdrawal #%v, fee Rp %v.", withdrawal.ID, withdrawal.Fee),
+	}).Error; err != nil {
+		return err
+	}
+
+	// the fee stays with the company, only the net amount leaves it
+	cashFlow := withdrawalCashFlow{Status: "out", Amount: withdrawal.NetAmount, Note: fmt.Sprintf("Paid withdrawal id %v.", withdrawal.ID)}
+	cashFlow.CreatedBy = withdrawal.UpdatedBy
+
+	if withdrawal.PayoutBatchID != 0 {
+		cashFlow.Note = fmt.Sprintf("Paid withdrawal id %v with payout batch id %v.", withdrawal.ID, withdrawal.PayoutBatchID)
+	}
+
+	return tx.Create(&cashFlow).Error
+}
+
+// refundEMoney gives back a request approved before holds existed, its amount was taken out of the wallet and
+// the company cash flow on approval
+func refundEMoney(tx *gorm.DB, withdrawal UserWithdrawalRequest) error {
+	if err := tx.Model(&User{}).Where("id = ?", withdrawal.UserID).
+		Update("e_money", gorm.Expr("e_money + ?", withdrawal.Amount)).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Create(&UserEMoneyFlow{
+		UserID: withdrawal.UserID,
+		Status: "in",
+		Amount: withdrawal.Amount,
+		Note:   fmt.Sprintf("Refund of withdrawal #%v.", withdrawal.ID),
+	}).Error; err != nil {
+		return err
+	}
+
+	cashFlow := withdrawalCashFlow{Status: "in", Amount: withdrawal.Amount, Note: fmt.Sprintf("Refunded withdrawal id %v.", withdrawal.ID)}
+	cashFlow.CreatedBy = withdrawal.UpdatedBy
+
+	return tx.Create(&cashFlow).Error
 }
 
 func (repo *repository) DeleteUserWithdrawalRequest(userWithdrawalRequest UserWithdrawalRequest) (bool, error) {
@@ -634,10 +861,15 @@ func (repo *repository) CreateForgotPasswordToken(userForgotPasswordToken UserFo
 	return userForgotPasswordToken, nil
 }
 
-func (repo *repository) GetDataForgotPasswordByToken(token string) (userForgotPasswordToken UserForgotPasswordToken, err error) {
-	if err := repo.DB.Where("token = ?", token).Find(&userForgotPasswordToken).Error; err != nil {
+func (repo *repository) GetDataForgotPasswordByToken(tokenHash string) (userForgotPasswordToken UserForgotPasswordToken, err error) {
+	if err := repo.DB.Where("token_hash = ?", tokenHash).Find(&userForgotPasswordToken).Error; err != nil {
 		return userForgotPasswordToken, err
 	}
+
+	if userForgotPasswordToken.ID == 0 {
+		return userForgotPasswordToken, errors.New("sql: no rows in result set")
+	}
+
 	return userForgotPasswordToken, nil
 }
 
@@ -647,3 +879,359 @@ func (repo *repository) DeleteForgotPasswordToken(userForgotPasswordToken UserFo
 	}
 	return true, nil
 }
+
+func (repo *repository) DeleteForgotPasswordTokenByUserID(userID int) (bool, error) {
+	if err := repo.DB.Where("user_id = ?", userID).Delete(&UserForgotPasswordToken{}).Error; err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (repo *repository) DeleteExpiredForgotPasswordToken(now time.Time) (int64, error) {
+	result := repo.DB.Where("expired_at <= ?", now).Delete(&UserForgotPasswordToken{})
+
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
+
+func (repo *repository) GetEmailVerificationByToken(tokenHash string) (userEmailVerificationToken UserEmailVerificationToken, err error) {
+	if err := repo.DB.Where("token_hash = ?", tokenHash).Find(&userEmailVerificationToken).Error; err != nil {
+		return userEmailVerificationToken, err
+	}
+
+	if userEmailVerificationToken.ID == 0 {
+		return userEmailVerificationToken, errors.New("sql: no rows in result set")
+	}
+
+	return userEmailVerificationToken, nil
+}
+
+func (repo *repository) GetLatestEmailVerificationByUserID(userID int) (userEmailVerificationToken UserEmailVerificationToken, err error) {
+	if err := repo.DB.Where("user_id = ?", userID).Order("created_at DESC").Limit(1).Find(&userEmailVerificationToken).Error; err != nil {
+		return userEmailVerificationToken, err
+	}
+
+	if userEmailVerificationToken.ID == 0 {
+		return userEmailVerificationToken, errors.New("sql: no rows in result set")
+	}
+
+	return userEmailVerificationToken, nil
+}
+
+func (repo *repository) CreateEmailVerificationToken(userEmailVerificationToken UserEmailVerificationToken) (UserEmailVerificationToken, error) {
+	if err := repo.DB.Create(&userEmailVerificationToken).Error; err != nil {
+		return userEmailVerificationToken, err
+	}
+	return userEmailVerificationToken, nil
+}
+
+func (repo *repository) DeleteEmailVerificationTokenByUserID(userID int) (bool, error) {
+	if err := repo.DB.Where("user_id = ?", userID).Delete(&UserEmailVerificationToken{}).Error; err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// VerifyEmailOfUsersCreatedBefore marks the accounts made before email verification existed as verified. An
+// account with a verification link waiting is skipped, its email was changed and has to be verified again.
+func (repo *repository) VerifyEmailOfUsersCreatedBefore(before time.Time) (int64, error) {
+	pending := repo.DB.Model(&UserEmailVerificationToken{}).Select("user_id")
+
+	result := repo.DB.Model(&User{}).
+		Where("email_verified_at IS NULL AND created_at < ? AND id NOT IN (?)", before, pending).
+		UpdateColumn("email_verified_at", gorm.Expr("created_at"))
+
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
+
+func (repo *repository) GetTwoFactorByUserID(userID int) (userTwoFactor UserTwoFactor, err error) {
+	if err := repo.DB.Where("user_id = ?", userID).Find(&userTwoFactor).Error; err != nil {
+		return userTwoFactor, err
+	}
+
+	if userTwoFactor.ID == 0 {
+		return userTwoFactor, errors.New("sql: no rows in result set")
+	}
+
+	return userTwoFactor, nil
+}
+
+func (repo *repository) SaveTwoFactor(userTwoFactor UserTwoFactor) (UserTwoFactor, error) {
+	if err := repo.DB.Save(&userTwoFactor).Error; err != nil {
+		return userTwoFactor, err
+	}
+	return userTwoFactor, nil
+}
+
+func (repo *repository) DeleteTwoFactor(userTwoFactor UserTwoFactor) (bool, error) {
+	if err := repo.DB.Unscoped().Delete(&userTwoFactor).Error; err != nil {
+		return false, err
+	}
+
+	if err := repo.DB.Unscoped().Where("user_id = ?", userTwoFactor.UserID).Delete(&UserRecoveryCode{}).Error; err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (repo *repository) ReplaceRecoveryCodes(userID int, codeHashes []string) error {
+	return repo.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&UserRecoveryCode{}).Error; err != nil {
+			return err
+		}
+
+		for _, codeHash := range codeHashes {
+			if err := tx.Create(&UserRecoveryCode{UserID: userID, CodeHash: codeHash}).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (repo *repository) UseRecoveryCode(userID int, codeHash string) (bool, error) {
+	result := repo.DB.Model(&UserRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+// UseTwoFactorStep marks a totp step as used, false when it or a later one was used already
+func (repo *repository) UseTwoFactorStep(id int, step int64) (bool, error) {
+	result := repo.DB.Model(&UserTwoFactor{}).
+		Where("id = ? AND last_used_step < ?", id, step).
+		Update("last_used_step", step)
+
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+func (repo *repository) GetNotificationPreferencesByUserID(userID int) (preferences []UserNotificationPreference, err error) {
+	if err := repo.DB.Where("user_id = ?", userID).Find(&preferences).Error; err != nil {
+		return preferences, err
+	}
+	return preferences, nil
+}
+
+func (repo *repository) GetNotificationPreference(userID int, category string) (preference UserNotificationPreference, err error) {
+	if err := repo.DB.Where("user_id = ? AND category = ?", userID, category).Find(&preference).Error; err != nil {
+		return preference, err
+	}
+
+	if preference.ID == 0 {
+		return preference, errors.New("sql: no rows in result set")
+	}
+
+	return preference, nil
+}
+
+// SaveNotificationPreference inserts when the id is zero and updates otherwise
+func (repo *repository) SaveNotificationPreference(preference UserNotificationPreference) (UserNotificationPreference, error) {
+	if err := repo.DB.Save(&preference).Error; err != nil {
+		return preference, err
+	}
+	return preference, nil
+}
+
+func (repo *repository) GetDeviceTokensByUserID(userID int) (deviceTokens []UserDeviceToken, err error) {
+	if err := repo.DB.Where("user_id = ?", userID).Order("updated_at DESC").Find(&deviceTokens).Error; err != nil {
+		return deviceTokens, err
+	}
+	return deviceTokens, nil
+}
+
+func (repo *repository) GetDeviceTokenByToken(token string) (deviceToken UserDeviceToken, err error) {
+	if err := repo.DB.Where("token = ?", token).Find(&deviceToken).Error; err != nil {
+		return deviceToken, err
+	}
+
+	if deviceToken.ID == 0 {
+		return deviceToken, errors.New("sql: no rows in result set")
+	}
+
+	return deviceToken, nil
+}
+
+// SaveDeviceToken inserts when the id is zero and updates otherwise
+func (repo *repository) SaveDeviceToken(deviceToken UserDeviceToken) (UserDeviceToken, error) {
+	if err := repo.DB.Save(&deviceToken).Error; err != nil {
+		return deviceToken, err
+	}
+	return deviceToken, nil
+}
+
+// DeleteDeviceToken is scoped to the owner so one user cannot remove another user device
+func (repo *repository) DeleteDeviceToken(userID, id int) (bool, error) {
+	result := repo.DB.Where("id = ? AND user_id = ?", id, userID).Delete(&UserDeviceToken{})
+
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return false, errors.New("sql: no rows in result set")
+	}
+
+	return true, nil
+}
+
+func (repo *repository) DeleteDeviceTokensByToken(tokens []string) (int64, error) {
+	if len(tokens) == 0 {
+		return 0, nil
+	}
+
+	result := repo.DB.Where("token IN ?", tokens).Delete(&UserDeviceToken{})
+
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
+
+func (repo *repository) GetBankAccountsByUserID(userID int) (bankAccounts []UserBankAccount, err error) {
+	if err := repo.DB.Where("user_id = ?", userID).Order("is_default DESC, id ASC").Find(&bankAccounts).Error; err != nil {
+		return bankAccounts, err
+	}
+	return bankAccounts, nil
+}
+
+// GetBankAccountByID is scoped to the owner so one user cannot withdraw to another user account
+func (repo *repository) GetBankAccountByID(userID, id int) (bankAccount UserBankAccount, err error) {
+	if err := repo.DB.Where("id = ? AND user_id = ?", id, userID).Find(&bankAccount).Error; err != nil {
+		return bankAccount, err
+	}
+
+	if bankAccount.ID == 0 {
+		return bankAccount, errors.New("sql: no rows in result set")
+	}
+
+	return bankAccount, nil
+}
+
+func (repo *repository) GetDefaultBankAccount(userID int) (bankAccount UserBankAccount, err error) {
+	if err := repo.DB.Where("user_id = ? AND is_default = 1", userID).Find(&bankAccount).Error; err != nil {
+		return bankAccount, err
+	}
+
+	if bankAccount.ID == 0 {
+		return bankAccount, errors.New("sql: no rows in result set")
+	}
+
+	return bankAccount, nil
+}
+
+// CreateBankAccount makes the first account of a user the default one
+func (repo *repository) CreateBankAccount(bankAccount UserBankAccount) (UserBankAccount, error) {
+	err := repo.DB.Transaction(func(tx *gorm.DB) error {
+		var count int64
+
+		if err := tx.Model(&UserBankAccount{}).Where("user_id = ?", bankAccount.UserID).Count(&count).Error; err != nil {
+			return err
+		}
+
+		if count == 0 {
+			bankAccount.IsDefault = 1
+		}
+
+		return tx.Create(&bankAccount).Error
+	})
+
+	return bankAccount, err
+}
+
+func (repo *repository) UpdateBankAccount(bankAccount UserBankAccount) (UserBankAccount, error) {
+	if err := repo.DB.Save(&bankAccount).Error; err != nil {
+		return bankAccount, err
+	}
+	return bankAccount, nil
+}
+
+func (repo *repository) SetDefaultBankAccount(userID, id int) (bool, error) {
+	err := repo.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&UserBankAccount{}).Where("user_id = ? AND id != ?", userID, id).Update("is_default", 0).Error; err != nil {
+			return err
+		}
+
+		result := tx.Model(&UserBankAccount{}).Where("id = ? AND user_id = ?", id, userID).Update("is_default", 1)
+
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			// already the default one, or not an account of this user
+			var count int64
+
+			if err := tx.Model(&UserBankAccount{}).Where("id = ? AND user_id = ?", id, userID).Count(&count).Error; err != nil {
+				return err
+			}
+
+			if count == 0 {
+				return errors.New("sql: no rows in result set")
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// DeleteBankAccount hands the default over to the newest account left
+func (repo *repository) DeleteBankAccount(bankAccount UserBankAccount) (bool, error) {
+	err := repo.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND user_id = ?", bankAccount.ID, bankAccount.UserID).Delete(&UserBankAccount{})
+
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return errors.New("sql: no rows in result set")
+		}
+
+		if bankAccount.IsDefault == 0 {
+			return nil
+		}
+
+		var next UserBankAccount
+
+		if err := tx.Where("user_id = ?", bankAccount.UserID).Order("id DESC").Limit(1).Find(&next).Error; err != nil {
+			return err
+		}
+
+		if next.ID == 0 {
+			return nil
+		}
+
+		return tx.Model(&UserBankAccount{}).Where("id = ?", next.ID).Update("is_default", 1).Error
+	})
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
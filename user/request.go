@@ -5,12 +5,22 @@ type (
 		Name     string `json:"name" binding:"required"`
 		Email    string `json:"email" binding:"required,email"`
 		Password string `json:"password" binding:"required"`
+		Locale   string `json:"locale" binding:"omitempty,oneof=en id"`
 	}
 
 	RequestLogin struct {
 		Email    string `json:"email" form:"email" binding:"required,email"`
 		Password string `json:"password" form:"password" binding:"required"`
 	}
+
+	RequestLoginTwoFactor struct {
+		ChallengeToken string `json:"challenge_token" form:"challenge_token" binding:"required"`
+		Code           string `json:"code" form:"code" binding:"required"`
+	}
+
+	RequestLoginTwoFactorSetup struct {
+		ChallengeToken string `json:"challenge_token" form:"challenge_token" binding:"required"`
+	}
 )
 
 type (
@@ -28,18 +38,25 @@ type (
 	}
 
 	RequestUpdateUser struct {
-		Role     string  `json:"role" binding:"required"`
-		Name     string  `json:"name" binding:"required"`
-		Email    string  `json:"email" binding:"required,email"`
-		Password string  `json:"password"`
-		EMoney   float64 `json:"e_money" binding:"required"`
+		Role     string   `json:"role" binding:"required"`
+		Name     string   `json:"name" binding:"required"`
+		Email    string   `json:"email" binding:"required,email"`
+		Password string   `json:"password"`
+		EMoney   *float64 `json:"e_money" binding:"required"`
+		Locale   string   `json:"locale" binding:"omitempty,oneof=en id"`
 		User     User
 	}
 
+	RequestUpdateUserRole struct {
+		Role string `json:"role" binding:"required"`
+		User User
+	}
+
 	RequestSelfUpdateUser struct {
 		Name     string `json:"name" binding:"required"`
 		Email    string `json:"email" binding:"required,email"`
 		Password string `json:"password"`
+		Locale   string `json:"locale" binding:"omitempty,oneof=en id"`
 		User     User
 	}
 
@@ -48,9 +65,11 @@ type (
 	}
 
 	RequestCreateWithdrawalRequest struct {
-		Amount int64  `json:"amount" binding:"required"`
-		Note   string `json:"note" binding:"required"`
-		User   User
+		Amount int64 `json:"amount" binding:"required"`
+		// the default account is used when it is empty
+		BankAccountID int    `json:"bank_account_id"`
+		Note          string `json:"note"`
+		User          User
 	}
 
 	RequestGetUserWithdrawalRequestByID struct {
@@ -58,14 +77,42 @@ type (
 	}
 
 	RequestUpdateUserWithdrawalRequest struct {
-		Status string `json:"status" binding:"required"`
+		// processing, paid and failed are for paying by hand, they are refused while payouts are on
+		Status string `json:"status" binding:"required,oneof=approved processing paid rejected failed"`
+		Reason string `json:"reason"`
 		User   User
 	}
 
+	RequestCancelWithdrawalRequest struct {
+		User User
+	}
+
+	// RequestMoveWithdrawalRequest is how the payout batches move a request, an empty User is the system
+	RequestMoveWithdrawalRequest struct {
+		Status          string
+		Reason          string
+		PayoutBatchID   int
+		PayoutReference string
+		User            User
+	}
+
 	RequestDeleteUserWithdrawalRequest struct {
 		User User
 	}
 
+	RequestTwoFactorCode struct {
+		Code string `json:"code" binding:"required"`
+		User User
+	}
+
+	RequestCreateEmailVerificationToken struct {
+		User User
+	}
+
+	RequestVerifyEmail struct {
+		Token string `uri:"token" binding:"required"`
+	}
+
 	RequestCreateForgotPasswordToken struct {
 		Email string `json:"email" binding:"required"`
 		User  User
@@ -75,4 +122,51 @@ type (
 		Token string `uri:"token" binding:"required"`
 		User  User
 	}
+
+	RequestResetPassword struct {
+		Token           string `json:"token" binding:"required"`
+		Password        string `json:"password" binding:"required"`
+		ConfirmPassword string `json:"confirm_password" binding:"required,eqfield=Password"`
+	}
+
+	// category to enabled, only the optional categories can be changed
+	RequestUpdateNotificationPreferences struct {
+		Preferences map[string]bool `json:"preferences" binding:"required"`
+		User        User
+	}
+
+	// an empty phone number removes it
+	RequestUpdatePhoneNumber struct {
+		PhoneNumber string `json:"phone_number"`
+		User        User
+	}
+
+	RequestRegisterDeviceToken struct {
+		Token    string `json:"token" binding:"required,max=512"`
+		Platform string `json:"platform" binding:"required,oneof=android ios web"`
+		User     User
+	}
+
+	RequestGetDeviceTokenByID struct {
+		ID int `uri:"id" binding:"required"`
+	}
+
+	RequestDeleteDeviceToken struct {
+		User User
+	}
+
+	RequestCreateBankAccount struct {
+		BankCode      string `json:"bank_code" binding:"required"`
+		AccountNumber string `json:"account_number" binding:"required,numeric,min=5,max=20"`
+		HolderName    string `json:"holder_name" binding:"required,max=100"`
+		User          User
+	}
+
+	RequestGetBankAccountByID struct {
+		ID int `uri:"id" binding:"required"`
+	}
+
+	RequestUpdateBankAccount struct {
+		User User
+	}
 )
@@ -1,7 +1,11 @@
 package user
 
 import (
+	"time"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/audit"
 	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+	"github.com/WeAreAmazingTeam/tcd-backend/payout"
 	"github.com/gin-gonic/gin"
 )
 
@@ -15,16 +19,47 @@ type Service interface {
 	GetAllUser() ([]User, error)
 	CreateUser(RequestCreateUser) (User, error)
 	UpdateUser(RequestGetUserByID, RequestUpdateUser) (User, error)
+	UpdateUserRole(RequestGetUserByID, RequestUpdateUserRole) (User, error)
 	DeleteUser(RequestGetUserByID, RequestDeleteUser) (bool, error)
 
+	IsTwoFactorRequired(User) bool
+	IsTwoFactorEnabled(userID int) (bool, error)
+	SetupTwoFactor(User) (secret string, provisioningURI string, err error)
+	EnableTwoFactor(RequestTwoFactorCode) (recoveryCodes []string, err error)
+	VerifyTwoFactor(RequestTwoFactorCode) error
+	DisableTwoFactor(RequestTwoFactorCode) error
+	RegenerateRecoveryCodes(RequestTwoFactorCode) (recoveryCodes []string, err error)
+
+	GetNotificationPreferences(userID int) ([]UserNotificationPreference, error)
+	UpdateNotificationPreferences(RequestUpdateNotificationPreferences) ([]UserNotificationPreference, error)
+
+	UpdatePhoneNumber(RequestUpdatePhoneNumber) (User, error)
+	GetDeviceTokens(userID int) ([]UserDeviceToken, error)
+	RegisterDeviceToken(RequestRegisterDeviceToken) (UserDeviceToken, error)
+	DeleteDeviceToken(RequestGetDeviceTokenByID, RequestDeleteDeviceToken) (bool, error)
+
+	GetBankAccounts(userID int) ([]UserBankAccount, error)
+	CreateBankAccount(RequestCreateBankAccount) (UserBankAccount, error)
+	SetDefaultBankAccount(RequestGetBankAccountByID, RequestUpdateBankAccount) (UserBankAccount, error)
+	VerifyBankAccount(RequestGetBankAccountByID, RequestUpdateBankAccount) (UserBankAccount, error)
+	DeleteBankAccount(RequestGetBankAccountByID, RequestUpdateBankAccount) (bool, error)
+
 	GetWithdrawalRequestByID(id int) (UserWithdrawalRequest, error)
 	CreateWithdrawalRequest(RequestCreateWithdrawalRequest) (UserWithdrawalRequest, error)
 	UpdateUserWithdrawalRequest(RequestGetUserWithdrawalRequestByID, RequestUpdateUserWithdrawalRequest) (UserWithdrawalRequest, error)
+	CancelWithdrawalRequest(RequestGetUserWithdrawalRequestByID, RequestCancelWithdrawalRequest) (UserWithdrawalRequest, error)
+	MoveWithdrawalRequest(RequestGetUserWithdrawalRequestByID, RequestMoveWithdrawalRequest) (UserWithdrawalRequest, error)
 	DeleteUserWithdrawalRequest(RequestGetUserWithdrawalRequestByID, RequestDeleteUserWithdrawalRequest) (bool, error)
 
 	GetDataForgotPasswordByToken(token string) (UserForgotPasswordToken, error)
-	CreateUserForgotPasswordToken(RequestCreateForgotPasswordToken) (UserForgotPasswordToken, error)
-	DeleteForgotPasswordToken(UserForgotPasswordToken) (bool, error)
+	CreateUserForgotPasswordToken(RequestCreateForgotPasswordToken) (token string, err error)
+	ResetPassword(RequestResetPassword) (User, error)
+	DeleteExpiredForgotPasswordToken() (int64, error)
+
+	CreateEmailVerificationToken(RequestCreateEmailVerificationToken) (token string, err error)
+	GetEmailVerificationCooldown(userID int) (time.Duration, error)
+	VerifyEmail(RequestVerifyEmail) (User, error)
+	VerifyEmailOfExistingUsers(before time.Time) (int64, error)
 
 	AdminDataTablesUsers(*gin.Context) (helper.DataTables, error)
 
@@ -38,13 +73,19 @@ type Service interface {
 }
 
 type service struct {
-	repo Repository
+	repo           Repository
+	payoutProvider payout.Provider
+	auditSvc       audit.Service
 }
 
 func NewService(
 	repository Repository,
+	payoutProvider payout.Provider,
+	auditService audit.Service,
 ) *service {
 	return &service{
-		repo: repository,
+		repo:           repository,
+		payoutProvider: payoutProvider,
+		auditSvc:       auditService,
 	}
 }
@@ -1,11 +1,17 @@
 package user
 
 import (
+	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/WeAreAmazingTeam/tcd-backend/audit"
 	"github.com/WeAreAmazingTeam/tcd-backend/constant"
+	"github.com/WeAreAmazingTeam/tcd-backend/payout"
 	"github.com/thanhpk/randstr"
 
 	"github.com/WeAreAmazingTeam/tcd-backend/helper"
@@ -13,10 +19,17 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+const totalRecoveryCodes = 10
+
 func (svc *service) Register(req RequestRegister) (User, error) {
 	user := User{
-		Name:  req.Name,
-		Email: req.Email,
+		Name:   req.Name,
+		Email:  req.Email,
+		Locale: req.Locale,
+	}
+
+	if user.Locale == "" {
+		user.Locale = constant.MAIL_DEFAULT_LOCALE
 	}
 
 	user.CreatedBy = helper.SetNS("New User")
@@ -36,6 +49,8 @@ func (svc *service) Register(req RequestRegister) (User, error) {
 		return newUserData, err
 	}
 
+	audit.Record(svc.auditSvc, newUserData, audit.ActionCreate, audit.EntityUser, newUserData.ID, nil, newUserData)
+
 	return newUserData, nil
 }
 
@@ -108,8 +123,12 @@ func (svc *service) CreateUser(req RequestCreateUser) (User, error) {
 	user.Name = req.Name
 	user.Email = req.Email
 	user.EMoney = req.EMoney
+	user.Locale = constant.MAIL_DEFAULT_LOCALE
 	user.UpdatedBy = helper.SetNS(strconv.Itoa(req.User.ID))
 
+	// accounts made by an admin are trusted, no need to verify the email
+	user.EmailVerifiedAt = sql.NullTime{Time: time.Now(), Valid: true}
+
 	password, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 
 	if err != nil {
@@ -124,47 +143,113 @@ func (svc *service) CreateUser(req RequestCreateUser) (User, error) {
 		return newUserData, err
 	}
 
+	audit.Record(svc.auditSvc, req.User, audit.ActionCreate, audit.EntityUser, newUserData.ID, nil, newUserData)
+
 	return newUserData, nil
 }
 
-func (svc *service) UpdateUserWithdrawalRequest(reqDetail RequestGetUserWithdrawalRequestByID, reqUpdate RequestUpdateUserWithdrawalRequest) (userWithdrawalRequest UserWithdrawalRequest, err error) {
-	userWithdrawalRequest, err = svc.repo.GetWithdrawalRequestByID(reqDetail.ID)
+func (svc *service) UpdateUserWithdrawalRequest(reqDetail RequestGetUserWithdrawalRequestByID, reqUpdate RequestUpdateUserWithdrawalRequest) (UserWithdrawalRequest, error) {
+	// the payout batches send and settle the transfers, an admin only decides whether one is made
+	if svc.payoutProvider.Name() != payout.ProviderNone && isPayoutStatus(reqUpdate.Status) {
+		return UserWithdrawalRequest{}, fmt.Errorf("withdrawal requests are paid by the payout provider, they can not be moved to %v by hand", reqUpdate.Status)
+	}
+
+	return svc.moveWithdrawalRequest(reqDetail.ID, RequestMoveWithdrawalRequest{
+		Status: reqUpdate.Status,
+		Reason: reqUpdate.Reason,
+		User:   reqUpdate.User,
+	})
+}
+
+func (svc *service) MoveWithdrawalRequest(reqDetail RequestGetUserWithdrawalRequestByID, reqMove RequestMoveWithdrawalRequest) (UserWithdrawalRequest, error) {
+	return svc.moveWithdrawalRequest(reqDetail.ID, reqMove)
+}
+
+// CancelWithdrawalRequest lets the owner take back a request nobody has approved yet
+func (svc *service) CancelWithdrawalRequest(reqDetail RequestGetUserWithdrawalRequestByID, reqCancel RequestCancelWithdrawalRequest) (UserWithdrawalRequest, error) {
+	userWithdrawalRequest, err := svc.repo.GetWithdrawalRequestByID(reqDetail.ID)
 
 	if err != nil {
 		return userWithdrawalRequest, err
 	}
 
-	userWithdrawalRequest.Status = reqUpdate.Status
-	userWithdrawalRequest.UpdatedBy = helper.SetNS(strconv.Itoa(reqUpdate.User.ID))
+	if userWithdrawalRequest.UserID != reqCancel.User.ID {
+		return userWithdrawalRequest, errors.New("sql: no rows in result set")
+	}
+
+	return svc.moveWithdrawalRequest(reqDetail.ID, RequestMoveWithdrawalRequest{
+		Status: WithdrawalStatusCancelled,
+		User:   reqCancel.User,
+	})
+}
 
-	updatedUserWithdrawalRequest, err := svc.repo.UpdateUserWithdrawalRequest(userWithdrawalRequest)
+func (svc *service) moveWithdrawalRequest(id int, req RequestMoveWithdrawalRequest) (userWithdrawalRequest UserWithdrawalRequest, err error) {
+	userWithdrawalRequest, err = svc.repo.GetWithdrawalRequestByID(id)
 
 	if err != nil {
-		return updatedUserWithdrawalRequest, err
+		return userWithdrawalRequest, err
 	}
 
-	if updatedUserWithdrawalRequest.Status == "approved" {
-		svc.repo.CreateEMoneyFlow(UserEMoneyFlow{
-			UserID: updatedUserWithdrawalRequest.UserID,
-			Status: "out",
-			Amount: int64(updatedUserWithdrawalRequest.Amount),
-			Note:   "Withdrawal.",
-		})
+	if !userWithdrawalRequest.CanMoveTo(req.Status) {
+		return userWithdrawalRequest, fmt.Errorf("a withdrawal request that is %v can not be %v", userWithdrawalRequest.Status, req.Status)
+	}
 
-		withdrawalRequestUserData, err := svc.repo.GetUserByID(updatedUserWithdrawalRequest.UserID)
+	// the provider callback settles a request in a payout batch, closing it by hand could pay it twice or release
+	// the hold of a transfer that is on its way
+	if userWithdrawalRequest.PayoutBatchID != 0 && req.User.ID != 0 && isPayoutStatus(req.Status) {
+		return userWithdrawalRequest, fmt.Errorf("the withdrawal request is being paid by payout batch %v, wait for its result", userWithdrawalRequest.PayoutBatchID)
+	}
 
-		if err != nil {
-			return updatedUserWithdrawalRequest, err
-		}
+	before := userWithdrawalRequest
+	now := sql.NullTime{Time: time.Now(), Valid: true}
 
-		withdrawalRequestUserData.EMoney = float64(withdrawalRequestUserData.EMoney) - float64(updatedUserWithdrawalRequest.Amount)
+	userWithdrawalRequest.Status = req.Status
+	userWithdrawalRequest.UpdatedBy = helper.SetNS("SYSTEM")
 
-		if _, err := svc.repo.UpdateUser(withdrawalRequestUserData); err != nil {
-			return updatedUserWithdrawalRequest, err
-		}
+	if req.User.ID != 0 {
+		userWithdrawalRequest.UpdatedBy = helper.SetNS(strconv.Itoa(req.User.ID))
+	}
+
+	switch req.Status {
+	case WithdrawalStatusApproved:
+		// pending requests were made before holds existed, approving them holds the amount now
+		userWithdrawalRequest.HeldAmount = userWithdrawalRequest.Amount
+		userWithdrawalRequest.ApprovedAt = now
+	case WithdrawalStatusProcessing:
+		userWithdrawalRequest.PayoutBatchID = req.PayoutBatchID
+		userWithdrawalRequest.PayoutReference = sql.NullString{String: req.PayoutReference, Valid: req.PayoutReference != ""}
+		userWithdrawalRequest.ProcessingAt = now
+	case WithdrawalStatusPaid:
+		userWithdrawalRequest.HeldAmount = 0
+		userWithdrawalRequest.PaidAt = now
+	case WithdrawalStatusRejected:
+		userWithdrawalRequest.HeldAmount = 0
+		userWithdrawalRequest.RejectReason = helper.SetNS(req.Reason)
+		userWithdrawalRequest.RejectedAt = now
+	case WithdrawalStatusCancelled:
+		userWithdrawalRequest.HeldAmount = 0
+		userWithdrawalRequest.CancelledAt = now
+	case WithdrawalStatusFailed:
+		// releasing the hold gives the amount back to the wallet, it was never taken out of it; a request
+		// deducted on approval is credited back instead
+		userWithdrawalRequest.HeldAmount = 0
+		userWithdrawalRequest.FailureReason = helper.SetNS(req.Reason)
+		userWithdrawalRequest.FailedAt = now
 	}
 
-	return updatedUserWithdrawalRequest, nil
+	updated, err := svc.repo.UpdateUserWithdrawalRequest(userWithdrawalRequest, before)
+
+	if err != nil {
+		return before, err
+	}
+
+	if !updated {
+		return before, errors.New("the withdrawal request was updated by someone else, reload and try again")
+	}
+
+	audit.Record(svc.auditSvc, req.User, audit.ActionUpdate, audit.EntityWithdrawalRequest, userWithdrawalRequest.ID, before, userWithdrawalRequest)
+
+	return userWithdrawalRequest, nil
 }
 
 func (svc *service) DeleteUser(reqDetail RequestGetUserByID, reqDelete RequestDeleteUser) (bool, error) {
@@ -175,6 +260,7 @@ func (svc *service) DeleteUser(reqDetail RequestGetUserByID, reqDelete RequestDe
 			return false, err
 		}
 
+		before := user
 		user.UpdatedBy = helper.SetNS(strconv.Itoa(reqDelete.User.ID))
 		user.DeletedAt = *helper.SetNowNT()
 		user.DeletedBy = helper.SetNS(strconv.Itoa(reqDelete.User.ID))
@@ -185,9 +271,13 @@ func (svc *service) DeleteUser(reqDetail RequestGetUserByID, reqDelete RequestDe
 			return status, err
 		}
 
+		audit.Record(svc.auditSvc, reqDelete.User, audit.ActionDelete, audit.EntityUser, user.ID, before, nil)
+
 		return status, nil
 	}
 
+	before, _ := svc.repo.GetUserByID(reqDetail.ID)
+
 	user := User{}
 	user.ID = reqDetail.ID
 	status, err := svc.repo.DeleteUser(user)
@@ -196,6 +286,8 @@ func (svc *service) DeleteUser(reqDetail RequestGetUserByID, reqDelete RequestDe
 		return status, err
 	}
 
+	audit.Record(svc.auditSvc, reqDelete.User, audit.ActionDelete, audit.EntityUser, reqDetail.ID, before, nil)
+
 	return status, nil
 }
 
@@ -240,19 +332,49 @@ func (svc *service) UserDataTablesEMoneyFlow(ctx *gin.Context, user User) (helpe
 }
 
 func (svc *service) CreateWithdrawalRequest(req RequestCreateWithdrawalRequest) (UserWithdrawalRequest, error) {
+	if req.Amount < constant.WITHDRAWAL_MIN_AMOUNT {
+		return UserWithdrawalRequest{}, fmt.Errorf("the minimum withdrawal is Rp %v", constant.WITHDRAWAL_MIN_AMOUNT)
+	}
+
+	if req.Amount <= constant.WITHDRAWAL_FEE {
+		return UserWithdrawalRequest{}, fmt.Errorf("the withdrawal must be more than the fee of Rp %v", constant.WITHDRAWAL_FEE)
+	}
+
+	bankAccount, err := svc.withdrawalBankAccount(req.User.ID, req.BankAccountID)
+
+	if err != nil {
+		return UserWithdrawalRequest{}, err
+	}
+
+	now := time.Now()
+
 	withdrawalRequest := UserWithdrawalRequest{}
 	withdrawalRequest.UserID = req.User.ID
-	withdrawalRequest.Status = "pending"
+	withdrawalRequest.Status = WithdrawalStatusRequested
+	withdrawalRequest.BankAccountID = bankAccount.ID
+	withdrawalRequest.BankCode = bankAccount.BankCode
+	withdrawalRequest.AccountNumber = bankAccount.AccountNumber
+	withdrawalRequest.AccountHolderName = bankAccount.HolderName
 	withdrawalRequest.Amount = req.Amount
+	withdrawalRequest.Fee = constant.WITHDRAWAL_FEE
+	withdrawalRequest.NetAmount = req.Amount - constant.WITHDRAWAL_FEE
+	withdrawalRequest.HeldAmount = req.Amount
 	withdrawalRequest.Note = req.Note
+	withdrawalRequest.CreatedAt = sql.NullTime{Time: now, Valid: true}
 	withdrawalRequest.CreatedBy = helper.SetNS(strconv.Itoa(req.User.ID))
 
-	withdrawalRequestData, err := svc.repo.CreateWithdrawalRequest(withdrawalRequest)
+	withdrawalRequestData, err := svc.repo.CreateWithdrawalRequest(withdrawalRequest, WithdrawalDailyLimit{
+		Since:  time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()),
+		Amount: constant.WITHDRAWAL_DAILY_LIMIT,
+		Count:  constant.WITHDRAWAL_DAILY_COUNT,
+	})
 
 	if err != nil {
 		return withdrawalRequestData, err
 	}
 
+	audit.Record(svc.auditSvc, req.User, audit.ActionCreate, audit.EntityWithdrawalRequest, withdrawalRequestData.ID, nil, withdrawalRequestData)
+
 	return withdrawalRequestData, nil
 }
 
@@ -297,13 +419,28 @@ func (svc *service) UpdateUser(reqDetail RequestGetUserByID, reqUpdate RequestUp
 		return user, err
 	}
 
+	before := user
+
+	// the new address must be verified again before it can be trusted,
+	// links sent to the old address must not verify the new one
+	if !strings.EqualFold(user.Email, reqUpdate.Email) {
+		user.EmailVerifiedAt = sql.NullTime{}
+
+		if _, err = svc.repo.DeleteEmailVerificationTokenByUserID(user.ID); err != nil {
+			return user, err
+		}
+	}
+
 	user.ID = reqDetail.ID
 	user.Role = reqUpdate.Role
 	user.Name = reqUpdate.Name
 	user.Email = reqUpdate.Email
-	user.EMoney = reqUpdate.EMoney
 	user.UpdatedBy = helper.SetNS(strconv.Itoa(reqUpdate.User.ID))
 
+	if reqUpdate.Locale != "" {
+		user.Locale = reqUpdate.Locale
+	}
+
 	if reqUpdate.Password != "" {
 		password, err := bcrypt.GenerateFromPassword([]byte(reqUpdate.Password), bcrypt.DefaultCost)
 
@@ -322,16 +459,56 @@ func (svc *service) UpdateUser(reqDetail RequestGetUserByID, reqUpdate RequestUp
 		user.Password = existingUser.Password
 	}
 
+	// only the admin form sends a balance, the change is applied on top of the payments made since it was loaded
+	if reqUpdate.EMoney != nil && *reqUpdate.EMoney != before.EMoney {
+		if err = svc.repo.AdjustEMoney(user.ID, *reqUpdate.EMoney-before.EMoney); err != nil {
+			return user, err
+		}
+	}
+
+	if _, err = svc.repo.UpdateUser(user); err != nil {
+		return user, err
+	}
+
+	updatedUser, err := svc.repo.GetUserByID(user.ID)
+
+	if err != nil {
+		return updatedUser, err
+	}
+
+	audit.Record(svc.auditSvc, reqUpdate.User, audit.ActionUpdate, audit.EntityUser, updatedUser.ID, before, updatedUser)
+
+	return updatedUser, nil
+}
+
+func (svc *service) UpdateUserRole(reqDetail RequestGetUserByID, reqUpdate RequestUpdateUserRole) (user User, err error) {
+	user, err = svc.repo.GetUserByID(reqDetail.ID)
+
+	if err != nil {
+		return user, err
+	}
+
+	before := user
+	user.Role = reqUpdate.Role
+	user.UpdatedBy = helper.SetNS(strconv.Itoa(reqUpdate.User.ID))
+
 	updatedUser, err := svc.repo.UpdateUser(user)
 
 	if err != nil {
 		return updatedUser, err
 	}
 
+	audit.Record(svc.auditSvc, reqUpdate.User, audit.ActionUpdate, audit.EntityUser, updatedUser.ID, before, updatedUser)
+
 	return updatedUser, nil
 }
 
 func (svc *service) DeleteUserWithdrawalRequest(reqDetail RequestGetUserWithdrawalRequestByID, reqDelete RequestDeleteUserWithdrawalRequest) (bool, error) {
+	// deleting an open request would keep its hold on the wallet forever
+	if userWithdrawalRequest, err := svc.repo.GetWithdrawalRequestByID(reqDetail.ID); err == nil && userWithdrawalRequest.HeldAmount > 0 {
+		return false, errors.New("the withdrawal request still holds e-money, reject it first")
+	}
+
 	if constant.DELETED_BY {
 		userWithdrawalRequest, err := svc.repo.GetWithdrawalRequestByID(reqDetail.ID)
 
@@ -339,6 +516,7 @@ func (svc *service) DeleteUserWithdrawalRequest(reqDetail RequestGetUserWithdraw
 			return false, err
 		}
 
+		before := userWithdrawalRequest
 		userWithdrawalRequest.UpdatedBy = helper.SetNS(strconv.Itoa(reqDelete.User.ID))
 		userWithdrawalRequest.DeletedAt = *helper.SetNowNT()
 		userWithdrawalRequest.DeletedBy = helper.SetNS(strconv.Itoa(reqDelete.User.ID))
@@ -349,9 +527,13 @@ func (svc *service) DeleteUserWithdrawalRequest(reqDetail RequestGetUserWithdraw
 			return status, err
 		}
 
+		audit.Record(svc.auditSvc, reqDelete.User, audit.ActionDelete, audit.EntityWithdrawalRequest, userWithdrawalRequest.ID, before, nil)
+
 		return status, nil
 	}
 
+	before, _ := svc.repo.GetWithdrawalRequestByID(reqDetail.ID)
+
 	userWithdrawalRequest := UserWithdrawalRequest{}
 	userWithdrawalRequest.ID = reqDetail.ID
 	status, err := svc.repo.DeleteUserWithdrawalRequest(userWithdrawalRequest)
@@ -360,21 +542,29 @@ func (svc *service) DeleteUserWithdrawalRequest(reqDetail RequestGetUserWithdraw
 		return status, err
 	}
 
+	audit.Record(svc.auditSvc, reqDelete.User, audit.ActionDelete, audit.EntityWithdrawalRequest, reqDetail.ID, before, nil)
+
 	return status, nil
 }
 
-func (svc *service) CreateUserForgotPasswordToken(req RequestCreateForgotPasswordToken) (UserForgotPasswordToken, error) {
+func (svc *service) CreateUserForgotPasswordToken(req RequestCreateForgotPasswordToken) (token string, err error) {
+	// a new request invalidates every link sent before
+	if _, err = svc.repo.DeleteForgotPasswordTokenByUserID(req.User.ID); err != nil {
+		return token, err
+	}
+
+	token = randstr.String(69)
+
 	userForgotPasswordToken := UserForgotPasswordToken{}
 	userForgotPasswordToken.UserID = req.User.ID
-	userForgotPasswordToken.Token = randstr.String(69)
+	userForgotPasswordToken.TokenHash = helper.HashToken(token)
+	userForgotPasswordToken.ExpiredAt = time.Now().Add(constant.ForgotPasswordTTL)
 
-	userForgotPasswordTokenData, err := svc.repo.CreateForgotPasswordToken(userForgotPasswordToken)
-
-	if err != nil {
-		return userForgotPasswordTokenData, err
+	if _, err = svc.repo.CreateForgotPasswordToken(userForgotPasswordToken); err != nil {
+		return "", err
 	}
 
-	return userForgotPasswordTokenData, nil
+	return token, nil
 }
 
 func (svc *service) GetUserByEmail(email string) (User, error) {
@@ -391,26 +581,616 @@ func (svc *service) GetUserByEmail(email string) (User, error) {
 	return user, nil
 }
 
+func (svc *service) CreateEmailVerificationToken(req RequestCreateEmailVerificationToken) (token string, err error) {
+	if req.User.IsEmailVerified() {
+		return token, errors.New("email already verified")
+	}
+
+	// only the newest link is valid
+	if _, err = svc.repo.DeleteEmailVerificationTokenByUserID(req.User.ID); err != nil {
+		return token, err
+	}
+
+	token = randstr.String(69)
+
+	userEmailVerificationToken := UserEmailVerificationToken{}
+	userEmailVerificationToken.UserID = req.User.ID
+	userEmailVerificationToken.TokenHash = helper.HashToken(token)
+
+	if _, err = svc.repo.CreateEmailVerificationToken(userEmailVerificationToken); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+func (svc *service) GetEmailVerificationCooldown(userID int) (time.Duration, error) {
+	latestToken, err := svc.repo.GetLatestEmailVerificationByUserID(userID)
+
+	if err != nil {
+		if helper.IsErrNoRows(err.Error()) {
+			return 0, nil
+		}
+
+		return 0, err
+	}
+
+	cooldown := time.Until(latestToken.CreatedAt.Add(constant.EmailVerificationResendInterval))
+
+	if cooldown < 0 {
+		return 0, nil
+	}
+
+	return cooldown, nil
+}
+
+func (svc *service) VerifyEmail(req RequestVerifyEmail) (user User, err error) {
+	userEmailVerificationToken, err := svc.repo.GetEmailVerificationByToken(helper.HashToken(req.Token))
+
+	if err != nil {
+		return user, err
+	}
+
+	if time.Now().After(userEmailVerificationToken.CreatedAt.Add(constant.EmailVerificationTTL)) {
+		return user, errors.New("token expired")
+	}
+
+	user, err = svc.repo.GetUserByID(userEmailVerificationToken.UserID)
+
+	if err != nil {
+		return user, err
+	}
+
+	if !user.IsEmailVerified() {
+		before := user
+		user.EmailVerifiedAt = sql.NullTime{Time: time.Now(), Valid: true}
+		user.UpdatedBy = helper.SetNS(strconv.Itoa(user.ID))
+
+		user, err = svc.repo.UpdateUser(user)
+
+		if err != nil {
+			return user, err
+		}
+
+		audit.Record(svc.auditSvc, user, audit.ActionUpdate, audit.EntityUser, user.ID, before, user)
+	}
+
+	if _, err = svc.repo.DeleteEmailVerificationTokenByUserID(user.ID); err != nil {
+		return user, err
+	}
+
+	return user, nil
+}
+
+// VerifyEmailOfExistingUsers grandfathers the accounts made before email verification was required
+func (svc *service) VerifyEmailOfExistingUsers(before time.Time) (int64, error) {
+	if before.IsZero() {
+		return 0, errors.New("the date email verification is required since is not set")
+	}
+
+	return svc.repo.VerifyEmailOfUsersCreatedBefore(before)
+}
+
 func (svc *service) GetDataForgotPasswordByToken(token string) (UserForgotPasswordToken, error) {
-	userForgotPasswordToken, err := svc.repo.GetDataForgotPasswordByToken(token)
+	userForgotPasswordToken, err := svc.repo.GetDataForgotPasswordByToken(helper.HashToken(token))
 
 	if err != nil {
 		return userForgotPasswordToken, err
 	}
 
-	if userForgotPasswordToken.ID == 0 {
-		return userForgotPasswordToken, fmt.Errorf("get data forgot password with token %s not found", token)
+	if time.Now().After(userForgotPasswordToken.ExpiredAt) {
+		return userForgotPasswordToken, errors.New("token expired")
 	}
 
 	return userForgotPasswordToken, nil
 }
 
-func (svc *service) DeleteForgotPasswordToken(userForgotPasswordToken UserForgotPasswordToken) (bool, error) {
-	status, err := svc.repo.DeleteForgotPasswordToken(userForgotPasswordToken)
+func (svc *service) ResetPassword(req RequestResetPassword) (user User, err error) {
+	userForgotPasswordToken, err := svc.GetDataForgotPasswordByToken(req.Token)
+
+	if err != nil {
+		return user, err
+	}
+
+	user, err = svc.repo.GetUserByID(userForgotPasswordToken.UserID)
+
+	if err != nil {
+		return user, err
+	}
+
+	password, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+
+	if err != nil {
+		return user, err
+	}
+
+	before := user
+	user.Password = string(password)
+	user.UpdatedBy = helper.SetNS(strconv.Itoa(user.ID))
+
+	user, err = svc.repo.UpdateUser(user)
+
+	if err != nil {
+		return user, err
+	}
+
+	audit.Record(svc.auditSvc, user, audit.ActionUpdate, audit.EntityUser, user.ID, before, user)
+
+	// single use, also drop any other link still lying in the inbox
+	if _, err = svc.repo.DeleteForgotPasswordTokenByUserID(user.ID); err != nil {
+		return user, err
+	}
+
+	return user, nil
+}
+
+func (svc *service) DeleteExpiredForgotPasswordToken() (int64, error) {
+	affected, err := svc.repo.DeleteExpiredForgotPasswordToken(time.Now())
+
+	if err != nil {
+		return affected, err
+	}
+
+	return affected, nil
+}
+
+// every role that passes middleware.AdminAuth must use two-factor authentication
+func (svc *service) IsTwoFactorRequired(user User) bool {
+	return user.Role != "user"
+}
+
+func (svc *service) IsTwoFactorEnabled(userID int) (bool, error) {
+	userTwoFactor, err := svc.repo.GetTwoFactorByUserID(userID)
+
+	if err != nil {
+		if helper.IsErrNoRows(err.Error()) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return userTwoFactor.IsEnabled == 1, nil
+}
+
+func (svc *service) SetupTwoFactor(user User) (string, string, error) {
+	userTwoFactor, err := svc.repo.GetTwoFactorByUserID(user.ID)
+
+	if err != nil && !helper.IsErrNoRows(err.Error()) {
+		return "", "", err
+	}
+
+	if userTwoFactor.IsEnabled == 1 {
+		return "", "", errors.New("two-factor authentication already enabled")
+	}
+
+	secret, err := helper.GenerateTOTPSecret()
+
+	if err != nil {
+		return "", "", err
+	}
+
+	userTwoFactor.UserID = user.ID
+	userTwoFactor.Secret = secret
+	userTwoFactor.LastUsedStep = 0
+
+	if userTwoFactor.ID == 0 {
+		userTwoFactor.CreatedBy = helper.SetNS(strconv.Itoa(user.ID))
+	} else {
+		userTwoFactor.UpdatedBy = helper.SetNS(strconv.Itoa(user.ID))
+	}
+
+	if _, err := svc.repo.SaveTwoFactor(userTwoFactor); err != nil {
+		return "", "", err
+	}
+
+	return secret, helper.TOTPProvisioningURI(constant.TwoFactorIssuer, user.Email, secret), nil
+}
+
+func (svc *service) EnableTwoFactor(req RequestTwoFactorCode) ([]string, error) {
+	userTwoFactor, err := svc.repo.GetTwoFactorByUserID(req.User.ID)
+
+	if err != nil {
+		if helper.IsErrNoRows(err.Error()) {
+			return nil, errors.New("two-factor authentication has not been set up")
+		}
+		return nil, err
+	}
+
+	if userTwoFactor.IsEnabled == 1 {
+		return nil, errors.New("two-factor authentication already enabled")
+	}
+
+	before := userTwoFactor
+
+	if err := svc.checkTOTP(&userTwoFactor, req.Code); err != nil {
+		return nil, err
+	}
+
+	userTwoFactor.IsEnabled = 1
+	userTwoFactor.EnabledAt = sql.NullTime{Time: time.Now(), Valid: true}
+	userTwoFactor.UpdatedBy = helper.SetNS(strconv.Itoa(req.User.ID))
+
+	if _, err := svc.repo.SaveTwoFactor(userTwoFactor); err != nil {
+		return nil, err
+	}
+
+	audit.Record(svc.auditSvc, req.User, audit.ActionUpdate, audit.EntityUserTwoFactor, userTwoFactor.ID, before, userTwoFactor)
+
+	return svc.generateRecoveryCodes(req.User.ID)
+}
+
+func (svc *service) VerifyTwoFactor(req RequestTwoFactorCode) error {
+	userTwoFactor, err := svc.repo.GetTwoFactorByUserID(req.User.ID)
+
+	if err != nil {
+		if helper.IsErrNoRows(err.Error()) {
+			return errors.New("two-factor authentication has not been set up")
+		}
+		return err
+	}
+
+	if userTwoFactor.IsEnabled != 1 {
+		return errors.New("two-factor authentication is not enabled")
+	}
+
+	// recovery codes are formatted as xxxxx-xxxxx, totp codes are digits only
+	if strings.Contains(req.Code, "-") {
+		used, err := svc.repo.UseRecoveryCode(req.User.ID, helper.HashToken(strings.ToLower(strings.TrimSpace(req.Code))))
+
+		if err != nil {
+			return err
+		}
+
+		if !used {
+			return errors.New("invalid two-factor code")
+		}
+
+		return nil
+	}
+
+	return svc.checkTOTP(&userTwoFactor, req.Code)
+}
+
+func (svc *service) DisableTwoFactor(req RequestTwoFactorCode) error {
+	if svc.IsTwoFactorRequired(req.User) {
+		return errors.New("two-factor authentication is mandatory for your role")
+	}
+
+	if err := svc.VerifyTwoFactor(req); err != nil {
+		return err
+	}
+
+	userTwoFactor, err := svc.repo.GetTwoFactorByUserID(req.User.ID)
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := svc.repo.DeleteTwoFactor(userTwoFactor); err != nil {
+		return err
+	}
+
+	audit.Record(svc.auditSvc, req.User, audit.ActionDelete, audit.EntityUserTwoFactor, userTwoFactor.ID, userTwoFactor, nil)
+
+	return nil
+}
+
+func (svc *service) RegenerateRecoveryCodes(req RequestTwoFactorCode) ([]string, error) {
+	if err := svc.VerifyTwoFactor(req); err != nil {
+		return nil, err
+	}
+
+	return svc.generateRecoveryCodes(req.User.ID)
+}
+
+// accepts one step of clock drift and rejects a code that was already used, the step is claimed with a
+// conditional update so two requests with the same code can not both pass
+func (svc *service) checkTOTP(userTwoFactor *UserTwoFactor, code string) error {
+	step, ok := helper.ValidateTOTP(userTwoFactor.Secret, code, time.Now(), 1)
+
+	if !ok || step <= userTwoFactor.LastUsedStep {
+		return errors.New("invalid two-factor code")
+	}
+
+	used, err := svc.repo.UseTwoFactorStep(userTwoFactor.ID, step)
+
+	if err != nil {
+		return err
+	}
+
+	if !used {
+		return errors.New("invalid two-factor code")
+	}
+
+	userTwoFactor.LastUsedStep = step
+
+	return nil
+}
+
+func (svc *service) generateRecoveryCodes(userID int) ([]string, error) {
+	codes := []string{}
+	codeHashes := []string{}
+
+	for i := 0; i < totalRecoveryCodes; i++ {
+		code := helper.GenerateRecoveryCode()
+		codes = append(codes, code)
+		codeHashes = append(codeHashes, helper.HashToken(code))
+	}
+
+	if err := svc.repo.ReplaceRecoveryCodes(userID, codeHashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// GetNotificationPreferences returns every category, the ones without a row are enabled
+func (svc *service) GetNotificationPreferences(userID int) ([]UserNotificationPreference, error) {
+	saved, err := svc.repo.GetNotificationPreferencesByUserID(userID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	preferences := []UserNotificationPreference{}
+
+	for _, category := range NotificationCategories {
+		preference := UserNotificationPreference{UserID: userID, Category: category, EmailEnabled: true}
+
+		for _, val := range saved {
+			if val.Category == category && IsOptionalNotificationCategory(category) {
+				preference = val
+			}
+		}
+
+		preferences = append(preferences, preference)
+	}
+
+	return preferences, nil
+}
+
+func (svc *service) UpdateNotificationPreferences(req RequestUpdateNotificationPreferences) ([]UserNotificationPreference, error) {
+	for category := range req.Preferences {
+		if !IsOptionalNotificationCategory(category) {
+			return nil, fmt.Errorf("notification category %s can't be changed", category)
+		}
+	}
+
+	before, err := svc.GetNotificationPreferences(req.User.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for category, enabled := range req.Preferences {
+		preference, err := svc.repo.GetNotificationPreference(req.User.ID, category)
+
+		if err != nil && !helper.IsErrNoRows(err.Error()) {
+			return nil, err
+		}
+
+		preference.UserID = req.User.ID
+		preference.Category = category
+		preference.EmailEnabled = enabled
+
+		if _, err := svc.repo.SaveNotificationPreference(preference); err != nil {
+			return nil, err
+		}
+	}
+
+	after, err := svc.GetNotificationPreferences(req.User.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	audit.Record(svc.auditSvc, req.User, audit.ActionUpdate, audit.EntityNotificationPreference, req.User.ID, notificationPreferenceMap(before), notificationPreferenceMap(after))
+
+	return after, nil
+}
+
+func notificationPreferenceMap(preferences []UserNotificationPreference) map[string]bool {
+	result := map[string]bool{}
+
+	for _, val := range preferences {
+		result[val.Category] = val.EmailEnabled
+	}
+
+	return result
+}
+
+func (svc *service) UpdatePhoneNumber(req RequestUpdatePhoneNumber) (user User, err error) {
+	phoneNumber := ""
+
+	if strings.TrimSpace(req.PhoneNumber) != "" {
+		var valid bool
+
+		if phoneNumber, valid = helper.NormalizePhoneNumber(req.PhoneNumber); !valid {
+			return user, errors.New("phone number is invalid")
+		}
+	}
+
+	user, err = svc.repo.GetUserByID(req.User.ID)
+
+	if err != nil {
+		return user, err
+	}
+
+	before := user
+
+	user.PhoneNumber = phoneNumber
+	user.UpdatedBy = helper.SetNS(strconv.Itoa(req.User.ID))
+
+	updatedUser, err := svc.repo.UpdateUser(user)
+
+	if err != nil {
+		return updatedUser, err
+	}
+
+	audit.Record(svc.auditSvc, req.User, audit.ActionUpdate, audit.EntityUser, updatedUser.ID, before, updatedUser)
+
+	return updatedUser, nil
+}
+
+func (svc *service) GetDeviceTokens(userID int) ([]UserDeviceToken, error) {
+	return svc.repo.GetDeviceTokensByUserID(userID)
+}
+
+// RegisterDeviceToken is called by the app on every start, a known token only moves to the current user
+func (svc *service) RegisterDeviceToken(req RequestRegisterDeviceToken) (UserDeviceToken, error) {
+	deviceToken, err := svc.repo.GetDeviceTokenByToken(req.Token)
+
+	if err != nil && !helper.IsErrNoRows(err.Error()) {
+		return deviceToken, err
+	}
+
+	deviceToken.UserID = req.User.ID
+	deviceToken.Token = req.Token
+	deviceToken.Platform = req.Platform
+
+	return svc.repo.SaveDeviceToken(deviceToken)
+}
+
+func (svc *service) DeleteDeviceToken(reqDetail RequestGetDeviceTokenByID, reqDelete RequestDeleteDeviceToken) (bool, error) {
+	return svc.repo.DeleteDeviceToken(reqDelete.User.ID, reqDetail.ID)
+}
+
+func (svc *service) GetBankAccounts(userID int) ([]UserBankAccount, error) {
+	return svc.repo.GetBankAccountsByUserID(userID)
+}
+
+func (svc *service) CreateBankAccount(req RequestCreateBankAccount) (UserBankAccount, error) {
+	bankCode := strings.ToLower(strings.TrimSpace(req.BankCode))
+
+	if !payout.IsSupportedBank(bankCode) {
+		return UserBankAccount{}, fmt.Errorf("bank %q is not supported", req.BankCode)
+	}
+
+	bankAccounts, err := svc.repo.GetBankAccountsByUserID(req.User.ID)
+
+	if err != nil {
+		return UserBankAccount{}, err
+	}
+
+	for _, val := range bankAccounts {
+		if val.BankCode == bankCode && val.AccountNumber == req.AccountNumber {
+			return UserBankAccount{}, errors.New("the bank account is already saved")
+		}
+	}
+
+	bankAccount := UserBankAccount{}
+	bankAccount.UserID = req.User.ID
+	bankAccount.BankCode = bankCode
+	bankAccount.AccountNumber = req.AccountNumber
+	bankAccount.HolderName = strings.TrimSpace(req.HolderName)
+	bankAccount.CreatedBy = helper.SetNS(strconv.Itoa(req.User.ID))
+
+	bankAccount, err = svc.repo.CreateBankAccount(bankAccount)
+
+	if err != nil {
+		return bankAccount, err
+	}
+
+	audit.Record(svc.auditSvc, req.User, audit.ActionCreate, audit.EntityUserBankAccount, bankAccount.ID, nil, bankAccount)
+
+	return bankAccount, nil
+}
+
+func (svc *service) SetDefaultBankAccount(reqDetail RequestGetBankAccountByID, reqUpdate RequestUpdateBankAccount) (UserBankAccount, error) {
+	before, err := svc.repo.GetBankAccountByID(reqUpdate.User.ID, reqDetail.ID)
+
+	if err != nil {
+		return before, err
+	}
+
+	if _, err := svc.repo.SetDefaultBankAccount(reqUpdate.User.ID, reqDetail.ID); err != nil {
+		return before, err
+	}
+
+	bankAccount := before
+	bankAccount.IsDefault = 1
+
+	audit.Record(svc.auditSvc, reqUpdate.User, audit.ActionUpdate, audit.EntityUserBankAccount, bankAccount.ID, before, bankAccount)
+
+	return bankAccount, nil
+}
+
+// VerifyBankAccount asks the payout provider who owns the account. The name the bank has is never returned,
+// so the lookup can not be used to find out who owns an account number.
+func (svc *service) VerifyBankAccount(reqDetail RequestGetBankAccountByID, reqUpdate RequestUpdateBankAccount) (UserBankAccount, error) {
+	bankAccount, err := svc.repo.GetBankAccountByID(reqUpdate.User.ID, reqDetail.ID)
+
+	if err != nil {
+		return bankAccount, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), constant.PAYOUT_TIMEOUT)
+	defer cancel()
+
+	holderName, err := svc.payoutProvider.InquireAccount(ctx, payout.Account{
+		BankCode:      bankAccount.BankCode,
+		AccountNumber: bankAccount.AccountNumber,
+		HolderName:    bankAccount.HolderName,
+	})
+
+	if err != nil {
+		return bankAccount, err
+	}
+
+	if !payout.SameHolderName(holderName, bankAccount.HolderName) {
+		return bankAccount, errors.New("the holder name does not match the name the bank has for this account")
+	}
+
+	before := bankAccount
+	bankAccount.VerifiedName = helper.SetNS(holderName)
+	bankAccount.VerifiedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	bankAccount.UpdatedBy = helper.SetNS(strconv.Itoa(reqUpdate.User.ID))
+
+	bankAccount, err = svc.repo.UpdateBankAccount(bankAccount)
+
+	if err != nil {
+		return bankAccount, err
+	}
+
+	audit.Record(svc.auditSvc, reqUpdate.User, audit.ActionUpdate, audit.EntityUserBankAccount, bankAccount.ID, before, bankAccount)
+
+	return bankAccount, nil
+}
+
+func (svc *service) DeleteBankAccount(reqDetail RequestGetBankAccountByID, reqUpdate RequestUpdateBankAccount) (bool, error) {
+	bankAccount, err := svc.repo.GetBankAccountByID(reqUpdate.User.ID, reqDetail.ID)
+
+	if err != nil {
+		return false, err
+	}
+
+	status, err := svc.repo.DeleteBankAccount(bankAccount)
 
 	if err != nil {
 		return status, err
 	}
 
+	audit.Record(svc.auditSvc, reqUpdate.User, audit.ActionDelete, audit.EntityUserBankAccount, bankAccount.ID, bankAccount, nil)
+
 	return status, nil
 }
+
+// withdrawalBankAccount is the account picked in the request, or the default one
+func (svc *service) withdrawalBankAccount(userID, bankAccountID int) (UserBankAccount, error) {
+	var (
+		bankAccount UserBankAccount
+		err         error
+	)
+
+	if bankAccountID == 0 {
+		bankAccount, err = svc.repo.GetDefaultBankAccount(userID)
+	} else {
+		bankAccount, err = svc.repo.GetBankAccountByID(userID, bankAccountID)
+	}
+
+	if err != nil && helper.IsErrNoRows(err.Error()) {
+		return bankAccount, errors.New("the bank account was not found, add one before withdrawing")
+	}
+
+	return bankAccount, err
+}
@@ -0,0 +1,87 @@
+package webhook
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/constant"
+)
+
+const (
+	StatusPending   = "pending"
+	StatusSending   = "sending"
+	StatusDelivered = "delivered"
+	StatusFailed    = "failed"
+	// the subscription was deleted or paused before the delivery went out
+	StatusCancelled = "cancelled"
+)
+
+type (
+	// WebhookSubscription belongs to the user whose campaigns and withdrawals it follows,
+	// events is a comma separated list of helper.WebhookEvents
+	WebhookSubscription struct {
+		ID          int    `json:"id"`
+		UserID      int    `json:"user_id"`
+		URL         string `json:"url" gorm:"column:url"`
+		Secret      string `json:"-"`
+		Events      string `json:"events"`
+		Description string `json:"description"`
+		IsActive    bool   `json:"is_active"`
+		constant.CreatedUpdatedDeleted
+	}
+
+	// WebhookDelivery is one event for one subscription, the payload is stored so a redelivery sends the same body
+	WebhookDelivery struct {
+		ID             int            `json:"id"`
+		SubscriptionID int            `json:"subscription_id"`
+		EventID        string         `json:"event_id"`
+		EventType      string         `json:"event_type"`
+		Payload        string         `json:"payload"`
+		Status         string         `json:"status"`
+		Attempts       int            `json:"attempts"`
+		MaxAttempts    int            `json:"max_attempts"`
+		NextAttemptAt  time.Time      `json:"next_attempt_at"`
+		LastStatusCode int            `json:"last_status_code"`
+		LastError      sql.NullString `json:"last_error"`
+		DeliveredAt    sql.NullTime   `json:"delivered_at"`
+		CreatedAt      time.Time      `json:"created_at"`
+		UpdatedAt      time.Time      `json:"updated_at"`
+	}
+
+	// WebhookDeliveryLog is one http attempt, like logs.ActivityWebhook for the webhooks we receive
+	WebhookDeliveryLog struct {
+		ID         int       `json:"id"`
+		DeliveryID int       `json:"delivery_id"`
+		Endpoint   string    `json:"endpoint"`
+		StatusCode int       `json:"status_code"`
+		Response   string    `json:"response"`
+		Error      string    `json:"error"`
+		DurationMs int64     `json:"duration_ms"`
+		CreatedAt  time.Time `json:"created_at"`
+	}
+
+	// Event is the json body every endpoint receives
+	Event struct {
+		ID        string    `json:"id"`
+		Type      string    `json:"type"`
+		CreatedAt time.Time `json:"created_at"`
+		Data      any       `json:"data"`
+	}
+)
+
+func (subscription WebhookSubscription) EventList() []string {
+	if subscription.Events == "" {
+		return []string{}
+	}
+	return strings.Split(subscription.Events, ",")
+}
+
+func (subscription WebhookSubscription) IsSubscribed(eventType string) bool {
+	for _, val := range subscription.EventList() {
+		if val == eventType {
+			return true
+		}
+	}
+	return false
+}
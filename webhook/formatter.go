@@ -0,0 +1,124 @@
+package webhook
+
+import "time"
+
+type (
+	WebhookSubscriptionFormatter struct {
+		ID          int       `json:"id"`
+		UserID      int       `json:"user_id"`
+		URL         string    `json:"url"`
+		Events      []string  `json:"events"`
+		Description string    `json:"description"`
+		IsActive    bool      `json:"is_active"`
+		CreatedAt   time.Time `json:"created_at"`
+	}
+
+	// returned on create and rotate only, the secret is never shown again
+	WebhookSubscriptionWithSecretFormatter struct {
+		WebhookSubscriptionFormatter
+		Secret string `json:"secret"`
+	}
+
+	WebhookDeliveryFormatter struct {
+		ID             int        `json:"id"`
+		SubscriptionID int        `json:"subscription_id"`
+		EventID        string     `json:"event_id"`
+		EventType      string     `json:"event_type"`
+		Status         string     `json:"status"`
+		Attempts       int        `json:"attempts"`
+		MaxAttempts    int        `json:"max_attempts"`
+		NextAttemptAt  time.Time  `json:"next_attempt_at"`
+		LastStatusCode int        `json:"last_status_code"`
+		LastError      string     `json:"last_error"`
+		DeliveredAt    *time.Time `json:"delivered_at"`
+		CreatedAt      time.Time  `json:"created_at"`
+	}
+
+	WebhookDeliveryDetailFormatter struct {
+		WebhookDeliveryFormatter
+		Payload string               `json:"payload"`
+		Logs    []WebhookDeliveryLog `json:"logs"`
+	}
+)
+
+func FormatWebhookSubscriptionData(subscription WebhookSubscription) WebhookSubscriptionFormatter {
+	formatData := WebhookSubscriptionFormatter{
+		ID:          subscription.ID,
+		UserID:      subscription.UserID,
+		URL:         subscription.URL,
+		Events:      subscription.EventList(),
+		Description: subscription.Description,
+		IsActive:    subscription.IsActive,
+	}
+
+	if subscription.CreatedAt.Valid {
+		formatData.CreatedAt = subscription.CreatedAt.Time
+	}
+
+	return formatData
+}
+
+func FormatWebhookSubscriptionWithSecretData(subscription WebhookSubscription) WebhookSubscriptionWithSecretFormatter {
+	return WebhookSubscriptionWithSecretFormatter{
+		WebhookSubscriptionFormatter: FormatWebhookSubscriptionData(subscription),
+		Secret:                       subscription.Secret,
+	}
+}
+
+func FormatListWebhookSubscriptionData(subscriptions []WebhookSubscription) (response []WebhookSubscriptionFormatter) {
+	for _, val := range subscriptions {
+		response = append(response, FormatWebhookSubscriptionData(val))
+	}
+
+	if len(response) == 0 {
+		return []WebhookSubscriptionFormatter{}
+	}
+
+	return response
+}
+
+func FormatWebhookDeliveryData(delivery WebhookDelivery) WebhookDeliveryFormatter {
+	formatData := WebhookDeliveryFormatter{
+		ID:             delivery.ID,
+		SubscriptionID: delivery.SubscriptionID,
+		EventID:        delivery.EventID,
+		EventType:      delivery.EventType,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		MaxAttempts:    delivery.MaxAttempts,
+		NextAttemptAt:  delivery.NextAttemptAt,
+		LastStatusCode: delivery.LastStatusCode,
+		LastError:      delivery.LastError.String,
+		CreatedAt:      delivery.CreatedAt,
+	}
+
+	if delivery.DeliveredAt.Valid {
+		formatData.DeliveredAt = &delivery.DeliveredAt.Time
+	}
+
+	return formatData
+}
+
+func FormatListWebhookDeliveryData(deliveries []WebhookDelivery) (response []WebhookDeliveryFormatter) {
+	for _, val := range deliveries {
+		response = append(response, FormatWebhookDeliveryData(val))
+	}
+
+	if len(response) == 0 {
+		return []WebhookDeliveryFormatter{}
+	}
+
+	return response
+}
+
+func FormatWebhookDeliveryDetailData(delivery WebhookDelivery, logs []WebhookDeliveryLog) WebhookDeliveryDetailFormatter {
+	if logs == nil {
+		logs = []WebhookDeliveryLog{}
+	}
+
+	return WebhookDeliveryDetailFormatter{
+		WebhookDeliveryFormatter: FormatWebhookDeliveryData(delivery),
+		Payload:                  delivery.Payload,
+		Logs:                     logs,
+	}
+}
@@ -0,0 +1,38 @@
+package webhook
+
+const (
+	QueryAdminDataTablesWebhookDelivery = `
+		SELECT
+			d.id,
+			d.subscription_id,
+			s.user_id,
+			s.url,
+			d.event_id,
+			d.event_type,
+			d.status,
+			d.attempts,
+			d.max_attempts,
+			d.next_attempt_at,
+			d.last_status_code,
+			d.last_error,
+			d.delivered_at,
+			d.created_at
+		FROM
+			webhook_deliveries d
+		JOIN
+			webhook_subscriptions s ON s.id = d.subscription_id
+		WHERE
+			1 = 1
+	`
+
+	QueryCountAllAdminDataTablesWebhookDelivery = `
+		SELECT
+			COUNT(d.id) AS count_id
+		FROM
+			webhook_deliveries d
+		JOIN
+			webhook_subscriptions s ON s.id = d.subscription_id
+		WHERE
+			1 = 1
+	`
+)
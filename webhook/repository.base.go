@@ -0,0 +1,40 @@
+package webhook
+
+import (
+	"time"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	GetWebhookSubscriptionByID(int) (WebhookSubscription, error)
+	GetWebhookSubscriptionsByUserID(userID int) ([]WebhookSubscription, error)
+	GetAllWebhookSubscription() ([]WebhookSubscription, error)
+	GetActiveWebhookSubscriptionsByUserID(userID int) ([]WebhookSubscription, error)
+	SaveWebhookSubscription(WebhookSubscription) (WebhookSubscription, error)
+	UpdateWebhookSubscription(WebhookSubscription) (WebhookSubscription, error)
+	DeleteWebhookSubscription(WebhookSubscription) (bool, error)
+
+	GetWebhookDeliveryByID(int) (WebhookDelivery, error)
+	GetWebhookDeliveriesBySubscriptionID(subscriptionID, limit int) ([]WebhookDelivery, error)
+	GetDueWebhookDelivery(now time.Time, limit int) ([]WebhookDelivery, error)
+	ClaimWebhookDelivery(WebhookDelivery) (bool, error)
+	ReleaseStaleWebhookDelivery(before time.Time) (int64, error)
+	SaveWebhookDelivery(WebhookDelivery) (WebhookDelivery, error)
+	UpdateWebhookDelivery(WebhookDelivery) (WebhookDelivery, error)
+
+	GetWebhookDeliveryLogs(deliveryID int) ([]WebhookDeliveryLog, error)
+	SaveWebhookDeliveryLog(WebhookDeliveryLog) (WebhookDeliveryLog, error)
+
+	AdminDataTablesWebhookDelivery(ctx *gin.Context) (helper.DataTables, error)
+}
+
+type repository struct {
+	DB *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) *repository {
+	return &repository{DB: db}
+}
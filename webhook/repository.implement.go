@@ -0,0 +1,313 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/constant"
+	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+	"github.com/gin-gonic/gin"
+)
+
+func (repo *repository) GetWebhookSubscriptionByID(id int) (subscription WebhookSubscription, err error) {
+	if err := repo.DB.Where("id = ?", id).Find(&subscription).Error; err != nil {
+		return subscription, err
+	}
+
+	if subscription.ID == 0 {
+		return subscription, errors.New("sql: no rows in result set")
+	}
+
+	return subscription, nil
+}
+
+func (repo *repository) GetWebhookSubscriptionsByUserID(userID int) (subscriptions []WebhookSubscription, err error) {
+	if err := repo.DB.Where("user_id = ?", userID).Order("id DESC").Find(&subscriptions).Error; err != nil {
+		return subscriptions, err
+	}
+	return subscriptions, nil
+}
+
+func (repo *repository) GetAllWebhookSubscription() (subscriptions []WebhookSubscription, err error) {
+	if err := repo.DB.Order("id DESC").Find(&subscriptions).Error; err != nil {
+		return subscriptions, err
+	}
+	return subscriptions, nil
+}
+
+func (repo *repository) GetActiveWebhookSubscriptionsByUserID(userID int) (subscriptions []WebhookSubscription, err error) {
+	if err := repo.DB.Where("user_id = ? AND is_active = ?", userID, true).Find(&subscriptions).Error; err != nil {
+		return subscriptions, err
+	}
+	return subscriptions, nil
+}
+
+func (repo *repository) SaveWebhookSubscription(subscription WebhookSubscription) (WebhookSubscription, error) {
+	if err := repo.DB.Create(&subscription).Error; err != nil {
+		return subscription, err
+	}
+	return subscription, nil
+}
+
+func (repo *repository) UpdateWebhookSubscription(subscription WebhookSubscription) (WebhookSubscription, error) {
+	if err := repo.DB.Save(&subscription).Error; err != nil {
+		return subscription, err
+	}
+	return subscription, nil
+}
+
+func (repo *repository) DeleteWebhookSubscription(subscription WebhookSubscription) (bool, error) {
+	tmpSubscription := WebhookSubscription{}
+
+	if err := repo.DB.Where("id = ?", subscription.ID).Find(&tmpSubscription).Error; err != nil {
+		return false, err
+	}
+
+	if tmpSubscription.ID == 0 {
+		return false, errors.New("sql: no rows in result set")
+	}
+
+	if constant.DELETED_BY {
+		if err := repo.DB.Save(&subscription).Error; err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	if err := repo.DB.Delete(&subscription).Error; err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (repo *repository) GetWebhookDeliveryByID(id int) (delivery WebhookDelivery, err error) {
+	if err := repo.DB.Where("id = ?", id).Find(&delivery).Error; err != nil {
+		return delivery, err
+	}
+
+	if delivery.ID == 0 {
+		return delivery, errors.New("sql: no rows in result set")
+	}
+
+	return delivery, nil
+}
+
+func (repo *repository) GetWebhookDeliveriesBySubscriptionID(subscriptionID, limit int) (deliveries []WebhookDelivery, err error) {
+	if err := repo.DB.Where("subscription_id = ?", subscriptionID).Order("id DESC").Limit(limit).Find(&deliveries).Error; err != nil {
+		return deliveries, err
+	}
+	return deliveries, nil
+}
+
+func (repo *repository) GetDueWebhookDelivery(now time.Time, limit int) (deliveries []WebhookDelivery, err error) {
+	if err := repo.DB.Where("status = ? AND next_attempt_at <= ?", StatusPending, now).Order("next_attempt_at ASC").Limit(limit).Find(&deliveries).Error; err != nil {
+		return deliveries, err
+	}
+	return deliveries, nil
+}
+
+// ClaimWebhookDelivery flips pending to sending, false means another worker got there first
+func (repo *repository) ClaimWebhookDelivery(delivery WebhookDelivery) (bool, error) {
+	result := repo.DB.Model(&WebhookDelivery{}).
+		Where("id = ? AND status = ?", delivery.ID, StatusPending).
+		Updates(map[string]any{"status": StatusSending, "updated_at": time.Now()})
+
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+// ReleaseStaleWebhookDelivery puts back deliveries left in sending by a process that died mid request
+func (repo *repository) ReleaseStaleWebhookDelivery(before time.Time) (int64, error) {
+	result := repo.DB.Model(&WebhookDelivery{}).
+		Where("status = ? AND updated_at < ?", StatusSending, before).
+		Updates(map[string]any{"status": StatusPending, "updated_at": time.Now()})
+
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
+
+func (repo *repository) SaveWebhookDelivery(delivery WebhookDelivery) (WebhookDelivery, error) {
+	if err := repo.DB.Create(&delivery).Error; err != nil {
+		return delivery, err
+	}
+	return delivery, nil
+}
+
+func (repo *repository) UpdateWebhookDelivery(delivery WebhookDelivery) (WebhookDelivery, error) {
+	if err := repo.DB.Save(&delivery).Error; err != nil {
+		return delivery, err
+	}
+	return delivery, nil
+}
+
+func (repo *repository) GetWebhookDeliveryLogs(deliveryID int) (deliveryLogs []WebhookDeliveryLog, err error) {
+	if err := repo.DB.Where("delivery_id = ?", deliveryID).Order("id ASC").Find(&deliveryLogs).Error; err != nil {
+		return deliveryLogs, err
+	}
+	return deliveryLogs, nil
+}
+
+func (repo *repository) SaveWebhookDeliveryLog(deliveryLog WebhookDeliveryLog) (WebhookDeliveryLog, error) {
+	if err := repo.DB.Create(&deliveryLog).Error; err != nil {
+		return deliveryLog, err
+	}
+	return deliveryLog, nil
+}
+
+// besides the datatables params it filters by the status, event_type and subscription_id query params
+func (repo *repository) AdminDataTablesWebhookDelivery(ctx *gin.Context) (result helper.DataTables, err error) {
+	var (
+		query string = QueryAdminDataTablesWebhookDelivery
+		where string = ""
+		order string = ""
+		limit string = ""
+	)
+
+	var (
+		no       int = 1
+		total    int = 0
+		filtered int = 0
+	)
+
+	var (
+		data []map[string]any
+		args []any
+	)
+
+	listOrder := []string{"", "s.url", "d.event_type", "d.status", "d.attempts", "d.last_status_code", "d.next_attempt_at", "d.delivered_at", "d.created_at", ""}
+
+	if status := ctx.Query("status"); status != "" {
+		where = fmt.Sprintf("%s AND d.status = ?", where)
+		args = append(args, status)
+	}
+
+	if eventType := ctx.Query("event_type"); eventType != "" {
+		where = fmt.Sprintf("%s AND d.event_type = ?", where)
+		args = append(args, eventType)
+	}
+
+	if subscriptionID, _ := strconv.Atoi(ctx.Query("subscription_id")); subscriptionID != 0 {
+		where = fmt.Sprintf("%s AND d.subscription_id = ?", where)
+		args = append(args, subscriptionID)
+	}
+
+	if searchValue := ctx.Query("search[value]"); searchValue != "" {
+		where = fmt.Sprintf("%s AND (s.url LIKE ? OR d.event_id LIKE ? OR d.event_type LIKE ? OR d.last_error LIKE ?)", where)
+		for i := 0; i < 4; i++ {
+			args = append(args, "%"+searchValue+"%")
+		}
+	}
+
+	orderColumn := ctx.Query("order[0][column]")
+	starting, _ := strconv.Atoi(ctx.Query("start"))
+
+	if orderColumn != "" {
+		orderType := "ASC"
+		orderColumn, _ := strconv.Atoi(orderColumn)
+
+		if strings.ToUpper(ctx.Query("order[0][dir]")) == "DESC" {
+			orderType = "DESC"
+		}
+
+		if orderColumn > 0 && orderColumn < len(listOrder) && listOrder[orderColumn] != "" {
+			order = fmt.Sprintf("ORDER BY %s %s", listOrder[orderColumn], orderType)
+		} else {
+			order = "ORDER BY d.id DESC"
+		}
+	} else {
+		order = "ORDER BY d.id DESC"
+	}
+
+	if starting != -1 {
+		length, _ := strconv.Atoi(ctx.Query("length"))
+		limit = fmt.Sprintf("LIMIT %v OFFSET %v", length, starting)
+		no = starting + 1
+	}
+
+	if err := repo.DB.Raw(helper.ConvertToInLineQuery(QueryCountAllAdminDataTablesWebhookDelivery)).Scan(&total).Error; err != nil {
+		return result, err
+	}
+
+	if where != "" {
+		query = query + where
+
+		if err := repo.DB.Raw(helper.ConvertToInLineQuery(QueryCountAllAdminDataTablesWebhookDelivery)+where, args...).Scan(&filtered).Error; err != nil {
+			return result, err
+		}
+	} else {
+		filtered = total
+	}
+
+	query = fmt.Sprintf("%s %s %s", query, order, limit)
+
+	rows, err := repo.DB.Raw(helper.ConvertToInLineQuery(query), args...).Rows()
+
+	if err != nil {
+		return result, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			tmp    WebhookDelivery
+			userID int
+			url    string
+		)
+
+		err := rows.Scan(
+			&tmp.ID,
+			&tmp.SubscriptionID,
+			&userID,
+			&url,
+			&tmp.EventID,
+			&tmp.EventType,
+			&tmp.Status,
+			&tmp.Attempts,
+			&tmp.MaxAttempts,
+			&tmp.NextAttemptAt,
+			&tmp.LastStatusCode,
+			&tmp.LastError,
+			&tmp.DeliveredAt,
+			&tmp.CreatedAt,
+		)
+
+		if err != nil {
+			return result, err
+		}
+
+		formatData := FormatWebhookDeliveryData(tmp)
+
+		data = append(data, map[string]any{
+			"no":               no,
+			"id":               formatData.ID,
+			"subscription_id":  formatData.SubscriptionID,
+			"user_id":          userID,
+			"url":              url,
+			"event_id":         formatData.EventID,
+			"event_type":       formatData.EventType,
+			"status":           formatData.Status,
+			"attempts":         formatData.Attempts,
+			"max_attempts":     formatData.MaxAttempts,
+			"next_attempt_at":  formatData.NextAttemptAt,
+			"last_status_code": formatData.LastStatusCode,
+			"last_error":       formatData.LastError,
+			"delivered_at":     formatData.DeliveredAt,
+			"created_at":       formatData.CreatedAt,
+		})
+
+		no++
+	}
+
+	return helper.BuildDatatTables(data, filtered, total), nil
+}
@@ -0,0 +1,42 @@
+package webhook
+
+import "github.com/WeAreAmazingTeam/tcd-backend/user"
+
+type (
+	RequestGetWebhookSubscriptionByID struct {
+		ID int `uri:"id" binding:"required"`
+	}
+
+	RequestCreateWebhookSubscription struct {
+		URL         string   `json:"url" binding:"required,url,max=255"`
+		Events      []string `json:"events" binding:"required,min=1"`
+		Description string   `json:"description" binding:"max=255"`
+		User        user.User
+	}
+
+	RequestUpdateWebhookSubscription struct {
+		URL         string   `json:"url" binding:"required,url,max=255"`
+		Events      []string `json:"events" binding:"required,min=1"`
+		Description string   `json:"description" binding:"max=255"`
+		IsActive    bool     `json:"is_active"`
+		User        user.User
+	}
+
+	RequestDeleteWebhookSubscription struct {
+		User user.User
+	}
+
+	RequestRotateWebhookSecret struct {
+		User user.User
+	}
+)
+
+type (
+	RequestGetWebhookDeliveryByID struct {
+		ID int `uri:"id" binding:"required"`
+	}
+
+	RequestRedeliverWebhook struct {
+		User user.User
+	}
+)
@@ -0,0 +1,56 @@
+package webhook
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/audit"
+	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+	"github.com/WeAreAmazingTeam/tcd-backend/user"
+	"github.com/gin-gonic/gin"
+)
+
+type Service interface {
+	PublishEvent(eventType string, ownerUserID int, data any) error
+	ProcessDueDeliveries() (int, error)
+	RunWorker(interval time.Duration)
+
+	GetWebhookSubscriptions(userID int) ([]WebhookSubscription, error)
+	GetAllWebhookSubscription() ([]WebhookSubscription, error)
+	CreateWebhookSubscription(RequestCreateWebhookSubscription) (WebhookSubscription, error)
+	UpdateWebhookSubscription(RequestGetWebhookSubscriptionByID, RequestUpdateWebhookSubscription) (WebhookSubscription, error)
+	DeleteWebhookSubscription(RequestGetWebhookSubscriptionByID, RequestDeleteWebhookSubscription) (bool, error)
+	RotateWebhookSecret(RequestGetWebhookSubscriptionByID, RequestRotateWebhookSecret) (WebhookSubscription, error)
+	GetWebhookSubscriptionDeliveries(RequestGetWebhookSubscriptionByID, user.User) ([]WebhookDelivery, error)
+
+	GetWebhookDeliveryDetail(RequestGetWebhookDeliveryByID) (WebhookDelivery, []WebhookDeliveryLog, error)
+	RedeliverWebhook(RequestGetWebhookDeliveryByID, RequestRedeliverWebhook) (WebhookDelivery, error)
+	AdminDataTablesWebhookDelivery(*gin.Context) (helper.DataTables, error)
+}
+
+type Config struct {
+	BatchSize   int
+	MaxAttempts int
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+	Timeout     time.Duration
+	AllowHTTP   bool
+	// lets endpoints on loopback and private ranges through, only for local development
+	AllowPrivateNetwork bool
+}
+
+type service struct {
+	repo     Repository
+	client   *http.Client
+	config   Config
+	auditSvc audit.Service
+}
+
+func NewService(repository Repository, config Config, auditService audit.Service) *service {
+	return &service{
+		repo:     repository,
+		client:   newClient(config.Timeout, config.AllowPrivateNetwork),
+		config:   config,
+		auditSvc: auditService,
+	}
+}
@@ -0,0 +1,446 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/WeAreAmazingTeam/tcd-backend/audit"
+	"github.com/WeAreAmazingTeam/tcd-backend/constant"
+	"github.com/WeAreAmazingTeam/tcd-backend/helper"
+	"github.com/WeAreAmazingTeam/tcd-backend/jobqueue"
+	"github.com/WeAreAmazingTeam/tcd-backend/user"
+	"github.com/gin-gonic/gin"
+	"github.com/thanhpk/randstr"
+)
+
+const (
+	// how much of the endpoint response is kept in the delivery log
+	maxLoggedResponse = 1024
+	// deliveries shown to the owner of a subscription
+	subscriptionDeliveriesLimit = 50
+)
+
+// PublishEvent stores one pending delivery for every active subscription of the owner that wants the event,
+// every delivery of the same event shares the event id so receivers can dedupe
+func (svc *service) PublishEvent(eventType string, ownerUserID int, data any) error {
+	if ownerUserID == 0 {
+		return nil
+	}
+
+	subscriptions, err := svc.repo.GetActiveWebhookSubscriptionsByUserID(ownerUserID)
+
+	if err != nil {
+		return err
+	}
+
+	event := Event{
+		ID:        "evt_" + randstr.Hex(12),
+		Type:      eventType,
+		CreatedAt: time.Now(),
+		Data:      data,
+	}
+
+	payload, err := json.Marshal(event)
+
+	if err != nil {
+		return err
+	}
+
+	for _, subscription := range subscriptions {
+		if !subscription.IsSubscribed(eventType) {
+			continue
+		}
+
+		if _, err := svc.repo.SaveWebhookDelivery(WebhookDelivery{
+			SubscriptionID: subscription.ID,
+			EventID:        event.ID,
+			EventType:      eventType,
+			Payload:        string(payload),
+			Status:         StatusPending,
+			MaxAttempts:    svc.config.MaxAttempts,
+			NextAttemptAt:  time.Now(),
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (svc *service) ProcessDueDeliveries() (int, error) {
+	return svc.poller().ProcessDue()
+}
+
+// RunWorker blocks, call it in its own goroutine
+func (svc *service) RunWorker(interval time.Duration) {
+	svc.poller().Run(interval, nil)
+}
+
+func (svc *service) poller() jobqueue.Poller[WebhookDelivery] {
+	return jobqueue.Poller[WebhookDelivery]{
+		Name:         "WEBHOOK",
+		BatchSize:    svc.config.BatchSize,
+		ReleaseStale: svc.repo.ReleaseStaleWebhookDelivery,
+		GetDue:       svc.repo.GetDueWebhookDelivery,
+		Claim:        svc.repo.ClaimWebhookDelivery,
+		Process:      svc.deliver,
+	}
+}
+
+func (svc *service) GetWebhookSubscriptions(userID int) ([]WebhookSubscription, error) {
+	return svc.repo.GetWebhookSubscriptionsByUserID(userID)
+}
+
+func (svc *service) GetAllWebhookSubscription() ([]WebhookSubscription, error) {
+	return svc.repo.GetAllWebhookSubscription()
+}
+
+func (svc *service) CreateWebhookSubscription(req RequestCreateWebhookSubscription) (subscription WebhookSubscription, err error) {
+	if err := svc.validateURL(req.URL); err != nil {
+		return subscription, err
+	}
+
+	events, err := normalizeEvents(req.Events)
+
+	if err != nil {
+		return subscription, err
+	}
+
+	subscription.UserID = req.User.ID
+	subscription.URL = req.URL
+	subscription.Secret = newSecret()
+	subscription.Events = events
+	subscription.Description = req.Description
+	subscription.IsActive = true
+	subscription.CreatedBy = helper.SetNS(strconv.Itoa(req.User.ID))
+
+	newSubscription, err := svc.repo.SaveWebhookSubscription(subscription)
+
+	if err != nil {
+		return newSubscription, err
+	}
+
+	audit.Record(svc.auditSvc, req.User, audit.ActionCreate, audit.EntityWebhookSubscription, newSubscription.ID, nil, newSubscription)
+
+	return newSubscription, nil
+}
+
+func (svc *service) UpdateWebhookSubscription(reqDetail RequestGetWebhookSubscriptionByID, reqUpdate RequestUpdateWebhookSubscription) (subscription WebhookSubscription, err error) {
+	subscription, err = svc.getOwnedSubscription(reqDetail.ID, reqUpdate.User)
+
+	if err != nil {
+		return subscription, err
+	}
+
+	if err := svc.validateURL(reqUpdate.URL); err != nil {
+		return subscription, err
+	}
+
+	events, err := normalizeEvents(reqUpdate.Events)
+
+	if err != nil {
+		return subscription, err
+	}
+
+	before := subscription
+
+	subscription.URL = reqUpdate.URL
+	subscription.Events = events
+	subscription.Description = reqUpdate.Description
+	subscription.IsActive = reqUpdate.IsActive
+	subscription.UpdatedBy = helper.SetNS(strconv.Itoa(reqUpdate.User.ID))
+
+	updatedSubscription, err := svc.repo.UpdateWebhookSubscription(subscription)
+
+	if err != nil {
+		return updatedSubscription, err
+	}
+
+	audit.Record(svc.auditSvc, reqUpdate.User, audit.ActionUpdate, audit.EntityWebhookSubscription, updatedSubscription.ID, before, updatedSubscription)
+
+	return updatedSubscription, nil
+}
+
+func (svc *service) DeleteWebhookSubscription(reqDetail RequestGetWebhookSubscriptionByID, reqDelete RequestDeleteWebhookSubscription) (bool, error) {
+	subscription, err := svc.getOwnedSubscription(reqDetail.ID, reqDelete.User)
+
+	if err != nil {
+		return false, err
+	}
+
+	before := subscription
+
+	if constant.DELETED_BY {
+		subscription.DeletedAt = *helper.SetNowNT()
+		subscription.DeletedBy = helper.SetNS(strconv.Itoa(reqDelete.User.ID))
+	}
+
+	status, err := svc.repo.DeleteWebhookSubscription(subscription)
+
+	if err != nil {
+		return status, err
+	}
+
+	audit.Record(svc.auditSvc, reqDelete.User, audit.ActionDelete, audit.EntityWebhookSubscription, subscription.ID, before, nil)
+
+	return status, nil
+}
+
+// RotateWebhookSecret replaces the secret at once, deliveries still queued are signed with the new one
+func (svc *service) RotateWebhookSecret(reqDetail RequestGetWebhookSubscriptionByID, reqRotate RequestRotateWebhookSecret) (subscription WebhookSubscription, err error) {
+	subscription, err = svc.getOwnedSubscription(reqDetail.ID, reqRotate.User)
+
+	if err != nil {
+		return subscription, err
+	}
+
+	subscription.Secret = newSecret()
+	subscription.UpdatedBy = helper.SetNS(strconv.Itoa(reqRotate.User.ID))
+
+	updatedSubscription, err := svc.repo.UpdateWebhookSubscription(subscription)
+
+	if err != nil {
+		return updatedSubscription, err
+	}
+
+	// the secret itself is json:"-", the audit entry only shows that it changed
+	audit.Record(svc.auditSvc, reqRotate.User, audit.ActionUpdate, audit.EntityWebhookSubscription, updatedSubscription.ID, map[string]any{"secret": "previous"}, map[string]any{"secret": "rotated"})
+
+	return updatedSubscription, nil
+}
+
+func (svc *service) GetWebhookSubscriptionDeliveries(reqDetail RequestGetWebhookSubscriptionByID, owner user.User) ([]WebhookDelivery, error) {
+	subscription, err := svc.getOwnedSubscription(reqDetail.ID, owner)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return svc.repo.GetWebhookDeliveriesBySubscriptionID(subscription.ID, subscriptionDeliveriesLimit)
+}
+
+func (svc *service) GetWebhookDeliveryDetail(req RequestGetWebhookDeliveryByID) (WebhookDelivery, []WebhookDeliveryLog, error) {
+	delivery, err := svc.repo.GetWebhookDeliveryByID(req.ID)
+
+	if err != nil {
+		return delivery, nil, err
+	}
+
+	deliveryLogs, err := svc.repo.GetWebhookDeliveryLogs(delivery.ID)
+
+	if err != nil {
+		return delivery, nil, err
+	}
+
+	return delivery, deliveryLogs, nil
+}
+
+// RedeliverWebhook queues the stored payload again with fresh attempts, the event id stays the same
+func (svc *service) RedeliverWebhook(reqDetail RequestGetWebhookDeliveryByID, reqRedeliver RequestRedeliverWebhook) (WebhookDelivery, error) {
+	delivery, err := svc.repo.GetWebhookDeliveryByID(reqDetail.ID)
+
+	if err != nil {
+		return delivery, err
+	}
+
+	if delivery.Status == StatusPending || delivery.Status == StatusSending {
+		return delivery, errors.New("delivery is already queued")
+	}
+
+	before := delivery
+
+	delivery.Status = StatusPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = time.Now()
+	delivery.LastError = sql.NullString{}
+
+	delivery, err = svc.repo.UpdateWebhookDelivery(delivery)
+
+	if err != nil {
+		return delivery, err
+	}
+
+	audit.Record(svc.auditSvc, reqRedeliver.User, audit.ActionUpdate, audit.EntityWebhookDelivery, delivery.ID, before, delivery)
+
+	return delivery, nil
+}
+
+func (svc *service) AdminDataTablesWebhookDelivery(ctx *gin.Context) (helper.DataTables, error) {
+	return svc.repo.AdminDataTablesWebhookDelivery(ctx)
+}
+
+// getOwnedSubscription hides subscriptions of other users behind the same error as a missing one
+func (svc *service) getOwnedSubscription(id int, owner user.User) (WebhookSubscription, error) {
+	subscription, err := svc.repo.GetWebhookSubscriptionByID(id)
+
+	if err != nil {
+		return subscription, err
+	}
+
+	if subscription.UserID != owner.ID {
+		return WebhookSubscription{}, errors.New("sql: no rows in result set")
+	}
+
+	return subscription, nil
+}
+
+func (svc *service) validateURL(endpoint string) error {
+	parsed, err := url.Parse(endpoint)
+
+	if err != nil || parsed.Host == "" {
+		return errors.New("webhook url is invalid")
+	}
+
+	if parsed.Scheme != "https" && !(svc.config.AllowHTTP && parsed.Scheme == "http") {
+		return errors.New("webhook url must use https")
+	}
+
+	if svc.config.AllowPrivateNetwork {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), svc.config.Timeout)
+	defer cancel()
+
+	return checkHost(ctx, parsed.Hostname())
+}
+
+func (svc *service) deliver(delivery WebhookDelivery) {
+	subscription, err := svc.repo.GetWebhookSubscriptionByID(delivery.SubscriptionID)
+
+	// deleted or paused after the event was queued
+	if err != nil || !subscription.IsActive {
+		delivery.Status = StatusCancelled
+		delivery.LastError = helper.SetNS("subscription is deleted or inactive")
+
+		if _, err := svc.repo.UpdateWebhookDelivery(delivery); err != nil {
+			log.Printf("[WEBHOOK] delivery %d status not saved, err: %s", delivery.ID, err.Error())
+		}
+
+		return
+	}
+
+	statusCode, sendErr := svc.send(subscription, delivery)
+
+	delivery.Attempts++
+	delivery.LastStatusCode = statusCode
+
+	if sendErr == nil {
+		delivery.Status = StatusDelivered
+		delivery.LastError = sql.NullString{}
+		delivery.DeliveredAt = sql.NullTime{Time: time.Now(), Valid: true}
+	} else {
+		delivery.Status = StatusPending
+		delivery.LastError = helper.SetNS(sendErr.Error())
+		delivery.NextAttemptAt = time.Now().Add(jobqueue.Backoff(delivery.Attempts, svc.config.BackoffBase, svc.config.BackoffMax))
+
+		if delivery.Attempts >= delivery.MaxAttempts {
+			delivery.Status = StatusFailed
+		}
+
+		log.Printf("[WEBHOOK] delivery %d of %v to %v failed, [err: %v]", delivery.ID, delivery.EventType, subscription.URL, sendErr.Error())
+	}
+
+	if _, err := svc.repo.UpdateWebhookDelivery(delivery); err != nil {
+		log.Printf("[WEBHOOK] delivery %d status not saved, err: %s", delivery.ID, err.Error())
+	}
+}
+
+// send makes one signed POST and logs it, only a 2xx counts as delivered
+func (svc *service) send(subscription WebhookSubscription, delivery WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	deliveryLog := WebhookDeliveryLog{DeliveryID: delivery.ID, Endpoint: subscription.URL}
+	startedAt := time.Now()
+
+	statusCode, response, err := svc.post(subscription, delivery, body)
+
+	deliveryLog.StatusCode = statusCode
+	deliveryLog.Response = response
+	deliveryLog.DurationMs = time.Since(startedAt).Milliseconds()
+
+	if err == nil && (statusCode < 200 || statusCode > 299) {
+		err = fmt.Errorf("endpoint responded %d", statusCode)
+	}
+
+	if err != nil {
+		deliveryLog.Error = err.Error()
+	}
+
+	if _, logErr := svc.repo.SaveWebhookDeliveryLog(deliveryLog); logErr != nil {
+		log.Printf("[WEBHOOK] delivery %d log not saved, err: %s", delivery.ID, logErr.Error())
+	}
+
+	return statusCode, err
+}
+
+func (svc *service) post(subscription WebhookSubscription, delivery WebhookDelivery, body []byte) (int, string, error) {
+	req, err := http.NewRequest(http.MethodPost, subscription.URL, bytes.NewReader(body))
+
+	if err != nil {
+		return 0, "", err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "TCD-Webhooks/1.0")
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderDelivery, delivery.EventID)
+	req.Header.Set(HeaderSignature, Sign(subscription.Secret, time.Now().Unix(), body))
+
+	res, err := svc.client.Do(req)
+
+	if err != nil {
+		return 0, "", err
+	}
+
+	defer res.Body.Close()
+
+	response, _ := io.ReadAll(io.LimitReader(res.Body, maxLoggedResponse))
+
+	return res.StatusCode, string(response), nil
+}
+
+func normalizeEvents(events []string) (string, error) {
+	result := []string{}
+
+	for _, event := range events {
+		event = strings.TrimSpace(event)
+
+		if !isValidEvent(event) {
+			return "", fmt.Errorf("unknown webhook event %s", event)
+		}
+
+		if !contains(result, event) {
+			result = append(result, event)
+		}
+	}
+
+	return strings.Join(result, ","), nil
+}
+
+func isValidEvent(event string) bool {
+	return contains(helper.WebhookEvents, event)
+}
+
+func contains(list []string, value string) bool {
+	for _, val := range list {
+		if val == value {
+			return true
+		}
+	}
+	return false
+}
+
+func newSecret() string {
+	return "whsec_" + randstr.Hex(24)
+}
@@ -0,0 +1,25 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+)
+
+const (
+	HeaderEvent     = "X-TCD-Event"
+	HeaderDelivery  = "X-TCD-Delivery"
+	HeaderSignature = "X-TCD-Signature"
+)
+
+// Sign returns the X-TCD-Signature value, "t=<unix>,v1=<hex hmac-sha256 of "<unix>.<body>">".
+// Receivers recompute v1 with their secret and should reject timestamps older than a few minutes.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+
+	return fmt.Sprintf("t=%d,v1=%s", timestamp, hex.EncodeToString(mac.Sum(nil)))
+}